@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/xonecas/mysis/internal/cli"
 	"github.com/xonecas/mysis/internal/config"
 	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/mcp"
 	"github.com/xonecas/mysis/internal/session"
 	"github.com/xonecas/mysis/internal/store"
@@ -21,17 +25,29 @@ import (
 var Version = "dev"
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintln(os.Stderr, styles.Error.Render("Error: "+err.Error()))
-		os.Exit(1)
+	// Parse flags
+	flags := features.ParseFlags()
+	if flags.NoColor || styles.PlainFromEnv() {
+		styles.SetPlain()
+	}
+	if flags.ScreenReader {
+		styles.SetScreenReader()
+	}
+
+	if err := run(flags); err != nil {
+		cli.PrintError(os.Stderr, err, flags.Errors)
+		os.Exit(cli.ExitCode(err))
 	}
 }
 
-func run() error {
+func run(flags *features.Flags) error {
 	ctx := context.Background()
 
-	// Parse flags
-	flags := features.ParseFlags()
+	if flags.Errors != cli.ErrorsText && flags.Errors != cli.ErrorsJSON {
+		err := fmt.Errorf("--errors=%s must be text or json", flags.Errors)
+		flags.Errors = cli.ErrorsText
+		return err
+	}
 
 	// Handle version flag
 	if flags.ShowVersion {
@@ -49,23 +65,95 @@ func run() error {
 	if err := setupLogging(flags); err != nil {
 		return err
 	}
+	toolResults, err := llm.ParseToolResultDisplay(flags.ToolResults)
+	if err != nil {
+		return err
+	}
+
+	// Subcommands; no subcommand or `chat` starts a conversation
+	command, args := "chat", []string(nil)
+	if len(flags.Args) > 0 {
+		command, args = flags.Args[0], flags.Args[1:]
+	}
+	switch command {
+	case "chat":
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected arguments %q - pass the first message with -m", strings.Join(args, " "))
+		}
+	case "session", "config", "db", "auth":
+	case "doctor":
+		if len(args) > 0 {
+			return errors.New(cli.DoctorUsage)
+		}
+	case "prune":
+		if len(args) > 0 {
+			return errors.New(cli.PruneUsage)
+		}
+	case "usage":
+		if len(args) > 0 {
+			return errors.New(cli.UsageUsage)
+		}
+	case "run":
+		if len(args) != 1 {
+			return errors.New(cli.RunUsage)
+		}
+	case "fleet":
+		switch {
+		case len(args) == 1 && (args[0] == "run" || args[0] == "status"):
+		case len(args) == 2 && args[0] == "start":
+		default:
+			return errors.New(cli.FleetUsage)
+		}
+	default:
+		return fmt.Errorf("unknown command %q - see mysis --help", command)
+	}
+
+	// Handle `mysis fleet status` (reads the status file, needs no config)
+	if command == "fleet" && args[0] == "status" {
+		return cli.FleetStatusCmd()
+	}
+
+	// Handle `mysis doctor` (checks the config itself)
+	if command == "doctor" {
+		return cli.DoctorCmd(ctx, flags.ConfigPath, flags.Profile)
+	}
 
 	// Check config path
 	if flags.ConfigPath == "" {
-		fmt.Fprintln(os.Stderr, styles.Error.Render("Error: config file not found"))
-		fmt.Fprintln(os.Stderr, "Tried: ./config.toml and ~/.config/mysis/config.toml")
-		return fmt.Errorf("config file not found")
+		return configError(errors.New("config file not found (tried ./config.toml and ~/.config/mysis/config.toml)"))
+	}
+
+	// Handle `mysis config ...`
+	if command == "config" {
+		return configError(cli.ConfigCmd(flags.ConfigPath, args))
 	}
 
 	log.Info().
 		Str("version", Version).
 		Str("config", flags.ConfigPath).
+		Str("profile", flags.Profile).
 		Msg("Starting Mysis")
 
 	// Load config
-	cfg, err := config.Load(flags.ConfigPath)
+	cfg, err := config.LoadProfile(flags.ConfigPath, flags.Profile)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return configError(fmt.Errorf("failed to load config: %w", err))
+	}
+	if cfg.TUI.ScreenReader && !styles.ScreenReader() {
+		styles.SetScreenReader()
+	}
+	if cfg.TUI.ReducedMotion {
+		styles.SetReducedMotion()
+	}
+	features.SetLogRotation(cfg.Logs)
+	if !styles.Plain() {
+		theme, _ := styles.ResolveTheme(cfg.Theme.Name, cfg.Theme.Colors) // Checked by config.Load
+		styles.ApplyTheme(theme)
+	}
+
+	// Handle `mysis auth ...`
+	if command == "auth" {
+		return cli.AuthCmd(cfg, args)
 	}
 
 	// Open database
@@ -82,9 +170,39 @@ func run() error {
 	// Create session manager
 	sessionMgr := session.NewManager(db)
 
+	// Handle `mysis session ...` and `mysis db ...`
+	switch command {
+	case "session":
+		listOpts, err := sessionListOptions(flags)
+		if err != nil {
+			return err
+		}
+		return cli.SessionCmd(sessionMgr, args, listOpts)
+	case "db":
+		return cli.DBCmd(db, args)
+	case "prune":
+		listOpts, err := sessionListOptions(flags)
+		if err != nil {
+			return err
+		}
+		return cli.PruneCmd(sessionMgr, listOpts.Filter, flags.DryRun, flags.Yes)
+	case "usage":
+		var since time.Time
+		if flags.Since != "" {
+			if since, err = session.ParseSince(flags.Since, time.Now()); err != nil {
+				return fmt.Errorf("--since %w", err)
+			}
+		}
+		return cli.UsageCmd(sessionMgr, since, flags.By, flags.Format)
+	}
+
 	// Handle --list-sessions flag
 	if flags.ListSessions {
-		return cli.ListSessionsCmd(sessionMgr)
+		listOpts, err := sessionListOptions(flags)
+		if err != nil {
+			return err
+		}
+		return cli.ListSessionsCmd(sessionMgr, listOpts)
 	}
 
 	// Handle --delete-session flag
@@ -93,7 +211,10 @@ func run() error {
 	}
 
 	// Load credentials
-	creds, err := config.LoadCredentials()
+	creds, err := config.LoadCredentials(cfg.Keyring, cli.PromptPassphrase)
+	if errors.Is(err, config.ErrWrongPassphrase) {
+		return err
+	}
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to load credentials, using empty credentials")
 		creds = &config.Credentials{}
@@ -102,6 +223,35 @@ func run() error {
 	// Initialize provider registry
 	registry := features.InitializeProviders(cfg, creds)
 
+	// Handle `mysis fleet run` and `mysis fleet start FILE`
+	if command == "fleet" {
+		if args[0] == "start" {
+			if err := cfg.LoadFleet(args[1]); err != nil {
+				return err
+			}
+		}
+		return cli.FleetRunCmd(ctx, cfg, sessionMgr, registry, db)
+	}
+
+	// Handle --continue: resume the most recently active named session
+	if flags.Continue {
+		if flags.SessionName != "" {
+			return fmt.Errorf("use either --continue or --session, not both")
+		}
+		name, err := sessionMgr.LatestName()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return fmt.Errorf("no named session to continue - start one with -s NAME")
+		}
+		flags.SessionName = name
+	}
+
+	if flags.Split != "" && !flags.TUI {
+		return fmt.Errorf("--split needs --tui")
+	}
+
 	// Determine provider and model
 	providerResult, err := sessionMgr.SelectProvider(cfg, flags.SessionName, flags.ProviderName)
 	if err != nil {
@@ -109,17 +259,24 @@ func run() error {
 	}
 	selectedProvider := providerResult.Provider
 	selectedModel := providerResult.Model
+	if flags.Model != "" {
+		selectedModel = flags.Model
+	}
 
 	// Verify provider exists in config
 	providerCfg, ok := cfg.Providers[selectedProvider]
 	if !ok {
-		return fmt.Errorf("provider '%s' not found in config", selectedProvider)
+		return configError(fmt.Errorf("provider '%s' not found in config", selectedProvider))
 	}
 
-	// Create provider instance
-	prov, err := registry.Create(selectedProvider, selectedModel, providerCfg.Temperature)
+	// Create provider instance, with sampling flags over the provider config
+	opts, err := flags.ApplySampling(features.ProviderOptions(providerCfg))
+	if err != nil {
+		return err
+	}
+	prov, err := registry.Create(selectedProvider, selectedModel, opts)
 	if err != nil {
-		return fmt.Errorf("failed to create provider: %w", err)
+		return &cli.ExitError{Code: cli.ExitProvider, Err: fmt.Errorf("failed to create provider: %w", err)}
 	}
 	defer func() {
 		if err := prov.Close(); err != nil {
@@ -127,16 +284,29 @@ func run() error {
 		}
 	}()
 
+	if flags.Model != "" {
+		if err := features.ValidateModel(ctx, prov, selectedModel); err != nil {
+			return &cli.ExitError{Code: cli.ExitProvider, Err: err}
+		}
+	}
+
 	log.Info().
 		Str("provider", selectedProvider).
 		Str("model", selectedModel).
 		Msg("Provider initialized")
 
 	// Initialize MCP client
-	mcpClient := mcp.NewClient(cfg.MCP.Upstream)
-	proxy := mcp.NewProxy(mcpClient)
+	proxy := mcp.NewProxy(mcp.NewUpstream(cfg.MCP.Upstream))
 
+	// Interactive sessions can go on without the game; scripts and one-shot
+	// runs fail so wrappers can tell
+	interactive := flags.TUI || flags.Autoplay != "" || flags.Playbook != ""
+	batch := command == "run" || flags.Message != "" || flags.Stdin || (!interactive && !cli.StdinIsTerminal())
 	if err := proxy.Initialize(ctx); err != nil {
+		if batch {
+			_ = proxy.Close()
+			return &cli.ExitError{Code: cli.ExitMCP, Err: fmt.Errorf("failed to initialize MCP: %w", err)}
+		}
 		log.Warn().Err(err).Msg("Failed to initialize MCP - continuing without game tools")
 	} else {
 		log.Info().Str("upstream", cfg.MCP.Upstream).Msg("MCP proxy initialized")
@@ -155,6 +325,13 @@ func run() error {
 	sessionID := sessionResult.SessionID
 	sessionInfo := sessionResult.SessionInfo
 
+	// Record a --model override on the session, so resuming it keeps the model
+	if flags.Model != "" {
+		if err := sessionMgr.SetProvider(sessionID, selectedProvider, selectedModel); err != nil {
+			log.Warn().Err(err).Msg("Failed to record model on session")
+		}
+	}
+
 	// Register credential tools (session-scoped)
 	proxy.RegisterTool(
 		mcp.NewSaveCredentialsTool(),
@@ -164,12 +341,25 @@ func run() error {
 		mcp.NewGetCredentialsTool(),
 		mcp.MakeGetCredentialsHandler(db, sessionID),
 	)
+
+	// Register agent messaging tools (inbox of the session name)
+	proxy.RegisterTool(
+		mcp.NewSendToAgentTool(),
+		mcp.MakeSendToAgentHandler(sessionMgr, sessionID),
+	)
+	proxy.RegisterTool(
+		mcp.NewReadAgentInboxTool(),
+		mcp.MakeReadAgentInboxHandler(sessionMgr, sessionID),
+	)
+
+	// Register the tools of plugins
+	features.RegisterPluginTools(proxy, features.Plugins())
 	log.Debug().
 		Str("session_id", sessionID).
 		Int("local_tools", proxy.LocalToolCount()).
-		Msg("Registered local credential tools")
+		Msg("Registered local tools")
 
-	// Get available tools (includes upstream + local credential tools)
+	// Get available tools (includes upstream + local tools)
 	tools, err := proxy.ListTools(ctx)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to list tools - continuing without tools")
@@ -184,25 +374,88 @@ func run() error {
 		return err
 	}
 
-	// Load system prompt from markdown file if provided
-	if flags.SystemFile != "" {
-		systemPrompt, err := features.LoadSystemPromptFromFile(flags.SystemFile)
+	// Load system prompt from markdown file or template if provided
+	if flags.SystemFile != "" || flags.Template != "" {
+		systemPrompt, err := loadSystemPrompt(flags, selectedProvider, selectedModel)
 		if err != nil {
 			return err
 		}
-		if !features.HistoryHasSystemPrompt(history, systemPrompt) {
+		switch {
+		case flags.Template != "":
+			// Templates render differently between runs ({{date}}), so the
+			// rendered prompt replaces the one of an earlier run
+			history = features.SetSystemPrompt(history, systemPrompt)
+		case !features.HistoryHasSystemPrompt(history, systemPrompt):
 			history = features.PrependSystemPrompt(history, systemPrompt)
 		}
+	} else {
+		// Restore a prompt set in an earlier run with /system
+		systemPrompt, err := sessionMgr.SystemPrompt(sessionID)
+		if err != nil {
+			return err
+		}
+		if systemPrompt == "" && len(history) == 0 {
+			// A new session gets the configured default prompt, recorded on
+			// the session so resuming it keeps the prompt
+			if systemPrompt, err = features.DefaultSystemPrompt(cfg, selectedProvider); err != nil {
+				return err
+			}
+			if systemPrompt != "" {
+				if err := sessionMgr.SetSystemPrompt(sessionID, systemPrompt); err != nil {
+					log.Warn().Err(err).Msg("Failed to record system prompt")
+				}
+			}
+		}
+		if systemPrompt != "" {
+			history = features.SetSystemPrompt(history, systemPrompt)
+		}
+	}
+
+	// Load autoplay playbook if provided
+	var playbook *features.Playbook
+	if flags.Playbook != "" {
+		if flags.Autoplay != "" {
+			return fmt.Errorf("use either --autoplay or --playbook, not both")
+		}
+		playbook, err = features.LoadPlaybook(flags.Playbook)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Handle `mysis run SCRIPT`
+	if command == "run" {
+		if interactive || flags.Message != "" || flags.Stdin {
+			return fmt.Errorf("mysis run cannot be combined with --tui, --autoplay, --playbook, --message or --stdin")
+		}
+		return cli.RunScript(ctx, cfg, sessionMgr, sessionID, prov, selectedModel, registry, proxy, tools, history, args[0], toolResults)
+	}
+
+	// Handle one-shot --message, --stdin or piped input
+	if flags.Message == "" && (flags.Stdin || (!interactive && !cli.StdinIsTerminal())) {
+		flags.Message, err = cli.ReadStdinMessage()
+		if err != nil {
+			return err
+		}
+	}
+	if flags.Message != "" {
+		if interactive {
+			return fmt.Errorf("--message and --stdin cannot be combined with --tui, --autoplay or --playbook")
+		}
+		return cli.RunOnce(ctx, cfg, sessionMgr, sessionID, prov, proxy, tools, history, flags.Message, flags.Quiet, toolResults)
 	}
 
 	// Delegate to TUI or CLI based on flag
 	if flags.TUI {
 		// Use TUI mode
-		return tui.Start(ctx, sessionMgr, sessionID, prov, proxy, tools, history)
+		if flags.Split != "" {
+			return tui.StartSplit(ctx, cfg, sessionMgr, sessionID, prov, selectedModel, registry, proxy, tools, history, flags.Autoplay, playbook, flags.Split)
+		}
+		return tui.Start(ctx, cfg, sessionMgr, sessionID, prov, selectedModel, registry, proxy, tools, history, flags.Autoplay, playbook)
 	}
 
 	// Use CLI mode
-	return cli.Start(ctx, sessionMgr, sessionID, sessionInfo, prov, proxy, tools, history, flags.Autoplay, selectedProvider, selectedModel)
+	return cli.Start(ctx, cfg, sessionMgr, sessionID, sessionInfo, prov, registry, proxy, tools, history, flags.Autoplay, playbook, selectedProvider, selectedModel, toolResults)
 }
 
 func setupLogging(flags *features.Flags) error {
@@ -214,11 +467,74 @@ func setupLogging(flags *features.Flags) error {
 	}
 
 	// CLI mode: log to stderr
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
-	if flags.Debug {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, NoColor: styles.Plain()})
+	switch {
+	case flags.Debug:
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	} else {
+	case flags.Quiet:
+		// Scripts only want the response; errors still reach stderr
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	default:
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	}
 	return nil
 }
+
+// configError marks err as a config error for the exit code.
+func configError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &cli.ExitError{Code: cli.ExitConfig, Err: err}
+}
+
+// sessionListOptions returns the session list and prune filters and the
+// list format of flags. --provider filters by provider here.
+func sessionListOptions(flags *features.Flags) (cli.SessionListOptions, error) {
+	filter := store.SessionFilter{
+		Provider:     flags.ProviderName,
+		NameContains: flags.NameContains,
+	}
+	if flags.Since != "" {
+		since, err := session.ParseSince(flags.Since, time.Now())
+		if err != nil {
+			return cli.SessionListOptions{}, fmt.Errorf("--since %w", err)
+		}
+		filter.Since = since
+	}
+	if flags.OlderThan != "" {
+		before, err := session.ParseSince(flags.OlderThan, time.Now())
+		if err != nil {
+			return cli.SessionListOptions{}, fmt.Errorf("--older-than %w", err)
+		}
+		filter.Before = before
+	}
+	filter.Anonymous = flags.Anonymous
+	return cli.NewSessionListOptions(filter, flags.Format)
+}
+
+// loadSystemPrompt loads the system prompt of --file or --template. Templates
+// get the variables session, provider, model and date, and those of --var.
+func loadSystemPrompt(flags *features.Flags, providerName, model string) (string, error) {
+	if flags.Template == "" {
+		return features.LoadSystemPromptFromFile(flags.SystemFile)
+	}
+	if flags.SystemFile != "" {
+		return "", fmt.Errorf("use either --file or --template, not both")
+	}
+
+	vars := features.TemplateVars(flags.SessionName, providerName, model)
+	for _, v := range flags.TemplateVars {
+		name, value, err := features.ParseTemplateVar(v)
+		if err != nil {
+			return "", err
+		}
+		vars[name] = value
+	}
+
+	dir, err := features.TemplatesDir()
+	if err != nil {
+		return "", err
+	}
+	return features.LoadTemplate(dir, flags.Template, vars)
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// AuthUsage is shown with auth usage errors.
+const AuthUsage = "Usage: mysis auth set PROVIDER | list | encrypt"
+
+// AuthCmd runs `mysis auth ...`; args excludes "auth".
+func AuthCmd(cfg *config.Config, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "set":
+		return AuthSetCmd(cfg, args[1])
+	case len(args) == 1 && args[0] == "list":
+		return AuthListCmd(cfg)
+	case len(args) == 1 && args[0] == "encrypt":
+		return AuthEncryptCmd(cfg)
+	default:
+		return errors.New(AuthUsage)
+	}
+}
+
+// AuthSetCmd reads an API key from the terminal, or from stdin when piped,
+// and stores it under the key name of provider (its api_key_name, or the
+// provider name) in the OS keyring, or in the credentials file when there
+// is no keyring or [keyring] excludes the key. A name that is not a configured provider is used as the
+// key name.
+func AuthSetCmd(cfg *config.Config, name string) error {
+	keyName := name
+	if provCfg, ok := cfg.Providers[name]; ok && provCfg.APIKeyName != "" {
+		keyName = provCfg.APIKeyName
+	}
+	if err := config.ValidateKeyName(keyName); err != nil {
+		return err
+	}
+
+	key, err := readAPIKey(keyName)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("no API key given")
+	}
+
+	source, err := config.StoreAPIKey(keyName, key, cfg.Keyring, PromptPassphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render(fmt.Sprintf("Stored API key '%s' in the %s", keyName, source)))
+	return nil
+}
+
+// readAPIKey reads a key without echo from the terminal, or the first line
+// of piped stdin.
+func readAPIKey(keyName string) (string, error) {
+	if !StdinIsTerminal() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read API key: %w", err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	fmt.Printf("API key for '%s': ", keyName)
+	key, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("read API key: %w", err)
+	}
+	return strings.TrimSpace(string(key)), nil
+}
+
+// AuthListCmd lists the API key of each configured provider and where it is
+// stored, plus keys in the credentials file no provider uses. Keys are
+// masked.
+func AuthListCmd(cfg *config.Config) error {
+	creds, err := config.LoadCredentials(cfg.Keyring, PromptPassphrase)
+	if err != nil {
+		return fmt.Errorf("load credentials: %w", err)
+	}
+
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println(styles.Brand.Render("API keys:"))
+	var used []string
+	for _, name := range names {
+		keyName := cfg.Providers[name].APIKeyName
+		if keyName == "" {
+			keyName = name
+		}
+		used = append(used, keyName)
+		fmt.Printf("  %-16s %-16s %s\n", name, keyName, keyStatus(creds, keyName))
+	}
+	for _, keyName := range creds.KeyNames() {
+		if !slices.Contains(used, keyName) {
+			fmt.Printf("  %-16s %-16s %s\n", "(unused)", keyName, keyStatus(creds, keyName))
+		}
+	}
+	return nil
+}
+
+// AuthEncryptCmd encrypts the credentials file with a passphrase read twice
+// from the terminal, or from $MYSIS_CREDENTIALS_PASSPHRASE. Later runs read
+// the passphrase the same way at startup.
+func AuthEncryptCmd(cfg *config.Config) error {
+	passphrase := os.Getenv(config.PassphraseEnv)
+	if passphrase == "" {
+		var err error
+		if passphrase, err = readPassphrase("New passphrase: "); err != nil {
+			return err
+		}
+		confirm, err := readPassphrase("Repeat the passphrase: ")
+		if err != nil {
+			return err
+		}
+		if confirm != passphrase {
+			return errors.New("the passphrases do not match")
+		}
+	}
+	if passphrase == "" {
+		return errors.New("no passphrase given")
+	}
+
+	if err := config.EncryptCredentials(cfg.Keyring, passphrase); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render("Encrypted the credentials file"))
+	fmt.Println(styles.Muted.Render("mysis asks for the passphrase at startup, or reads it from " + config.PassphraseEnv))
+	return nil
+}
+
+// PromptPassphrase reads the passphrase of the encrypted credentials file
+// from the terminal, see config.PassphraseFunc.
+func PromptPassphrase() (string, error) {
+	return readPassphrase("Credentials passphrase: ")
+}
+
+// readPassphrase reads a passphrase without echo from the terminal.
+func readPassphrase(prompt string) (string, error) {
+	if !StdinIsTerminal() {
+		return "", fmt.Errorf("the credentials passphrase needs a terminal: set %s", config.PassphraseEnv)
+	}
+	fmt.Print(prompt)
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// keyStatus describes the key stored under keyName, e.g.
+// "keyring  ****a1b2".
+func keyStatus(creds *config.Credentials, keyName string) string {
+	key, source := creds.LookupAPIKey(keyName)
+	if key == "" {
+		return styles.Muted.Render("not set")
+	}
+	return fmt.Sprintf("%-16s %s", source, maskKey(key))
+}
+
+// maskKey hides all but the last four characters of long keys.
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
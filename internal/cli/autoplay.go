@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/xonecas/mysis/internal/constants"
 	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/provider"
 	"github.com/xonecas/mysis/internal/styles"
 )
@@ -17,21 +18,114 @@ import (
 // initAutoplayService initializes the autoplay service with CLI-specific callbacks.
 // This should be called once when creating the App.
 func (app *App) initAutoplayService() {
-	app.autoplayService = features.NewAutoplayService(features.AutoplayCallbacks{
-		OnStarted: func(message string, interval time.Duration) {
-			fmt.Println(styles.Secondary.Render(fmt.Sprintf("Autoplay started: \"%s\"", message)))
-			fmt.Println(styles.Muted.Render(fmt.Sprintf("Interval: %ds (%d avg tool calls × %ds/tick)",
-				int(interval.Seconds()),
-				constants.AvgToolCallsPerTurn,
+	app.autoplayService = features.NewAutoplayService(app.cfg.Autoplay, features.AutoplayCallbacks{
+		OnStarted: func(status features.AutoplayStatus) {
+			fmt.Println(styles.Secondary.Render(fmt.Sprintf("Autoplay started: \"%s\"", status.Message)))
+			if status.Goals > 1 {
+				fmt.Println(styles.Muted.Render(fmt.Sprintf("Playbook: goal 1 of %d", status.Goals)))
+			}
+			fmt.Println(styles.Muted.Render(fmt.Sprintf("Interval: adaptive, %s–%s (tool calls × %ds/tick)",
+				status.MinInterval, status.MaxInterval,
 				int(constants.GameTickDuration.Seconds()))))
+			if status.Jitter > 0 {
+				fmt.Println(styles.Muted.Render(fmt.Sprintf("Jitter: ±%.0f%%", status.Jitter*100)))
+			}
+			if schedule := status.ScheduleText(); schedule != "" {
+				fmt.Println(styles.Muted.Render("Schedule: " + schedule))
+			}
+			fmt.Println(styles.Muted.Render("Limits: " + status.Limits.String()))
+			if status.DryRun {
+				fmt.Println(styles.Secondary.Render("Dry run: game actions are simulated, state queries are real"))
+			}
+			if status.Safe {
+				fmt.Println(styles.Secondary.Render("Safe mode: tools limited to " + strings.Join(app.proxy.ToolFilter(), ", ")))
+			}
+			if status.Limits.MaxCost > 0 && features.PricingFor(app.cfg, app.currentProvider().Name()) == (llm.Pricing{}) {
+				fmt.Println(styles.Muted.Render("Warning: no input_cost/output_cost configured for this provider - cost budget cannot be reached"))
+			}
 			fmt.Println(styles.Muted.Render("Type '/autoplay stop' to stop"))
 			fmt.Println()
 		},
-		OnStopped: func() {
+		OnStopped: func(summary features.AutoplaySummary) {
 			fmt.Println(styles.Muted.Render("Autoplay stopped"))
+			for _, line := range summary.Lines() {
+				fmt.Println(styles.Muted.Render(line))
+			}
+		},
+		OnPaused: func() {
+			fmt.Println(styles.Muted.Render("Autoplay paused - type '/autoplay resume' to continue"))
+		},
+		OnResumed: func() {
+			fmt.Println(styles.Muted.Render("Autoplay resumed"))
+		},
+		OnGoalChanged: func(status features.AutoplayStatus) {
+			fmt.Println(styles.Secondary.Render(fmt.Sprintf("Playbook goal %d/%d: \"%s\"", status.Goal, status.Goals, status.Message)))
+		},
+		OnInterjection: func(message string) {
+			userMsg := provider.Message{
+				Role:      "user",
+				Content:   message,
+				CreatedAt: time.Now(),
+			}
+
+			app.mu.Lock()
+			app.history = append(app.history, userMsg)
+			app.mu.Unlock()
+
+			if err := app.sessionMgr.SaveMessage(app.sessionID, userMsg); err != nil {
+				log.Warn().Err(err).Msg("Failed to save queued message")
+			}
+			fmt.Println(styles.Secondary.Render("Sending queued message: ") + message)
+		},
+		OnRefine: func(ctx context.Context, goal string) (string, error) {
+			fmt.Println(styles.Muted.Render("Refining autoplay goal..."))
+			app.mu.Lock()
+			historyCopy := make([]provider.Message, len(app.history))
+			copy(historyCopy, app.history)
+			app.mu.Unlock()
+
+			return llm.RefineGoal(ctx, llm.RefineOptions{
+				Provider: app.currentProvider(),
+				History:  historyCopy,
+				Goal:     goal,
+			})
+		},
+		OnGoalRefined: func(status features.AutoplayStatus) {
+			fmt.Println(styles.Secondary.Render(fmt.Sprintf("Autoplay goal refined: \"%s\"", status.Message)))
+			fmt.Println()
+		},
+		OnFailover: func(ctx context.Context, providerName string) error {
+			if err := app.failover(providerName); err != nil {
+				return err
+			}
+			fmt.Println(styles.Error.Render("Provider kept failing - autoplay switched to " + providerName))
+			return nil
+		},
+		OnWatchdog: func(match features.WatchdogMatch, action string) {
+			fmt.Println(styles.Error.Render("Autoplay watchdog: " + match.String() + " - " + action))
+		},
+		OnNudge: func(message string) {
+			app.addMessage(provider.Message{
+				Role:      "system",
+				Content:   message,
+				CreatedAt: time.Now(),
+			})
 		},
-		OnTurn: func(ctx context.Context, message string) error {
-			fmt.Println(styles.Muted.Render("─── Autoplay Turn ───"))
+		OnStopCondition: func(match features.StopConditionMatch) {
+			fmt.Println(styles.Error.Render("Autoplay stop condition met - " + match.String()))
+		},
+		OnAlert: func(alert features.AlertMatch) {
+			fmt.Println(styles.Error.Render("Autoplay alert - " + alert.String()))
+		},
+		OnSchedule: func(inWindow bool, next time.Time) {
+			if inWindow {
+				fmt.Println(styles.Muted.Render("Autoplay schedule window opened"))
+				return
+			}
+			fmt.Println(styles.Muted.Render("Outside autoplay schedule - next turn at " + next.Format("Mon 15:04")))
+		},
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			fmt.Println(styles.Muted.Render(styles.Rule("Autoplay Turn")))
 			fmt.Println(styles.Brand.Render("> ") + message)
 			log.Debug().Msg("About to process turn")
 
@@ -50,63 +144,115 @@ func (app *App) initAutoplayService() {
 			}
 
 			// Process turn
-			if err := app.processTurn(ctx); err != nil {
+			result, err := app.processTurn(ctx, app.currentProvider())
+			if err != nil {
 				fmt.Fprintln(os.Stderr, styles.Error.Render("Error: "+err.Error()))
-				// Don't stop autoplay on errors - just log and continue
-				log.Warn().Err(err).Msg("Autoplay turn failed, continuing...")
 			}
 
 			fmt.Println() // Blank line after response
-			return nil
+			// Failed turns count towards the circuit breaker
+			return result, err
+		},
+		OnBreaker: func(open bool, until time.Time) {
+			if open {
+				fmt.Println(styles.Error.Render("Autoplay circuit breaker open - retrying at " + until.Format("15:04:05")))
+				return
+			}
+			fmt.Println(styles.Success.Render("Autoplay circuit breaker closed - turns succeeding again"))
 		},
 		OnError: func(err error) {
 			log.Error().Err(err).Msg("Autoplay error")
 		},
 	})
-}
-
-// startAutoplayFromFlag starts autoplay from CLI flag.
-func (app *App) startAutoplayFromFlag(ctx context.Context, message string) error {
-	return app.autoplayService.Start(ctx, message)
+	app.autoplayService.SetToolGate(app.proxy)
+	app.autoplayService.SetScript(app.script)
 }
 
 // handleAutoplayCommand handles /autoplay commands
-func (app *App) handleAutoplayCommand(ctx context.Context, input string) error {
-	parts := strings.Fields(input)
-
-	if len(parts) == 1 {
-		// Just "/autoplay" - show status
-		status := app.autoplayService.Status()
-		if status.Enabled {
-			fmt.Println(styles.Secondary.Render(fmt.Sprintf("Autoplay active: \"%s\"", status.Message)))
-		} else {
-			fmt.Println(styles.Muted.Render("Autoplay not active"))
-			fmt.Println(styles.Muted.Render("Usage: /autoplay <message>"))
-		}
+func (app *App) handleAutoplayCommand(ctx context.Context, args []string) error {
+	cmd, err := features.ParseAutoplayCommand(args)
+	if err != nil {
+		return err
+	}
+	if cmd.Action == features.AutoplayActionStatus {
+		app.printAutoplayStatus()
 		return nil
 	}
+	return app.autoplayService.Execute(ctx, cmd)
+}
 
-	// Check for "stop" subcommand
-	if parts[1] == "stop" {
-		if err := app.autoplayService.Stop(); err != nil {
-			fmt.Println(styles.Muted.Render(err.Error()))
-		} else {
-			fmt.Println(styles.Success.Render("Autoplay stopped"))
+// printAutoplayStatus prints the autoplay state for /autoplay status.
+func (app *App) printAutoplayStatus() {
+	status := app.autoplayService.Status()
+	if status.Enabled {
+		state := "active"
+		if status.Paused {
+			state = "paused"
 		}
-		return nil
+		fmt.Println(styles.Secondary.Render(fmt.Sprintf("Autoplay %s: \"%s\"", state, status.Message)))
+		if status.DryRun {
+			fmt.Println(styles.Muted.Render("Dry run: game actions are simulated"))
+		}
+		if status.Safe {
+			fmt.Println(styles.Muted.Render("Safe mode: tools limited to " + strings.Join(app.proxy.ToolFilter(), ", ")))
+		}
+		for _, line := range status.DetailLines() {
+			fmt.Println(styles.Muted.Render(line))
+		}
+	} else {
+		fmt.Println(styles.Muted.Render("Autoplay not active"))
+		fmt.Println(styles.Muted.Render("Usage: /autoplay <message> [--turns N] [--for 2h] [--dry-run]"))
 	}
-
-	// Join all parts after /autoplay as the message
-	message := strings.Join(parts[1:], " ")
-
-	if message == "" {
-		return fmt.Errorf("missing message for autoplay")
+	if schedule := status.ScheduleText(); schedule != "" {
+		fmt.Println(styles.Muted.Render("Schedule: " + schedule))
+	}
+	if names := app.autoplayService.GoalNames(); len(names) > 0 {
+		fmt.Println(styles.Muted.Render("Goals: " + strings.Join(names, ", ")))
 	}
+}
 
-	// Start autoplay
-	if err := app.autoplayService.Start(ctx, message); err != nil {
-		return fmt.Errorf("%s - use '/autoplay stop' first", err.Error())
+// failover switches the conversation to the named backup provider, records
+// the switch on the session and in its history, and closes the old provider.
+func (app *App) failover(providerName string) error {
+	prov, model, err := features.CreateProvider(app.cfg, app.registry, providerName)
+	if err != nil {
+		return err
 	}
 
+	oldName := app.replaceProvider(prov, model)
+	app.addMessage(provider.Message{
+		Role:      "system",
+		Content:   features.FailoverNote(oldName, providerName, model),
+		CreatedAt: time.Now(),
+	})
 	return nil
 }
+
+// switchProvider switches the conversation to the named provider and
+// model for /provider and /model.
+func (app *App) switchProvider(ctx context.Context, name, model string) error {
+	prov, model, err := features.SwitchProvider(ctx, app.cfg, app.registry, name, model)
+	if err != nil {
+		return err
+	}
+	app.replaceProvider(prov, model)
+	return nil
+}
+
+// replaceProvider makes prov the provider for the next turn, closes the old
+// provider and records the switch on the session. Returns the old name.
+func (app *App) replaceProvider(prov provider.Provider, model string) string {
+	app.mu.Lock()
+	old := app.provider
+	app.provider = prov
+	app.model = model
+	app.mu.Unlock()
+
+	if err := old.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close provider")
+	}
+	if err := app.sessionMgr.SetProvider(app.sessionID, prov.Name(), model); err != nil {
+		log.Warn().Err(err).Msg("Failed to record provider switch")
+	}
+	return old.Name()
+}
@@ -1,15 +1,17 @@
 package cli
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
 	"github.com/xonecas/mysis/internal/features"
 	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/mcp"
@@ -20,21 +22,29 @@ import (
 
 // App holds the application state
 type App struct {
+	cfg             *config.Config
 	provider        provider.Provider
+	model           string             // Model of provider
+	registry        *provider.Registry // Creates backup providers for failover
 	proxy           *mcp.Proxy
 	tools           []mcp.Tool
 	history         []provider.Message
 	sessionMgr      *session.Manager
 	sessionID       string
-	autoplayService *features.Service // Autoplay service (display-agnostic)
-	mu              sync.Mutex        // Protects history
+	autoplayService *features.Service   // Autoplay service (display-agnostic)
+	script          *features.Script    // Session script from [scripts], nil for none
+	commands        *features.Commands  // Slash commands
+	stats           *llm.Stats          // Metrics for this run
+	failures        *llm.FailureTracker // Repeated tool failures across turns
+	events          *llm.EventLog       // Event log of the session
+	quiet           bool                // Suppress tool and response output (one-shot --quiet)
+	toolResults     llm.ToolResultDisplay
+	mu              sync.Mutex // Protects history and provider
 }
 
 // printWelcome displays the welcome banner.
 func printWelcome(provider, model string, toolCount int, sessionInfo string) {
-	fmt.Println(styles.Brand.Render("╔══════════════════════════════════════╗"))
-	fmt.Println(styles.Brand.Render("║") + "      " + styles.BrandBold.Render("Mysis") + " - SpaceMolt Agent CLI     " + styles.Brand.Render("║"))
-	fmt.Println(styles.Brand.Render("╚══════════════════════════════════════╝"))
+	fmt.Println(styles.Banner(styles.BrandBold.Render("Mysis") + " - SpaceMolt Agent CLI"))
 	fmt.Println()
 	fmt.Println(styles.Muted.Render(fmt.Sprintf("Provider: %s (%s)", provider, model)))
 	fmt.Println(styles.Muted.Render(fmt.Sprintf("Tools: %d available", toolCount)))
@@ -46,18 +56,25 @@ func printWelcome(provider, model string, toolCount int, sessionInfo string) {
 // This is the main entry point for CLI mode after all initialization is done.
 func Start(
 	ctx context.Context,
+	cfg *config.Config,
 	sessionMgr *session.Manager,
 	sessionID string,
 	sessionInfo string,
 	prov provider.Provider,
+	registry *provider.Registry,
 	proxy *mcp.Proxy,
 	tools []mcp.Tool,
 	history []provider.Message,
 	autoplayMsg string,
+	playbook *features.Playbook,
 	selectedProvider string,
 	selectedModel string,
+	toolResults llm.ToolResultDisplay,
 ) error {
 	// Nil checks for required dependencies
+	if cfg == nil {
+		return fmt.Errorf("config cannot be nil")
+	}
 	if prov == nil {
 		return fmt.Errorf("provider cannot be nil")
 	}
@@ -81,22 +98,33 @@ func Start(
 
 	// Start conversation loop
 	app := &App{
-		provider:   prov,
-		proxy:      proxy,
-		tools:      tools,
-		history:    history,
-		sessionMgr: sessionMgr,
-		sessionID:  sessionID,
+		cfg:         cfg,
+		provider:    prov,
+		model:       selectedModel,
+		registry:    registry,
+		proxy:       proxy,
+		tools:       tools,
+		history:     history,
+		sessionMgr:  sessionMgr,
+		sessionID:   sessionID,
+		stats:       llm.NewStats(),
+		failures:    llm.NewFailureTracker(),
+		events:      features.OpenEventLog(sessionID),
+		toolResults: toolResults,
+	}
+	defer func() { _ = app.events.Close() }()
+
+	if err := app.loadScript(); err != nil {
+		return err
 	}
 
-	// Initialize autoplay service
+	// Initialize autoplay service and slash commands
 	app.initAutoplayService()
+	app.initCommands()
 
 	// Start autoplay if requested
-	if autoplayMsg != "" {
-		if err := app.startAutoplayFromFlag(ctx, autoplayMsg); err != nil {
-			return fmt.Errorf("failed to start autoplay: %w", err)
-		}
+	if err := app.autoplayService.StartFromFlags(ctx, autoplayMsg, playbook); err != nil {
+		return err
 	}
 
 	return app.runLoop(ctx)
@@ -104,18 +132,26 @@ func Start(
 
 // runLoop runs the main conversation loop.
 func (app *App) runLoop(ctx context.Context) error {
-	scanner := bufio.NewScanner(os.Stdin)
+	historyPath, err := HistoryPath()
+	if err != nil {
+		log.Warn().Err(err).Msg("Input history disabled")
+	}
+	editor, err := newLineEditor(historyPath)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load input history")
+	}
 
 	for {
-		// Display prompt
-		fmt.Print(styles.Brand.Render("> "))
-
 		// Read user input
-		if !scanner.Scan() {
+		line, err := readMessage(editor.ReadLine, styles.Brand.Render("> "), styles.Muted.Render("... "))
+		if errors.Is(err, io.EOF) || errors.Is(err, errInterrupted) {
 			break
 		}
+		if err != nil {
+			return err
+		}
 
-		input := strings.TrimSpace(scanner.Text())
+		input := strings.TrimSpace(line)
 		if input == "" {
 			continue
 		}
@@ -126,56 +162,172 @@ func (app *App) runLoop(ctx context.Context) error {
 			break
 		}
 
-		// Handle /autoplay commands
-		if strings.HasPrefix(input, "/autoplay") {
-			if err := app.handleAutoplayCommand(ctx, input); err != nil {
+		// Handle slash commands
+		if features.IsCommand(input) {
+			err := app.commands.Run(ctx, input)
+			if errors.Is(err, features.ErrQuit) {
+				fmt.Println(styles.Muted.Render("Goodbye!"))
+				break
+			}
+			if err != nil {
 				fmt.Fprintln(os.Stderr, styles.Error.Render("Error: "+err.Error()))
 			}
 			continue
 		}
 
-		// Add user message to history
-		userMsg := provider.Message{
-			Role:      "user",
-			Content:   input,
-			CreatedAt: time.Now(),
+		if err := app.sendMessage(ctx, input); err != nil {
+			fmt.Fprintln(os.Stderr, styles.Error.Render("Error: "+err.Error()))
 		}
-		app.history = append(app.history, userMsg)
+	}
 
-		// Save user message
-		if err := app.sessionMgr.SaveMessage(app.sessionID, userMsg); err != nil {
-			log.Warn().Err(err).Msg("Failed to save user message")
-		}
+	return nil
+}
 
-		// Process turn (may involve multiple LLM calls if tools are used)
-		if err := app.processTurn(ctx); err != nil {
-			fmt.Fprintln(os.Stderr, styles.Error.Render("Error: "+err.Error()))
-			continue
+// sendMessage runs a turn for a user message, or queues the message while
+// autoplay runs rather than racing its turns.
+func (app *App) sendMessage(ctx context.Context, input string) error {
+	if app.autoplayService.Status().Enabled {
+		if n, err := app.autoplayService.Interject(input); err == nil {
+			fmt.Println(styles.Muted.Render(fmt.Sprintf("Queued for the next autoplay turn (%d pending)", n)))
+			return nil
 		}
+	}
+
+	// Add user message to history
+	userMsg := provider.Message{
+		Role:      "user",
+		Content:   input,
+		CreatedAt: time.Now(),
+	}
+	app.mu.Lock()
+	app.history = append(app.history, userMsg)
+	app.mu.Unlock()
 
-		fmt.Println() // Blank line after response
+	// Save user message
+	if err := app.sessionMgr.SaveMessage(app.sessionID, userMsg); err != nil {
+		log.Warn().Err(err).Msg("Failed to save user message")
 	}
 
-	return scanner.Err()
+	// Process turn (may involve multiple LLM calls if tools are used)
+	if _, err := app.processTurn(ctx, app.currentProvider()); err != nil {
+		return err
+	}
+
+	fmt.Println() // Blank line after response
+	return nil
 }
 
-// processTurn handles one conversation turn, which may involve tool calls
-func (app *App) processTurn(ctx context.Context) error {
+// processTurn handles one conversation turn with prov, which may involve
+// tool calls
+func (app *App) processTurn(ctx context.Context, prov provider.Provider) (*llm.TurnResult, error) {
 	// Get a snapshot of history for this turn
 	app.mu.Lock()
 	historyCopy := make([]provider.Message, len(app.history))
 	copy(historyCopy, app.history)
 	app.mu.Unlock()
 
-	return llm.ProcessTurn(ctx, llm.ProcessTurnOptions{
-		Provider:        app.provider,
-		Proxy:           app.proxy,
-		Tools:           app.tools,
-		History:         historyCopy,
-		OnMessage:       app.addMessage,
-		MaxToolRounds:   20,
-		HistoryKeepLast: 10,
+	result, err := llm.ProcessTurn(ctx, llm.ProcessTurnOptions{
+		Provider:           prov,
+		Proxy:              app.proxy,
+		Tools:              app.tools,
+		History:            historyCopy,
+		OnMessage:          app.addMessage,
+		Stats:              app.stats,
+		Events:             app.events,
+		Failures:           app.failures,
+		Pricing:            features.PricingFor(app.cfg, prov.Name()),
+		MaxToolRounds:      20,
+		HistoryKeepLast:    app.cfg.History.KeepTurns,
+		HistoryTokenBudget: app.cfg.History.TokenWindow(),
+		SuppressOutput:     app.quiet,
+		ToolHooks:          app.script,
+		ToolResults:        app.toolResults,
+		ImageText:          features.ToolImageText,
 	})
+	if plugins := features.Plugins(); features.HasPluginHook(plugins, features.PluginHookTurn) {
+		features.RunTurnHooks(ctx, plugins, features.NewPluginTurn(app.sessionInfo(), historyCopy, result, err))
+	}
+	return result, err
+}
+
+// runTurn runs a turn on the current history for /retry, closing prov
+// afterwards unless it is the session provider.
+func (app *App) runTurn(ctx context.Context, prov provider.Provider) error {
+	if prov != app.currentProvider() {
+		defer func() { _ = prov.Close() }()
+	}
+	if _, err := app.processTurn(ctx, prov); err != nil {
+		return err
+	}
+	fmt.Println() // Blank line after response
+	return nil
+}
+
+// currentProvider returns the provider used for the next turn.
+func (app *App) currentProvider() provider.Provider {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.provider
+}
+
+// initCommands registers the CLI handlers of the slash commands.
+func (app *App) initCommands() {
+	app.commands = features.NewSessionCommands(app.cfg, features.CommandHandlers{
+		Print:    printLines,
+		Autoplay: app.handleAutoplayCommand,
+		Compact:  app.handleCompactCommand,
+		Provider: func() (provider.Provider, string) {
+			app.mu.Lock()
+			defer app.mu.Unlock()
+			return app.provider, app.model
+		},
+		SwitchProvider: app.switchProvider,
+		History: func() ([]provider.Message, error) {
+			return app.sessionMgr.LoadHistory(app.sessionID)
+		},
+		Context:         app.historySnapshot,
+		ReplaceContext:  app.replaceHistory,
+		AutoplayStatus:  app.autoplayService.Status,
+		Stats:           app.stats.Snapshot,
+		SessionInfo:     app.sessionInfo,
+		Proxy:           func() *mcp.Proxy { return app.proxy },
+		Registry:        func() *provider.Registry { return app.registry },
+		RunTurn:         app.runTurn,
+		Undo:            app.undo,
+		SetSystemPrompt: app.setSystemPrompt,
+	})
+}
+
+// loadScript loads the session's script, if [scripts] has one for it.
+func (app *App) loadScript() error {
+	name, err := app.sessionMgr.Name(app.sessionID)
+	if err != nil {
+		return err
+	}
+	app.script, err = features.SessionScript(app.cfg, name)
+	return err
+}
+
+// sessionInfo describes the session for transcripts.
+func (app *App) sessionInfo() features.TranscriptInfo {
+	name, err := app.sessionMgr.Name(app.sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to look up session name")
+	}
+	var goal string
+	if app.autoplayService != nil {
+		goal = app.autoplayService.Status().Message
+	}
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return features.TranscriptInfo{Session: name, Provider: app.provider.Name(), Model: app.model, Goal: goal}
+}
+
+// printLines prints command output.
+func printLines(lines []string) {
+	for _, line := range lines {
+		fmt.Println(styles.Muted.Render(line))
+	}
 }
 
 // addMessage adds a message to history and saves it to the database.
@@ -190,13 +342,17 @@ func (app *App) addMessage(msg provider.Message) {
 }
 
 // listSessionsCmd lists recent sessions.
-// ListSessionsCmd lists all recent sessions.
-func ListSessionsCmd(mgr *session.Manager) error {
-	sessions, err := mgr.List(20)
+// ListSessionsCmd lists the sessions matching opts, most recent first.
+func ListSessionsCmd(mgr *session.Manager, opts SessionListOptions) error {
+	sessions, err := mgr.List(opts.Filter)
 	if err != nil {
 		return err
 	}
 
+	if opts.Format == ListFormatJSON {
+		return printSessionsJSON(os.Stdout, sessions)
+	}
+
 	if len(sessions) == 0 {
 		fmt.Println("No sessions found")
 		return nil
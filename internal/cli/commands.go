@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/store"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// Subcommand usage, shown with usage errors.
+const (
+	SessionUsage = "Usage: mysis session list | delete NAME | rename OLD NEW | export NAME [FILE] | share NAME [FILE]"
+	ConfigUsage  = "Usage: mysis config validate"
+	DBUsage      = "Usage: mysis db backup [FILE]"
+	FleetUsage   = "Usage: mysis fleet run | start FILE | status"
+	RunUsage     = "Usage: mysis run SCRIPT"
+)
+
+// SessionCmd runs `mysis session ...`; args excludes "session". list
+// applies to `session list`.
+func SessionCmd(mgr *session.Manager, args []string, list SessionListOptions) error {
+	if len(args) == 0 {
+		return errors.New(SessionUsage)
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		return ListSessionsCmd(mgr, list)
+	case args[0] == "delete" && len(args) == 2:
+		return DeleteSessionCmd(mgr, args[1])
+	case args[0] == "rename" && len(args) == 3:
+		return RenameSessionCmd(mgr, args[1], args[2])
+	case args[0] == "export" && (len(args) == 2 || len(args) == 3):
+		path := ""
+		if len(args) == 3 {
+			path = args[2]
+		}
+		return ExportSessionCmd(mgr, args[1], path)
+	case args[0] == "share" && (len(args) == 2 || len(args) == 3):
+		path := ""
+		if len(args) == 3 {
+			path = args[2]
+		}
+		return ShareSessionCmd(mgr, args[1], path)
+	default:
+		return errors.New(SessionUsage)
+	}
+}
+
+// RenameSessionCmd renames a session.
+func RenameSessionCmd(mgr *session.Manager, oldName, newName string) error {
+	if err := mgr.Rename(oldName, newName); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render(fmt.Sprintf("Session '%s' renamed to '%s'", oldName, newName)))
+	return nil
+}
+
+// ExportSessionCmd writes the history of a session as a Markdown
+// transcript to path, or to stdout if path is empty.
+func ExportSessionCmd(mgr *session.Manager, name, path string) error {
+	sess, err := mgr.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if sess == nil {
+		return fmt.Errorf("session '%s' not found", name)
+	}
+
+	history, err := mgr.LoadHistory(sess.ID)
+	if err != nil {
+		return err
+	}
+
+	transcript := features.RenderMarkdown(features.TranscriptInfo{
+		Session:  name,
+		Provider: sess.Provider,
+		Model:    sess.Model,
+	}, history)
+
+	if path == "" {
+		fmt.Print(transcript)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(transcript), 0600); err != nil {
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	fmt.Println(styles.Success.Render(fmt.Sprintf("Exported %d messages to %s", len(history), path)))
+	return nil
+}
+
+// ShareSessionCmd writes a session as a shareable transcript with secrets
+// redacted to path, as HTML for .html files and JSON otherwise, or as JSON
+// to stdout if path is empty.
+func ShareSessionCmd(mgr *session.Manager, name, path string) error {
+	sess, err := mgr.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if sess == nil {
+		return fmt.Errorf("session '%s' not found", name)
+	}
+
+	history, err := mgr.LoadHistory(sess.ID)
+	if err != nil {
+		return err
+	}
+
+	info := features.TranscriptInfo{Session: name, Provider: sess.Provider, Model: sess.Model}
+	if path == "" {
+		data, err := features.RenderShareJSON(info, history)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if _, err := features.ExportTranscript(info, history, features.ShareFormatFor(path), path); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render("Exported a shareable transcript to " + path))
+	fmt.Println(styles.Muted.Render("Credentials and tokens are redacted; review it before publishing"))
+	return nil
+}
+
+// ConfigCmd runs `mysis config ...`; args excludes "config".
+func ConfigCmd(path string, args []string) error {
+	if len(args) != 1 || args[0] != "validate" {
+		return errors.New(ConfigUsage)
+	}
+	return ValidateConfigCmd(path)
+}
+
+// ValidateConfigCmd loads the config file and reports errors and keys that
+// match no setting.
+func ValidateConfigCmd(path string) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("%s is invalid:\n%w", path, err)
+	}
+	for _, profile := range cfg.ProfileNames() {
+		if _, err := config.LoadProfile(path, profile); err != nil {
+			return fmt.Errorf("%s is invalid with profile %s:\n%w", path, profile, err)
+		}
+	}
+
+	unknown, err := config.UnknownKeys(path)
+	if err != nil {
+		return err
+	}
+	for _, key := range unknown {
+		fmt.Println(styles.Muted.Render("Warning: unknown key " + key))
+	}
+
+	fmt.Println(styles.Success.Render(path + " is valid"))
+	return nil
+}
+
+// DBCmd runs `mysis db ...`; args excludes "db".
+func DBCmd(db *store.Store, args []string) error {
+	if len(args) == 0 || args[0] != "backup" || len(args) > 2 {
+		return errors.New(DBUsage)
+	}
+
+	path := ""
+	if len(args) == 2 {
+		path = args[1]
+	}
+	return BackupDBCmd(db, path)
+}
+
+// BackupDBCmd copies the database to path, or to a timestamped file in the
+// backups directory of the data dir if path is empty.
+func BackupDBCmd(db *store.Store, path string) error {
+	if path == "" {
+		dataDir, err := config.DataDir()
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(dataDir, "backups")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("create backup dir: %w", err)
+		}
+		path = filepath.Join(dir, "mysis-"+time.Now().Format("20060102-150405")+".db")
+	}
+
+	if err := db.Backup(path); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render("Database backed up to " + path))
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// handleCompactCommand handles /compact [turns].
+// It summarizes older turns and persists the compacted history.
+func (app *App) handleCompactCommand(ctx context.Context, args []string) error {
+	keepTurns, err := features.ParseCompactArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if app.autoplayService.Status().Enabled {
+		return fmt.Errorf("stop autoplay before compacting")
+	}
+
+	historyCopy := app.historySnapshot()
+
+	fmt.Println(styles.Muted.Render("Compacting history..."))
+
+	compacted, err := llm.CompactHistory(ctx, llm.CompactOptions{
+		Provider:  app.currentProvider(),
+		History:   historyCopy,
+		KeepTurns: keepTurns,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := app.replaceHistory(compacted); err != nil {
+		return err
+	}
+
+	fmt.Println(styles.Success.Render(fmt.Sprintf("Compacted %d messages into %d (~%d %s ~%d tokens)",
+		len(historyCopy), len(compacted),
+		store.EstimateTokenCount(historyCopy), styles.SymbolArrow, store.EstimateTokenCount(compacted))))
+	return nil
+}
+
+// historySnapshot returns a copy of the conversation history.
+func (app *App) historySnapshot() []provider.Message {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	historyCopy := make([]provider.Message, len(app.history))
+	copy(historyCopy, app.history)
+	return historyCopy
+}
+
+// undo deletes the last exchange from the store and the history for /undo.
+func (app *App) undo() (int, error) {
+	removed, err := app.sessionMgr.UndoExchange(app.sessionID)
+	if err != nil {
+		return 0, err
+	}
+	app.mu.Lock()
+	app.history = store.RemoveLastExchange(app.history)
+	app.mu.Unlock()
+	return removed, nil
+}
+
+// setSystemPrompt replaces the system prompt in the history for /system
+// and stores it on the session.
+func (app *App) setSystemPrompt(prompt string) error {
+	if err := app.sessionMgr.SetSystemPrompt(app.sessionID, prompt); err != nil {
+		return err
+	}
+	app.mu.Lock()
+	app.history = features.SetSystemPrompt(app.history, prompt)
+	app.mu.Unlock()
+	return nil
+}
+
+// replaceHistory persists a rewritten history and makes it current.
+func (app *App) replaceHistory(messages []provider.Message) error {
+	if err := app.sessionMgr.ReplaceHistory(app.sessionID, messages); err != nil {
+		return err
+	}
+	app.mu.Lock()
+	app.history = messages
+	app.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// DoctorUsage is shown with doctor usage errors.
+const DoctorUsage = "Usage: mysis doctor"
+
+// checkStatus is the outcome of a doctor check.
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+// checkResult is one line of the doctor report.
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+	hint   string // Remediation, for warnings and failures
+}
+
+func pass(name, detail string) checkResult {
+	return checkResult{name: name, status: checkPass, detail: detail}
+}
+
+func warn(name, detail, hint string) checkResult {
+	return checkResult{name: name, status: checkWarn, detail: detail, hint: hint}
+}
+
+func fail(name string, err error, hint string) checkResult {
+	return checkResult{name: name, status: checkFail, detail: err.Error(), hint: hint}
+}
+
+// DoctorCmd runs `mysis doctor`: it checks the config, the data directory,
+// the database, each provider, the MCP handshake and the tool listing, and
+// prints a pass/fail report with hints. The providers and MCP server are
+// those of profile, "" for none. Returns an error if a check failed.
+func DoctorCmd(ctx context.Context, configPath, profile string) error {
+	var results []checkResult
+	report := func(r checkResult) {
+		results = append(results, r)
+		printCheck(r)
+	}
+
+	fmt.Println(styles.Banner("Mysis doctor"))
+
+	cfg := checkConfig(configPath, profile, report)
+	checkDataDir(report)
+	checkDatabase(report)
+	if cfg != nil {
+		checkProviders(ctx, cfg, report)
+		checkMCP(ctx, cfg, report)
+		checkScripts(cfg, report)
+	}
+	checkPlugins(ctx, report)
+
+	failed := 0
+	for _, r := range results {
+		if r.status == checkFail {
+			failed++
+		}
+	}
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	fmt.Println(styles.Success.Render("All checks passed"))
+	return nil
+}
+
+// printCheck prints a check result and its hint.
+func printCheck(r checkResult) {
+	var label string
+	switch r.status {
+	case checkPass:
+		label = styles.Success.Render("PASS")
+	case checkWarn:
+		label = styles.Brand.Render("WARN")
+	default:
+		label = styles.Error.Render("FAIL")
+	}
+	fmt.Printf("%s  %s: %s\n", label, r.name, r.detail)
+	if r.hint != "" {
+		fmt.Println("      " + styles.Muted.Render(r.hint))
+	}
+}
+
+// checkConfig loads the config file with profile applied. Returns nil if it
+// cannot be loaded.
+func checkConfig(path, profile string, report func(checkResult)) *config.Config {
+	const name = "config"
+	if path == "" {
+		report(fail(name, fmt.Errorf("no config file found"), "create ./config.toml or ~/.config/mysis/config.toml, or pass -c PATH"))
+		return nil
+	}
+	cfg, err := config.LoadProfile(path, profile)
+	if err != nil {
+		report(fail(name, err, "fix the TOML syntax or setting in "+path))
+		return nil
+	}
+
+	unknown, err := config.UnknownKeys(path)
+	if err == nil && len(unknown) > 0 {
+		report(warn(name, fmt.Sprintf("%s loaded, unknown keys: %s", path, strings.Join(unknown, ", ")),
+			"remove or correct the unknown keys, see mysis config validate"))
+		return cfg
+	}
+	if profile != "" {
+		path += " (profile " + profile + ")"
+	}
+	report(pass(name, path+" loaded"))
+	return cfg
+}
+
+// checkDataDir checks that the data directory exists, is writable and is
+// private to the user.
+func checkDataDir(report func(checkResult)) {
+	const name = "data directory"
+	dir, err := config.EnsureDataDir()
+	if err != nil {
+		report(fail(name, err, "check that your home directory is writable"))
+		return
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		report(fail(name, fmt.Errorf("%s is not writable: %w", dir, err), "fix the ownership and permissions of "+dir))
+		return
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		report(fail(name, err, "check the permissions of "+dir))
+		return
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		report(warn(name, fmt.Sprintf("%s is accessible by other users (%#o)", dir, perm),
+			"it holds credentials and session history: chmod 700 "+dir))
+		return
+	}
+	report(pass(name, dir+" is writable"))
+}
+
+// checkDatabase opens the session database and runs an integrity check.
+func checkDatabase(report func(checkResult)) {
+	const name = "database"
+	db, err := store.Open()
+	if err != nil {
+		report(fail(name, err, "move mysis.db out of the data directory to start with a fresh database"))
+		return
+	}
+	defer func() { _ = db.Close() }()
+
+	problems, err := db.IntegrityCheck()
+	switch {
+	case err != nil:
+		report(fail(name, err, "move mysis.db out of the data directory to start with a fresh database"))
+	case len(problems) > 0:
+		report(fail(name, fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; ")),
+			"restore a backup from the backups directory, or copy what is readable with mysis db backup"))
+	default:
+		report(pass(name, "integrity check ok"))
+	}
+}
+
+// checkScripts loads the session scripts of [scripts].
+func checkScripts(cfg *config.Config, report func(checkResult)) {
+	for _, session := range features.ScriptSessions(cfg) {
+		name := "script " + session
+		script, err := features.SessionScript(cfg, session)
+		if err != nil {
+			report(fail(name, err, "fix the Lua script or its path in [scripts]"))
+			continue
+		}
+		script.Close()
+		report(pass(name, cfg.Scripts[session]+" loaded"))
+	}
+}
+
+// checkPlugins loads the plugins, if there is a plugins directory.
+func checkPlugins(ctx context.Context, report func(checkResult)) {
+	const name = "plugins"
+	dir, err := features.PluginsDir()
+	if err != nil {
+		return // Reported by the data directory check
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	plugins, err := features.LoadPlugins(ctx, dir)
+	if err != nil {
+		report(fail(name, err, "check the permissions of "+dir))
+		return
+	}
+	if len(plugins) == 0 {
+		report(warn(name, "no plugins loaded from "+dir,
+			"plugins must be executable and answer the describe request; the log above says why one was skipped"))
+		return
+	}
+	names := make([]string, len(plugins))
+	for i, p := range plugins {
+		names[i] = p.Name
+	}
+	report(pass(name, fmt.Sprintf("%d loaded: %s", len(plugins), strings.Join(names, ", "))))
+}
+
+// checkProviders checks that each configured provider has credentials,
+// is reachable and serves its configured model.
+func checkProviders(ctx context.Context, cfg *config.Config, report func(checkResult)) {
+	creds, err := config.LoadCredentials(cfg.Keyring, nil)
+	if err != nil {
+		report(fail("credentials", err, "fix or remove credentials.json in the data directory, or set "+config.PassphraseEnv+" for credentials.enc"))
+		creds = &config.Credentials{}
+	}
+	registry := features.InitializeProviders(cfg, creds)
+	registered := registry.List()
+
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		report(checkProvider(ctx, cfg, registry, registered, name))
+	}
+}
+
+func checkProvider(ctx context.Context, cfg *config.Config, registry *provider.Registry, registered []string, name string) checkResult {
+	check := "provider " + name
+	provCfg := cfg.Providers[name]
+	if !slices.Contains(registered, name) {
+		keyName := provCfg.APIKeyName
+		if keyName == "" {
+			keyName = name
+		}
+		return fail(check, fmt.Errorf("not available (unknown endpoint type or missing API key '%s')", keyName),
+			fmt.Sprintf("use an Ollama or OpenCode Zen endpoint, and store the key with mysis auth set %s", name))
+	}
+
+	prov, err := registry.Create(name, provCfg.Model, features.ProviderOptions(provCfg))
+	if err != nil {
+		return fail(check, err, "check the provider settings in the config")
+	}
+	defer func() { _ = prov.Close() }()
+
+	lister, ok := prov.(provider.ModelLister)
+	if !ok {
+		return warn(check, "registered, reachability not checked", "this provider cannot list its models")
+	}
+	ctx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+	defer cancel()
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return fail(check, fmt.Errorf("%s unreachable: %w", provCfg.Endpoint, err),
+			"check that the endpoint is running and reachable, and that the API key is valid")
+	}
+	if !slices.Contains(models, provCfg.Model) {
+		return fail(check, fmt.Errorf("model '%s' is not served (%d models available)", provCfg.Model, len(models)),
+			fmt.Sprintf("pull the model or set model in [providers.%s]; mysis --provider %s, then /model lists them", name, name))
+	}
+	return pass(check, fmt.Sprintf("%s reachable, model %s available", provCfg.Endpoint, provCfg.Model))
+}
+
+// checkMCP runs the MCP initialize handshake and lists the tools.
+func checkMCP(ctx context.Context, cfg *config.Config, report func(checkResult)) {
+	proxy := mcp.NewProxy(mcp.NewUpstream(cfg.MCP.Upstream))
+	defer func() { _ = proxy.Close() }()
+
+	ctx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+	defer cancel()
+	if err := proxy.Initialize(ctx); err != nil {
+		report(fail("MCP handshake", err, "check mcp.upstream ("+cfg.MCP.Upstream+") and your network connection"))
+		return
+	}
+	report(pass("MCP handshake", cfg.MCP.Upstream+" initialized"))
+
+	tools, err := proxy.ListTools(ctx)
+	switch {
+	case err != nil:
+		report(fail("MCP tools", err, "the game server accepted the handshake but did not list tools; try again later"))
+	case len(tools) == 0:
+		report(warn("MCP tools", "no tools listed", "the game server offers no tools; check mcp.upstream"))
+	default:
+		report(pass("MCP tools", fmt.Sprintf("%d tools available", len(tools))))
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// Exit codes for non-interactive runs, so scripts and cron can tell a broken
+// setup from a provider outage from a model that got lost in tool calls.
+const (
+	ExitOK         = 0
+	ExitFailure    = 1 // Config, session or other setup error
+	ExitToolRounds = 3 // The model kept calling tools without answering
+	ExitNoResponse = 4 // The turn finished without a text response
+	ExitConfig     = 5 // The config file is missing or invalid
+	ExitMCP        = 6 // The game server (MCP) could not be reached
+	ExitTurn       = 7 // The turn failed for another reason
+	ExitProvider   = 8 // The LLM provider failed; not 2, which flag parse errors use
+)
+
+// exitKinds names the exit codes in --errors=json output.
+var exitKinds = map[int]string{
+	ExitFailure:    "failure",
+	ExitProvider:   "provider",
+	ExitToolRounds: "tool_rounds",
+	ExitNoResponse: "no_response",
+	ExitConfig:     "config",
+	ExitMCP:        "mcp",
+	ExitTurn:       "turn",
+}
+
+// Error output formats, see --errors.
+const (
+	ErrorsText = "text"
+	ErrorsJSON = "json"
+)
+
+// ExitError carries the process exit code for an error.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode returns the exit code for an error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+// PrintError writes err to w as a styled line, or for ErrorsJSON as one
+// JSON object: {"error": "...", "code": 2, "kind": "provider"}.
+func PrintError(w io.Writer, err error, format string) {
+	if format != ErrorsJSON {
+		fmt.Fprintln(w, styles.Error.Render("Error: "+err.Error()))
+		return
+	}
+
+	code := ExitCode(err)
+	data, jsonErr := json.Marshal(struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+		Kind  string `json:"kind"`
+	}{err.Error(), code, exitKinds[code]})
+	if jsonErr != nil {
+		fmt.Fprintln(w, "Error: "+err.Error())
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
+
+// turnExitError classifies a failed turn.
+func turnExitError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, llm.ErrLLMCall):
+		return &ExitError{Code: ExitProvider, Err: err}
+	case errors.Is(err, llm.ErrTooManyRounds):
+		return &ExitError{Code: ExitToolRounds, Err: err}
+	default:
+		return &ExitError{Code: ExitTurn, Err: err}
+	}
+}
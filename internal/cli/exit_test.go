@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOK},
+		{errors.New("session not found"), ExitFailure},
+		{turnExitError(fmt.Errorf("%w: timeout", llm.ErrLLMCall)), ExitProvider},
+		{turnExitError(llm.ErrTooManyRounds), ExitToolRounds},
+		{turnExitError(errors.New("canceled")), ExitTurn},
+		{fmt.Errorf("demo.txt:3: %w", &ExitError{Code: ExitMCP, Err: errors.New("refused")}), ExitMCP},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.code {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.code)
+		}
+	}
+}
+
+func TestPrintErrorJSON(t *testing.T) {
+	var buf bytes.Buffer
+	PrintError(&buf, &ExitError{Code: ExitConfig, Err: errors.New("config file not found")}, ErrorsJSON)
+
+	var got struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+		Kind  string `json:"kind"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output %q is not JSON: %v", buf.String(), err)
+	}
+	if got.Error != "config file not found" || got.Code != ExitConfig || got.Kind != "config" {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/fleet"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// FleetRunCmd runs autoplay for every [[fleet.bot]] until all bots stop or
+// the process is interrupted, keeping the fleet status file up to date.
+func FleetRunCmd(ctx context.Context, cfg *config.Config, sessionMgr *session.Manager, registry *provider.Registry, creds mcp.CredentialStore) error {
+	statusPath, err := fleet.StatusPath()
+	if err != nil {
+		return err
+	}
+
+	orch := fleet.New(cfg, sessionMgr, registry, creds, func(name, text string) {
+		fmt.Println(styles.Secondary.Render("["+name+"]") + " " + text)
+	})
+	defer orch.Close()
+
+	if cfg.Fleet.Listen != "" {
+		server, err := serveFleet(cfg, registry, orch)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = server.Close() }()
+		fmt.Println(styles.Muted.Render("Streaming events on ws://" + cfg.Fleet.Listen + "/events, probes at /healthz and /readyz"))
+	}
+
+	fmt.Println(styles.Brand.Render(fmt.Sprintf("Starting fleet of %d bots (Ctrl+C to stop)", len(cfg.Fleet.Bots))))
+	if err := orch.Start(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		orch.Wait()
+		close(done)
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ticker := time.NewTicker(fleet.StatusInterval)
+	defer ticker.Stop()
+
+	writeStatus := func() {
+		if err := fleet.WriteStatus(statusPath, orch.Status()); err != nil {
+			log.Warn().Err(err).Msg("Failed to write fleet status")
+		}
+	}
+	writeStatus()
+
+	for {
+		select {
+		case <-ticker.C:
+			writeStatus()
+		case <-sigCh:
+			fmt.Println(styles.Muted.Render("Stopping fleet..."))
+			orch.Stop()
+		case <-done:
+			writeStatus()
+			fmt.Println(styles.Success.Render("All bots stopped"))
+			return nil
+		}
+	}
+}
+
+// serveFleet serves the orchestrator's event stream and the health and
+// readiness probes on fleet.listen in the background.
+func serveFleet(cfg *config.Config, registry *provider.Registry, orch *fleet.Orchestrator) (*http.Server, error) {
+	listener, err := net.Listen("tcp", cfg.Fleet.Listen)
+	if err != nil {
+		return nil, fmt.Errorf("fleet.listen: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/events", orch.Stream())
+	mux.Handle("/healthz", fleetHealth())
+	mux.Handle("/readyz", fleetReadiness(cfg, registry, orch))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Fleet server stopped")
+		}
+	}()
+	return server, nil
+}
+
+// FleetStatusCmd prints the status written by a running `mysis fleet run`.
+func FleetStatusCmd() error {
+	statusPath, err := fleet.StatusPath()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := fleet.ReadStatus(statusPath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("No fleet has been run yet")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if snapshot.Stale(now) {
+		fmt.Println(styles.Muted.Render(fmt.Sprintf("Fleet not running (last update %s ago)", session.FormatDuration(now.Sub(snapshot.UpdatedAt)))))
+	} else {
+		fmt.Println(styles.Brand.Render(fmt.Sprintf("Fleet running (pid %d, up %s)", snapshot.PID, session.FormatDuration(now.Sub(snapshot.StartedAt)))))
+	}
+	fmt.Println()
+
+	for _, bot := range snapshot.Bots {
+		fmt.Printf("%s  %s", styles.BrandBold.Render(bot.Session), fleetStateStyle(bot.State).Render(bot.State))
+		if bot.Provider != "" {
+			fmt.Printf(" - %s (%s)", bot.Provider, bot.Model)
+		}
+		fmt.Println()
+
+		if bot.Goal != "" {
+			fmt.Printf("       Goal: %s\n", bot.Goal)
+		}
+		counters := []string{
+			fmt.Sprintf("%d turns", bot.Turns),
+			fmt.Sprintf("%d failed", bot.FailedTurns),
+			fmt.Sprintf("~%d tokens", bot.Tokens),
+		}
+		if bot.Cost > 0 {
+			counters = append(counters, fmt.Sprintf("~$%.4f", bot.Cost))
+		}
+		if bot.ConsecutiveErrors > 0 {
+			counters = append(counters, fmt.Sprintf("%d consecutive errors", bot.ConsecutiveErrors))
+		}
+		fmt.Printf("       %s\n", styles.Muted.Render(strings.Join(counters, ", ")))
+
+		if bot.State == fleet.StateRunning && !bot.NextTurnAt.IsZero() && bot.NextTurnAt.After(now) {
+			fmt.Printf("       %s\n", styles.Muted.Render("Next turn in "+bot.NextTurnAt.Sub(now).Round(time.Second).String()))
+		}
+		if bot.StopReason != "" {
+			fmt.Printf("       %s\n", styles.Muted.Render("Stopped: "+bot.StopReason))
+		}
+		if bot.LastError != "" {
+			fmt.Printf("       %s\n", styles.Error.Render("Last error: "+bot.LastError))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// fleetStateStyle colors a bot state.
+func fleetStateStyle(state string) lipgloss.Style {
+	switch state {
+	case fleet.StateRunning:
+		return styles.Success
+	case fleet.StateFailed:
+		return styles.Error
+	default:
+		return styles.Muted
+	}
+}
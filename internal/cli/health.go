@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/fleet"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// healthCacheTTL is how long probe results are reused, so frequent probes
+// don't hammer the providers and the game server.
+const healthCacheTTL = 15 * time.Second
+
+// healthTimeout bounds a run of the readiness checks.
+const healthTimeout = 10 * time.Second
+
+// healthCheck is a check in a probe response.
+type healthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // "pass", "warn" or "fail"
+	Detail string `json:"detail"`
+}
+
+// healthResponse is the JSON body of /healthz and /readyz.
+type healthResponse struct {
+	Status string        `json:"status"` // "ok", or "fail" with status 503
+	Checks []healthCheck `json:"checks"`
+}
+
+// healthHandler serves a probe running doctor checks: 200 when none
+// failed, 503 otherwise. Warnings don't fail the probe. The checks run once
+// for concurrent probes, under their own timeout rather than a probe's
+// context, so a probe hanging up doesn't fail them for the others.
+type healthHandler struct {
+	run func(ctx context.Context, report func(checkResult))
+
+	mu      sync.Mutex
+	checked time.Time
+	resp    healthResponse
+	running chan struct{} // Closed when the running checks finish; nil when none run
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	if h.running == nil && time.Since(h.checked) > healthCacheTTL {
+		h.running = make(chan struct{})
+		go h.check(h.running)
+	}
+	running := h.running
+	h.mu.Unlock()
+
+	if running != nil {
+		select {
+		case <-running:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	h.mu.Lock()
+	resp := h.resp
+	h.mu.Unlock()
+	writeHealth(w, resp)
+}
+
+// check runs the checks and caches their response, then closes done.
+func (h *healthHandler) check(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+
+	resp := healthResponse{Status: "ok", Checks: []healthCheck{}}
+	h.run(ctx, func(c checkResult) {
+		check := healthCheck{Name: c.name, Status: "pass", Detail: c.detail}
+		switch c.status {
+		case checkWarn:
+			check.Status = "warn"
+		case checkFail:
+			check.Status = "fail"
+			resp.Status = "fail"
+		}
+		resp.Checks = append(resp.Checks, check)
+	})
+
+	h.mu.Lock()
+	h.resp = resp
+	h.checked = time.Now()
+	h.running = nil
+	h.mu.Unlock()
+	close(done)
+}
+
+// writeHealth writes a probe response, with status 503 if it failed.
+func writeHealth(w http.ResponseWriter, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// fleetHealth returns the liveness probe of a fleet: the process serves
+// requests. Checks belong in the readiness probe.
+func fleetHealth() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeHealth(w, healthResponse{Status: "ok", Checks: []healthCheck{}})
+	})
+}
+
+// fleetReadiness returns the readiness probe of a fleet: the database, the
+// providers of its bots and the MCP server are reachable.
+func fleetReadiness(cfg *config.Config, registry *provider.Registry, orch *fleet.Orchestrator) http.Handler {
+	return &healthHandler{run: func(ctx context.Context, report func(checkResult)) {
+		checkDatabase(report)
+
+		var names []string
+		for _, bot := range orch.Status().Bots {
+			if bot.Provider != "" && !slices.Contains(names, bot.Provider) {
+				names = append(names, bot.Provider)
+			}
+		}
+		slices.Sort(names)
+		registered := registry.List()
+		for _, name := range names {
+			report(checkProvider(ctx, cfg, registry, registered, name))
+		}
+
+		checkMCP(ctx, cfg, report)
+	}}
+}
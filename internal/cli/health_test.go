@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	if rec := serveProbe(context.Background(), fleetHealth()); rec.Code != http.StatusOK {
+		t.Errorf("liveness status = %d", rec.Code)
+	}
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	h := &healthHandler{run: func(ctx context.Context, report func(checkResult)) {
+		runs.Add(1)
+		<-release
+		status := checkPass
+		if ctx.Err() != nil {
+			status = checkFail
+		}
+		report(checkResult{name: "provider", status: status})
+	}}
+
+	// A probe hanging up doesn't fail the checks of the others
+	ctx, cancel := context.WithCancel(context.Background())
+	hungUp := make(chan struct{})
+	go func() {
+		defer close(hungUp)
+		serveProbe(ctx, h)
+	}()
+	for runs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-hungUp
+
+	var wg sync.WaitGroup
+	codes := make([]int, 3)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serveProbe(context.Background(), h).Code
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("probe %d status = %d", i, code)
+		}
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("checks ran %d times, want once for concurrent probes", n)
+	}
+}
+
+func serveProbe(ctx context.Context, h http.Handler) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(ctx))
+	return rec
+}
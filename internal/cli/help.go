@@ -13,25 +13,74 @@ func PrintVersion(version string) {
 
 // PrintHelp displays usage information with CLI styling.
 func PrintHelp(version string) {
-	fmt.Println(styles.Brand.Render("╔══════════════════════════════════════╗"))
-	fmt.Println(styles.Brand.Render("║") + "  " + styles.BrandBold.Render("Mysis") + " - SpaceMolt Agent CLI         " + styles.Brand.Render("║"))
-	fmt.Println(styles.Brand.Render("╚══════════════════════════════════════╝"))
+	fmt.Println(styles.Banner(styles.BrandBold.Render("Mysis") + " - SpaceMolt Agent CLI"))
 	fmt.Println()
 	fmt.Println(styles.BrandBold.Render("USAGE:"))
-	fmt.Println("  mysis [flags]")
+	fmt.Println("  mysis [command] [flags]")
+	fmt.Println()
+	fmt.Println(styles.BrandBold.Render("COMMANDS:"))
+	fmt.Println("  " + styles.Secondary.Render("chat") + "                    Start a conversation (default)")
+	fmt.Println("  " + styles.Secondary.Render("session list") + "            List recent sessions")
+	fmt.Println("  " + styles.Secondary.Render("session delete") + " NAME     Delete a session")
+	fmt.Println("  " + styles.Secondary.Render("session rename") + " OLD NEW  Rename a session")
+	fmt.Println("  " + styles.Secondary.Render("session export") + " NAME [FILE]  Export a session as a Markdown transcript")
+	fmt.Println("  " + styles.Secondary.Render("session share") + " NAME [FILE]   Export a redacted run to publish: HTML for .html files, else JSON")
+	fmt.Println("  " + styles.Secondary.Render("config validate") + "         Check the config file for errors and unknown keys")
+	fmt.Println("  " + styles.Secondary.Render("db backup") + " [FILE]        Back up the session database")
+	fmt.Println("  " + styles.Secondary.Render("auth set") + " PROVIDER       Store a provider API key in the OS keyring (or credentials file)")
+	fmt.Println("  " + styles.Secondary.Render("auth list") + "               Show where each provider API key is stored")
+	fmt.Println("  " + styles.Secondary.Render("auth encrypt") + "            Encrypt the credentials file with a passphrase asked at startup")
+	fmt.Println("  " + styles.Secondary.Render("doctor") + "                  Check config, data dir, database, providers and game server")
+	fmt.Println("  " + styles.Secondary.Render("prune") + "                   Delete sessions chosen with --anonymous and/or --older-than")
+	fmt.Println("  " + styles.Secondary.Render("usage") + "                   Report estimated tokens and cost from the session event logs")
+	fmt.Println("  " + styles.Secondary.Render("fleet run") + "               Run autoplay for every [[fleet.bot]] in the config")
+	fmt.Println("  " + styles.Secondary.Render("fleet start") + " FILE        Run the fleet of a fleet file instead of the config's [fleet]")
+	fmt.Println("  " + styles.Secondary.Render("fleet status") + "            Show the state of the running fleet")
+	fmt.Println("  " + styles.Secondary.Render("run") + " SCRIPT              Send each line of a file as input: messages, /commands, @sleep 2s")
 	fmt.Println()
 	fmt.Println(styles.BrandBold.Render("FLAGS:"))
 	fmt.Println("  " + styles.Secondary.Render("-h, --help") + "              Show this help message")
 	fmt.Println("  " + styles.Secondary.Render("-v, --version") + "           Show version information")
 	fmt.Println("  " + styles.Secondary.Render("-c, --config") + " PATH       Path to config file (default: config.toml)")
+	fmt.Println("  " + styles.Secondary.Render("--profile") + " NAME          Apply [profiles.NAME] of the config: providers, MCP upstream, autoplay")
 	fmt.Println("  " + styles.Secondary.Render("-d, --debug") + "             Enable debug logging")
 	fmt.Println("  " + styles.Secondary.Render("-p, --provider") + " NAME     Provider name (overrides config default)")
+	fmt.Println("  " + styles.Secondary.Render("-M, --model") + " NAME        Model name (overrides the provider's configured model)")
+	fmt.Println("  " + styles.Secondary.Render("--temperature") + " T       Sampling temperature, 0.0-2.0 (overrides config)")
+	fmt.Println("  " + styles.Secondary.Render("--top-p") + " P             Nucleus sampling top_p, 0.0-1.0 (overrides config)")
+	fmt.Println("  " + styles.Secondary.Render("--max-tokens") + " N        Response token limit (overrides config)")
 	fmt.Println("  " + styles.Secondary.Render("-s, --session") + " NAME      Session name (resume or create)")
+	fmt.Println("  " + styles.Secondary.Render("-C, --continue") + "          Resume the most recently active named session")
 	fmt.Println("  " + styles.Secondary.Render("-a, --autoplay") + " MSG      Start autoplay immediately with message")
-	fmt.Println("  " + styles.Secondary.Render("-f, --file") + " PATH      Load system prompt from markdown file")
+	fmt.Println("  " + styles.Secondary.Render("--playbook") + " FILE         Start autoplay with goals from a playbook file")
+	fmt.Println("  " + styles.Secondary.Render("-f, --file") + " PATH      Load system prompt from markdown file (overrides the config system_prompt)")
+	fmt.Println("  " + styles.Secondary.Render("--template") + " NAME         Load system prompt template NAME.md from the templates directory")
+	fmt.Println("  " + styles.Secondary.Render("--var") + " NAME=VALUE        Set a template variable (repeatable)")
 	fmt.Println("  " + styles.Secondary.Render("-t, --tui") + "              Use terminal UI mode")
+	fmt.Println("  " + styles.Secondary.Render("--screen-reader") + "         With -t, no animations, borders or icons; new messages announced on one line")
+	fmt.Println("  " + styles.Secondary.Render("--split") + " NAME            With -t, show session NAME beside the main one (Alt-W switches)")
+	fmt.Println("  " + styles.Secondary.Render("--show-tool-results") + " M Tool result output: full, truncated (default) or hidden")
+	fmt.Println("  " + styles.Secondary.Render("--errors") + " FORMAT        Error output on stderr: text (default) or json")
+	fmt.Println("  " + styles.Secondary.Render("--no-color") + "              Plain ASCII output without colors (also NO_COLOR, TERM=dumb)")
+	fmt.Println("  " + styles.Secondary.Render("-m, --message") + " MSG       Run a single turn with tools and exit")
+	fmt.Println("  " + styles.Secondary.Render("-q, --quiet") + "             With -m, print only the final response")
+	fmt.Println("  " + styles.Secondary.Render("--stdin") + "                 Read the -m message from stdin (automatic when piped)")
 	fmt.Println("  " + styles.Secondary.Render("-l, --list-sessions") + "     List recent sessions and exit")
+	fmt.Println("  " + styles.Secondary.Render("--since") + " WHEN            With -l, sessions active since 24h, 7d or 2006-01-02; with usage, calls since")
+	fmt.Println("  " + styles.Secondary.Render("--name-contains") + " TEXT    With -l, sessions whose name contains TEXT")
+	fmt.Println("  " + styles.Secondary.Render("--format") + " FORMAT         With -l or usage, output text or json; -p filters by provider")
+	fmt.Println("  " + styles.Secondary.Render("--by") + " GROUP              With usage, group by session (default), day or provider")
 	fmt.Println("  " + styles.Secondary.Render("-D, --delete-session") + " N  Delete session by name and exit")
+	fmt.Println("  " + styles.Secondary.Render("--anonymous") + "             With prune, only anonymous sessions")
+	fmt.Println("  " + styles.Secondary.Render("--older-than") + " AGE        With prune, sessions inactive for AGE (7d) or since a date")
+	fmt.Println("  " + styles.Secondary.Render("--dry-run") + "               With prune, list the sessions without deleting")
+	fmt.Println("  " + styles.Secondary.Render("--yes") + "                   With prune, delete without asking")
+	fmt.Println()
+	fmt.Println(styles.BrandBold.Render("EXIT CODES:"))
+	fmt.Println("  0 success, 1 other error, 2 invalid flags, 3 too many tool rounds,")
+	fmt.Println("  4 no response, 5 config error, 6 game server (MCP) unreachable, 7 turn failed,")
+	fmt.Println("  8 provider error")
+	fmt.Println("  With --errors=json the error is one line: {\"error\": ..., \"code\": 8, \"kind\": \"provider\"}")
 	fmt.Println()
 	fmt.Println(styles.BrandBold.Render("EXAMPLES:"))
 	fmt.Println("  # Start anonymous session")
@@ -43,17 +92,101 @@ func PrintHelp(version string) {
 	fmt.Println("  # Start with autoplay enabled")
 	fmt.Println("  mysis -s mybot -a \"explore and mine resources\"")
 	fmt.Println()
+	fmt.Println("  # Run one turn from a script (exit code 0 on success)")
+	fmt.Println("  mysis -s mybot -q -m \"dock at the nearest station\"")
+	fmt.Println()
+	fmt.Println("  # Replay a scenario (with upstream = \"stub\" in [mcp] for offline runs)")
+	fmt.Println("  mysis -s demo run scenarios/dock.txt")
+	fmt.Println()
+	fmt.Println("  # Use the personality in ~/.config/mysis/templates/miner.md, where")
+	fmt.Println("  # {{session}}, {{provider}}, {{model}}, {{date}} and --var names are filled in")
+	fmt.Println("  mysis -s mybot --template miner --var ore=iron")
+	fmt.Println()
+	fmt.Println("  # Find the miners active this week, as JSON")
+	fmt.Println("  mysis -l --name-contains miner --since 7d --format json")
+	fmt.Println()
+	fmt.Println("  # See which anonymous sessions older than a week would be deleted")
+	fmt.Println("  mysis prune --anonymous --older-than 7d --dry-run")
+	fmt.Println()
+	fmt.Println("  # See which bot spent the most this week")
+	fmt.Println("  mysis usage --since 7d --by session")
+	fmt.Println()
+	fmt.Println("  # Pipe a prompt in")
+	fmt.Println("  echo \"check my notifications\" | mysis -s mybot")
+	fmt.Println()
 	fmt.Println("  # List all sessions")
-	fmt.Println("  mysis -l")
+	fmt.Println("  mysis session list")
 	fmt.Println()
 	fmt.Println("  # Delete a session")
-	fmt.Println("  mysis -D mybot")
+	fmt.Println("  mysis session delete mybot")
 	fmt.Println()
 	fmt.Println(styles.BrandBold.Render("IN-SESSION COMMANDS:"))
 	fmt.Println("  " + styles.Secondary.Render("/autoplay <message>") + "    Start autonomous gameplay with given goal")
+	fmt.Println("  " + styles.Secondary.Render("  --turns N, --for 2h") + "  Stop autoplay after N turns or elapsed time")
+	fmt.Println("  " + styles.Secondary.Render("  --max-tokens N") + "       Stop autoplay after ~N LLM tokens")
+	fmt.Println("  " + styles.Secondary.Render("  --max-cost USD") + "       Stop autoplay after an estimated cost")
+	fmt.Println("  " + styles.Secondary.Render("  --dry-run") + "            Simulate game actions, only run state queries")
+	fmt.Println("  " + styles.Secondary.Render("  --safe") + "               Only allow state queries and mining (autoplay.safe_tools)")
+	fmt.Println("  " + styles.Secondary.Render("/autoplay playbook") + "     Run the goals of a playbook FILE in order")
+	fmt.Println("  " + styles.Secondary.Render("/autoplay rotate") + " [NAMES] Alternate configured goals each turn")
+	fmt.Println("  " + styles.Secondary.Render("/autoplay pause") + "        Pause autoplay, keeping the goal")
+	fmt.Println("  " + styles.Secondary.Render("/autoplay resume") + "       Resume paused autoplay")
 	fmt.Println("  " + styles.Secondary.Render("/autoplay stop") + "         Stop autonomous gameplay")
+	fmt.Println("  " + styles.Secondary.Render("/autoplay status") + "       Show turns, errors and time to next turn")
+	fmt.Println("  " + styles.Secondary.Render("/compact [turns]") + "       Summarize older history, keeping recent turns")
+	fmt.Println("  " + styles.Secondary.Render("/stats") + "                 Show messages, token usage, cost, tool calls and autoplay turns")
+	fmt.Println("  " + styles.Secondary.Render("/provider") + " [NAME [MODEL]] Show or switch the provider mid-session")
+	fmt.Println("  " + styles.Secondary.Render("/model") + " [NAME]           Show available models or switch the model")
+	fmt.Println("  " + styles.Secondary.Render("/history") + " [N]           Show the last N stored messages")
+	fmt.Println("  " + styles.Secondary.Render("/clear") + " [summary]        Start a fresh context; stored history is kept")
+	fmt.Println("  " + styles.Secondary.Render("/save") + " [PATH]            Save the transcript as Markdown (default: ~/.config/mysis/transcripts)")
+	fmt.Println("  " + styles.Secondary.Render("/share") + " [PATH]           Save a redacted per-turn transcript to publish, HTML or JSON")
+	fmt.Println("  " + styles.Secondary.Render("/tools") + " [FILTER]         List MCP tools, local or upstream, and which are blocked")
+	fmt.Println("  " + styles.Secondary.Render("/tool") + " NAME [JSON]       Call an MCP tool directly, e.g. /tool get_status")
+	fmt.Println("  " + styles.Secondary.Render("/retry") + " [PROVIDER] [TEMP] Run the last turn again, e.g. /retry 0.9")
+	fmt.Println("  " + styles.Secondary.Render("/undo") + "                  Delete the last exchange from the context and the store")
+	fmt.Println("  " + styles.Secondary.Render("/system") + " [set|append TEXT] Show or change the system prompt; kept for the session")
+	fmt.Println("  " + styles.Secondary.Render("/help") + "                  List all in-session commands")
 	fmt.Println("  " + styles.Secondary.Render("exit, quit") + "             Exit the session")
 	fmt.Println()
+	fmt.Println(styles.BrandBold.Render("INPUT KEYS:"))
+	fmt.Println("  " + styles.Secondary.Render("Up/Down, Ctrl-P/N") + "      Recall previous input (saved in ~/.config/mysis/history)")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-R") + "                 Search input history")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-A/E, Ctrl-K/U/W") + "   Start/end of line, delete to end/start/previous word")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-D, Ctrl-C") + "         Exit on an empty line (Ctrl-C clears a typed line)")
+	fmt.Println("  " + styles.Secondary.Render("\\ at line end") + "          Continue the message on the next line")
+	fmt.Println("  " + styles.Secondary.Render("\"\"\"") + "                    Start or end a multi-line message block")
+	fmt.Println()
+	fmt.Println(styles.BrandBold.Render("TUI KEYS:"))
+	fmt.Println("  " + styles.Secondary.Render("Alt-Enter, Ctrl-J") + "      Insert a newline (Shift-Enter if the terminal sends Alt-Enter)")
+	fmt.Println("  " + styles.Secondary.Render("PgUp, PgDn") + "             Scroll the conversation a page")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-U, Ctrl-D") + "         Scroll half a page (on an empty input)")
+	fmt.Println("  " + styles.Secondary.Render("Home, End") + "              Scroll to the top or bottom (on an empty input; g/G while browsing)")
+	fmt.Println("  " + styles.Secondary.Render("Alt-J, Ctrl-End") + "        Jump to the latest message, past the new-messages pill")
+	fmt.Println("  " + styles.Secondary.Render("Alt-S") + "                  Select messages; Enter opens copy, retry, delete, raw JSON and expand")
+	fmt.Println("  " + styles.Secondary.Render("Alt-C") + "                  Copy mode: select lines with j/k and v, copy with y (releases the mouse)")
+	fmt.Println("  " + styles.Secondary.Render("Alt-E") + "                  Show the full text and context of recent errors (y copies the last)")
+	fmt.Println("  " + styles.Secondary.Render("Alt-P") + "                  Switch provider and model from a list (or click them in the status bar)")
+	fmt.Println("  " + styles.Secondary.Render("Alt-W") + "                  Move the input to the other session of the --split view")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-N") + "                 Create a named session with a provider, model and template, and switch to it")
+	fmt.Println("  " + styles.Secondary.Render("Alt-X, Alt-Shift-X") + "     Export the session as a Markdown or JSON transcript into the data dir")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-F") + "                 Search the conversation, n/N for next/previous match")
+	fmt.Println("  " + styles.Secondary.Render("Tab") + "                    Browse tool results, Up/Down to select, Enter/Space to expand")
+	fmt.Println("  " + styles.Secondary.Render("Alt-A") + "                  Show or hide the autoplay panel (goal, countdown, turns, errors)")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-G, Alt-N") + "          Show or hide the game state or notification panel")
+	fmt.Println("  " + styles.Secondary.Render("Alt-M") + "                  Show or hide the map of the current system (get_system, get_map)")
+	fmt.Println("  " + styles.Secondary.Render("Alt-L") + "                  Show or hide the log pane")
+	fmt.Println("  " + styles.Secondary.Render("Alt-Up, Alt-Down") + "       Grow or shrink the input")
+	fmt.Println("  " + styles.Secondary.Render("Alt-=, Alt--") + "           Widen or narrow the side panels")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-L") + "                 Mark game notifications read")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-T") + "                 Show or hide reasoning lines")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-O") + "                 Show the full reasoning of the conversation")
+	fmt.Println("  " + styles.Secondary.Render("Alt-T") + "                  Cycle timestamps: absolute, relative (2m ago), hidden")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-P") + "                 Pause or resume autoplay")
+	fmt.Println("  " + styles.Secondary.Render("Ctrl-X") + "                 Stop autoplay")
+	fmt.Println("  " + styles.Secondary.Render("?, F1") + "                  Show key bindings, commands and the session configuration")
+	fmt.Println("  " + styles.Secondary.Render("Esc") + "                    Stop autoplay, or close the search or tool browsing")
+	fmt.Println()
 	fmt.Println(styles.Muted.Render("Note: Running without -s/--session creates an anonymous session (not saved by name)."))
 	fmt.Println()
 }
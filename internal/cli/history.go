@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+)
+
+const historyFile = "history"
+
+// HistoryPath returns the path of the CLI input history file.
+func HistoryPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFile), nil
+}
+
+// inputHistory holds previous input lines, oldest first, and appends new
+// lines to a file so they survive restarts. Entries are stored one per
+// line with backslashes and newlines escaped.
+type inputHistory struct {
+	path    string // "" keeps history in memory only
+	limit   int
+	entries []string
+}
+
+// loadHistory reads the history file at path. A missing file is an empty
+// history; the file is rewritten when it holds more than twice the limit.
+func loadHistory(path string, limit int) (*inputHistory, error) {
+	if limit <= 0 {
+		limit = constants.InputHistoryLimit
+	}
+	h := &inputHistory{path: path, limit: limit}
+	if path == "" {
+		return h, nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+
+	var all []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			all = append(all, unescapeHistory(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return h, fmt.Errorf("read history: %w", err)
+	}
+
+	h.entries = all
+	if len(all) > limit {
+		h.entries = all[len(all)-limit:]
+	}
+	if len(all) > 2*limit {
+		if err := h.rewrite(); err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
+
+// add records a line, skipping blanks and repeats of the previous entry.
+func (h *inputHistory) add(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	if len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0750); err != nil {
+		return fmt.Errorf("create history dir: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(escapeHistory(line) + "\n"); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	return nil
+}
+
+// rewrite replaces the history file with the kept entries.
+func (h *inputHistory) rewrite() error {
+	var b strings.Builder
+	for _, entry := range h.entries {
+		b.WriteString(escapeHistory(entry) + "\n")
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("replace history: %w", err)
+	}
+	return nil
+}
+
+func escapeHistory(line string) string {
+	line = strings.ReplaceAll(line, `\`, `\\`)
+	return strings.ReplaceAll(line, "\n", `\n`)
+}
+
+func unescapeHistory(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) {
+			i++
+			if line[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(line[i])
+			}
+			continue
+		}
+		b.WriteByte(line[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/session"
+)
+
+// RunOnce sends a single message, runs the turn with tools and returns, for
+// `mysis -m "dock at the nearest station"` from scripts and cron. With quiet
+// only the final assistant response is printed. Errors carry an exit code,
+// see ExitCode.
+func RunOnce(
+	ctx context.Context,
+	cfg *config.Config,
+	sessionMgr *session.Manager,
+	sessionID string,
+	prov provider.Provider,
+	proxy *mcp.Proxy,
+	tools []mcp.Tool,
+	history []provider.Message,
+	message string,
+	quiet bool,
+	toolResults llm.ToolResultDisplay,
+) error {
+	app := &App{
+		cfg:         cfg,
+		provider:    prov,
+		proxy:       proxy,
+		tools:       tools,
+		history:     history,
+		sessionMgr:  sessionMgr,
+		sessionID:   sessionID,
+		stats:       llm.NewStats(),
+		failures:    llm.NewFailureTracker(),
+		events:      features.OpenEventLog(sessionID),
+		quiet:       quiet,
+		toolResults: toolResults,
+	}
+	defer func() { _ = app.events.Close() }()
+	if err := app.loadScript(); err != nil {
+		return err
+	}
+
+	app.addMessage(provider.Message{
+		Role:      "user",
+		Content:   message,
+		CreatedAt: time.Now(),
+	})
+
+	result, err := app.processTurn(ctx, app.currentProvider())
+	if err != nil {
+		return turnExitError(err)
+	}
+
+	response := result.FinalResponse()
+	if response == "" {
+		return &ExitError{Code: ExitNoResponse, Err: errors.New("no response from the model")}
+	}
+	if quiet {
+		fmt.Println(response)
+	}
+	return nil
+}
+
+// StdinIsTerminal reports whether stdin is an interactive terminal rather
+// than a pipe or file, e.g. `echo "check my notifications" | mysis`.
+func StdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return true
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadStdinMessage reads the whole of stdin as a one-shot message.
+func ReadStdinMessage() (string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("read stdin: %w", err)
+	}
+	message := strings.TrimSpace(string(data))
+	if message == "" {
+		return "", errors.New("no message on stdin")
+	}
+	return message, nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/store"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// PruneUsage is shown with prune usage errors.
+const PruneUsage = "Usage: mysis prune [--anonymous] [--older-than 7d] [--dry-run] [--yes]"
+
+// PruneCmd runs `mysis prune`: it lists the sessions matching filter and,
+// unless dryRun, deletes them after confirmation on the terminal or with
+// yes. The filter must select by age or anonymity, so a bare prune does not
+// delete every session.
+func PruneCmd(mgr *session.Manager, filter store.SessionFilter, dryRun, yes bool) error {
+	if !filter.Anonymous && filter.Before.IsZero() {
+		return errors.New(PruneUsage + " - select sessions with --anonymous and/or --older-than")
+	}
+
+	sessions, err := mgr.List(filter)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions to prune")
+		return nil
+	}
+
+	fmt.Println(styles.Brand.Render(fmt.Sprintf("Sessions to prune (%d):", len(sessions))))
+	ids := make([]string, len(sessions))
+	for i, sess := range sessions {
+		ids[i] = sess.ID
+		name := "(anonymous)"
+		if sess.Name != nil {
+			name = *sess.Name
+		}
+		fmt.Printf("  %s  %s - %s (%s), active %s ago\n", styles.Muted.Render(sess.ID[:8]), name,
+			sess.Provider, sess.Model, session.FormatDuration(time.Since(sess.LastActiveAt)))
+	}
+	if dryRun {
+		fmt.Println(styles.Muted.Render("Dry run - nothing deleted"))
+		return nil
+	}
+
+	if !yes {
+		if !StdinIsTerminal() {
+			return errors.New("not deleting without confirmation - pass --yes")
+		}
+		if !confirm(os.Stdin, fmt.Sprintf("Delete %d sessions and their messages? [y/N] ", len(sessions))) {
+			fmt.Println("Nothing deleted")
+			return nil
+		}
+	}
+
+	deleted, err := mgr.DeleteSessions(ids)
+	if err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render(fmt.Sprintf("Deleted %d sessions", deleted)))
+	return nil
+}
+
+// confirm prints prompt and reports whether the answer read from r is yes.
+func confirm(r io.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
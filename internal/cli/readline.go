@@ -0,0 +1,429 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/rs/zerolog/log"
+)
+
+// Multi-line input, see readMessage.
+const (
+	continuationMark = `\`
+	blockDelimiter   = `"""`
+)
+
+// errInterrupted is returned by ReadLine when Ctrl-C is pressed on an
+// empty line.
+var errInterrupted = errors.New("interrupted")
+
+// Control keys handled by the line editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// lineEditor reads input lines. On a terminal it supports Emacs-style
+// editing, history recall with the arrow keys and Ctrl-R reverse search;
+// otherwise it reads plain lines.
+type lineEditor struct {
+	in      *os.File
+	reader  *bufio.Reader
+	out     io.Writer
+	history *inputHistory
+
+	buf  []rune
+	pos  int // Cursor position in buf
+	rows int // Row of the cursor below the first prompt row
+}
+
+// newLineEditor creates an editor on stdin with history persisted to
+// historyPath ("" keeps it in memory).
+func newLineEditor(historyPath string) (*lineEditor, error) {
+	history, err := loadHistory(historyPath, 0)
+	return &lineEditor{
+		in:      os.Stdin,
+		reader:  bufio.NewReader(os.Stdin),
+		out:     os.Stdout,
+		history: history,
+	}, err
+}
+
+// ReadLine prints prompt and reads one line, returning io.EOF at the end of
+// input. Entered lines are added to the history.
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	fd := e.in.Fd()
+	if !term.IsTerminal(fd) {
+		fmt.Fprint(e.out, prompt)
+		return e.readPlain()
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		return e.readPlain()
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+	// Keep newline translation so output from autoplay stays aligned
+	enableOutputProcessing(fd)
+
+	line, err := e.edit(prompt)
+	if err == nil {
+		if err := e.history.add(line); err != nil {
+			log.Warn().Err(err).Msg("Failed to save input history")
+		}
+	}
+	return line, err
+}
+
+// readMessage reads a message that may span several lines with read: a
+// line ending with a backslash continues on the next line, and a line of
+// """ starts a block that ends at the next """ line. Continuation lines
+// are read with prompt cont. Ctrl-C while continuing drops the message and
+// returns "".
+func readMessage(read func(prompt string) (string, error), prompt, cont string) (string, error) {
+	line, err := read(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	if strings.TrimSpace(line) == blockDelimiter {
+		for {
+			line, err := read(cont)
+			if err != nil {
+				return "", dropInterrupted(err)
+			}
+			if strings.TrimSpace(line) == blockDelimiter {
+				return strings.Join(lines, "\n"), nil
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for strings.HasSuffix(line, continuationMark) {
+		lines = append(lines, strings.TrimSuffix(line, continuationMark))
+		if line, err = read(cont); err != nil {
+			return "", dropInterrupted(err)
+		}
+	}
+	return strings.Join(append(lines, line), "\n"), nil
+}
+
+// dropInterrupted turns Ctrl-C in a continuation line into an empty
+// message, so it cancels the message rather than the session.
+func dropInterrupted(err error) error {
+	if errors.Is(err, errInterrupted) {
+		return nil
+	}
+	return err
+}
+
+// readPlain reads a line without editing support.
+func (e *lineEditor) readPlain() (string, error) {
+	line, err := e.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// edit runs the editing loop on a terminal in raw mode.
+func (e *lineEditor) edit(prompt string) (string, error) {
+	e.buf, e.pos, e.rows = nil, 0, 0
+	browse := len(e.history.entries) // History entry being shown
+	var draft []rune                 // Line being typed before browsing
+
+	e.refresh(prompt, e.buf, e.pos)
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			e.refresh(prompt, e.buf, len(e.buf))
+			fmt.Fprint(e.out, "\n")
+			e.rows = 0
+			return string(e.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\n")
+			e.rows = 0
+			if len(e.buf) == 0 {
+				return "", errInterrupted
+			}
+			e.buf, e.pos = nil, 0
+			browse = len(e.history.entries)
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.pos = max(e.pos-1, 0)
+		case keyCtrlF:
+			e.pos = min(e.pos+1, len(e.buf))
+		case keyBackspace, keyCtrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append([]rune(nil), e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.pos
+			for start > 0 && e.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && e.buf[start-1] != ' ' {
+				start--
+			}
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+			e.rows = 0
+		case keyCtrlP:
+			browse, draft = e.browseHistory(browse, -1, draft)
+		case keyCtrlN:
+			browse, draft = e.browseHistory(browse, 1, draft)
+		case keyCtrlR:
+			submit, err := e.search()
+			if err != nil {
+				return "", err
+			}
+			if submit {
+				e.refresh(prompt, e.buf, len(e.buf))
+				fmt.Fprint(e.out, "\n")
+				e.rows = 0
+				return string(e.buf), nil
+			}
+		case keyEscape:
+			key, err := e.readEscape()
+			if err != nil {
+				return "", err
+			}
+			switch key {
+			case "A":
+				browse, draft = e.browseHistory(browse, -1, draft)
+			case "B":
+				browse, draft = e.browseHistory(browse, 1, draft)
+			case "C":
+				e.pos = min(e.pos+1, len(e.buf))
+			case "D":
+				e.pos = max(e.pos-1, 0)
+			case "H", "1~", "7~":
+				e.pos = 0
+			case "F", "4~", "8~":
+				e.pos = len(e.buf)
+			case "3~":
+				e.deleteAt(e.pos)
+			}
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+		e.refresh(prompt, e.buf, e.pos)
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns its final
+// part, e.g. "A" for the up arrow or "3~" for delete.
+func (e *lineEditor) readEscape() (string, error) {
+	r, _, err := e.reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	if r != '[' && r != 'O' {
+		return "", nil // Alt+key, ignored
+	}
+
+	var seq strings.Builder
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		seq.WriteRune(r)
+		if r >= 0x40 && r <= 0x7e {
+			return seq.String(), nil
+		}
+	}
+}
+
+// browseHistory moves through history by step, saving the typed line when
+// leaving it and restoring it when returning.
+func (e *lineEditor) browseHistory(index, step int, draft []rune) (int, []rune) {
+	entries := e.history.entries
+	next := index + step
+	if next < 0 || next > len(entries) {
+		return index, draft
+	}
+	if index == len(entries) {
+		draft = append([]rune(nil), e.buf...)
+	}
+	if next == len(entries) {
+		e.buf = append([]rune(nil), draft...)
+	} else {
+		e.buf = []rune(entries[next])
+	}
+	e.pos = len(e.buf)
+	return next, draft
+}
+
+// search runs an incremental reverse history search. Enter submits the
+// match and Ctrl-G restores the line. Escape accepts the match for editing;
+// other keys accept it and are then handled by the editor.
+func (e *lineEditor) search() (submit bool, err error) {
+	entries := e.history.entries
+	original, originalPos := e.buf, e.pos
+	var query []rune
+	match := len(entries) // Index of the current match, len when none
+
+	find := func(from int) {
+		match = len(entries)
+		if len(query) == 0 {
+			return
+		}
+		for i := min(from, len(entries)-1); i >= 0; i-- {
+			if strings.Contains(entries[i], string(query)) {
+				match = i
+				return
+			}
+		}
+	}
+	render := func() {
+		label := "(reverse-i-search)"
+		text := []rune(nil)
+		if match < len(entries) {
+			text = []rune(entries[match])
+		} else if len(query) > 0 {
+			label = "(failed reverse-i-search)"
+		}
+		e.refresh(label+"`"+string(query)+"': ", text, 0)
+	}
+
+	render()
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case r == keyCtrlR:
+			if match < len(entries) {
+				prev := match
+				find(match - 1)
+				if match == len(entries) {
+					match = prev // No older match, keep this one
+				}
+			}
+		case r == keyBackspace || r == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(len(entries) - 1)
+			}
+		case r == keyCtrlG || r == keyCtrlC:
+			e.buf, e.pos = original, originalPos
+			return false, nil
+		case r == '\r' || r == '\n':
+			if match < len(entries) {
+				e.buf = []rune(entries[match])
+			}
+			return true, nil
+		case r >= ' ' && r != keyBackspace:
+			query = append(query, r)
+			find(min(match, len(entries)-1))
+		default:
+			if match < len(entries) {
+				e.buf = []rune(entries[match])
+				e.pos = len(e.buf)
+			}
+			if r == keyEscape {
+				return false, nil
+			}
+			_ = e.reader.UnreadRune()
+			return false, nil
+		}
+		render()
+	}
+}
+
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *lineEditor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+// refresh redraws prompt and text, which may wrap over several rows, and
+// places the cursor at text position cursor.
+func (e *lineEditor) refresh(prompt string, text []rune, cursor int) {
+	cols := 80
+	if w, _, err := term.GetSize(e.in.Fd()); err == nil && w > 0 {
+		cols = w
+	}
+
+	var b strings.Builder
+	if e.rows > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", e.rows)
+	}
+	b.WriteString("\r\x1b[J" + prompt + string(text))
+
+	promptWidth := lipgloss.Width(prompt)
+	end := promptWidth + lipgloss.Width(string(text))
+	at := promptWidth + lipgloss.Width(string(text[:cursor]))
+	if len(text) > 0 && end%cols == 0 {
+		b.WriteString("\r\n") // Leave the pending wrap at the last column
+	}
+
+	row, col := at/cols, at%cols
+	if up := end/cols - row; up > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", up)
+	}
+	b.WriteString("\r")
+	if col > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", col)
+	}
+	e.rows = row
+
+	fmt.Fprint(e.out, b.String())
+}
@@ -0,0 +1,6 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package cli
+
+// enableOutputProcessing is a no-op where raw mode keeps output processing.
+func enableOutputProcessing(fd uintptr) {}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(t *testing.T, input string, entries ...string) *lineEditor {
+	t.Helper()
+	history, err := loadHistory("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.entries = entries
+	return &lineEditor{
+		reader:  bufio.NewReader(strings.NewReader(input)),
+		out:     io.Discard,
+		history: history,
+	}
+}
+
+func TestLineEditorEditing(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		history []string
+		want    string
+	}{
+		{"plain", "mine\r", nil, "mine"},
+		{"backspace", "minx\x7fe\r", nil, "mine"},
+		{"insert at start", "ine\x01m\r", nil, "mine"},
+		{"left arrow", "mne\x1b[D\x1b[Di\r", nil, "mine"},
+		{"kill to end", "mine ore\x01\x06\x06\x06\x06\x0b\r", nil, "mine"},
+		{"delete word", "travel to sol\x17\x17dock\r", nil, "travel dock"},
+		{"history up", "\x1b[A\r", []string{"dock", "mine"}, "mine"},
+		{"history up twice", "\x1b[A\x1b[A\r", []string{"dock", "mine"}, "dock"},
+		{"history down restores draft", "sell\x1b[A\x1b[B\r", []string{"mine"}, "sell"},
+		{"reverse search", "\x12do\r", []string{"dock", "mine", "undock"}, "undock"},
+		{"reverse search older", "\x12do\x12\r", []string{"dock", "mine", "undock"}, "dock"},
+		{"reverse search edit", "\x12mi\x05 belt\r", []string{"mine", "sell"}, "mine belt"},
+		{"reverse search cancel", "go\x12mi\x07\r", []string{"mine"}, "go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEditor(t, tt.input, tt.history...)
+			got, err := e.edit("> ")
+			if err != nil {
+				t.Fatalf("edit: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineEditorEOF(t *testing.T) {
+	e := newTestEditor(t, "\x04")
+	if _, err := e.edit("> "); err != io.EOF {
+		t.Errorf("Ctrl-D on empty line: got %v, want io.EOF", err)
+	}
+
+	e = newTestEditor(t, "\x03")
+	if _, err := e.edit("> "); err != errInterrupted {
+		t.Errorf("Ctrl-C on empty line: got %v, want errInterrupted", err)
+	}
+}
+
+func TestInputHistoryPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h, err := loadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"mine", "mine", "", "sell\nall", `path\to`, "dock"} {
+		if err := h.add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err = loadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"sell\nall", `path\to`, "dock"}
+	if strings.Join(h.entries, "|") != strings.Join(want, "|") {
+		t.Errorf("entries = %q, want %q", h.entries, want)
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"single line", []string{"mine"}, "mine"},
+		{"continuation", []string{`travel to sol \`, `then dock\`, "and sell"}, "travel to sol \nthen dock\nand sell"},
+		{"block", []string{`"""`, "Strategy:", "", "- mine", ` """ `}, "Strategy:\n\n- mine"},
+		{"interrupted", []string{`travel \`}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := tt.lines
+			var prompts []string
+			read := func(prompt string) (string, error) {
+				prompts = append(prompts, prompt)
+				if len(lines) == 0 {
+					return "", errInterrupted
+				}
+				line := lines[0]
+				lines = lines[1:]
+				return line, nil
+			}
+
+			got, err := readMessage(read, "> ", "... ")
+			if err != nil {
+				t.Fatalf("readMessage: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if prompts[0] != "> " || (len(prompts) > 1 && prompts[1] != "... ") {
+				t.Errorf("prompts = %q", prompts)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package cli
+
+import (
+	"github.com/charmbracelet/x/term"
+	"golang.org/x/sys/unix"
+)
+
+// enableOutputProcessing turns output post-processing back on after
+// term.MakeRaw, so "\n" written by other goroutines still returns the
+// carriage while a line is being edited.
+func enableOutputProcessing(fd uintptr) {
+	state, err := term.GetState(fd)
+	if err != nil {
+		return
+	}
+	state.Oflag |= unix.OPOST
+	_ = term.SetState(fd, state)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// sleepDirective starts a script line that waits, e.g. "@sleep 2s".
+const sleepDirective = "@sleep"
+
+// scriptStep is one line of a script: a message, a slash command or a wait.
+type scriptStep struct {
+	line  int           // Line number in the script, for errors
+	text  string        // Message or slash command; empty for a wait
+	delay time.Duration // Wait of an @sleep line
+}
+
+// parseScript reads a script. Blank lines and lines starting with # are
+// skipped.
+func parseScript(r io.Reader) ([]scriptStep, error) {
+	var steps []scriptStep
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if directive, arg, _ := strings.Cut(text, " "); directive == sleepDirective {
+			delay, err := time.ParseDuration(strings.TrimSpace(arg))
+			if err != nil || delay < 0 {
+				return nil, fmt.Errorf("line %d: usage: %s DURATION, e.g. %s 2s", n, sleepDirective, sleepDirective)
+			}
+			steps = append(steps, scriptStep{line: n, delay: delay})
+			continue
+		}
+		steps = append(steps, scriptStep{line: n, text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+	return steps, nil
+}
+
+// RunScript runs `mysis run script.txt`: each line of the script is sent
+// like typed input, in order, for reproducible demos and regression
+// scenarios. A script looks like:
+//
+//	# Comments and blank lines are skipped
+//	/provider local
+//	check my cargo
+//	/autoplay --turns 3 mine ore
+//	@sleep 1m
+//	/stats
+//
+// The script stops at the first failing line; autoplay still running at the
+// end is stopped. Errors carry an exit code, see ExitCode.
+func RunScript(
+	ctx context.Context,
+	cfg *config.Config,
+	sessionMgr *session.Manager,
+	sessionID string,
+	prov provider.Provider,
+	model string,
+	registry *provider.Registry,
+	proxy *mcp.Proxy,
+	tools []mcp.Tool,
+	history []provider.Message,
+	path string,
+	toolResults llm.ToolResultDisplay,
+) error {
+	//nolint:gosec // G304: Script path from the command line
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open script: %w", err)
+	}
+	steps, err := parseScript(f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	app := &App{
+		cfg:         cfg,
+		provider:    prov,
+		model:       model,
+		registry:    registry,
+		proxy:       proxy,
+		tools:       tools,
+		history:     history,
+		sessionMgr:  sessionMgr,
+		sessionID:   sessionID,
+		stats:       llm.NewStats(),
+		failures:    llm.NewFailureTracker(),
+		events:      features.OpenEventLog(sessionID),
+		toolResults: toolResults,
+	}
+	defer func() { _ = app.events.Close() }()
+	if err := app.loadScript(); err != nil {
+		return err
+	}
+	app.initAutoplayService()
+	app.initCommands()
+	defer func() {
+		if app.autoplayService.Status().Enabled {
+			_ = app.autoplayService.Stop()
+		}
+	}()
+
+	for _, step := range steps {
+		if step.text == "" {
+			fmt.Println(styles.Muted.Render(fmt.Sprintf("%s %s", sleepDirective, step.delay)))
+			select {
+			case <-time.After(step.delay):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		fmt.Println(styles.Brand.Render("> ") + step.text)
+		if features.IsCommand(step.text) {
+			err = app.commands.Run(ctx, step.text)
+			if errors.Is(err, features.ErrQuit) {
+				return nil
+			}
+		} else {
+			err = turnExitError(app.sendMessage(ctx, step.text))
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, step.line, err)
+		}
+	}
+	return nil
+}
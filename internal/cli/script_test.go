@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScript(t *testing.T) {
+	steps, err := parseScript(strings.NewReader(`# Dock and sell
+/provider local
+
+  check my cargo
+@sleep 1.5s
+/stats
+`))
+	if err != nil {
+		t.Fatalf("parseScript: %v", err)
+	}
+
+	want := []scriptStep{
+		{line: 2, text: "/provider local"},
+		{line: 4, text: "check my cargo"},
+		{line: 5, delay: 1500 * time.Millisecond},
+		{line: 6, text: "/stats"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d: %+v", len(steps), len(want), steps)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, steps[i], want[i])
+		}
+	}
+
+	if _, err := parseScript(strings.NewReader("hello\n@sleep soon\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("bad @sleep: got %v, want a line 2 error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// Session list output formats, see SessionListOptions.
+const (
+	ListFormatText = "text"
+	ListFormatJSON = "json"
+)
+
+// defaultListLimit is the number of sessions an unfiltered text listing
+// shows.
+const defaultListLimit = 20
+
+// SessionListOptions are the filters and output format of `mysis -l` and
+// `mysis session list`.
+type SessionListOptions struct {
+	Filter store.SessionFilter
+	Format string // ListFormatText or ListFormatJSON
+}
+
+// NewSessionListOptions validates format and returns the list options.
+// Unfiltered text listings show the most recent sessions only; filtered
+// and JSON listings show every match.
+func NewSessionListOptions(filter store.SessionFilter, format string) (SessionListOptions, error) {
+	switch format {
+	case ListFormatText:
+		if filter == (store.SessionFilter{}) {
+			filter.Limit = defaultListLimit
+		}
+	case ListFormatJSON:
+	default:
+		return SessionListOptions{}, fmt.Errorf("--format=%s must be text or json", format)
+	}
+	return SessionListOptions{Filter: filter, Format: format}, nil
+}
+
+// sessionJSON is a session in JSON listings.
+type sessionJSON struct {
+	ID           string    `json:"id"`
+	Name         *string   `json:"name"` // null for anonymous sessions
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+}
+
+// printSessionsJSON writes sessions to w as a JSON array.
+func printSessionsJSON(w io.Writer, sessions []store.Session) error {
+	list := make([]sessionJSON, len(sessions))
+	for i, sess := range sessions {
+		list[i] = sessionJSON(sess)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// UsageUsage is shown with usage command errors.
+const UsageUsage = "Usage: mysis usage [--since 7d] [--by session|day|provider] [--format text|json]"
+
+// UsageCmd runs `mysis usage`: the estimated tokens and cost of the LLM
+// calls recorded in the session event logs since the given time (zero for
+// all), grouped by session, day or provider, as a table or JSON.
+func UsageCmd(mgr *session.Manager, since time.Time, by, format string) error {
+	if format != ListFormatText && format != ListFormatJSON {
+		return fmt.Errorf("--format=%s must be text or json", format)
+	}
+	calls, err := features.ReadLLMCalls(since)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string)
+	report, err := features.UsageReport(calls, by, func(id string) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		name, err := mgr.Name(id)
+		if err != nil || name == "" {
+			name = "(anonymous " + id[:min(len(id), 8)] + ")"
+		}
+		names[id] = name
+		return name
+	})
+	if err != nil {
+		return err
+	}
+
+	if format == ListFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	if len(report) == 0 {
+		fmt.Println("No LLM calls recorded")
+		return nil
+	}
+	printUsageTable(os.Stdout, report, by)
+	return nil
+}
+
+// printUsageTable writes report to w as a table with a total row.
+func printUsageTable(w io.Writer, report []features.UsageRow, by string) {
+	width := len(by)
+	var total features.UsageRow
+	for _, row := range report {
+		width = max(width, len(row.Key))
+		total.Calls += row.Calls
+		total.InputTokens += row.InputTokens
+		total.OutputTokens += row.OutputTokens
+		total.Cost += row.Cost
+	}
+	total.Key = "total"
+
+	line := func(row features.UsageRow) string {
+		return fmt.Sprintf("%-*s  %7d  %12d  %12d  %10s", width, row.Key, row.Calls,
+			row.InputTokens, row.OutputTokens, fmt.Sprintf("$%.4f", row.Cost))
+	}
+	fmt.Fprintln(w, styles.Brand.Render(fmt.Sprintf("%-*s  %7s  %12s  %12s  %10s", width, by, "calls", "input tok", "output tok", "cost")))
+	for _, row := range report {
+		fmt.Fprintln(w, line(row))
+	}
+	fmt.Fprintln(w, styles.BrandBold.Render(line(total)))
+	fmt.Fprintln(w, styles.Muted.Render("Tokens and costs are estimates; costs need input_cost and output_cost in the provider config"))
+}
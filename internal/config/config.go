@@ -4,18 +4,181 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/xonecas/mysis/internal/styles"
 )
 
 // Config is the root configuration structure.
 type Config struct {
 	DefaultProvider string                    `toml:"default_provider"`
 	Providers       map[string]ProviderConfig `toml:"providers"`
-	MCP             MCPConfig                 `toml:"mcp"`
+	// System prompt of new sessions, inline or from a markdown file; a
+	// provider's own prompt takes precedence, see DefaultSystemPrompt
+	SystemPrompt string         `toml:"system_prompt"`
+	SystemFile   string         `toml:"system_file"`
+	MCP          MCPConfig      `toml:"mcp"`
+	History      HistoryConfig  `toml:"history"`
+	Autoplay     AutoplayConfig `toml:"autoplay"`
+	Fleet        FleetConfig    `toml:"fleet"`
+	TUI          TUIConfig      `toml:"tui"`
+	Theme        ThemeConfig    `toml:"theme"`
+	Keyring      KeyringConfig  `toml:"keyring"`
+	Logs         LogsConfig     `toml:"logs"`
+	// Lua scripts hooking into the turns of named sessions, by session
+	// name, see features.Script
+	Scripts map[string]string `toml:"scripts"`
+	// Named overrides of the providers, MCP upstream and autoplay
+	// defaults, see LoadProfile
+	Profiles map[string]toml.Primitive `toml:"profiles"`
+	Profile  string                    `toml:"-"` // Profile applied by LoadProfile, "" for none
+}
+
+// profileOverrides are the settings a profile overrides, pointing into the
+// config the profile applies to. Providers of a profile replace those of
+// the same name.
+type profileOverrides struct {
+	DefaultProvider *string                   `toml:"default_provider"`
+	Providers       map[string]ProviderConfig `toml:"providers"`
+	MCP             *MCPConfig                `toml:"mcp"`
+	Autoplay        *AutoplayConfig           `toml:"autoplay"`
+}
+
+// TUIConfig holds terminal UI display settings.
+type TUIConfig struct {
+	Markdown  bool `toml:"markdown"`  // Render assistant messages as Markdown (default true)
+	Reasoning bool `toml:"reasoning"` // Show reasoning lines in the conversation (default true)
+	// Interval of get_notifications calls for the notification panel; 0 only
+	// shows the notifications of the model's calls
+	NotificationPoll time.Duration `toml:"notification_poll"`
+	// Tool calls that wait for the user's approval: ApproveToolsOff,
+	// ApproveToolsMutating or ApproveToolsAll
+	ApproveTools string `toml:"approve_tools"`
+	ScreenReader bool   `toml:"screen_reader"` // Plain TUI for screen readers, like --screen-reader
+	// Static status bar icons without the animation ticks, see
+	// styles.SetReducedMotion
+	ReducedMotion bool `toml:"reduced_motion"`
+	Title         bool `toml:"title"` // Show the session and its state in the terminal title (default true)
+	// Alert when a turn ends or an error occurs while the terminal is not
+	// focused: NotifyOff, NotifyBell or NotifyDesktop
+	Notify string `toml:"notify"`
+	// How tool result images are shown: ImagesAuto, ImagesKitty,
+	// ImagesSixel or ImagesOff
+	Images string    `toml:"images"`
+	Layout TUILayout `toml:"layout"`
+}
+
+// Values of tui.approve_tools.
+const (
+	ApproveToolsOff      = "off"      // Run tool calls without asking (also "")
+	ApproveToolsMutating = "mutating" // Ask before game tools that change state
+	ApproveToolsAll      = "all"      // Ask before every tool call
+)
+
+// Values of tui.notify.
+const (
+	NotifyOff     = "off"     // No alerts (also "")
+	NotifyBell    = "bell"    // Ring the terminal bell
+	NotifyDesktop = "desktop" // Desktop notification through OSC 777
+)
+
+// Values of tui.images.
+const (
+	ImagesAuto  = "auto"  // Kitty or sixel as the terminal supports, else a placeholder (also "")
+	ImagesKitty = "kitty" // Kitty graphics protocol
+	ImagesSixel = "sixel" // Sixel graphics
+	ImagesOff   = "off"   // Placeholder lines only
+)
+
+// TUILayout holds the TUI pane sizes and visibility. Changes made with the
+// layout keys are saved to layout.json in the data directory, see SaveLayout.
+type TUILayout struct {
+	PanelWidth        int  `toml:"panel_width" json:"panel_width"`               // Side panel columns, 0 for the default
+	InputHeight       int  `toml:"input_height" json:"input_height"`             // Minimum input rows, 0 for 1
+	LogsHeight        int  `toml:"logs_height" json:"logs_height"`               // Log pane rows, 0 for the default
+	HideAutoplay      bool `toml:"hide_autoplay" json:"hide_autoplay"`           // Hide the autoplay panel
+	HideGameState     bool `toml:"hide_game_state" json:"hide_game_state"`       // Hide the game state panel
+	HideMap           bool `toml:"hide_map" json:"hide_map"`                     // Hide the map panel
+	HideNotifications bool `toml:"hide_notifications" json:"hide_notifications"` // Hide the notification panel
+	ShowLogs          bool `toml:"show_logs" json:"show_logs"`                   // Show the log pane
+}
+
+// TUI layout bounds, shared by the config checks and the layout keys.
+const (
+	MinPanelWidth  = 24
+	MaxPanelWidth  = 80
+	MaxInputHeight = 8
+	MinLogsHeight  = 3
+	MaxLogsHeight  = 30
+)
+
+// minNotificationPoll is the shortest tui.notification_poll, to spare the
+// game server.
+const minNotificationPoll = 10 * time.Second
+
+// ThemeConfig selects the color theme, see styles.ResolveTheme.
+type ThemeConfig struct {
+	Name   string            `toml:"name"`   // Built-in theme: default, light or high-contrast
+	Colors map[string]string `toml:"colors"` // Hex colors overriding the theme's, e.g. brand = "#FF8800"
+}
+
+// LogsConfig controls the rotation of the log files written in TUI mode.
+type LogsConfig struct {
+	MaxSizeMB int           `toml:"max_size_mb"` // Rotate a file past this size (default 10), 0 never
+	MaxAge    time.Duration `toml:"max_age"`     // Rotate a file this old, 0 never
+	Keep      int           `toml:"keep"`        // Rotated files kept (default 5)
+}
+
+// KeyringConfig selects the API keys read from the OS keyring before the
+// credentials file.
+type KeyringConfig struct {
+	Enabled bool            `toml:"enabled"` // Read keys from the keyring (default true)
+	Keys    map[string]bool `toml:"keys"`    // Per key name, overriding enabled
+}
+
+// Reads reports whether the API key name is read from the OS keyring.
+func (k KeyringConfig) Reads(name string) bool {
+	if enabled, ok := k.Keys[name]; ok {
+		return enabled
+	}
+	return k.Enabled
+}
+
+// FleetConfig lists the bots run together by `mysis fleet run`, or by
+// `mysis fleet start` from a fleet file, see LoadFleet.
+type FleetConfig struct {
+	Bots []FleetBot `toml:"bot"`
+	// Address serving the event stream WebSocket at /events and the
+	// /healthz and /readyz probes, e.g. "127.0.0.1:7777"; empty disables it
+	Listen string `toml:"listen"`
+	// Shared turn scheduler: at most MaxConcurrent bots take a turn at
+	// once, and turns start at least Stagger apart. Zero means no limit.
+	MaxConcurrent int           `toml:"max_concurrent"`
+	Stagger       time.Duration `toml:"stagger"`
+}
+
+// FleetBot is one autoplay session of a fleet. Each bot has its own
+// provider instance and game connection.
+type FleetBot struct {
+	Session    string `toml:"session"`     // Session name; resumed or created
+	Provider   string `toml:"provider"`    // Defaults to the session's provider, then default_provider
+	Autoplay   string `toml:"autoplay"`    // Goal and /autoplay options, e.g. "mine ore --turns 50 --safe"
+	Playbook   string `toml:"playbook"`    // Playbook file, instead of autoplay
+	SystemFile string `toml:"system_file"` // Optional system prompt markdown file
+	// Budget of the bot's run, unless set by autoplay options; 0 falls back
+	// to the [autoplay] limits
+	MaxTurns    int           `toml:"max_turns"`
+	MaxDuration time.Duration `toml:"max_duration"`
+	MaxTokens   int           `toml:"max_tokens"`
+	MaxCost     float64       `toml:"max_cost"`
 }
 
 // ProviderConfig holds LLM provider settings.
@@ -24,6 +187,16 @@ type ProviderConfig struct {
 	Model       string  `toml:"model"`
 	APIKeyName  string  `toml:"api_key_name"`
 	Temperature float64 `toml:"temperature"`
+	TopP        float64 `toml:"top_p"`       // Nucleus sampling, 0 for the server default
+	MaxTokens   int     `toml:"max_tokens"`  // Response token limit, 0 for the server default
+	InputCost   float64 `toml:"input_cost"`  // USD per million input tokens, for cost estimates
+	OutputCost  float64 `toml:"output_cost"` // USD per million output tokens, for cost estimates
+	// Model context window in tokens, for the TUI context fill; 0 when unknown
+	ContextWindow int `toml:"context_window"`
+	// System prompt of new sessions with this provider, inline or from a
+	// markdown file, instead of the global one
+	SystemPrompt string `toml:"system_prompt"`
+	SystemFile   string `toml:"system_file"`
 }
 
 // MCPConfig holds MCP proxy settings.
@@ -31,10 +204,210 @@ type MCPConfig struct {
 	Upstream string `toml:"upstream"`
 }
 
+// HistoryConfig controls which recent messages are sent to the LLM uncompressed.
+type HistoryConfig struct {
+	Window      string `toml:"window"`       // "turns" (default) or "tokens"
+	KeepTurns   int    `toml:"keep_turns"`   // Recent turns kept full in "turns" mode
+	TokenBudget int    `toml:"token_budget"` // Estimated tokens kept full in "tokens" mode
+}
+
+// History window modes.
+const (
+	HistoryWindowTurns  = "turns"
+	HistoryWindowTokens = "tokens"
+)
+
+// TokenWindow returns the token budget when windowing by tokens, or 0 when windowing by turns.
+func (h HistoryConfig) TokenWindow() int {
+	if h.Window == HistoryWindowTokens {
+		return h.TokenBudget
+	}
+	return 0
+}
+
+// AutoplayConfig holds autoplay scheduling settings.
+// Zero values fall back to the defaults in the constants package.
+type AutoplayConfig struct {
+	MinInterval time.Duration   `toml:"min_interval"` // Shortest wait between turns
+	MaxInterval time.Duration   `toml:"max_interval"` // Longest wait between turns
+	Schedule    []string        `toml:"schedule"`     // Local time windows ("22:00-06:00") when turns may run; empty means always
+	MaxTurns    int             `toml:"max_turns"`    // Default turn limit per run; 0 means unlimited
+	MaxDuration time.Duration   `toml:"max_duration"` // Default time limit per run; 0 means unlimited
+	MaxTokens   int             `toml:"max_tokens"`   // Default estimated token budget per run; 0 means unlimited
+	MaxCost     float64         `toml:"max_cost"`     // Default estimated cost budget per run in USD; 0 means unlimited
+	Goals       []AutoplayGoal  `toml:"goal"`         // Named goals for /autoplay rotate
+	Rotation    string          `toml:"rotation"`     // "round-robin" (default) or "weighted"
+	RefineEvery int             `toml:"refine_every"` // Ask the model to refine the goal every N turns; 0 disables
+	Jitter      float64         `toml:"jitter"`       // Random ± fraction (0-1) applied to each wait so bots started together drift apart
+	SafeTools   []string        `toml:"safe_tools"`   // Tool name globs allowed by /autoplay --safe; empty uses a built-in allowlist
+	Alerts      AlertConfig     `toml:"alerts"`
+	Breaker     BreakerConfig   `toml:"breaker"`
+	Watchdog    WatchdogConfig  `toml:"watchdog"`
+	Stop        []StopCondition `toml:"stop"`    // Checked against tool results after each turn
+	Webhooks    []WebhookConfig `toml:"webhook"` // POSTed JSON on autoplay lifecycle events
+	Milestones  MilestoneConfig `toml:"milestones"`
+}
+
+// WebhookConfig is an HTTP endpoint notified of autoplay lifecycle events.
+type WebhookConfig struct {
+	URL     string            `toml:"url"`
+	Events  []string          `toml:"events"`  // Subset of WebhookEvents; empty means all
+	Headers map[string]string `toml:"headers"` // Extra request headers, e.g. Authorization
+	Format  string            `toml:"format"`  // Body: "json" (default), or a summary message for "discord" or "slack"
+	// Consecutive failed turns before "error" is sent, so chat channels
+	// only hear about repeated errors; 0 sends every error
+	MinErrors int `toml:"min_errors"`
+}
+
+// Webhook body formats.
+const (
+	WebhookFormatJSON    = "json"
+	WebhookFormatDiscord = "discord"
+	WebhookFormatSlack   = "slack"
+)
+
+// MilestoneConfig sends the "milestone" webhook event each time an autoplay
+// run reaches a multiple of turns or of estimated cost. Zero disables.
+type MilestoneConfig struct {
+	Turns int     `toml:"turns"` // Every N turns
+	Cost  float64 `toml:"cost"`  // Every N USD
+}
+
+// Autoplay webhook events.
+const (
+	WebhookEventStarted   = "started"
+	WebhookEventStopped   = "stopped"
+	WebhookEventError     = "error"
+	WebhookEventBreaker   = "breaker"
+	WebhookEventAlert     = "alert"
+	WebhookEventFailover  = "failover"
+	WebhookEventGoal      = "goal"
+	WebhookEventMilestone = "milestone"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{WebhookEventStarted, WebhookEventStopped, WebhookEventError, WebhookEventBreaker, WebhookEventAlert, WebhookEventFailover, WebhookEventGoal, WebhookEventMilestone}
+
+// Wants reports whether the webhook subscribes to event.
+func (w WebhookConfig) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// BreakerConfig controls the autoplay circuit breaker, which trips after
+// consecutive failed turns. Zero values fall back to the defaults in the
+// constants package.
+type BreakerConfig struct {
+	Threshold  int           `toml:"threshold"`   // Consecutive failed turns that trip the breaker
+	Cooldown   time.Duration `toml:"cooldown"`    // Wait before retrying when auto_resume is set
+	AutoResume bool          `toml:"auto_resume"` // Retry after the cooldown instead of stopping
+	Failover   string        `toml:"failover"`    // Backup provider to switch to when the provider keeps failing
+}
+
+// AutoplayGoal is a named autoplay message that can be rotated with others.
+type AutoplayGoal struct {
+	Name    string `toml:"name"`
+	Message string `toml:"message"`
+	Weight  int    `toml:"weight"` // Relative share of turns in "weighted" rotation (default 1)
+}
+
+// AlertConfig pauses or stops autoplay when a game notification matches a
+// critical pattern, e.g. "under attack" or "destroyed".
+type AlertConfig struct {
+	Patterns []string `toml:"patterns"` // Case-insensitive text; empty disables alerts
+	Action   string   `toml:"action"`   // "pause" (default) or "stop"
+	Poll     bool     `toml:"poll"`     // Call get_notifications after every turn instead of only checking the model's calls
+}
+
+// WatchdogConfig controls detection of autoplay stuck in a loop: the same
+// tool calls with the same arguments, or identical tool results, turn after
+// turn.
+type WatchdogConfig struct {
+	Repeats int    `toml:"repeats"` // Identical turns in a row that count as stuck (default 3)
+	Action  string `toml:"action"`  // "nudge" (default), "pause", "stop" or "off"
+}
+
+// Watchdog actions besides StopActionPause and StopActionStop. A nudge adds
+// a corrective system message and stops autoplay if the loop continues.
+const (
+	WatchdogActionNudge = "nudge"
+	WatchdogActionOff   = "off"
+)
+
+// StopCondition pauses or stops autoplay when a numeric field of a tool
+// result crosses a threshold, e.g. hull below 20% or credits above 50000.
+type StopCondition struct {
+	Name   string  `toml:"name"`
+	Tool   string  `toml:"tool"`   // Only check results of this tool; empty means any tool
+	Field  string  `toml:"field"`  // Dotted JSON path in the tool result, e.g. "ship.hull"
+	Of     string  `toml:"of"`     // Optional dotted path; compares field as a percentage of it
+	Op     string  `toml:"op"`     // One of <, <=, >, >=, ==, !=
+	Value  float64 `toml:"value"`  // Threshold
+	Action string  `toml:"action"` // "pause" (default) or "stop"
+}
+
+// Stop condition actions.
+const (
+	StopActionPause = "pause"
+	StopActionStop  = "stop"
+)
+
+// stopConditionOps are the comparison operators a StopCondition accepts.
+var stopConditionOps = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true}
+
+// Autoplay goal rotation policies.
+const (
+	RotationRoundRobin = "round-robin"
+	RotationWeighted   = "weighted"
+)
+
+// Windows parses the autoplay schedule.
+func (a AutoplayConfig) Windows() ([]TimeWindow, error) {
+	windows := make([]TimeWindow, 0, len(a.Schedule))
+	for _, value := range a.Schedule {
+		w, err := ParseTimeWindow(value)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// UnknownKeys returns the keys of the config file at path that match no
+// setting, which are usually typos silently ignored by Load.
+func UnknownKeys(path string) ([]string, error) {
+	cfg := &Config{Providers: make(map[string]ProviderConfig)}
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	// Decoding the profiles marks their keys
+	for _, name := range cfg.ProfileNames() {
+		if err := cfg.applyProfile(md, name); err != nil {
+			return nil, err
+		}
+	}
+
+	var keys []string
+	for _, key := range md.Undecoded() {
+		keys = append(keys, key.String())
+	}
+	return keys, nil
+}
+
 // Load reads configuration from a TOML file and applies environment variable overrides.
 func Load(path string) (*Config, error) {
+	return LoadProfile(path, "")
+}
+
+// LoadProfile reads configuration like Load, with the named profile of
+// [profiles.NAME] applied over the file's settings; "" applies none.
+func LoadProfile(path, profile string) (*Config, error) {
 	cfg := &Config{
 		Providers: make(map[string]ProviderConfig),
+		TUI:       TUIConfig{Markdown: true, Reasoning: true, Title: true},
+		Keyring:   KeyringConfig{Enabled: true},
+		Logs:      LogsConfig{MaxSizeMB: 10, Keep: 5},
 	}
 
 	// Config file is required
@@ -48,10 +421,15 @@ func Load(path string) (*Config, error) {
 	}
 
 	// Load from file
-	_, err := toml.DecodeFile(path, cfg)
+	md, err := toml.DecodeFile(path, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if profile != "" {
+		if err := cfg.applyProfile(md, profile); err != nil {
+			return nil, err
+		}
+	}
 
 	// Apply environment variable overrides
 	applyEnvOverrides(cfg)
@@ -63,6 +441,67 @@ func Load(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// LoadFleet reads a fleet file for `mysis fleet start`: the settings of
+// [fleet] at the top level and a [[bot]] table per bot. It replaces the
+// config's own [fleet] and is validated against its providers.
+func (c *Config) LoadFleet(path string) error {
+	var fleet FleetConfig
+	if _, err := toml.DecodeFile(path, &fleet); err != nil {
+		return fmt.Errorf("failed to parse fleet file: %w", err)
+	}
+	if errs := validateFleetConfig(fleet, c.Providers); len(errs) > 0 {
+		return fmt.Errorf("invalid fleet file %s: %w", path, errors.Join(errs...))
+	}
+	c.Fleet = fleet
+	return nil
+}
+
+// ProfileNames returns the names of the profiles in the config, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// applyProfile applies the named profile over the settings decoded from
+// the file with md.
+func (c *Config) applyProfile(md toml.MetaData, name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("profile %q not found: the config has no [profiles]", name)
+		}
+		return fmt.Errorf("profile %q not found, the config has %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if c.Providers == nil {
+		c.Providers = make(map[string]ProviderConfig)
+	}
+	overrides := profileOverrides{
+		DefaultProvider: &c.DefaultProvider,
+		Providers:       c.Providers,
+		MCP:             &c.MCP,
+		Autoplay:        &c.Autoplay,
+	}
+	if err := md.PrimitiveDecode(profile, &overrides); err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	c.Profile = name
+	return nil
+}
+
+// DefaultSystemPrompt returns the system prompt configured for new
+// sessions with the named provider: its own inline prompt or file, else the
+// global ones. At most one of prompt and file is set.
+func (c *Config) DefaultSystemPrompt(provider string) (prompt, file string) {
+	if p := c.Providers[provider]; p.SystemPrompt != "" || p.SystemFile != "" {
+		return p.SystemPrompt, p.SystemFile
+	}
+	return c.SystemPrompt, c.SystemFile
+}
+
 // Validate returns an error if the configuration is invalid.
 func (c *Config) Validate() error {
 	var errs []error
@@ -75,6 +514,10 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.SystemPrompt != "" && c.SystemFile != "" {
+		errs = append(errs, errors.New("set either system_prompt or system_file, not both"))
+	}
+
 	// Validate default provider if specified
 	if c.DefaultProvider != "" {
 		if _, ok := c.Providers[c.DefaultProvider]; !ok {
@@ -82,6 +525,53 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	errs = append(errs, validateHistoryConfig(c.History)...)
+	errs = append(errs, validateAutoplayConfig(c.Autoplay)...)
+	if failover := c.Autoplay.Breaker.Failover; failover != "" {
+		if _, ok := c.Providers[failover]; !ok {
+			errs = append(errs, fmt.Errorf("autoplay.breaker.failover=%q does not exist in providers", failover))
+		}
+	}
+	errs = append(errs, validateFleetConfig(c.Fleet, c.Providers)...)
+	for session, path := range c.Scripts {
+		if path == "" {
+			errs = append(errs, fmt.Errorf("scripts.%s: path is required", session))
+		}
+	}
+	if poll := c.TUI.NotificationPoll; poll != 0 && poll < minNotificationPoll {
+		errs = append(errs, fmt.Errorf("tui.notification_poll=%s must be 0 or at least %s", poll, minNotificationPoll))
+	}
+	switch c.TUI.ApproveTools {
+	case "", ApproveToolsOff, ApproveToolsMutating, ApproveToolsAll:
+	default:
+		errs = append(errs, fmt.Errorf("tui.approve_tools=%q must be %s, %s or %s",
+			c.TUI.ApproveTools, ApproveToolsOff, ApproveToolsMutating, ApproveToolsAll))
+	}
+	switch c.TUI.Notify {
+	case "", NotifyOff, NotifyBell, NotifyDesktop:
+	default:
+		errs = append(errs, fmt.Errorf("tui.notify=%q must be %s, %s or %s",
+			c.TUI.Notify, NotifyOff, NotifyBell, NotifyDesktop))
+	}
+	switch c.TUI.Images {
+	case "", ImagesAuto, ImagesKitty, ImagesSixel, ImagesOff:
+	default:
+		errs = append(errs, fmt.Errorf("tui.images=%q must be %s, %s, %s or %s",
+			c.TUI.Images, ImagesAuto, ImagesKitty, ImagesSixel, ImagesOff))
+	}
+	errs = append(errs, validateTUILayout(c.TUI.Layout)...)
+	if c.Logs.MaxSizeMB < 0 || c.Logs.MaxAge < 0 || c.Logs.Keep < 0 {
+		errs = append(errs, errors.New("logs.max_size_mb, max_age and keep must not be negative"))
+	}
+	for name := range c.Keyring.Keys {
+		if err := ValidateKeyName(name); err != nil {
+			errs = append(errs, fmt.Errorf("keyring.keys: %w", err))
+		}
+	}
+	if _, err := styles.ResolveTheme(c.Theme.Name, c.Theme.Colors); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -89,6 +579,197 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func validateFleetConfig(cfg FleetConfig, providers map[string]ProviderConfig) []error {
+	var errs []error
+	if cfg.Listen != "" {
+		if _, _, err := net.SplitHostPort(cfg.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("fleet.listen=%q must be host:port: %w", cfg.Listen, err))
+		}
+	}
+	if cfg.MaxConcurrent < 0 {
+		errs = append(errs, fmt.Errorf("fleet.max_concurrent=%d must not be negative", cfg.MaxConcurrent))
+	}
+	if cfg.Stagger < 0 {
+		errs = append(errs, fmt.Errorf("fleet.stagger=%s must not be negative", cfg.Stagger))
+	}
+	sessions := make(map[string]bool)
+	for i, bot := range cfg.Bots {
+		if bot.Session == "" {
+			errs = append(errs, fmt.Errorf("fleet.bot[%d]: session is required", i))
+		} else if sessions[bot.Session] {
+			errs = append(errs, fmt.Errorf("fleet.bot[%d]: duplicate session %q", i, bot.Session))
+		}
+		sessions[bot.Session] = true
+		if bot.Provider != "" {
+			if _, ok := providers[bot.Provider]; !ok {
+				errs = append(errs, fmt.Errorf("fleet.bot[%d]: provider=%q does not exist in providers", i, bot.Provider))
+			}
+		}
+		if (bot.Autoplay == "") == (bot.Playbook == "") {
+			errs = append(errs, fmt.Errorf("fleet.bot[%d]: exactly one of autoplay or playbook is required", i))
+		}
+		if bot.MaxTurns < 0 || bot.MaxDuration < 0 || bot.MaxTokens < 0 || bot.MaxCost < 0 {
+			errs = append(errs, fmt.Errorf("fleet.bot[%d]: max_turns, max_duration, max_tokens and max_cost must not be negative", i))
+		}
+	}
+	return errs
+}
+
+func validateHistoryConfig(cfg HistoryConfig) []error {
+	var errs []error
+	switch cfg.Window {
+	case "", HistoryWindowTurns:
+	case HistoryWindowTokens:
+		if cfg.TokenBudget <= 0 {
+			errs = append(errs, errors.New("history.token_budget must be positive when history.window is \"tokens\""))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("history.window=%q must be \"turns\" or \"tokens\"", cfg.Window))
+	}
+
+	if cfg.KeepTurns < 0 {
+		errs = append(errs, fmt.Errorf("history.keep_turns=%d must not be negative", cfg.KeepTurns))
+	}
+
+	return errs
+}
+
+func validateAutoplayConfig(cfg AutoplayConfig) []error {
+	var errs []error
+	if cfg.MinInterval < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.min_interval=%s must not be negative", cfg.MinInterval))
+	}
+	if cfg.MaxInterval < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.max_interval=%s must not be negative", cfg.MaxInterval))
+	}
+	if cfg.MinInterval > 0 && cfg.MaxInterval > 0 && cfg.MinInterval > cfg.MaxInterval {
+		errs = append(errs, fmt.Errorf("autoplay.min_interval=%s must not exceed autoplay.max_interval=%s", cfg.MinInterval, cfg.MaxInterval))
+	}
+	if cfg.MaxTurns < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.max_turns=%d must not be negative", cfg.MaxTurns))
+	}
+	if cfg.MaxDuration < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.max_duration=%s must not be negative", cfg.MaxDuration))
+	}
+	if cfg.MaxTokens < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.max_tokens=%d must not be negative", cfg.MaxTokens))
+	}
+	if cfg.MaxCost < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.max_cost=%v must not be negative", cfg.MaxCost))
+	}
+	switch cfg.Alerts.Action {
+	case "", StopActionPause, StopActionStop:
+	default:
+		errs = append(errs, fmt.Errorf("autoplay.alerts.action=%q must be %q or %q", cfg.Alerts.Action, StopActionPause, StopActionStop))
+	}
+	if cfg.Watchdog.Repeats < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.watchdog.repeats=%d must not be negative", cfg.Watchdog.Repeats))
+	}
+	switch cfg.Watchdog.Action {
+	case "", WatchdogActionNudge, StopActionPause, StopActionStop, WatchdogActionOff:
+	default:
+		errs = append(errs, fmt.Errorf("autoplay.watchdog.action=%q must be %q, %q, %q or %q",
+			cfg.Watchdog.Action, WatchdogActionNudge, StopActionPause, StopActionStop, WatchdogActionOff))
+	}
+	for i, pattern := range cfg.SafeTools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("autoplay.safe_tools[%d]=%q is not a valid pattern: %v", i, pattern, err))
+		}
+	}
+	if cfg.RefineEvery < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.refine_every=%d must not be negative", cfg.RefineEvery))
+	}
+	if cfg.Jitter < 0 || cfg.Jitter > 1 {
+		errs = append(errs, fmt.Errorf("autoplay.jitter=%v must be between 0 and 1", cfg.Jitter))
+	}
+	if cfg.Breaker.Threshold < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.breaker.threshold=%d must not be negative", cfg.Breaker.Threshold))
+	}
+	if cfg.Breaker.Cooldown < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.breaker.cooldown=%s must not be negative", cfg.Breaker.Cooldown))
+	}
+	switch cfg.Rotation {
+	case "", RotationRoundRobin, RotationWeighted:
+	default:
+		errs = append(errs, fmt.Errorf("autoplay.rotation=%q must be %q or %q", cfg.Rotation, RotationRoundRobin, RotationWeighted))
+	}
+	names := make(map[string]bool)
+	for i, g := range cfg.Goals {
+		if g.Name == "" {
+			errs = append(errs, fmt.Errorf("autoplay.goal[%d]: name is required", i))
+		} else if names[g.Name] {
+			errs = append(errs, fmt.Errorf("autoplay.goal[%d]: duplicate name %q", i, g.Name))
+		}
+		names[g.Name] = true
+		if g.Message == "" {
+			errs = append(errs, fmt.Errorf("autoplay.goal[%d]: message is required", i))
+		}
+		if g.Weight < 0 {
+			errs = append(errs, fmt.Errorf("autoplay.goal[%d]: weight=%d must not be negative", i, g.Weight))
+		}
+	}
+	for i, c := range cfg.Stop {
+		if c.Name == "" {
+			errs = append(errs, fmt.Errorf("autoplay.stop[%d]: name is required", i))
+		}
+		if c.Field == "" {
+			errs = append(errs, fmt.Errorf("autoplay.stop[%d]: field is required", i))
+		}
+		if !stopConditionOps[c.Op] {
+			errs = append(errs, fmt.Errorf("autoplay.stop[%d]: op=%q must be one of <, <=, >, >=, ==, !=", i, c.Op))
+		}
+		switch c.Action {
+		case "", StopActionPause, StopActionStop:
+		default:
+			errs = append(errs, fmt.Errorf("autoplay.stop[%d]: action=%q must be %q or %q", i, c.Action, StopActionPause, StopActionStop))
+		}
+	}
+	for i, w := range cfg.Webhooks {
+		if w.URL == "" {
+			errs = append(errs, fmt.Errorf("autoplay.webhook[%d]: url is required", i))
+		} else if err := validateEndpoint(w.URL); err != nil {
+			errs = append(errs, fmt.Errorf("autoplay.webhook[%d]: url=%q is invalid: %v", i, w.URL, err))
+		}
+		for _, event := range w.Events {
+			if !slices.Contains(WebhookEvents, event) {
+				errs = append(errs, fmt.Errorf("autoplay.webhook[%d]: unknown event %q (want one of %s)", i, event, strings.Join(WebhookEvents, ", ")))
+			}
+		}
+		switch w.Format {
+		case "", WebhookFormatJSON, WebhookFormatDiscord, WebhookFormatSlack:
+		default:
+			errs = append(errs, fmt.Errorf("autoplay.webhook[%d]: format=%q must be %q, %q or %q", i, w.Format, WebhookFormatJSON, WebhookFormatDiscord, WebhookFormatSlack))
+		}
+		if w.MinErrors < 0 {
+			errs = append(errs, fmt.Errorf("autoplay.webhook[%d]: min_errors=%d must not be negative", i, w.MinErrors))
+		}
+	}
+	if cfg.Milestones.Turns < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.milestones.turns=%d must not be negative", cfg.Milestones.Turns))
+	}
+	if cfg.Milestones.Cost < 0 {
+		errs = append(errs, fmt.Errorf("autoplay.milestones.cost=%v must not be negative", cfg.Milestones.Cost))
+	}
+	if _, err := cfg.Windows(); err != nil {
+		errs = append(errs, fmt.Errorf("autoplay.schedule: %w", err))
+	}
+	return errs
+}
+
+func validateTUILayout(layout TUILayout) []error {
+	var errs []error
+	if w := layout.PanelWidth; w != 0 && (w < MinPanelWidth || w > MaxPanelWidth) {
+		errs = append(errs, fmt.Errorf("tui.layout.panel_width=%d must be 0 or between %d and %d", w, MinPanelWidth, MaxPanelWidth))
+	}
+	if h := layout.InputHeight; h < 0 || h > MaxInputHeight {
+		errs = append(errs, fmt.Errorf("tui.layout.input_height=%d must be between 0 and %d", h, MaxInputHeight))
+	}
+	if h := layout.LogsHeight; h != 0 && (h < MinLogsHeight || h > MaxLogsHeight) {
+		errs = append(errs, fmt.Errorf("tui.layout.logs_height=%d must be 0 or between %d and %d", h, MinLogsHeight, MaxLogsHeight))
+	}
+	return errs
+}
+
 func validateProviderConfig(name string, cfg ProviderConfig) []error {
 	var errs []error
 	if cfg.Endpoint == "" {
@@ -105,6 +786,26 @@ func validateProviderConfig(name string, cfg ProviderConfig) []error {
 		errs = append(errs, fmt.Errorf("providers.%s.temperature=%v must be between 0.0 and 2.0", name, cfg.Temperature))
 	}
 
+	if cfg.TopP < 0.0 || cfg.TopP > 1.0 {
+		errs = append(errs, fmt.Errorf("providers.%s.top_p=%v must be between 0.0 and 1.0", name, cfg.TopP))
+	}
+
+	if cfg.MaxTokens < 0 {
+		errs = append(errs, fmt.Errorf("providers.%s.max_tokens=%d must not be negative", name, cfg.MaxTokens))
+	}
+
+	if cfg.InputCost < 0 || cfg.OutputCost < 0 {
+		errs = append(errs, fmt.Errorf("providers.%s.input_cost and output_cost must not be negative", name))
+	}
+
+	if cfg.ContextWindow < 0 {
+		errs = append(errs, fmt.Errorf("providers.%s.context_window=%d must not be negative", name, cfg.ContextWindow))
+	}
+
+	if cfg.SystemPrompt != "" && cfg.SystemFile != "" {
+		errs = append(errs, fmt.Errorf("providers.%s: set either system_prompt or system_file, not both", name))
+	}
+
 	return errs
 }
 
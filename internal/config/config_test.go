@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profilesConfig = `
+default_provider = "opencode"
+
+[providers.opencode]
+endpoint = "https://opencode.ai/zen/v1"
+model = "big-model"
+
+[providers.ollama]
+endpoint = "http://localhost:11434"
+model = "llama3"
+
+[mcp]
+upstream = "https://game.example.com/mcp"
+
+[autoplay]
+min_interval = "30s"
+max_turns = 100
+
+[profiles.local]
+default_provider = "ollama"
+
+[profiles.local.providers.ollama]
+endpoint = "http://gpu-box:11434"
+model = "qwen3"
+
+[profiles.local.mcp]
+upstream = "http://localhost:8080/mcp"
+
+[profiles.local.autoplay]
+max_turns = 5
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := writeConfig(t, profilesConfig)
+
+	cfg, err := LoadProfile(path, "local")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if cfg.Profile != "local" || cfg.DefaultProvider != "ollama" || cfg.MCP.Upstream != "http://localhost:8080/mcp" {
+		t.Errorf("profile not applied: profile %q, provider %q, upstream %q", cfg.Profile, cfg.DefaultProvider, cfg.MCP.Upstream)
+	}
+	if got := cfg.Providers["ollama"]; got.Endpoint != "http://gpu-box:11434" || got.Model != "qwen3" {
+		t.Errorf("ollama = %+v, want the profile's", got)
+	}
+	if got := cfg.Providers["opencode"]; got.Model != "big-model" {
+		t.Errorf("opencode = %+v, want the file's", got)
+	}
+	// Autoplay settings the profile leaves alone are kept
+	if cfg.Autoplay.MaxTurns != 5 || cfg.Autoplay.MinInterval != 30*time.Second {
+		t.Errorf("autoplay = max_turns %d, min_interval %s; want 5, 30s", cfg.Autoplay.MaxTurns, cfg.Autoplay.MinInterval)
+	}
+
+	base, err := Load(path)
+	if err != nil || base.DefaultProvider != "opencode" || base.Autoplay.MaxTurns != 100 || base.Profile != "" {
+		t.Errorf("Load applied a profile: %+v, %v", base, err)
+	}
+
+	if _, err := LoadProfile(path, "prod"); err == nil || !strings.Contains(err.Error(), "local") {
+		t.Errorf("unknown profile: err = %v, want the profiles listed", err)
+	}
+}
+
+func TestUnknownKeysInProfiles(t *testing.T) {
+	path := writeConfig(t, profilesConfig+"\n[profiles.local.tui]\nmarkdown = false\n")
+	keys, err := UnknownKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"profiles.local.tui", "profiles.local.tui.markdown"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("UnknownKeys() = %q, want %q", keys, want)
+	}
+}
+
+func TestDefaultSystemPrompt(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+system_prompt = "Be brief."
+
+[providers.opencode]
+endpoint = "https://opencode.ai/zen/v1"
+model = "big-model"
+
+[providers.ollama]
+endpoint = "http://localhost:11434"
+model = "llama3"
+system_file = "prompts/ollama.md"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt, file := cfg.DefaultSystemPrompt("opencode"); prompt != "Be brief." || file != "" {
+		t.Errorf("DefaultSystemPrompt(opencode) = %q, %q, want the global prompt", prompt, file)
+	}
+	if prompt, file := cfg.DefaultSystemPrompt("ollama"); prompt != "" || file != "prompts/ollama.md" {
+		t.Errorf("DefaultSystemPrompt(ollama) = %q, %q, want the provider's file", prompt, file)
+	}
+
+	_, err = Load(writeConfig(t, `
+system_prompt = "Be brief."
+system_file = "prompt.md"
+
+[providers.ollama]
+endpoint = "http://localhost:11434"
+model = "llama3"
+`))
+	if err == nil || !strings.Contains(err.Error(), "system_prompt or system_file") {
+		t.Errorf("Load() error = %v, want both system prompts rejected", err)
+	}
+}
+
+func TestLoadFleet(t *testing.T) {
+	cfg, err := Load(writeConfig(t, profilesConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fleet.toml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`
+max_concurrent = 2
+stagger = "10s"
+
+[[bot]]
+session = "miner"
+provider = "ollama"
+autoplay = "mine ore"
+max_cost = 1.5
+
+[[bot]]
+session = "scout"
+playbook = "explore.toml"
+max_turns = 20
+`)
+	if err := cfg.LoadFleet(path); err != nil {
+		t.Fatalf("LoadFleet: %v", err)
+	}
+	if cfg.Fleet.MaxConcurrent != 2 || cfg.Fleet.Stagger != 10*time.Second || len(cfg.Fleet.Bots) != 2 {
+		t.Fatalf("fleet = %+v", cfg.Fleet)
+	}
+	if cfg.Fleet.Bots[0].MaxCost != 1.5 || cfg.Fleet.Bots[1].MaxTurns != 20 {
+		t.Errorf("bot budgets = %+v", cfg.Fleet.Bots)
+	}
+
+	write("[[bot]]\nsession = \"miner\"\nprovider = \"missing\"\nautoplay = \"mine ore\"\n")
+	if err := cfg.LoadFleet(path); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("unknown provider: err = %v", err)
+	}
+	if len(cfg.Fleet.Bots) != 2 {
+		t.Errorf("invalid fleet file replaced the fleet: %+v", cfg.Fleet)
+	}
+}
@@ -2,13 +2,36 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// API key sources, see Credentials.LookupAPIKey.
+const (
+	SourceKeyring = "keyring"
+	SourceFile    = "credentials file"
 )
 
-// Credentials holds API keys for LLM providers.
+// Credentials file names in the data directory; credentials.enc replaces
+// credentials.json once encrypted, see EncryptCredentials.
+const (
+	credentialsFile          = "credentials.json"
+	encryptedCredentialsFile = "credentials.enc"
+)
+
+// Credentials holds API keys for LLM providers. Keys are looked up in the
+// OS keyring first, then in the credentials file.
 type Credentials struct {
 	Providers map[string]ProviderCredentials `json:"providers"`
+
+	keyring      Keyring                // nil when there is no OS keyring
+	readsKeyring func(name string) bool // Keys read from the keyring; nil for all
+	passphrase   string                 // Of the encrypted file; "" when not encrypted
 }
 
 // ProviderCredentials holds authentication for a single provider.
@@ -16,23 +39,48 @@ type ProviderCredentials struct {
 	APIKey string `json:"api_key"`
 }
 
-// LoadCredentials reads credentials from ~/.zoea-nova/credentials.json.
-func LoadCredentials() (*Credentials, error) {
-	path, err := credentialsPath()
+// LoadCredentials reads credentials from credentials.json in the data
+// directory, or from credentials.enc when it is encrypted, with the
+// passphrase of $MYSIS_CREDENTIALS_PASSPHRASE or else from passphrase. The
+// API keys keyring selects are read from the OS keyring first, see
+// SystemKeyring.
+func LoadCredentials(keyring KeyringConfig, passphrase PassphraseFunc) (*Credentials, error) {
+	dir, err := DataDir()
 	if err != nil {
 		return nil, err
 	}
 
 	creds := &Credentials{
-		Providers: make(map[string]ProviderCredentials),
+		Providers:    make(map[string]ProviderCredentials),
+		keyring:      SystemKeyring(),
+		readsKeyring: keyring.Reads,
 	}
 
-	//nolint:gosec // G304: Path from validated config file
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		return creds, nil
-	}
-	if err != nil {
+	//nolint:gosec // G304: Path from the data directory
+	data, err := os.ReadFile(filepath.Join(dir, encryptedCredentialsFile))
+	switch {
+	case err == nil:
+		if creds.passphrase = os.Getenv(PassphraseEnv); creds.passphrase == "" {
+			if passphrase == nil {
+				return nil, fmt.Errorf("the credentials file is encrypted: set %s", PassphraseEnv)
+			}
+			if creds.passphrase, err = passphrase(); err != nil {
+				return nil, err
+			}
+		}
+		if data, err = openCredentials(data, creds.passphrase); err != nil {
+			return nil, err
+		}
+	case os.IsNotExist(err):
+		//nolint:gosec // G304: Path from the data directory
+		data, err = os.ReadFile(filepath.Join(dir, credentialsFile))
+		if os.IsNotExist(err) {
+			return creds, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
 		return nil, err
 	}
 
@@ -43,28 +91,142 @@ func LoadCredentials() (*Credentials, error) {
 	return creds, nil
 }
 
-// SaveCredentials writes credentials to ~/.zoea-nova/credentials.json with 0600 permissions.
+// SaveCredentials writes credentials to credentials.json in the data
+// directory with 0600 permissions, or to credentials.enc when they were
+// loaded from it.
 func SaveCredentials(creds *Credentials) error {
 	dir, err := EnsureDataDir()
 	if err != nil {
 		return err
 	}
 
-	path := filepath.Join(dir, "credentials.json")
 	data, err := json.MarshalIndent(creds, "", "  ")
 	if err != nil {
 		return err
 	}
+	if creds.passphrase == "" {
+		return os.WriteFile(filepath.Join(dir, credentialsFile), data, 0600)
+	}
+	if data, err = sealCredentials(data, creds.passphrase); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, encryptedCredentialsFile), data, 0600)
+}
 
-	return os.WriteFile(path, data, 0600)
+// Encrypted reports whether the credentials were loaded from the
+// encrypted credentials file.
+func (c *Credentials) Encrypted() bool {
+	return c.passphrase != ""
+}
+
+// EncryptCredentials encrypts credentials.json with passphrase into
+// credentials.enc and removes credentials.json. Keys in the OS keyring are
+// left there.
+func EncryptCredentials(keyring KeyringConfig, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("empty passphrase")
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, encryptedCredentialsFile)); err == nil {
+		return errors.New("the credentials file is already encrypted")
+	}
+	path := filepath.Join(dir, credentialsFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("no credentials file to encrypt: %s", path)
+	}
+	creds, err := LoadCredentials(keyring, nil)
+	if err != nil {
+		return fmt.Errorf("load credentials: %w", err)
+	}
+	creds.passphrase = passphrase
+	if err := SaveCredentials(creds); err != nil {
+		return fmt.Errorf("save encrypted credentials: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %s: %w", path, err)
+	}
+	return nil
 }
 
 // GetAPIKey returns the API key for a given provider, or empty string if not set.
 func (c *Credentials) GetAPIKey(provider string) string {
-	if c == nil || c.Providers == nil {
-		return ""
+	key, _ := c.LookupAPIKey(provider)
+	return key
+}
+
+// KeyNames returns the names of the keys in the credentials file, sorted.
+func (c *Credentials) KeyNames() []string {
+	var names []string
+	for name, p := range c.Providers {
+		if p.APIKey != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupAPIKey returns the API key for a given provider and where it is
+// stored, SourceKeyring or SourceFile, or empty strings if not set.
+func (c *Credentials) LookupAPIKey(provider string) (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	if c.usesKeyring(provider) {
+		key, err := c.keyring.Get(provider)
+		switch {
+		case err == nil && key != "":
+			return key, SourceKeyring
+		case err != nil && !errors.Is(err, ErrKeyNotFound):
+			log.Warn().Err(err).Str("key_name", provider).Msg("Failed to read OS keyring - using credentials file")
+		}
+	}
+	if key := c.Providers[provider].APIKey; key != "" {
+		return key, SourceFile
 	}
-	return c.Providers[provider].APIKey
+	return "", ""
+}
+
+// usesKeyring reports whether the API key name is read from the OS keyring.
+func (c *Credentials) usesKeyring(name string) bool {
+	return c.keyring != nil && (c.readsKeyring == nil || c.readsKeyring(name))
+}
+
+// StoreAPIKey stores the API key name in the OS keyring, removing it from
+// the credentials file, or in the credentials file when there is no
+// keyring or keyring does not select the key. Returns where the key was
+// stored.
+func StoreAPIKey(name, key string, keyring KeyringConfig, passphrase PassphraseFunc) (string, error) {
+	if err := ValidateKeyName(name); err != nil {
+		return "", err
+	}
+	creds, err := LoadCredentials(keyring, passphrase)
+	if err != nil {
+		return "", fmt.Errorf("load credentials: %w", err)
+	}
+
+	if creds.usesKeyring(name) {
+		if err := creds.keyring.Set(name, key); err != nil {
+			return "", err
+		}
+		if _, ok := creds.Providers[name]; !ok {
+			return SourceKeyring, nil
+		}
+		delete(creds.Providers, name)
+		if err := SaveCredentials(creds); err != nil {
+			return "", fmt.Errorf("remove key from credentials file: %w", err)
+		}
+		return SourceKeyring, nil
+	}
+
+	creds.SetAPIKey(name, key)
+	if err := SaveCredentials(creds); err != nil {
+		return "", fmt.Errorf("save credentials: %w", err)
+	}
+	return SourceFile, nil
 }
 
 // SetAPIKey sets the API key for a given provider.
@@ -74,11 +236,3 @@ func (c *Credentials) SetAPIKey(provider, apiKey string) {
 	}
 	c.Providers[provider] = ProviderCredentials{APIKey: apiKey}
 }
-
-func credentialsPath() (string, error) {
-	dir, err := DataDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, "credentials.json"), nil
-}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeKeyring is an in-memory Keyring.
+type fakeKeyring map[string]string
+
+func (k fakeKeyring) Get(name string) (string, error) {
+	key, ok := k[name]
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (k fakeKeyring) Set(name, key string) error {
+	k[name] = key
+	return nil
+}
+
+func TestLookupAPIKey(t *testing.T) {
+	creds := &Credentials{keyring: fakeKeyring{"zen": "from-keyring"}}
+	creds.SetAPIKey("zen", "from-file")
+	creds.SetAPIKey("other", "file-only")
+
+	tests := []struct {
+		name, key, source string
+	}{
+		{"zen", "from-keyring", SourceKeyring},
+		{"other", "file-only", SourceFile},
+		{"missing", "", ""},
+	}
+	for _, tt := range tests {
+		key, source := creds.LookupAPIKey(tt.name)
+		if key != tt.key || source != tt.source {
+			t.Errorf("LookupAPIKey(%q) = %q, %q; want %q, %q", tt.name, key, source, tt.key, tt.source)
+		}
+	}
+
+	creds.keyring = nil
+	if key := creds.GetAPIKey("zen"); key != "from-file" {
+		t.Errorf("GetAPIKey() without keyring = %q, want the file key", key)
+	}
+}
+
+func TestLookupAPIKeyPerKeyKeyring(t *testing.T) {
+	keyring := KeyringConfig{Enabled: true, Keys: map[string]bool{"local": false}}
+	creds := &Credentials{
+		keyring:      fakeKeyring{"zen": "from-keyring", "local": "from-keyring"},
+		readsKeyring: keyring.Reads,
+	}
+	creds.SetAPIKey("local", "from-file")
+
+	if key, source := creds.LookupAPIKey("zen"); key != "from-keyring" || source != SourceKeyring {
+		t.Errorf("LookupAPIKey(zen) = %q, %q; want the keyring key", key, source)
+	}
+	if key, source := creds.LookupAPIKey("local"); key != "from-file" || source != SourceFile {
+		t.Errorf("LookupAPIKey(local) = %q, %q; want the file key", key, source)
+	}
+
+	keyring = KeyringConfig{Keys: map[string]bool{"zen": true}}
+	creds.readsKeyring = keyring.Reads
+	if key, _ := creds.LookupAPIKey("local"); key != "from-file" {
+		t.Errorf("LookupAPIKey(local) with the keyring disabled = %q, want the file key", key)
+	}
+	if key, _ := creds.LookupAPIKey("zen"); key != "from-keyring" {
+		t.Errorf("LookupAPIKey(zen) enabled per key = %q, want the keyring key", key)
+	}
+}
+
+func TestEncryptCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "")
+	SystemKeyring = func() Keyring { return nil }
+	t.Cleanup(func() { SystemKeyring = newSystemKeyring })
+
+	creds := &Credentials{}
+	creds.SetAPIKey("zen", "secret-key")
+	if err := SaveCredentials(creds); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptCredentials(KeyringConfig{}, "hunter2"); err != nil {
+		t.Fatalf("EncryptCredentials() error = %v", err)
+	}
+	dir, _ := DataDir()
+	if _, err := os.Stat(filepath.Join(dir, credentialsFile)); !os.IsNotExist(err) {
+		t.Errorf("credentials.json left after encrypting: %v", err)
+	}
+	sealed, err := os.ReadFile(filepath.Join(dir, encryptedCredentialsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "secret-key") {
+		t.Error("credentials.enc holds the key in plain text")
+	}
+
+	if _, err := LoadCredentials(KeyringConfig{}, nil); err == nil {
+		t.Error("LoadCredentials() without a passphrase succeeded")
+	}
+	wrong := func() (string, error) { return "wrong", nil }
+	if _, err := LoadCredentials(KeyringConfig{}, wrong); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("LoadCredentials() with a wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+
+	t.Setenv(PassphraseEnv, "hunter2")
+	loaded, err := LoadCredentials(KeyringConfig{}, nil)
+	if err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if key := loaded.GetAPIKey("zen"); key != "secret-key" || !loaded.Encrypted() {
+		t.Errorf("GetAPIKey() = %q, encrypted %v; want the stored key from the encrypted file", key, loaded.Encrypted())
+	}
+
+	// Saving keeps the file encrypted
+	loaded.SetAPIKey("other", "second-key")
+	if err := SaveCredentials(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := LoadCredentials(KeyringConfig{}, nil); err != nil || reloaded.GetAPIKey("other") != "second-key" {
+		t.Errorf("LoadCredentials() after saving = %v, %v", reloaded, err)
+	}
+	if err := EncryptCredentials(KeyringConfig{}, "hunter2"); err == nil {
+		t.Error("EncryptCredentials() of an encrypted file succeeded")
+	}
+
+	// An edited iteration count is refused before deriving the key
+	for _, iterations := range []int{0, -1, 1000, 1 << 40} {
+		edited := strings.Replace(string(sealed), `"iterations": 600000`, fmt.Sprintf(`"iterations": %d`, iterations), 1)
+		if _, err := openCredentials([]byte(edited), "hunter2"); err == nil || !strings.Contains(err.Error(), "iterations") {
+			t.Errorf("openCredentials() with %d iterations error = %v", iterations, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase of the
+// encrypted credentials file, read before prompting.
+const PassphraseEnv = "MYSIS_CREDENTIALS_PASSPHRASE"
+
+// Key derivation of the encrypted credentials file: PBKDF2 with
+// HMAC-SHA256, deriving an AES-256-GCM key.
+const (
+	credentialsKDF        = "pbkdf2-sha256"
+	credentialsIterations = 600_000
+	credentialsKeySize    = 32
+	credentialsSaltSize   = 16
+
+	// Bounds of the iteration count read from the file: fewer gives a weak
+	// key, more stalls startup
+	credentialsMinIterations = 100_000
+	credentialsMaxIterations = 10_000_000
+)
+
+// ErrWrongPassphrase is returned when the encrypted credentials file
+// cannot be decrypted with the passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or damaged encrypted credentials file")
+
+// PassphraseFunc returns the passphrase of the encrypted credentials file,
+// e.g. by prompting on the terminal.
+type PassphraseFunc func() (string, error)
+
+// encryptedCredentials is the content of credentials.enc: credentials.json
+// sealed with a key derived from the passphrase.
+type encryptedCredentials struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// sealCredentials encrypts the JSON credentials data with passphrase.
+func sealCredentials(data []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, credentialsSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := credentialsCipher(passphrase, salt, credentialsIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(encryptedCredentials{
+		KDF:        credentialsKDF,
+		Iterations: credentialsIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Data:       aead.Seal(nil, nonce, data, []byte(credentialsKDF)),
+	}, "", "  ")
+}
+
+// openCredentials decrypts the content of credentials.enc with passphrase.
+func openCredentials(sealed []byte, passphrase string) ([]byte, error) {
+	var file encryptedCredentials
+	if err := json.Unmarshal(sealed, &file); err != nil {
+		return nil, fmt.Errorf("parse encrypted credentials: %w", err)
+	}
+	if file.KDF != credentialsKDF {
+		return nil, fmt.Errorf("unsupported encrypted credentials: kdf %q", file.KDF)
+	}
+	if file.Iterations < credentialsMinIterations || file.Iterations > credentialsMaxIterations {
+		return nil, fmt.Errorf("unsupported encrypted credentials: %d iterations, want %d to %d",
+			file.Iterations, credentialsMinIterations, credentialsMaxIterations)
+	}
+	aead, err := credentialsCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	data, err := aead.Open(nil, file.Nonce, file.Data, []byte(file.KDF))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return data, nil
+}
+
+// credentialsCipher returns the AES-GCM cipher keyed by passphrase.
+func credentialsCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, credentialsKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// keyringService is the service API keys are stored under in the OS
+// keyring; the key name is the account.
+const keyringService = "mysis"
+
+// ErrKeyNotFound is returned by Keyring.Get for a key that is not stored.
+var ErrKeyNotFound = errors.New("key not found in keyring")
+
+// keyNamePattern matches valid API key names, e.g. "opencode_zen".
+var keyNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Keyring stores API keys in the OS keyring: the macOS keychain or the
+// freedesktop secret service on Linux.
+type Keyring interface {
+	// Get returns the key stored under name, or ErrKeyNotFound.
+	Get(name string) (string, error)
+	// Set stores key under name, replacing any stored key.
+	Set(name, key string) error
+}
+
+// SystemKeyring returns the keyring of this system, or nil when there is
+// none (no secret-tool on Linux, or an unsupported OS).
+var SystemKeyring = newSystemKeyring
+
+// ValidateKeyName checks that name can be used as an API key name.
+func ValidateKeyName(name string) error {
+	if !keyNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid key name %q: use letters, digits, '_', '.' and '-'", name)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+//go:build darwin
+
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychain stores keys in the macOS keychain with the security tool.
+type keychain struct{}
+
+func newSystemKeyring() Keyring {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil
+	}
+	return keychain{}
+}
+
+func (keychain) Get(name string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", name, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return "", ErrKeyNotFound
+		}
+		return "", fmt.Errorf("read keychain: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (keychain) Set(name, key string) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	// Commands on stdin keep the key out of the process list; -X takes it
+	// hex encoded so it needs no quoting
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+		keyringService, name, hex.EncodeToString([]byte(key))))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("write keychain: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
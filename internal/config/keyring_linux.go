@@ -0,0 +1,51 @@
+//go:build linux
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretService stores keys in the freedesktop secret service (GNOME
+// Keyring, KWallet) with libsecret's secret-tool.
+type secretService struct{}
+
+func newSystemKeyring() Keyring {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil
+	}
+	return secretService{}
+}
+
+func (secretService) Get(name string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", name).Output()
+	if len(out) == 0 {
+		// secret-tool exits 1 without output for a missing key
+		if err == nil || isExitError(err) {
+			return "", ErrKeyNotFound
+		}
+		return "", fmt.Errorf("read secret service: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (secretService) Set(name, key string) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", "mysis API key: "+name,
+		"service", keyringService, "account", name)
+	cmd.Stdin = strings.NewReader(key)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("write secret service: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !linux
+
+package config
+
+// newSystemKeyring returns nil: the OS keyring is only supported on macOS
+// and Linux, other systems use the credentials file.
+func newSystemKeyring() Keyring {
+	return nil
+}
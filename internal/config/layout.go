@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// layoutFile is the file in the data directory the TUI saves its layout to.
+const layoutFile = "layout.json"
+
+// LoadLayout returns the TUI layout saved by SaveLayout, or defaults (the
+// [tui.layout] config) when none was saved.
+func LoadLayout(defaults TUILayout) (TUILayout, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return defaults, err
+	}
+
+	//nolint:gosec // G304: Path from the data directory
+	data, err := os.ReadFile(filepath.Join(dir, layoutFile))
+	if os.IsNotExist(err) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
+	}
+
+	layout := defaults
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return defaults, err
+	}
+	if len(validateTUILayout(layout)) > 0 {
+		return defaults, nil // Edited by hand; the config applies
+	}
+	return layout, nil
+}
+
+// SaveLayout writes the TUI layout to layout.json in the data directory.
+func SaveLayout(layout TUILayout) error {
+	dir, err := EnsureDataDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, layoutFile), data, 0600)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeWindow is a daily window of local time. Windows with End before Start
+// wrap past midnight (e.g. 22:00-06:00).
+type TimeWindow struct {
+	Start time.Duration // Offset from midnight
+	End   time.Duration // Offset from midnight
+}
+
+// ParseTimeWindow parses a window in "HH:MM-HH:MM" form.
+func ParseTimeWindow(value string) (TimeWindow, error) {
+	startText, endText, ok := strings.Cut(value, "-")
+	if !ok {
+		return TimeWindow{}, fmt.Errorf("invalid window %q: expected HH:MM-HH:MM", value)
+	}
+
+	start, err := parseClock(strings.TrimSpace(startText))
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("invalid window %q: %w", value, err)
+	}
+	end, err := parseClock(strings.TrimSpace(endText))
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("invalid window %q: %w", value, err)
+	}
+	if start == end {
+		return TimeWindow{}, fmt.Errorf("invalid window %q: start and end must differ", value)
+	}
+
+	return TimeWindow{Start: start, End: end}, nil
+}
+
+// parseClock parses "HH:MM" into an offset from midnight.
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	offset := t.Sub(midnight(t))
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// NextStart returns the next time the window opens after t.
+func (w TimeWindow) NextStart(t time.Time) time.Time {
+	start := midnight(t).Add(w.Start)
+	if !start.After(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// String formats the window as "HH:MM-HH:MM".
+func (w TimeWindow) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
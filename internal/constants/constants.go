@@ -22,11 +22,33 @@ const (
 	// AvgToolCallsPerTurn is the expected average tool calls per turn for autoplay timing.
 	// Database analysis shows actual average is ~3, but we use 10 for safety margin.
 	AvgToolCallsPerTurn = 10
+
+	// AutoplayTickFactor scales the per-tool-call game tick budget between turns.
+	// Per DESIGN.md: "game tick time * max tool calls * .75"
+	AutoplayTickFactor = 0.75
+
+	// AutoplayMinInterval is the default shortest wait between autoplay turns.
+	AutoplayMinInterval = GameTickDuration
+
+	// AutoplayBreakerThreshold is the default number of consecutive failed
+	// autoplay turns that trips the circuit breaker.
+	AutoplayBreakerThreshold = 3
+
+	// AutoplayBreakerCooldown is the default wait before autoplay resumes
+	// after the circuit breaker trips (with auto-resume enabled).
+	AutoplayBreakerCooldown = 5 * time.Minute
+
+	// AutoplayWatchdogRepeats is the default number of identical autoplay
+	// turns in a row that the watchdog treats as a stuck loop.
+	AutoplayWatchdogRepeats = 3
+
+	// InputHistoryLimit is the number of entries kept in the CLI input
+	// history file.
+	InputHistoryLimit = 1000
 )
 
 var (
-	// AutoplayInterval is the interval between autoplay turns.
-	// Calculated as: AvgToolCallsPerTurn × GameTickDuration × 0.75
-	// Per DESIGN.md: "game tick time * max tool calls * .75"
-	AutoplayInterval = time.Duration(float64(AvgToolCallsPerTurn)*GameTickDuration.Seconds()*0.75) * time.Second
+	// AutoplayMaxInterval is the default longest wait between autoplay turns.
+	// Calculated as: AvgToolCallsPerTurn × GameTickDuration × AutoplayTickFactor
+	AutoplayMaxInterval = time.Duration(float64(AvgToolCallsPerTurn)*GameTickDuration.Seconds()*AutoplayTickFactor) * time.Second
 )
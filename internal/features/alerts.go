@@ -0,0 +1,139 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// notificationsTool is the game tool that returns pending notifications.
+const notificationsTool = "get_notifications"
+
+// AlertMatch is a game notification that matched a critical pattern.
+type AlertMatch struct {
+	Pattern      string
+	Notification string // Notification text, shortened for display
+}
+
+// String describes the alert for display.
+func (a AlertMatch) String() string {
+	return "critical notification (" + a.Pattern + "): " + a.Notification
+}
+
+// checkAlerts looks for critical notifications in the turn's
+// get_notifications results and, with polling enabled, in a fresh
+// get_notifications call. Polled notifications are consumed by the game, so
+// they are queued for the next turn to keep the model informed.
+func (s *Service) checkAlerts(ctx context.Context, result *llm.TurnResult) (AlertMatch, bool) {
+	alerts := s.alerts
+	if len(alerts.Patterns) == 0 {
+		return AlertMatch{}, false
+	}
+
+	if result != nil {
+		notifications := make(map[string]bool)
+		for _, msg := range result.Messages {
+			for _, tc := range msg.ToolCalls {
+				if tc.Name == notificationsTool {
+					notifications[tc.ID] = true
+				}
+			}
+		}
+		for _, msg := range result.Messages {
+			if msg.Role == "tool" && notifications[msg.ToolCallID] {
+				if match, ok := matchAlert(alerts.Patterns, msg.Content); ok {
+					return match, true
+				}
+			}
+		}
+	}
+
+	if !alerts.Poll {
+		return AlertMatch{}, false
+	}
+
+	s.mu.Lock()
+	gate := s.toolGate
+	s.mu.Unlock()
+	if gate == nil {
+		return AlertMatch{}, false
+	}
+
+	polled, err := gate.CallTool(ctx, notificationsTool, json.RawMessage(`{}`))
+	if err != nil || polled == nil || polled.IsError {
+		log.Debug().Err(err).Msg("Autoplay notification poll failed")
+		return AlertMatch{}, false
+	}
+
+	var text strings.Builder
+	for _, block := range polled.Content {
+		text.WriteString(block.Text)
+	}
+	if !hasNotifications(text.String()) {
+		return AlertMatch{}, false
+	}
+
+	s.mu.Lock()
+	if s.enabled {
+		s.interjections = append(s.interjections, "Game notifications received since your last turn:\n"+text.String())
+	}
+	s.mu.Unlock()
+
+	return matchAlert(alerts.Patterns, text.String())
+}
+
+// matchAlert returns the first pattern found in the notification text.
+func matchAlert(patterns []string, text string) (AlertMatch, bool) {
+	lower := strings.ToLower(text)
+	for _, pattern := range patterns {
+		i := strings.Index(lower, strings.ToLower(pattern))
+		if pattern == "" || i < 0 {
+			continue
+		}
+		return AlertMatch{Pattern: pattern, Notification: excerpt(text, i, len(pattern))}, true
+	}
+	return AlertMatch{}, false
+}
+
+// excerpt returns the text around text[i:i+n], at most about 160 bytes.
+func excerpt(text string, i, n int) string {
+	const margin = 60
+	start := max(i-margin, 0)
+	end := min(i+n+margin, len(text))
+	out := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		out = "..." + out
+	}
+	if end < len(text) {
+		out += "..."
+	}
+	return out
+}
+
+// hasNotifications reports whether a get_notifications result carries any
+// notifications. Non-JSON results count when they are not empty.
+func hasNotifications(text string) bool {
+	var resp struct {
+		Count         *int              `json:"count"`
+		Notifications []json.RawMessage `json:"notifications"`
+	}
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return strings.TrimSpace(text) != ""
+	}
+	if resp.Count != nil {
+		return *resp.Count > 0
+	}
+	return len(resp.Notifications) > 0
+}
+
+// alertAction returns the configured alert action.
+func alertAction(alerts config.AlertConfig) string {
+	if alerts.Action == "" {
+		return config.StopActionPause
+	}
+	return alerts.Action
+}
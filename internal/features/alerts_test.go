@@ -0,0 +1,34 @@
+package features
+
+import "testing"
+
+func TestMatchAlert(t *testing.T) {
+	text := `{"count": 1, "notifications": [{"type": "combat", "message": "You are UNDER ATTACK by pirate_42"}]}`
+
+	match, ok := matchAlert([]string{"destroyed", "under attack"}, text)
+	if !ok {
+		t.Fatal("matchAlert() found no match")
+	}
+	if match.Pattern != "under attack" {
+		t.Errorf("Pattern = %q, want %q", match.Pattern, "under attack")
+	}
+
+	if _, ok := matchAlert([]string{"destroyed"}, text); ok {
+		t.Error("matchAlert() matched a pattern not in the text")
+	}
+}
+
+func TestHasNotifications(t *testing.T) {
+	tests := map[string]bool{
+		`{"count": 0, "notifications": [], "remaining": 0}`: false,
+		`{"count": 2, "notifications": [{}, {}]}`:           true,
+		`{"notifications": [{"message": "docked"}]}`:        true,
+		"Ship destroyed": true,
+		"  ":             false,
+	}
+	for text, want := range tests {
+		if got := hasNotifications(text); got != want {
+			t.Errorf("hasNotifications(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -1,16 +1,20 @@
 package features
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/provider"
 )
 
@@ -41,6 +45,15 @@ func InitializeProviders(cfg *config.Config, creds *config.Credentials) *provide
 			factory := provider.NewOpenCodeFactory(name, provCfg.Endpoint, apiKey)
 			registry.RegisterFactory(name, factory)
 			log.Debug().Str("name", name).Str("endpoint", provCfg.Endpoint).Msg("Registered OpenCode provider")
+		case strings.HasPrefix(provCfg.Endpoint, PluginProviderScheme):
+			// Provider served by a plugin
+			factory, err := NewPluginProviderFactory(name, provCfg.Endpoint)
+			if err != nil {
+				log.Warn().Err(err).Str("name", name).Msg("Invalid plugin provider")
+				continue
+			}
+			registry.RegisterFactory(name, factory)
+			log.Debug().Str("name", name).Str("endpoint", provCfg.Endpoint).Msg("Registered plugin provider")
 		default:
 			log.Warn().Str("name", name).Str("endpoint", provCfg.Endpoint).Msg("Unknown provider type")
 		}
@@ -49,6 +62,82 @@ func InitializeProviders(cfg *config.Config, creds *config.Credentials) *provide
 	return registry
 }
 
+// ProviderOptions returns the sampling options from a provider config.
+func ProviderOptions(cfg config.ProviderConfig) provider.Options {
+	return provider.Options{
+		Temperature: cfg.Temperature,
+		TopP:        cfg.TopP,
+		MaxTokens:   cfg.MaxTokens,
+	}
+}
+
+// CreateProvider creates an instance of the named provider with the model and
+// sampling options from its config. Returns the model used.
+func CreateProvider(cfg *config.Config, registry *provider.Registry, name string) (provider.Provider, string, error) {
+	provCfg, ok := cfg.Providers[name]
+	if !ok {
+		return nil, "", fmt.Errorf("provider '%s' not found in config", name)
+	}
+	prov, err := registry.Create(name, provCfg.Model, ProviderOptions(provCfg))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create provider: %w", err)
+	}
+	return prov, provCfg.Model, nil
+}
+
+// SwitchProvider creates the provider a session switches to: the named
+// provider with model, or with its configured model if model is "". An
+// explicit model is checked with ValidateModel.
+func SwitchProvider(ctx context.Context, cfg *config.Config, registry *provider.Registry, name, model string) (provider.Provider, string, error) {
+	if model == "" {
+		return CreateProvider(cfg, registry, name)
+	}
+
+	provCfg, ok := cfg.Providers[name]
+	if !ok {
+		return nil, "", fmt.Errorf("provider '%s' not found in config", name)
+	}
+	prov, err := registry.Create(name, model, ProviderOptions(provCfg))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create provider: %w", err)
+	}
+	if err := ValidateModel(ctx, prov, model); err != nil {
+		_ = prov.Close()
+		return nil, "", err
+	}
+	return prov, model, nil
+}
+
+// ValidateModel checks that the provider serves model, when the provider can
+// list its models. If the list is unavailable the model is accepted.
+func ValidateModel(ctx context.Context, prov provider.Provider, model string) error {
+	lister, ok := prov.(provider.ModelLister)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+	defer cancel()
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		log.Debug().Err(err).Str("provider", prov.Name()).Msg("Could not list models - skipping model check")
+		return nil
+	}
+	if !slices.Contains(models, model) {
+		return fmt.Errorf("model '%s' is not available from provider '%s' (available: %s)", model, prov.Name(), strings.Join(models, ", "))
+	}
+	return nil
+}
+
+// PricingFor returns the configured pricing of a provider for cost estimates.
+func PricingFor(cfg *config.Config, providerName string) llm.Pricing {
+	provCfg := cfg.Providers[providerName]
+	return llm.Pricing{
+		InputPerMTok:  provCfg.InputCost,
+		OutputPerMTok: provCfg.OutputCost,
+	}
+}
+
 // LoadSystemPromptFromFile loads a system prompt from a markdown file.
 func LoadSystemPromptFromFile(path string) (string, error) {
 	ext := strings.ToLower(filepath.Ext(path))
@@ -70,6 +159,17 @@ func LoadSystemPromptFromFile(path string) (string, error) {
 	return content, nil
 }
 
+// DefaultSystemPrompt returns the system prompt the config gives new
+// sessions with the named provider, read from its file if it is one, or ""
+// when none is configured.
+func DefaultSystemPrompt(cfg *config.Config, providerName string) (string, error) {
+	prompt, file := cfg.DefaultSystemPrompt(providerName)
+	if file != "" {
+		return LoadSystemPromptFromFile(file)
+	}
+	return strings.TrimSpace(prompt), nil
+}
+
 // HistoryHasSystemPrompt checks if the history already has a system prompt with the given content.
 func HistoryHasSystemPrompt(history []provider.Message, content string) bool {
 	for _, msg := range history {
@@ -86,6 +186,37 @@ func PrependSystemPrompt(history []provider.Message, content string) []provider.
 	return append([]provider.Message{systemMsg}, history...)
 }
 
+// SystemPrompt returns the session system prompt in history: the first
+// system message that is not a compaction summary.
+func SystemPrompt(history []provider.Message) (string, bool) {
+	if i := systemPromptIndex(history); i >= 0 {
+		return history[i].Content, true
+	}
+	return "", false
+}
+
+// SetSystemPrompt returns a copy of history with the session system prompt
+// replaced by content, or prepended if there is none.
+func SetSystemPrompt(history []provider.Message, content string) []provider.Message {
+	i := systemPromptIndex(history)
+	if i < 0 {
+		return PrependSystemPrompt(history, content)
+	}
+	updated := make([]provider.Message, len(history))
+	copy(updated, history)
+	updated[i].Content = content
+	return updated
+}
+
+func systemPromptIndex(history []provider.Message) int {
+	for i, msg := range history {
+		if msg.Role == "system" && !strings.HasPrefix(msg.Content, llm.CompactSummaryPrefix) {
+			return i
+		}
+	}
+	return -1
+}
+
 // SetupFileLogging configures zerolog to write to a file.
 // This is used by TUI mode to avoid collision with the UI.
 func SetupFileLogging(debug bool) error {
@@ -101,13 +232,12 @@ func SetupFileLogging(debug bool) error {
 		return fmt.Errorf("create logs directory: %w", err)
 	}
 
-	// Create log file
-	logFile := filepath.Join(logDir, "mysis.log")
-	//nolint:gosec // G304: Path from validated config file
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	// Create log file, rotated with the defaults until SetLogRotation
+	file, err := openRotatingFile(filepath.Join(logDir, "mysis.log"), defaultLogRotation)
 	if err != nil {
 		return fmt.Errorf("open log file: %w", err)
 	}
+	files := []*rotatingFile{file}
 
 	// Set up multi-writer: file (JSON) + console writer for debugging
 	var writers []io.Writer
@@ -115,18 +245,24 @@ func SetupFileLogging(debug bool) error {
 	// Always write JSON to file
 	writers = append(writers, file)
 
+	// Keep the last lines readable for the TUI log pane, see RecentLogs
+	writers = append(writers, zerolog.ConsoleWriter{Out: logs, NoColor: true, TimeFormat: "15:04:05"})
+
 	// In debug mode, also write human-readable logs to a separate debug file
 	if debug {
-		debugFile := filepath.Join(logDir, "mysis-debug.log")
-		//nolint:gosec // G304: Debug log file path is constructed from validated data directory
-		debugFileWriter, err := os.OpenFile(debugFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		debugFileWriter, err := openRotatingFile(filepath.Join(logDir, "mysis-debug.log"), defaultLogRotation)
 		if err != nil {
 			return fmt.Errorf("open debug log file: %w", err)
 		}
+		files = append(files, debugFileWriter)
 		consoleWriter := zerolog.ConsoleWriter{Out: debugFileWriter, TimeFormat: time.RFC3339}
 		writers = append(writers, consoleWriter)
 	}
 
+	logFilesMu.Lock()
+	logFiles = files
+	logFilesMu.Unlock()
+
 	// Configure logger
 	multi := io.MultiWriter(writers...)
 	log.Logger = zerolog.New(multi).With().Timestamp().Logger()
@@ -139,7 +275,7 @@ func SetupFileLogging(debug bool) error {
 	}
 
 	log.Info().
-		Str("log_file", logFile).
+		Str("log_file", file.path).
 		Bool("debug", debug).
 		Msg("File logging initialized")
 
@@ -2,75 +2,434 @@ package features
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
 	"github.com/xonecas/mysis/internal/constants"
-)
-
-const (
-	// maxConsecutiveErrors is the threshold for circuit breaker.
-	// After this many consecutive errors, autoplay will stop.
-	maxConsecutiveErrors = 3
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
 )
 
 // AutoplayStatus represents the current state of autoplay.
 type AutoplayStatus struct {
-	Enabled  bool
-	Message  string
-	Interval time.Duration
+	Enabled      bool
+	Paused       bool // Goal kept, but no turns are scheduled
+	DryRun       bool // Mutating game tools are simulated
+	Safe         bool // Tools are restricted to the safe-mode allowlist
+	Queued       int  // User messages waiting for the next turn
+	Message      string
+	GoalName     string // Named goal of the current turn when rotating
+	MinInterval  time.Duration
+	MaxInterval  time.Duration
+	NextInterval time.Duration // Wait scheduled after the last turn
+	Jitter       float64       // ± fraction applied to each wait
+	Limits       AutoplayLimits
+	Turns        int       // Turns completed in this run
+	TotalTurns   int       // Turns completed in all runs of the session
+	FailedTurns  int       // Turns of this run that returned an error
+	NextTurnAt   time.Time // When the next turn is due; zero while a turn runs
+	Usage        llm.Usage // Estimated LLM usage of this run
+	StartedAt    time.Time // Start of this run
+	Goal         int       // Current playbook goal (1-based)
+	Goals        int       // Number of goals; 1 unless running a playbook
+	Schedule     []config.TimeWindow
+	InWindow     bool      // Current time is inside the schedule (always true without one)
+	NextWindow   time.Time // Next window start when outside the schedule
+	// Circuit breaker state
+	ConsecutiveErrors int
+	BreakerOpen       bool      // Tripped and cooling down
+	BreakerUntil      time.Time // End of the cooldown while open
+	FailedOver        string    // Backup provider switched to, if any
+}
+
+// NextTurnIn returns the time left until the next turn, or 0 when it is
+// due or running.
+func (s AutoplayStatus) NextTurnIn() time.Duration {
+	if s.NextTurnAt.IsZero() {
+		return 0
+	}
+	return max(time.Until(s.NextTurnAt), 0)
+}
+
+// DetailLines describes a running autoplay for display: goal progress,
+// turn counters, usage, breaker state and the next-turn countdown.
+func (s AutoplayStatus) DetailLines() []string {
+	var lines []string
+	if s.Goals > 1 {
+		lines = append(lines, fmt.Sprintf("Playbook goal %d of %d", s.Goal, s.Goals))
+	}
+	if s.GoalName != "" {
+		lines = append(lines, "Rotating goals, next: "+s.GoalName)
+	}
+	lines = append(lines,
+		fmt.Sprintf("Turns: %d (%d failed), running for %s (limits: %s)",
+			s.Turns, s.FailedTurns, time.Since(s.StartedAt).Round(time.Second), s.Limits),
+		"LLM usage: "+s.Usage.String())
+	switch {
+	case s.BreakerOpen:
+		lines = append(lines, "Circuit breaker open until "+s.BreakerUntil.Format("15:04:05"))
+	case s.ConsecutiveErrors > 0:
+		lines = append(lines, fmt.Sprintf("Consecutive failed turns: %d", s.ConsecutiveErrors))
+	}
+	if s.FailedOver != "" {
+		lines = append(lines, "Failed over to provider "+s.FailedOver)
+	}
+	if s.Queued > 0 {
+		lines = append(lines, fmt.Sprintf("Queued messages: %d", s.Queued))
+	}
+	switch {
+	case s.Paused:
+	case s.NextTurnAt.IsZero():
+		lines = append(lines, "Turn in progress")
+	default:
+		lines = append(lines, fmt.Sprintf("Next turn in %s (interval %s)",
+			s.NextTurnIn().Round(time.Second), s.NextInterval.Round(time.Second)))
+	}
+	return lines
+}
+
+// ScheduleText describes the schedule for display, or returns "" without one.
+func (s AutoplayStatus) ScheduleText() string {
+	if len(s.Schedule) == 0 {
+		return ""
+	}
+
+	windows := make([]string, len(s.Schedule))
+	for i, w := range s.Schedule {
+		windows[i] = w.String()
+	}
+
+	text := strings.Join(windows, ", ")
+	if s.InWindow {
+		return text + " (in window)"
+	}
+	return text + " (next window " + s.NextWindow.Format("Mon 15:04") + ")"
 }
 
 // AutoplayCallbacks defines the callback functions for autoplay events.
 // These allow display-specific implementations to handle events differently.
 type AutoplayCallbacks struct {
 	// OnStarted is called when autoplay starts.
-	OnStarted func(message string, interval time.Duration)
+	OnStarted func(status AutoplayStatus)
+
+	// OnStopped is called when autoplay stops, with a summary of the run.
+	OnStopped func(summary AutoplaySummary)
 
-	// OnStopped is called when autoplay stops.
-	OnStopped func()
+	// OnPaused is called when autoplay is paused.
+	OnPaused func()
 
-	// OnTurn is called before sending each autoplay message.
-	// Should return an error if the turn should not be processed.
-	OnTurn func(ctx context.Context, message string) error
+	// OnResumed is called when autoplay is resumed.
+	OnResumed func()
+
+	// OnGoalChanged is called when a playbook advances to its next goal.
+	OnGoalChanged func(status AutoplayStatus)
+
+	// OnBreaker is called when the circuit breaker trips into cooldown
+	// (open true) and when a turn succeeds after the cooldown (open false).
+	OnBreaker func(open bool, until time.Time)
+
+	// OnFailover is called when the breaker trips because the provider keeps
+	// failing and autoplay.breaker.failover is set. It should switch the
+	// conversation to the named provider and record the switch in the
+	// history; autoplay continues if it returns nil.
+	OnFailover func(ctx context.Context, providerName string) error
+
+	// OnWatchdog is called when the same tool calls or tool results repeat
+	// for several turns, with the action taken: "nudge", "pause" or "stop".
+	OnWatchdog func(match WatchdogMatch, action string)
+
+	// OnNudge is called with a corrective system message when the watchdog
+	// nudges the model. It should add the message to the conversation; the
+	// next turn sees it.
+	OnNudge func(message string)
+
+	// OnStopCondition is called when a configured stop condition matches a
+	// tool result, before autoplay is paused or stopped.
+	OnStopCondition func(match StopConditionMatch)
+
+	// OnInterjection is called before a turn for each user message queued
+	// with Interject. It should add the message to the conversation without
+	// processing it; the turn that follows answers it.
+	OnInterjection func(message string)
+
+	// OnRefine asks the model to restate the goal based on recent progress.
+	// Called every refine_every turns of a goal; returning an error keeps
+	// the current goal.
+	OnRefine func(ctx context.Context, goal string) (string, error)
+
+	// OnGoalRefined is called after the goal was replaced by OnRefine.
+	OnGoalRefined func(status AutoplayStatus)
+
+	// OnTurnDone is called after each turn with the updated status, once the
+	// next turn is scheduled.
+	OnTurnDone func(status AutoplayStatus)
+
+	// OnAlert is called when a game notification matches a critical pattern,
+	// before autoplay is paused or stopped.
+	OnAlert func(alert AlertMatch)
+
+	// OnSchedule is called when autoplay starts waiting for the next
+	// schedule window (inWindow false) and when that window opens.
+	OnSchedule func(inWindow bool, next time.Time)
+
+	// OnTurn is called to process each autoplay message.
+	// Should return an error if the turn could not be processed.
+	// The result is used to schedule the next turn and may be nil.
+	OnTurn func(ctx context.Context, message string) (*llm.TurnResult, error)
 
 	// OnError is called when an error occurs during autoplay.
 	OnError func(err error)
 }
 
+// ToolGate controls which game tools autoplay runs may use, see mcp.Proxy:
+// dry-run simulation of mutating tools and the safe-mode allowlist. The
+// service also calls tools through it to poll game notifications.
+type ToolGate interface {
+	SetDryRun(enabled bool)
+	DryRun() bool
+	SetToolFilter(allow []string)
+	ToolFilter() []string
+	CallTool(ctx context.Context, name string, arguments json.RawMessage) (*mcp.ToolResult, error)
+}
+
 // Service manages autoplay functionality in a display-agnostic way.
 // It handles the timing, state management, and loop control for autoplay,
 // while delegating display-specific concerns to callbacks.
 type Service struct {
-	enabled           bool
-	message           string
-	interval          time.Duration
-	cancel            context.CancelFunc
+	enabled        bool
+	paused         bool
+	resumeCh       chan struct{} // Closed on resume to wake a paused loop
+	message        string
+	minInterval    time.Duration
+	maxInterval    time.Duration
+	nextInterval   time.Duration
+	nextTurnAt     time.Time
+	jitter         float64 // ± fraction applied to each wait
+	schedule       []config.TimeWindow
+	defaultLimits  AutoplayLimits // From config, used when Start gets no limits
+	limits         AutoplayLimits
+	startedAt      time.Time
+	turns          int
+	totalTurns     int // Across runs, never reset
+	failedTurns    int
+	toolCalls      map[string]int
+	usage          llm.Usage
+	stopReason     string
+	goals          []PlaybookGoal
+	goalIndex      int
+	goalTurns      int // Turns of the current goal
+	goalStartedAt  time.Time
+	namedGoals     []config.AutoplayGoal // From config, for rotation
+	rotationPolicy string
+	rotation       *goalRotation // Set when rotating named goals
+	goalName       string
+	cancel         context.CancelFunc
+	run            int        // Counts runs, so a finished loop only clears the state of its own
+	toolModeMu     sync.Mutex // Held while a run's tool mode is applied or reset
+	// loops has the runs whose loop hasn't exited, with the summary of a run
+	// a new one replaced, taken before start reset the counters
+	loops             map[int]*AutoplaySummary
 	mu                sync.Mutex
 	callbacks         AutoplayCallbacks
 	consecutiveErrors int // P3: Track consecutive failures for circuit breaker
+	breaker           config.BreakerConfig
+	stopConditions    []config.StopCondition
+	webhooks          *webhookNotifier
+	milestones        config.MilestoneConfig
+	toolGate          ToolGate
+	script            *Script  // Session script, whose should_stop runs after each turn
+	safeTools         []string // Allowlist applied by --safe
+	alerts            config.AlertConfig
+	watchdog          config.WatchdogConfig
+	interjections     []string // User messages queued for the next turn
+	refineEvery       int
+	breakerOpen       bool
+	breakerUntil      time.Time
+	providerErrors    int    // Consecutive failed turns caused by the provider
+	failedOver        string // Backup provider switched to in this run
 }
 
-// NewAutoplayService creates a new autoplay service with the given callbacks.
-func NewAutoplayService(callbacks AutoplayCallbacks) *Service {
+// NewAutoplayService creates a new autoplay service with the given config and callbacks.
+func NewAutoplayService(cfg config.AutoplayConfig, callbacks AutoplayCallbacks) *Service {
+	minInterval := cfg.MinInterval
+	if minInterval <= 0 {
+		minInterval = constants.AutoplayMinInterval
+	}
+	maxInterval := cfg.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = constants.AutoplayMaxInterval
+	}
+	if maxInterval < minInterval {
+		maxInterval = minInterval
+	}
+
+	breaker := cfg.Breaker
+	if breaker.Threshold <= 0 {
+		breaker.Threshold = constants.AutoplayBreakerThreshold
+	}
+	if breaker.Cooldown <= 0 {
+		breaker.Cooldown = constants.AutoplayBreakerCooldown
+	}
+
+	safeTools := cfg.SafeTools
+	if len(safeTools) == 0 {
+		safeTools = mcp.DefaultSafeTools
+	}
+
+	// The schedule is validated when the config is loaded
+	schedule, err := cfg.Windows()
+	if err != nil {
+		log.Warn().Err(err).Msg("Ignoring invalid autoplay schedule")
+		schedule = nil
+	}
+
 	return &Service{
-		interval:  constants.AutoplayInterval,
+		minInterval:    minInterval,
+		maxInterval:    maxInterval,
+		schedule:       schedule,
+		namedGoals:     cfg.Goals,
+		breaker:        breaker,
+		stopConditions: cfg.Stop,
+		refineEvery:    cfg.RefineEvery,
+		safeTools:      safeTools,
+		alerts:         cfg.Alerts,
+		watchdog:       cfg.Watchdog,
+		webhooks:       newWebhookNotifier(cfg.Webhooks),
+		milestones:     cfg.Milestones,
+		jitter:         cfg.Jitter,
+		rotationPolicy: cfg.Rotation,
+		defaultLimits: AutoplayLimits{
+			MaxTurns:    cfg.MaxTurns,
+			MaxDuration: cfg.MaxDuration,
+			MaxTokens:   cfg.MaxTokens,
+			MaxCost:     cfg.MaxCost,
+		},
 		callbacks: callbacks,
 	}
 }
 
+// SetToolGate sets where --dry-run and --safe runs restrict game tools.
+func (s *Service) SetToolGate(g ToolGate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolGate = g
+}
+
+// SetScript sets the session script consulted after each turn.
+func (s *Service) SetScript(script *Script) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = script
+}
+
+// SetDefaultLimits sets the limits of runs started without them, e.g. a
+// fleet bot's budget; unset ones keep the [autoplay] defaults.
+func (s *Service) SetDefaultLimits(limits AutoplayLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultLimits = limits.withDefaults(s.defaultLimits)
+}
+
+// SetSession names the session in webhook payloads, e.g. a fleet bot's.
+func (s *Service) SetSession(name string) {
+	s.webhooks.setSession(name)
+}
+
+// setToolMode applies dry-run and safe mode for a run, if a ToolGate is set.
+func (s *Service) setToolMode(dryRun, safe bool) {
+	s.mu.Lock()
+	g := s.toolGate
+	allow := s.safeTools
+	s.mu.Unlock()
+	if g == nil {
+		return
+	}
+
+	g.SetDryRun(dryRun)
+	if safe {
+		g.SetToolFilter(allow)
+	} else {
+		g.SetToolFilter(nil)
+	}
+}
+
 // Start begins autoplay with the given message.
+// Zero limits fall back to the configured defaults.
 // Returns an error if autoplay is already running or if inputs are invalid.
-func (s *Service) Start(ctx context.Context, message string) error {
+func (s *Service) Start(ctx context.Context, message string, limits AutoplayLimits) error {
+	if message == "" {
+		return fmt.Errorf("message cannot be empty")
+	}
+	return s.start(ctx, []PlaybookGoal{{Message: message}}, nil, limits)
+}
+
+// StartRotation begins autoplay alternating between named goals from the
+// config, using the configured rotation policy. No names means all goals.
+func (s *Service) StartRotation(ctx context.Context, names []string, limits AutoplayLimits) error {
+	if len(s.namedGoals) == 0 {
+		return fmt.Errorf("no autoplay goals configured - add [[autoplay.goal]] entries to the config")
+	}
+
+	goals := s.namedGoals
+	if len(names) > 0 {
+		goals = make([]config.AutoplayGoal, 0, len(names))
+		for _, name := range names {
+			g, ok := s.GoalByName(name)
+			if !ok {
+				return fmt.Errorf("unknown autoplay goal %q (available: %s)", name, strings.Join(s.GoalNames(), ", "))
+			}
+			goals = append(goals, g)
+		}
+	}
+
+	return s.start(ctx, []PlaybookGoal{{Message: goals[0].Message}}, newGoalRotation(goals, s.rotationPolicy), limits)
+}
+
+// GoalNames returns the names of the configured goals.
+func (s *Service) GoalNames() []string {
+	names := make([]string, len(s.namedGoals))
+	for i, g := range s.namedGoals {
+		names[i] = g.Name
+	}
+	return names
+}
+
+// GoalByName returns a configured goal.
+func (s *Service) GoalByName(name string) (config.AutoplayGoal, bool) {
+	for _, g := range s.namedGoals {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return config.AutoplayGoal{}, false
+}
+
+// StartPlaybook begins autoplay with the first goal of a playbook and advances
+// through its goals. The limits bound the whole run.
+func (s *Service) StartPlaybook(ctx context.Context, playbook *Playbook, limits AutoplayLimits) error {
+	if playbook == nil {
+		return fmt.Errorf("playbook cannot be nil")
+	}
+	if err := playbook.Validate(); err != nil {
+		return err
+	}
+	return s.start(ctx, playbook.Goals, nil, limits)
+}
+
+// start begins autoplay with a sequence of goals, optionally rotating named
+// goals within it.
+func (s *Service) start(ctx context.Context, goals []PlaybookGoal, rotation *goalRotation, limits AutoplayLimits) error {
 	// P2: Validate inputs
 	if ctx == nil {
 		return fmt.Errorf("context cannot be nil")
 	}
-	if message == "" {
-		return fmt.Errorf("message cannot be empty")
-	}
 
 	s.mu.Lock()
 	if s.enabled {
@@ -78,9 +437,37 @@ func (s *Service) Start(ctx context.Context, message string) error {
 		return fmt.Errorf("autoplay already running")
 	}
 
+	if _, alive := s.loops[s.run]; alive {
+		summary := s.summaryLocked()
+		s.loops[s.run] = &summary
+	}
+
 	s.enabled = true
-	s.message = message
+	s.paused = false
+	s.goals = goals
+	s.goalIndex = 0
+	s.goalTurns = 0
+	s.goalStartedAt = time.Now()
+	s.message = goals[0].Message
+	s.rotation = rotation
+	s.goalName = ""
+	if rotation != nil {
+		s.rotateLocked()
+	}
+	s.nextInterval = 0
 	s.consecutiveErrors = 0 // P3: Reset error counter on start
+	s.breakerOpen = false
+	s.breakerUntil = time.Time{}
+	s.providerErrors = 0
+	s.failedOver = ""
+	s.limits = limits.withDefaults(s.defaultLimits)
+	s.startedAt = time.Now()
+	s.turns = 0
+	s.failedTurns = 0
+	s.toolCalls = make(map[string]int)
+	s.usage = llm.Usage{}
+	s.stopReason = ""
+	s.interjections = nil
 
 	// P1: Use Background context for autoplay loop independence
 	// The autoplay loop needs to run independently of the caller's context.
@@ -88,20 +475,30 @@ func (s *Service) Start(ctx context.Context, message string) error {
 	// Instead, we create our own context from Background that we control via Stop().
 	autoplayCtx, cancel := context.WithCancel(context.Background())
 	s.cancel = cancel
+	s.run++
+	run := s.run
+	if s.loops == nil {
+		s.loops = make(map[int]*AutoplaySummary)
+	}
+	s.loops[run] = nil
 	s.mu.Unlock()
 
 	// Notify via callback
 	if s.callbacks.OnStarted != nil {
-		s.callbacks.OnStarted(message, s.interval)
+		s.callbacks.OnStarted(s.Status())
 	}
+	s.webhooks.notify(WebhookPayload{Event: config.WebhookEventStarted, Message: goals[0].Message})
 
 	log.Info().
-		Str("message", message).
-		Dur("interval", s.interval).
+		Str("message", goals[0].Message).
+		Int("goals", len(goals)).
+		Dur("min_interval", s.minInterval).
+		Dur("max_interval", s.maxInterval).
+		Str("limits", s.limits.String()).
 		Msg("Autoplay started")
 
 	// Start autoplay loop in background
-	go s.runLoop(autoplayCtx)
+	go s.runLoop(autoplayCtx, cancel, run)
 
 	return nil
 }
@@ -116,6 +513,7 @@ func (s *Service) Stop() error {
 	}
 
 	s.enabled = false
+	s.stopReason = StopReasonUser
 	if s.cancel != nil {
 		s.cancel()
 		s.cancel = nil
@@ -126,122 +524,713 @@ func (s *Service) Stop() error {
 	return nil
 }
 
+// Pause keeps the autoplay goal but stops scheduling new turns.
+// A turn already in progress is allowed to finish.
+// Returns an error if autoplay is not running or already paused.
+func (s *Service) Pause() error {
+	s.mu.Lock()
+	if !s.enabled {
+		s.mu.Unlock()
+		return fmt.Errorf("autoplay not active")
+	}
+	if s.paused {
+		s.mu.Unlock()
+		return fmt.Errorf("autoplay already paused")
+	}
+
+	s.paused = true
+	s.resumeCh = make(chan struct{})
+	s.mu.Unlock()
+
+	if s.callbacks.OnPaused != nil {
+		s.callbacks.OnPaused()
+	}
+
+	log.Info().Msg("Autoplay paused")
+	return nil
+}
+
+// Resume continues a paused autoplay with the same goal.
+// If the scheduled interval already elapsed, the next turn is sent immediately.
+// Returns an error if autoplay is not paused.
+func (s *Service) Resume() error {
+	s.mu.Lock()
+	if !s.enabled || !s.paused {
+		s.mu.Unlock()
+		return fmt.Errorf("autoplay not paused")
+	}
+
+	s.paused = false
+	close(s.resumeCh)
+	s.resumeCh = nil
+	s.mu.Unlock()
+
+	if s.callbacks.OnResumed != nil {
+		s.callbacks.OnResumed()
+	}
+
+	log.Info().Msg("Autoplay resumed")
+	return nil
+}
+
 // Status returns the current autoplay status.
 func (s *Service) Status() AutoplayStatus {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := time.Now()
+	nextWindow := nextWindowStart(s.schedule, now)
+
 	return AutoplayStatus{
-		Enabled:  s.enabled,
-		Message:  s.message,
-		Interval: s.interval,
+		Enabled:      s.enabled,
+		Paused:       s.paused,
+		DryRun:       s.toolGate != nil && s.toolGate.DryRun(),
+		Safe:         s.toolGate != nil && len(s.toolGate.ToolFilter()) > 0,
+		Queued:       len(s.interjections),
+		Message:      s.message,
+		GoalName:     s.goalName,
+		MinInterval:  s.minInterval,
+		MaxInterval:  s.maxInterval,
+		NextInterval: s.nextInterval,
+		Jitter:       s.jitter,
+		Limits:       s.limits,
+		Turns:        s.turns,
+		TotalTurns:   s.totalTurns,
+		FailedTurns:  s.failedTurns,
+		NextTurnAt:   s.nextTurnAt,
+		Usage:        s.usage,
+		StartedAt:    s.startedAt,
+		Goal:         s.goalIndex + 1,
+		Goals:        len(s.goals),
+		Schedule:     s.schedule,
+		InWindow:     nextWindow.IsZero(),
+		NextWindow:   nextWindow,
+
+		ConsecutiveErrors: s.consecutiveErrors,
+		BreakerOpen:       s.breakerOpen,
+		BreakerUntil:      s.breakerUntil,
+		FailedOver:        s.failedOver,
 	}
 }
 
+// Interject queues a user message to be added to the conversation before
+// the next autoplay turn, so it never races with a turn in progress.
+// Returns the number of queued messages, or an error if autoplay is not active.
+func (s *Service) Interject(message string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return 0, fmt.Errorf("autoplay not active")
+	}
+	s.interjections = append(s.interjections, message)
+	log.Info().Int("queued", len(s.interjections)).Msg("Queued user message for next autoplay turn")
+	return len(s.interjections), nil
+}
+
 // runLoop is the main autoplay loop that runs in a background goroutine.
-func (s *Service) runLoop(ctx context.Context) {
+// The first turn is sent immediately (or when the schedule next opens); each
+// following turn is scheduled from the duration and tool calls of the previous one.
+// cancel cancels ctx and run is the number of the run, see start.
+func (s *Service) runLoop(ctx context.Context, cancel context.CancelFunc, run int) {
 	log.Debug().Msg("Autoplay goroutine started")
 
 	defer func() {
-		// Normal cleanup
+		// Normal cleanup. After a stop, a new run may already have started:
+		// its state is left alone.
+		cancel()
 		s.mu.Lock()
-		s.enabled = false
-		s.cancel = nil
+		var summary AutoplaySummary
+		if s.run == run {
+			s.enabled = false
+			s.paused = false
+			s.resumeCh = nil
+			s.nextTurnAt = time.Time{}
+			s.cancel = nil
+			summary = s.summaryLocked()
+		} else {
+			summary = *s.loops[run]
+		}
+		delete(s.loops, run)
+		message := summary.Message
 		s.mu.Unlock()
 
+		log.Info().
+			Str("reason", summary.Reason).
+			Int("turns", summary.Turns).
+			Dur("duration", summary.Duration).
+			Msg("Autoplay run finished")
+
+		// Reset under toolModeMu, so the mode of a run being started is kept
+		s.toolModeMu.Lock()
+		s.mu.Lock()
+		current := s.run == run
+		s.mu.Unlock()
+		if current {
+			s.setToolMode(false, false)
+		}
+		s.toolModeMu.Unlock()
+
 		// Notify via callback
 		if s.callbacks.OnStopped != nil {
-			s.callbacks.OnStopped()
+			s.callbacks.OnStopped(summary)
 		}
 
+		// Deliver before exiting so a stop right before shutdown still reaches monitors
+		s.webhooks.notify(WebhookPayload{
+			Event:           config.WebhookEventStopped,
+			Message:         message,
+			Reason:          summary.Reason,
+			Turns:           summary.Turns,
+			FailedTurns:     summary.FailedTurns,
+			DurationSeconds: summary.Duration.Seconds(),
+			Tokens:          summary.Usage.Tokens(),
+			Cost:            summary.Usage.Cost,
+		})
+		s.webhooks.wait()
+
 		log.Debug().Msg("Autoplay goroutine exiting")
 	}()
 
-	// Send first message immediately
-	log.Debug().Msg("Sending first autoplay message")
-	if err := s.sendMessage(ctx); err != nil {
-		log.Error().Err(err).Msg("Autoplay failed to send first message")
+	dog := newWatchdog(s.watchdog)
+
+	// Stagger the first turn so bots started together don't fire at once
+	delay := time.Duration(float64(s.minInterval) * s.jitter * rand.Float64())
+	s.mu.Lock()
+	s.nextTurnAt = time.Now().Add(delay)
+	s.mu.Unlock()
+	for {
+		// Wait for the next turn, or stop if canceled
+		if !s.wait(ctx, delay) {
+			return
+		}
+
 		s.mu.Lock()
-		s.consecutiveErrors++
-		consecutiveErrors := s.consecutiveErrors
+		enabled := s.enabled
 		s.mu.Unlock()
 
-		if s.callbacks.OnError != nil {
-			s.callbacks.OnError(err)
+		if !enabled {
+			return
 		}
 
-		// P3: Circuit breaker - stop if too many consecutive errors
-		if consecutiveErrors >= maxConsecutiveErrors {
-			log.Warn().Int("consecutive_errors", consecutiveErrors).Msg("Circuit breaker triggered - stopping autoplay")
-			return
+		started := time.Now()
+		s.mu.Lock()
+		s.nextTurnAt = time.Time{}
+		s.mu.Unlock()
+		result, err := s.sendMessage(ctx)
+		elapsed := time.Since(started)
+		if ctx.Err() != nil {
+			return // Stopped mid-turn: the canceled turn is not a failure
 		}
-		return
-	}
-	// Reset error counter on success
-	s.mu.Lock()
-	s.consecutiveErrors = 0
-	s.mu.Unlock()
-	log.Debug().Msg("First autoplay message sent successfully")
 
-	// Check if canceled during first message processing
-	select {
-	case <-ctx.Done():
-		return
-	default:
-	}
+		s.mu.Lock()
+		s.turns++
+		s.totalTurns++
+		s.goalTurns++
+		prevCost := s.usage.Cost
+		if result != nil {
+			for _, msg := range result.Messages {
+				for _, tc := range msg.ToolCalls {
+					s.toolCalls[tc.Name]++
+				}
+			}
+			s.usage = s.usage.Add(result.Usage)
+		}
+		turns, cost := s.turns, s.usage.Cost
+		turnLimitReached := s.limits.MaxTurns > 0 && s.turns >= s.limits.MaxTurns
+		budgetReason := s.budgetExceededLocked()
+		s.mu.Unlock()
 
-	// Then wait and send subsequent messages
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+		if milestone := reachedMilestone(s.milestones, turns, prevCost, cost); milestone != "" {
+			s.webhooks.notify(WebhookPayload{
+				Event:     config.WebhookEventMilestone,
+				Message:   s.Status().Message,
+				Milestone: milestone,
+				Turns:     turns,
+				Cost:      cost,
+			})
+		}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
+		if err != nil {
+			log.Warn().Err(err).Msg("Autoplay turn failed")
 			s.mu.Lock()
-			enabled := s.enabled
+			s.failedTurns++
+			s.consecutiveErrors++
+			consecutiveErrors := s.consecutiveErrors
+			if errors.Is(err, llm.ErrLLMCall) {
+				s.providerErrors++
+			} else {
+				s.providerErrors = 0
+			}
 			s.mu.Unlock()
 
-			if !enabled {
+			if s.callbacks.OnError != nil {
+				s.callbacks.OnError(err)
+			}
+			s.webhooks.notify(WebhookPayload{
+				Event:             config.WebhookEventError,
+				Message:           s.Status().Message,
+				Error:             err.Error(),
+				ConsecutiveErrors: consecutiveErrors,
+			})
+
+			// Back off after a failed turn
+			delay = applyJitter(s.maxInterval, s.jitter, rand.Float64())
+
+			// P3: Circuit breaker - fail over, stop or cool down after too many consecutive errors
+			if consecutiveErrors >= s.breaker.Threshold {
+				switch {
+				case s.failover(ctx):
+					delay = s.minInterval
+				case !s.breaker.AutoResume:
+					log.Warn().Int("consecutive_errors", consecutiveErrors).Msg("Circuit breaker triggered - stopping autoplay")
+					s.webhooks.notify(WebhookPayload{
+						Event:             config.WebhookEventBreaker,
+						Message:           s.Status().Message,
+						ConsecutiveErrors: consecutiveErrors,
+					})
+					s.setStopReason(StopReasonErrors)
+					return
+				default:
+					delay = s.tripBreaker(consecutiveErrors)
+				}
+			}
+		} else {
+			// Reset error counter on success
+			s.mu.Lock()
+			s.consecutiveErrors = 0
+			s.providerErrors = 0
+			wasOpen := s.breakerOpen
+			s.breakerOpen = false
+			s.breakerUntil = time.Time{}
+			s.mu.Unlock()
+
+			if wasOpen {
+				log.Info().Msg("Circuit breaker closed - autoplay recovered")
+				if s.callbacks.OnBreaker != nil {
+					s.callbacks.OnBreaker(false, time.Time{})
+				}
+			}
+
+			toolCalls := 0
+			if result != nil {
+				toolCalls = result.ToolCallCount()
+			}
+			delay = applyJitter(nextInterval(elapsed, toolCalls, s.minInterval, s.maxInterval), s.jitter, rand.Float64())
+		}
+
+		if turnLimitReached {
+			log.Info().Int("turns", s.limits.MaxTurns).Msg("Autoplay turn limit reached")
+			s.setStopReason(StopReasonTurnLimit)
+			return
+		}
+
+		if budgetReason != "" {
+			log.Warn().Str("reason", budgetReason).Msg("Autoplay budget reached")
+			s.setStopReason(budgetReason)
+			return
+		}
+
+		if match, ok := matchStopConditions(s.stopConditions, result); ok {
+			log.Warn().Str("condition", match.String()).Msg("Autoplay stop condition met")
+			if s.callbacks.OnStopCondition != nil {
+				s.callbacks.OnStopCondition(match)
+			}
+			if match.Condition.Action == config.StopActionStop {
+				s.setStopReason(StopReasonCondition + " (" + match.Condition.Name + ")")
 				return
 			}
+			if err := s.Pause(); err != nil {
+				log.Debug().Err(err).Msg("Stop condition pause skipped")
+			}
+		}
 
-			if err := s.sendMessage(ctx); err != nil {
-				log.Warn().Err(err).Msg("Autoplay turn failed")
-				s.mu.Lock()
-				s.consecutiveErrors++
-				consecutiveErrors := s.consecutiveErrors
-				s.mu.Unlock()
+		s.mu.Lock()
+		script := s.script
+		s.mu.Unlock()
+		if reason, ok := script.ShouldStop(result); ok {
+			log.Warn().Str("reason", reason).Msg("Autoplay stopped by script")
+			s.setStopReason(StopReasonScript + " (" + reason + ")")
+			return
+		}
 
-				if s.callbacks.OnError != nil {
-					s.callbacks.OnError(err)
+		if alert, ok := s.checkAlerts(ctx, result); ok {
+			log.Warn().Str("alert", alert.String()).Msg("Autoplay critical notification")
+			if s.callbacks.OnAlert != nil {
+				s.callbacks.OnAlert(alert)
+			}
+			s.webhooks.notify(WebhookPayload{
+				Event:   config.WebhookEventAlert,
+				Message: s.Status().Message,
+				Error:   alert.String(),
+			})
+			if alertAction(s.alerts) == config.StopActionStop {
+				s.setStopReason(StopReasonAlert)
+				return
+			}
+			if err := s.Pause(); err != nil {
+				log.Debug().Err(err).Msg("Alert pause skipped")
+			}
+		}
+
+		if match, ok := dog.observe(result); ok {
+			action := dog.nextAction()
+			log.Warn().Str("watchdog", match.String()).Str("action", action).Msg("Autoplay looks stuck")
+			if s.callbacks.OnWatchdog != nil {
+				s.callbacks.OnWatchdog(match, action)
+			}
+			switch action {
+			case config.StopActionStop:
+				s.setStopReason(StopReasonStuck)
+				return
+			case config.StopActionPause:
+				if err := s.Pause(); err != nil {
+					log.Debug().Err(err).Msg("Watchdog pause skipped")
 				}
+			default:
+				if s.callbacks.OnNudge != nil {
+					s.callbacks.OnNudge(match.Nudge())
+				}
+			}
+		}
 
-				// P3: Circuit breaker - stop if too many consecutive errors
-				if consecutiveErrors >= maxConsecutiveErrors {
-					log.Warn().Int("consecutive_errors", consecutiveErrors).Msg("Circuit breaker triggered - stopping autoplay")
-					return
+		if s.goalFinished(result) {
+			status := s.Status()
+			s.webhooks.notify(WebhookPayload{
+				Event:   config.WebhookEventGoal,
+				Message: status.Message,
+				Goal:    status.Goal,
+				Goals:   status.Goals,
+			})
+			if !s.advanceGoal() {
+				log.Info().Msg("Autoplay playbook finished")
+				s.setStopReason(StopReasonPlaybookDone)
+				return
+			}
+		} else if err == nil {
+			s.refineGoal(ctx)
+		}
+
+		s.mu.Lock()
+		if s.rotation != nil {
+			s.rotateLocked()
+		}
+		s.mu.Unlock()
+
+		s.mu.Lock()
+		s.nextInterval = delay
+		s.nextTurnAt = time.Now().Add(delay)
+		s.mu.Unlock()
+
+		if s.callbacks.OnTurnDone != nil {
+			s.callbacks.OnTurnDone(s.Status())
+		}
+
+		log.Debug().
+			Dur("turn_duration", elapsed).
+			Dur("next_interval", delay).
+			Msg("Scheduled next autoplay turn")
+	}
+}
+
+// wait blocks until the next turn is due: the delay has elapsed, autoplay
+// is not paused, and the current time is inside the schedule.
+// Returns false if the context was canceled.
+func (s *Service) wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	// The time limit ends the run while waiting; a running turn always completes
+	var deadline <-chan time.Time
+	s.mu.Lock()
+	if s.limits.MaxDuration > 0 {
+		deadlineTimer := time.NewTimer(time.Until(s.startedAt.Add(s.limits.MaxDuration)))
+		defer deadlineTimer.Stop()
+		deadline = deadlineTimer.C
+	}
+	s.mu.Unlock()
+	timeLimitReached := func() bool {
+		log.Info().Msg("Autoplay time limit reached")
+		s.setStopReason(StopReasonTimeLimit)
+		return false
+	}
+
+	elapsed := false
+	waitingForWindow := false
+	for {
+		if !elapsed {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-deadline:
+				return timeLimitReached()
+			case <-timer.C:
+				elapsed = true
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		paused := s.paused
+		resumeCh := s.resumeCh
+		nextWindow := nextWindowStart(s.schedule, time.Now())
+		s.mu.Unlock()
+
+		if paused {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-deadline:
+				return timeLimitReached()
+			case <-resumeCh:
+			}
+			continue
+		}
+
+		if !nextWindow.IsZero() {
+			if !waitingForWindow {
+				waitingForWindow = true
+				log.Info().Time("next_window", nextWindow).Msg("Autoplay waiting for schedule window")
+				if s.callbacks.OnSchedule != nil {
+					s.callbacks.OnSchedule(false, nextWindow)
 				}
-			} else {
-				// Reset error counter on success
-				s.mu.Lock()
-				s.consecutiveErrors = 0
-				s.mu.Unlock()
 			}
 
-			// Check if canceled immediately after processing turn
+			windowTimer := time.NewTimer(time.Until(nextWindow))
 			select {
 			case <-ctx.Done():
-				return
-			default:
+				windowTimer.Stop()
+				return false
+			case <-deadline:
+				windowTimer.Stop()
+				return timeLimitReached()
+			case <-windowTimer.C:
 			}
+			continue
 		}
+
+		if waitingForWindow {
+			log.Info().Msg("Autoplay schedule window opened")
+			if s.callbacks.OnSchedule != nil {
+				s.callbacks.OnSchedule(true, time.Time{})
+			}
+		}
+		return true
+	}
+}
+
+// tripBreaker opens the circuit breaker and returns the cooldown to wait.
+// After the cooldown a single turn is tried: one more failure trips it again.
+func (s *Service) tripBreaker(consecutiveErrors int) time.Duration {
+	until := time.Now().Add(s.breaker.Cooldown)
+
+	s.mu.Lock()
+	s.breakerOpen = true
+	s.breakerUntil = until
+	s.consecutiveErrors = s.breaker.Threshold - 1
+	s.mu.Unlock()
+
+	log.Warn().
+		Int("consecutive_errors", consecutiveErrors).
+		Dur("cooldown", s.breaker.Cooldown).
+		Msg("Circuit breaker triggered - pausing autoplay for cooldown")
+
+	if s.callbacks.OnBreaker != nil {
+		s.callbacks.OnBreaker(true, until)
+	}
+	s.webhooks.notify(WebhookPayload{
+		Event:             config.WebhookEventBreaker,
+		Message:           s.Status().Message,
+		ConsecutiveErrors: consecutiveErrors,
+		BreakerUntil:      &until,
+	})
+	return s.breaker.Cooldown
+}
+
+// budgetExceededLocked returns the stop reason if the run used up its token
+// or cost budget, or "" otherwise. Must be called with mu held.
+func (s *Service) budgetExceededLocked() string {
+	if s.limits.MaxTokens > 0 && s.usage.Tokens() >= s.limits.MaxTokens {
+		return StopReasonTokenBudget
+	}
+	if s.limits.MaxCost > 0 && s.usage.Cost >= s.limits.MaxCost {
+		return StopReasonCostBudget
+	}
+	return ""
+}
+
+// rotateLocked switches the message to the next named goal.
+// Must be called with mu held.
+func (s *Service) rotateLocked() {
+	g := s.rotation.next()
+	s.goalName = g.Name
+	s.message = g.Message
+}
+
+// goalFinished reports whether the current goal reached its limits or
+// matched a stop condition in the last turn.
+func (s *Service) goalFinished(result *llm.TurnResult) bool {
+	s.mu.Lock()
+	goal := s.goals[s.goalIndex]
+	goalTurns := s.goalTurns
+	goalStartedAt := s.goalStartedAt
+	s.mu.Unlock()
+
+	switch {
+	case goal.Turns > 0 && goalTurns >= goal.Turns:
+		log.Info().Int("turns", goalTurns).Msg("Autoplay goal turn limit reached")
+		return true
+	case goal.Duration > 0 && time.Since(goalStartedAt) >= goal.Duration:
+		log.Info().Dur("duration", goal.Duration).Msg("Autoplay goal time limit reached")
+		return true
+	case goal.matchesStopCondition(result):
+		log.Info().Strs("stop_on", goal.StopOn).Msg("Autoplay goal stop condition matched")
+		return true
+	}
+	return false
+}
+
+// refineGoal replaces the standing goal with a model-refined version every
+// refineEvery turns of the current goal. Rotation picks its goals from
+// config each turn, so it is never refined.
+func (s *Service) refineGoal(ctx context.Context) {
+	s.mu.Lock()
+	due := s.refineEvery > 0 && s.rotation == nil && s.goalTurns%s.refineEvery == 0
+	goal := s.message
+	s.mu.Unlock()
+
+	if !due || s.callbacks.OnRefine == nil {
+		return
+	}
+
+	refined, err := s.callbacks.OnRefine(ctx, goal)
+	if err != nil {
+		log.Warn().Err(err).Msg("Autoplay goal refinement failed, keeping goal")
+		return
+	}
+	if refined == goal {
+		return
+	}
+
+	s.mu.Lock()
+	s.message = refined
+	s.mu.Unlock()
+
+	log.Info().Str("from", goal).Str("to", refined).Msg("Autoplay goal refined")
+	if s.callbacks.OnGoalRefined != nil {
+		s.callbacks.OnGoalRefined(s.Status())
+	}
+}
+
+// advanceGoal moves to the next goal. Returns false if there is none.
+func (s *Service) advanceGoal() bool {
+	s.mu.Lock()
+	if s.goalIndex+1 >= len(s.goals) {
+		s.mu.Unlock()
+		return false
+	}
+
+	s.goalIndex++
+	s.goalTurns = 0
+	s.goalStartedAt = time.Now()
+	s.message = s.goals[s.goalIndex].Message
+	s.mu.Unlock()
+
+	status := s.Status()
+	log.Info().
+		Int("goal", status.Goal).
+		Int("goals", status.Goals).
+		Str("message", status.Message).
+		Msg("Autoplay advanced to next goal")
+
+	if s.callbacks.OnGoalChanged != nil {
+		s.callbacks.OnGoalChanged(status)
+	}
+	return true
+}
+
+// setStopReason records why the run ended, keeping the first reason.
+func (s *Service) setStopReason(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopReason == "" {
+		s.stopReason = reason
+	}
+}
+
+// summaryLocked builds the run summary. Must be called with mu held.
+func (s *Service) summaryLocked() AutoplaySummary {
+	toolCalls := make(map[string]int, len(s.toolCalls))
+	for name, n := range s.toolCalls {
+		toolCalls[name] = n
+	}
+
+	reason := s.stopReason
+	if reason == "" {
+		reason = StopReasonUser
+	}
+
+	// The current goal counts as completed only if the playbook finished
+	goalsCompleted := s.goalIndex
+	if s.stopReason == StopReasonPlaybookDone {
+		goalsCompleted = len(s.goals)
+	}
+
+	return AutoplaySummary{
+		Message:        s.message,
+		Goals:          len(s.goals),
+		GoalsCompleted: goalsCompleted,
+		Reason:         reason,
+		Turns:          s.turns,
+		FailedTurns:    s.failedTurns,
+		ToolCalls:      toolCalls,
+		Duration:       time.Since(s.startedAt),
+		Unsent:         len(s.interjections),
+		Usage:          s.usage,
 	}
 }
 
+// nextWindowStart returns when the next schedule window opens, or the zero
+// time if now is inside a window or there is no schedule.
+func nextWindowStart(schedule []config.TimeWindow, now time.Time) time.Time {
+	var next time.Time
+	for _, w := range schedule {
+		if w.Contains(now) {
+			return time.Time{}
+		}
+		if start := w.NextStart(now); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next
+}
+
+// nextInterval schedules the wait before the next turn.
+// It applies DESIGN.md's budget (tool calls × game tick × 0.75) to the
+// previous turn's actual tool calls, minus the time the turn already took,
+// clamped to [minInterval, maxInterval].
+func nextInterval(turnDuration time.Duration, toolCalls int, minInterval, maxInterval time.Duration) time.Duration {
+	budget := time.Duration(float64(toolCalls) * float64(constants.GameTickDuration) * constants.AutoplayTickFactor)
+	delay := budget - turnDuration
+
+	if delay < minInterval {
+		return minInterval
+	}
+	if delay > maxInterval {
+		return maxInterval
+	}
+	return delay
+}
+
+// applyJitter spreads d by up to ±fraction. r is a random number in [0, 1).
+func applyJitter(d time.Duration, fraction, r float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	return d + time.Duration(float64(d)*fraction*(2*r-1))
+}
+
 // sendMessage sends a single autoplay message by calling the OnTurn callback.
-func (s *Service) sendMessage(ctx context.Context) error {
+func (s *Service) sendMessage(ctx context.Context) (*llm.TurnResult, error) {
 	log.Debug().Msg("sendAutoplayMessage called")
 
 	s.mu.Lock()
@@ -252,18 +1241,30 @@ func (s *Service) sendMessage(ctx context.Context) error {
 	log.Debug().Bool("enabled", enabled).Str("message", message).Msg("Autoplay state")
 
 	if !enabled {
-		return fmt.Errorf("autoplay disabled")
+		return nil, fmt.Errorf("autoplay disabled")
+	}
+
+	// Add queued user messages ahead of the autoplay message
+	s.mu.Lock()
+	interjections := s.interjections
+	s.interjections = nil
+	s.mu.Unlock()
+	for _, msg := range interjections {
+		if s.callbacks.OnInterjection != nil {
+			s.callbacks.OnInterjection(msg)
+		}
 	}
 
 	// Call the OnTurn callback to process the turn
 	if s.callbacks.OnTurn == nil {
-		return fmt.Errorf("no OnTurn callback configured")
+		return nil, fmt.Errorf("no OnTurn callback configured")
 	}
 
-	if err := s.callbacks.OnTurn(ctx, message); err != nil {
-		return fmt.Errorf("autoplay turn failed: %w", err)
+	result, err := s.callbacks.OnTurn(ctx, message)
+	if err != nil {
+		return result, fmt.Errorf("autoplay turn failed: %w", err)
 	}
 
 	log.Debug().Msg("Autoplay message sent successfully")
-	return nil
+	return result, nil
 }
@@ -0,0 +1,140 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Autoplay command actions, the first word after /autoplay.
+const (
+	AutoplayActionStart    = "start" // Implicit: /autoplay <message>
+	AutoplayActionStatus   = "status"
+	AutoplayActionStop     = "stop"
+	AutoplayActionPause    = "pause"
+	AutoplayActionResume   = "resume"
+	AutoplayActionPlaybook = "playbook"
+	AutoplayActionRotate   = "rotate"
+)
+
+// AutoplayUsage is the one-line usage shown for an invalid /autoplay command.
+const AutoplayUsage = "usage: /autoplay <message> [--turns N] [--for 2h] [--max-tokens N] [--max-cost USD] [--dry-run] [--safe], " +
+	"/autoplay playbook <file>, /autoplay rotate [names...], /autoplay status|pause|resume|stop"
+
+// AutoplayCommand is a parsed /autoplay command, shared by the CLI and TUI.
+type AutoplayCommand struct {
+	Action string
+	Args   AutoplayArgs // Options and message for start, playbook and rotate
+}
+
+// ParseAutoplayCommand parses the fields of an /autoplay command line,
+// including the leading "/autoplay". A bare "/autoplay" is a status request.
+func ParseAutoplayCommand(fields []string) (AutoplayCommand, error) {
+	if len(fields) > 0 && fields[0] == "/autoplay" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return AutoplayCommand{Action: AutoplayActionStatus}, nil
+	}
+
+	switch action := fields[0]; action {
+	case AutoplayActionStatus, AutoplayActionStop, AutoplayActionPause, AutoplayActionResume:
+		return AutoplayCommand{Action: action}, nil
+	case AutoplayActionPlaybook, AutoplayActionRotate:
+		args, err := ParseAutoplayArgs(fields[1:])
+		if err != nil {
+			return AutoplayCommand{}, err
+		}
+		if action == AutoplayActionPlaybook && args.Message == "" {
+			return AutoplayCommand{}, fmt.Errorf("usage: /autoplay playbook <file> [--turns N] [--for 2h]")
+		}
+		return AutoplayCommand{Action: action, Args: args}, nil
+	}
+
+	args, err := ParseAutoplayArgs(fields)
+	if err != nil {
+		return AutoplayCommand{}, err
+	}
+	if args.Message == "" {
+		return AutoplayCommand{}, fmt.Errorf("missing message for autoplay")
+	}
+	return AutoplayCommand{Action: AutoplayActionStart, Args: args}, nil
+}
+
+// Execute runs an autoplay command. Status is display-specific and left to
+// the caller; results are reported through the service callbacks.
+func (s *Service) Execute(ctx context.Context, cmd AutoplayCommand) error {
+	switch cmd.Action {
+	case AutoplayActionStatus:
+		return nil
+	case AutoplayActionStop:
+		return s.Stop()
+	case AutoplayActionPause:
+		return s.Pause()
+	case AutoplayActionResume:
+		return s.Resume()
+	case AutoplayActionStart:
+		return s.startWithArgs(cmd.Args, func() error {
+			return s.Start(ctx, cmd.Args.Message, cmd.Args.Limits)
+		})
+	case AutoplayActionPlaybook:
+		playbook, err := LoadPlaybook(cmd.Args.Message)
+		if err != nil {
+			return err
+		}
+		return s.startWithArgs(cmd.Args, func() error {
+			return s.StartPlaybook(ctx, playbook, cmd.Args.Limits)
+		})
+	case AutoplayActionRotate:
+		return s.startWithArgs(cmd.Args, func() error {
+			return s.StartRotation(ctx, strings.Fields(cmd.Args.Message), cmd.Args.Limits)
+		})
+	}
+	return fmt.Errorf("unknown autoplay command %q", cmd.Action)
+}
+
+// StartFromFlags starts autoplay from the --autoplay and --playbook flags.
+// The --autoplay value accepts the same options as /autoplay. It does
+// nothing when neither flag is set.
+func (s *Service) StartFromFlags(ctx context.Context, autoplay string, playbook *Playbook) error {
+	if playbook != nil {
+		if err := s.StartPlaybook(ctx, playbook, AutoplayLimits{}); err != nil {
+			return fmt.Errorf("failed to start playbook: %w", err)
+		}
+		return nil
+	}
+	if autoplay == "" {
+		return nil
+	}
+
+	args, err := ParseAutoplayArgs(strings.Fields(autoplay))
+	if err != nil {
+		return fmt.Errorf("failed to start autoplay: %w", err)
+	}
+	err = s.startWithArgs(args, func() error {
+		return s.Start(ctx, args.Message, args.Limits)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start autoplay: %w", err)
+	}
+	return nil
+}
+
+// startWithArgs applies dry-run and safe mode for the run and calls start.
+// Both are turned off again when autoplay stops.
+func (s *Service) startWithArgs(args AutoplayArgs, start func() error) error {
+	if s.Status().Enabled {
+		return fmt.Errorf("autoplay already running - use '/autoplay stop' first")
+	}
+
+	// A stopped run's loop may still be exiting: it must not reset the
+	// mode between here and start
+	s.toolModeMu.Lock()
+	defer s.toolModeMu.Unlock()
+	s.setToolMode(args.DryRun, args.Safe)
+	if err := start(); err != nil {
+		s.setToolMode(false, false)
+		return err
+	}
+	return nil
+}
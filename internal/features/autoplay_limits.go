@@ -0,0 +1,201 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// Reasons autoplay stopped, reported in AutoplaySummary.
+const (
+	StopReasonUser         = "stopped by user"
+	StopReasonTurnLimit    = "turn limit reached"
+	StopReasonTimeLimit    = "time limit reached"
+	StopReasonErrors       = "too many consecutive errors"
+	StopReasonPlaybookDone = "playbook finished"
+	StopReasonTokenBudget  = "token budget reached"
+	StopReasonCostBudget   = "cost budget reached"
+	StopReasonCondition    = "stop condition met"
+	StopReasonAlert        = "critical notification"
+	StopReasonStuck        = "stuck in a loop"
+	StopReasonScript       = "stopped by script"
+)
+
+// AutoplayLimits bounds an autoplay run. Zero values mean unlimited.
+type AutoplayLimits struct {
+	MaxTurns    int
+	MaxDuration time.Duration
+	MaxTokens   int     // Estimated LLM tokens (input + output)
+	MaxCost     float64 // Estimated USD, requires provider pricing
+}
+
+// withDefaults fills unset limits from defaults.
+func (l AutoplayLimits) withDefaults(defaults AutoplayLimits) AutoplayLimits {
+	if l.MaxTurns == 0 {
+		l.MaxTurns = defaults.MaxTurns
+	}
+	if l.MaxDuration == 0 {
+		l.MaxDuration = defaults.MaxDuration
+	}
+	if l.MaxTokens == 0 {
+		l.MaxTokens = defaults.MaxTokens
+	}
+	if l.MaxCost == 0 {
+		l.MaxCost = defaults.MaxCost
+	}
+	return l
+}
+
+// String describes the limits for display.
+func (l AutoplayLimits) String() string {
+	var parts []string
+	if l.MaxTurns > 0 {
+		parts = append(parts, fmt.Sprintf("%d turns", l.MaxTurns))
+	}
+	if l.MaxDuration > 0 {
+		parts = append(parts, l.MaxDuration.String())
+	}
+	if l.MaxTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens", l.MaxTokens))
+	}
+	if l.MaxCost > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f", l.MaxCost))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " or ")
+}
+
+// AutoplayArgs are the parsed arguments of an /autoplay command.
+type AutoplayArgs struct {
+	Message string // Remaining words: the goal, playbook path or goal names
+	Limits  AutoplayLimits
+	DryRun  bool // Simulate mutating game tools (see mcp.Proxy.SetDryRun)
+	Safe    bool // Restrict tools to the safe-mode allowlist (see mcp.Proxy.SetToolFilter)
+}
+
+// ParseAutoplayArgs splits "/autoplay" arguments into the goal message and
+// its options, e.g. "mine ore --turns 20 --for 2h --max-cost 1.50 --dry-run".
+func ParseAutoplayArgs(fields []string) (AutoplayArgs, error) {
+	var args AutoplayArgs
+	var words []string
+
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "--turns":
+			if i+1 >= len(fields) {
+				return args, fmt.Errorf("--turns requires a number")
+			}
+			i++
+			n, err := strconv.Atoi(fields[i])
+			if err != nil || n < 1 {
+				return args, fmt.Errorf("invalid --turns %q: must be a positive number", fields[i])
+			}
+			args.Limits.MaxTurns = n
+		case "--for":
+			if i+1 >= len(fields) {
+				return args, fmt.Errorf("--for requires a duration (e.g. 2h)")
+			}
+			i++
+			d, err := time.ParseDuration(fields[i])
+			if err != nil || d <= 0 {
+				return args, fmt.Errorf("invalid --for %q: must be a positive duration (e.g. 2h)", fields[i])
+			}
+			args.Limits.MaxDuration = d
+		case "--max-tokens":
+			if i+1 >= len(fields) {
+				return args, fmt.Errorf("--max-tokens requires a number")
+			}
+			i++
+			n, err := strconv.Atoi(fields[i])
+			if err != nil || n < 1 {
+				return args, fmt.Errorf("invalid --max-tokens %q: must be a positive number", fields[i])
+			}
+			args.Limits.MaxTokens = n
+		case "--max-cost":
+			if i+1 >= len(fields) {
+				return args, fmt.Errorf("--max-cost requires an amount in USD")
+			}
+			i++
+			cost, err := strconv.ParseFloat(strings.TrimPrefix(fields[i], "$"), 64)
+			if err != nil || cost <= 0 {
+				return args, fmt.Errorf("invalid --max-cost %q: must be a positive amount in USD", fields[i])
+			}
+			args.Limits.MaxCost = cost
+		case "--dry-run":
+			args.DryRun = true
+		case "--safe":
+			args.Safe = true
+		default:
+			words = append(words, fields[i])
+		}
+	}
+
+	args.Message = strings.Join(words, " ")
+	return args, nil
+}
+
+// AutoplaySummary describes what an autoplay run did.
+type AutoplaySummary struct {
+	Message        string
+	Goals          int // Goals in the run; 1 unless running a playbook
+	GoalsCompleted int
+	Reason         string
+	Turns          int
+	FailedTurns    int
+	ToolCalls      map[string]int // Calls per tool name
+	Duration       time.Duration
+	Usage          llm.Usage
+	Unsent         int // Queued user messages dropped because the run ended
+}
+
+// Lines formats the summary as display-agnostic text lines.
+func (s AutoplaySummary) Lines() []string {
+	total := 0
+	names := make([]string, 0, len(s.ToolCalls))
+	for name, n := range s.ToolCalls {
+		total += n
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.ToolCalls[names[i]] != s.ToolCalls[names[j]] {
+			return s.ToolCalls[names[i]] > s.ToolCalls[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	lines := []string{
+		fmt.Sprintf("Autoplay summary (%s): %d turns in %s, %d failed",
+			s.Reason, s.Turns, s.Duration.Round(time.Second), s.FailedTurns),
+		fmt.Sprintf("Tool calls: %d", total),
+		"LLM usage: " + s.Usage.String(),
+	}
+
+	if s.Unsent > 0 {
+		lines = append(lines, fmt.Sprintf("Queued messages not sent: %d", s.Unsent))
+	}
+
+	if s.Goals > 1 {
+		lines = append(lines, fmt.Sprintf("Playbook goals completed: %d/%d", s.GoalsCompleted, s.Goals))
+	}
+
+	const maxTools = 5
+	if len(names) > 0 {
+		top := make([]string, 0, maxTools)
+		for i, name := range names {
+			if i == maxTools {
+				top = append(top, fmt.Sprintf("+%d more", len(names)-maxTools))
+				break
+			}
+			top = append(top, fmt.Sprintf("%s×%d", name, s.ToolCalls[name]))
+		}
+		lines[1] += " (" + strings.Join(top, ", ") + ")"
+	}
+
+	return lines
+}
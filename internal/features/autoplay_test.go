@@ -0,0 +1,624 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestNextInterval(t *testing.T) {
+	minInterval := 10 * time.Second
+	maxInterval := 75 * time.Second
+
+	tests := []struct {
+		name         string
+		turnDuration time.Duration
+		toolCalls    int
+		want         time.Duration
+	}{
+		{"no tool calls uses minimum", 2 * time.Second, 0, minInterval},
+		{"budget minus turn duration", 5 * time.Second, 4, 25 * time.Second},
+		{"slow turn clamps to minimum", 40 * time.Second, 4, minInterval},
+		{"many tool calls clamps to maximum", 0, 20, maxInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextInterval(tt.turnDuration, tt.toolCalls, minInterval, maxInterval)
+			if got != tt.want {
+				t.Errorf("nextInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        time.Duration
+		fraction float64
+		r        float64
+		want     time.Duration
+	}{
+		{"disabled", 60 * time.Second, 0, 0.9, 60 * time.Second},
+		{"lowest", 60 * time.Second, 0.2, 0, 48 * time.Second},
+		{"middle", 60 * time.Second, 0.2, 0.5, 60 * time.Second},
+		{"highest", 60 * time.Second, 0.2, 1, 72 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyJitter(tt.d, tt.fraction, tt.r); got != tt.want {
+				t.Errorf("applyJitter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	turns := make(chan struct{}, 10)
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: 10 * time.Millisecond,
+		MaxInterval: 10 * time.Millisecond,
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			turns <- struct{}{}
+			return &llm.TurnResult{}, nil
+		},
+	})
+
+	if err := svc.Resume(); err == nil {
+		t.Fatal("Resume() before Start() should fail")
+	}
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = svc.Stop() }()
+
+	<-turns // First turn runs immediately
+	if err := svc.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if err := svc.Pause(); err == nil {
+		t.Error("second Pause() should fail")
+	}
+
+	status := svc.Status()
+	if !status.Enabled || !status.Paused || status.Message != "mine" {
+		t.Errorf("Status() = %+v, want enabled and paused with goal kept", status)
+	}
+
+	select {
+	case <-turns:
+		t.Fatal("turn ran while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := svc.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	select {
+	case <-turns:
+	case <-time.After(time.Second):
+		t.Fatal("no turn after Resume()")
+	}
+}
+
+func TestRestartAfterStop(t *testing.T) {
+	turns := make(chan string, 10)
+	release := make(chan struct{})
+	stopped := make(chan AutoplaySummary, 2)
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: 10 * time.Millisecond,
+		MaxInterval: 10 * time.Millisecond,
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			turns <- message
+			if message == "mine" {
+				<-release // The first run's turn outlives its stop
+			}
+			return &llm.TurnResult{}, nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+	svc.SetToolGate(mcp.NewProxy(nil))
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	<-turns
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := svc.StartFromFlags(context.Background(), "trade --dry-run", nil); err != nil {
+		t.Fatalf("second start error = %v", err)
+	}
+	defer func() { _ = svc.Stop() }()
+	close(release)
+
+	// The first run reports its own summary, not the second's
+	select {
+	case summary := <-stopped:
+		if summary.Message != "mine" || summary.Reason != StopReasonUser {
+			t.Errorf("first run summary = %+v", summary)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("first run did not finish")
+	}
+	if status := svc.Status(); !status.Enabled || status.Message != "trade" || !status.DryRun {
+		t.Fatalf("Status() = %+v, want the second run enabled in dry-run", status)
+	}
+	for {
+		select {
+		case message := <-turns:
+			if message == "trade" {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no turn of the second run after the first finished")
+		}
+	}
+}
+
+func TestStopDuringTurn(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		events = append(events, payload.Event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	inTurn := make(chan struct{})
+	stopped := make(chan AutoplaySummary, 1)
+	var failed atomic.Bool
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: 10 * time.Millisecond,
+		MaxInterval: 10 * time.Millisecond,
+		Breaker:     config.BreakerConfig{Threshold: 1},
+		Webhooks:    []config.WebhookConfig{{URL: server.URL}},
+		Milestones:  config.MilestoneConfig{Turns: 1},
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			close(inTurn)
+			<-ctx.Done()
+			return &llm.TurnResult{}, ctx.Err()
+		},
+		OnError:   func(error) { failed.Store(true) },
+		OnStopped: func(summary AutoplaySummary) { stopped <- summary },
+	})
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	<-inTurn
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	var summary AutoplaySummary
+	select {
+	case summary = <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not stop")
+	}
+	svc.webhooks.wait()
+
+	if failed.Load() || summary.FailedTurns != 0 || summary.Reason != StopReasonUser {
+		t.Errorf("OnError called %v, summary %+v; want a user stop without failures", failed.Load(), summary)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, event := range events {
+		if event != config.WebhookEventStarted && event != config.WebhookEventStopped {
+			t.Errorf("webhook events = %v, want only started and stopped", events)
+			break
+		}
+	}
+}
+
+func TestNextWindowStart(t *testing.T) {
+	night, err := config.ParseTimeWindow("22:00-06:00")
+	if err != nil {
+		t.Fatalf("ParseTimeWindow() error = %v", err)
+	}
+	lunch, err := config.ParseTimeWindow("12:00-13:30")
+	if err != nil {
+		t.Fatalf("ParseTimeWindow() error = %v", err)
+	}
+	schedule := []config.TimeWindow{night, lunch}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"inside wrapping window before midnight", at(23, 0), time.Time{}},
+		{"inside wrapping window after midnight", at(5, 59), time.Time{}},
+		{"inside daytime window", at(12, 30), time.Time{}},
+		{"morning waits for lunch", at(6, 0), at(12, 0)},
+		{"afternoon waits for night", at(13, 30), at(22, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextWindowStart(schedule, tt.now)
+			if !got.Equal(tt.want) {
+				t.Errorf("nextWindowStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := nextWindowStart(nil, at(9, 0)); !got.IsZero() {
+		t.Errorf("nextWindowStart() without schedule = %v, want zero", got)
+	}
+	if _, err := config.ParseTimeWindow("22:00"); err == nil {
+		t.Error("ParseTimeWindow() without end should fail")
+	}
+}
+
+func TestTurnLimit(t *testing.T) {
+	stopped := make(chan AutoplaySummary, 1)
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		MaxTurns:    10,
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			return &llm.TurnResult{Messages: []provider.Message{
+				{Role: "assistant", ToolCalls: []provider.ToolCall{{Name: "mine"}, {Name: "get_status"}}},
+			}}, nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	// Explicit limits override the configured default
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{MaxTurns: 3}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case summary := <-stopped:
+		if summary.Reason != StopReasonTurnLimit {
+			t.Errorf("Reason = %q, want %q", summary.Reason, StopReasonTurnLimit)
+		}
+		if summary.Turns != 3 {
+			t.Errorf("Turns = %d, want 3", summary.Turns)
+		}
+		if summary.ToolCalls["mine"] != 3 || summary.ToolCalls["get_status"] != 3 {
+			t.Errorf("ToolCalls = %v, want 3 of each", summary.ToolCalls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not stop at turn limit")
+	}
+}
+
+func TestCircuitBreakerAutoResume(t *testing.T) {
+	var breaker []bool
+	stopped := make(chan AutoplaySummary, 1)
+	turn := 0
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		Breaker: config.BreakerConfig{
+			Threshold:  2,
+			Cooldown:   10 * time.Millisecond,
+			AutoResume: true,
+		},
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			turn++
+			if turn <= 2 {
+				return nil, errors.New("provider unavailable")
+			}
+			return &llm.TurnResult{}, nil
+		},
+		OnBreaker: func(open bool, until time.Time) {
+			breaker = append(breaker, open)
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{MaxTurns: 3}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case summary := <-stopped:
+		if summary.Reason != StopReasonTurnLimit {
+			t.Errorf("Reason = %q, want %q", summary.Reason, StopReasonTurnLimit)
+		}
+		if summary.FailedTurns != 2 {
+			t.Errorf("FailedTurns = %d, want 2", summary.FailedTurns)
+		}
+		if len(breaker) != 2 || !breaker[0] || breaker[1] {
+			t.Errorf("breaker transitions = %v, want [true false]", breaker)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not recover after breaker cooldown")
+	}
+}
+
+func TestProviderFailover(t *testing.T) {
+	var failedOver []string
+	stopped := make(chan AutoplaySummary, 1)
+	turn := 0
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		Breaker: config.BreakerConfig{
+			Threshold: 2,
+			Failover:  "backup",
+		},
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			turn++
+			if turn <= 2 {
+				return nil, fmt.Errorf("%w: connection refused", llm.ErrLLMCall)
+			}
+			return &llm.TurnResult{}, nil
+		},
+		OnFailover: func(ctx context.Context, providerName string) error {
+			failedOver = append(failedOver, providerName)
+			return nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{MaxTurns: 3}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case summary := <-stopped:
+		if summary.Reason != StopReasonTurnLimit {
+			t.Errorf("Reason = %q, want %q", summary.Reason, StopReasonTurnLimit)
+		}
+		if len(failedOver) != 1 || failedOver[0] != "backup" {
+			t.Errorf("failovers = %v, want [backup]", failedOver)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not continue after failover")
+	}
+}
+
+func TestNoFailoverOnGameErrors(t *testing.T) {
+	stopped := make(chan AutoplaySummary, 1)
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		Breaker: config.BreakerConfig{
+			Threshold: 2,
+			Failover:  "backup",
+		},
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			return nil, errors.New("too many tool call rounds")
+		},
+		OnFailover: func(ctx context.Context, providerName string) error {
+			t.Error("OnFailover called for a non-provider error")
+			return nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case summary := <-stopped:
+		if summary.Reason != StopReasonErrors {
+			t.Errorf("Reason = %q, want %q", summary.Reason, StopReasonErrors)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("breaker did not stop autoplay")
+	}
+}
+
+func TestParseAutoplayArgs(t *testing.T) {
+	args, err := ParseAutoplayArgs([]string{"mine", "--turns", "20", "ore", "--for", "2h", "--dry-run"})
+	if err != nil {
+		t.Fatalf("ParseAutoplayArgs() error = %v", err)
+	}
+	if args.Message != "mine ore" {
+		t.Errorf("Message = %q, want %q", args.Message, "mine ore")
+	}
+	if args.Limits.MaxTurns != 20 || args.Limits.MaxDuration != 2*time.Hour {
+		t.Errorf("Limits = %+v, want 20 turns and 2h", args.Limits)
+	}
+	if !args.DryRun {
+		t.Error("DryRun = false, want true")
+	}
+
+	for _, args := range [][]string{{"mine", "--turns"}, {"mine", "--turns", "0"}, {"mine", "--for", "soon"}} {
+		if _, err := ParseAutoplayArgs(args); err == nil {
+			t.Errorf("ParseAutoplayArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestTokenBudget(t *testing.T) {
+	stopped := make(chan AutoplaySummary, 1)
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			return &llm.TurnResult{Usage: llm.Usage{InputTokens: 900, OutputTokens: 100, Cost: 0.01}}, nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{MaxCost: 0.025}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case summary := <-stopped:
+		if summary.Reason != StopReasonCostBudget || summary.Turns != 3 {
+			t.Errorf("summary = %+v, want cost budget reached after 3 turns", summary)
+		}
+		if summary.Usage.Tokens() != 3000 {
+			t.Errorf("Usage.Tokens() = %d, want 3000", summary.Usage.Tokens())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not stop at cost budget")
+	}
+}
+
+func TestParseAutoplayCommand(t *testing.T) {
+	tests := []struct {
+		input   string
+		action  string
+		message string
+		wantErr bool
+	}{
+		{"/autoplay", AutoplayActionStatus, "", false},
+		{"/autoplay status", AutoplayActionStatus, "", false},
+		{"/autoplay stop", AutoplayActionStop, "", false},
+		{"/autoplay pause", AutoplayActionPause, "", false},
+		{"/autoplay mine ore --turns 5", AutoplayActionStart, "mine ore", false},
+		{"/autoplay rotate mine explore", AutoplayActionRotate, "mine explore", false},
+		{"/autoplay rotate", AutoplayActionRotate, "", false},
+		{"/autoplay playbook pb.toml --for 1h", AutoplayActionPlaybook, "pb.toml", false},
+		{"/autoplay playbook", "", "", true},
+		{"/autoplay --turns 5", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cmd, err := ParseAutoplayCommand(strings.Fields(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAutoplayCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cmd.Action != tt.action || cmd.Args.Message != tt.message {
+				t.Errorf("got %q %q, want %q %q", cmd.Action, cmd.Args.Message, tt.action, tt.message)
+			}
+		})
+	}
+}
+
+func TestInterject(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	firstTurn := make(chan struct{})
+	release := make(chan struct{})
+	stopped := make(chan AutoplaySummary, 1)
+
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+	}, AutoplayCallbacks{
+		OnInterjection: func(message string) {
+			mu.Lock()
+			events = append(events, "user: "+message)
+			mu.Unlock()
+		},
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			mu.Lock()
+			events = append(events, "turn")
+			n := len(events)
+			mu.Unlock()
+			if n == 1 {
+				close(firstTurn)
+				<-release
+			}
+			return &llm.TurnResult{}, nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	if _, err := svc.Interject("hello"); err == nil {
+		t.Error("Interject() before start should fail")
+	}
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{MaxTurns: 2}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	<-firstTurn
+	if n, err := svc.Interject("dock now"); err != nil || n != 1 {
+		t.Fatalf("Interject() = %d, %v; want 1, nil", n, err)
+	}
+	close(release)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not stop")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"turn", "user: dock now", "turn"}
+	if strings.Join(events, "|") != strings.Join(want, "|") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestRefineGoal(t *testing.T) {
+	var messages []string
+	stopped := make(chan AutoplaySummary, 1)
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		RefineEvery: 2,
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			messages = append(messages, message)
+			return &llm.TurnResult{}, nil
+		},
+		OnRefine: func(ctx context.Context, goal string) (string, error) {
+			return goal + " and sell", nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	if err := svc.Start(context.Background(), "mine", AutoplayLimits{MaxTurns: 5}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("autoplay did not stop")
+	}
+
+	want := []string{"mine", "mine", "mine and sell", "mine and sell", "mine and sell and sell"}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+}
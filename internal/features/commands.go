@@ -0,0 +1,286 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// ErrQuit is returned by the /quit command; frontends end the session.
+var ErrQuit = errors.New("quit")
+
+// CommandFunc runs a slash command. args are the words after the command.
+type CommandFunc func(ctx context.Context, args []string) error
+
+// Command is a slash command available in CLI and TUI sessions.
+type Command struct {
+	Name    string   // Including the slash, e.g. "/compact"
+	Aliases []string // Other names, e.g. "/exit"
+	Args    string   // Argument synopsis for /help, e.g. "[turns]"
+	Help    string
+	Run     CommandFunc
+	RawArgs bool // Pass the text after the name as one argument, spacing kept
+}
+
+// Commands is a registry of slash commands. It always provides /help,
+// which lists every registered command.
+type Commands struct {
+	commands map[string]*Command // By name and alias
+	order    []*Command
+	print    func(lines []string)
+}
+
+// NewCommands creates a registry that displays output such as /help with
+// print.
+func NewCommands(print func(lines []string)) *Commands {
+	c := &Commands{commands: make(map[string]*Command), print: print}
+	c.Register(Command{
+		Name: "/help",
+		Help: "List the available commands",
+		Run: func(ctx context.Context, args []string) error {
+			c.print(c.HelpLines())
+			return nil
+		},
+	})
+	return c
+}
+
+// Register adds a command, replacing any command with the same name.
+// Commands without a handler are skipped.
+func (c *Commands) Register(cmd Command) {
+	if cmd.Run == nil {
+		return
+	}
+	entry := &cmd
+	if old, ok := c.commands[cmd.Name]; ok {
+		for i, existing := range c.order {
+			if existing == old {
+				c.order[i] = entry
+			}
+		}
+	} else {
+		c.order = append(c.order, entry)
+	}
+	c.commands[cmd.Name] = entry
+	for _, alias := range cmd.Aliases {
+		c.commands[alias] = entry
+	}
+}
+
+// Lookup returns the command registered under name or one of its aliases.
+func (c *Commands) Lookup(name string) (Command, bool) {
+	cmd, ok := c.commands[name]
+	if !ok {
+		return Command{}, false
+	}
+	return *cmd, true
+}
+
+// IsCommand reports whether input is a slash command line.
+func IsCommand(input string) bool {
+	return strings.HasPrefix(strings.TrimSpace(input), "/")
+}
+
+// Run runs the command line input, e.g. "/compact 5".
+func (c *Commands) Run(ctx context.Context, input string) error {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return nil
+	}
+	cmd, ok := c.commands[fields[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %s - type /help for a list", fields[0])
+	}
+	if cmd.RawArgs {
+		_, rest, _ := strings.Cut(strings.TrimSpace(input), fields[0])
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return cmd.Run(ctx, []string{rest})
+		}
+		return cmd.Run(ctx, nil)
+	}
+	return cmd.Run(ctx, fields[1:])
+}
+
+// HelpLines returns one aligned line per command, sorted by name.
+func (c *Commands) HelpLines() []string {
+	cmds := make([]*Command, len(c.order))
+	copy(cmds, c.order)
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+
+	usage := make([]string, len(cmds))
+	width := 0
+	for i, cmd := range cmds {
+		usage[i] = strings.Join(append([]string{cmd.Name}, cmd.Aliases...), ", ")
+		if cmd.Args != "" {
+			usage[i] += " " + cmd.Args
+		}
+		width = max(width, len(usage[i]))
+	}
+
+	lines := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		lines[i] = fmt.Sprintf("%-*s  %s", width, usage[i], cmd.Help)
+	}
+	return lines
+}
+
+// CommandHandlers are the frontend implementations of the session slash
+// commands, in the style of AutoplayCallbacks. Commands whose handler is
+// nil are not offered.
+type CommandHandlers struct {
+	// Print displays command output.
+	Print func(lines []string)
+
+	Autoplay CommandFunc // /autoplay, see ParseAutoplayCommand
+	Compact  CommandFunc // /compact [turns]
+
+	// Provider returns the provider and model of the session.
+	Provider func() (provider.Provider, string)
+	// SwitchProvider replaces the session provider, see SwitchProvider.
+	SwitchProvider func(ctx context.Context, name, model string) error
+	// History returns the stored messages of the session.
+	History func() ([]provider.Message, error)
+	// Context returns a copy of the messages sent to the LLM next turn.
+	Context func() []provider.Message
+	// ReplaceContext stores and displays a rewritten context, like /compact.
+	ReplaceContext func(messages []provider.Message) error
+	// AutoplayStatus returns the autoplay state of the session.
+	AutoplayStatus func() AutoplayStatus
+	// Stats returns the metrics of this run.
+	Stats func() llm.StatsSnapshot
+	// SessionInfo describes the session for transcripts.
+	SessionInfo func() TranscriptInfo
+	// Proxy returns the MCP proxy of the session.
+	Proxy func() *mcp.Proxy
+	// Undo deletes the last exchange from the store and the context, see
+	// store.LastExchange. Returns the number of stored messages deleted.
+	Undo func() (int, error)
+	// SetSystemPrompt replaces the session system prompt in the context and
+	// persists it, see SetSystemPrompt.
+	SetSystemPrompt func(prompt string) error
+	// Registry returns the provider registry, for one-off providers.
+	Registry func() *provider.Registry
+	// RunTurn runs an LLM turn on the current context with prov. A provider
+	// other than the session provider is closed after the turn.
+	RunTurn func(ctx context.Context, prov provider.Provider) error
+}
+
+// NewSessionCommands creates the slash command registry of a chat session.
+// New commands are added here so the CLI and TUI offer the same set.
+func NewSessionCommands(cfg *config.Config, h CommandHandlers) *Commands {
+	c := NewCommands(h.Print)
+	c.Register(Command{
+		Name: "/autoplay",
+		Args: "<message>|playbook|rotate|pause|resume|stop|status",
+		Help: "Control autonomous gameplay",
+		Run:  h.Autoplay,
+	})
+	c.Register(Command{
+		Name: "/compact",
+		Args: "[turns]",
+		Help: "Summarize older history, keeping recent turns",
+		Run:  h.Compact,
+	})
+	c.Register(Command{
+		Name: "/stats",
+		Help: "Show messages, token usage, cost, tool calls and autoplay turns",
+		Run:  statsCommand(h),
+	})
+	c.Register(Command{
+		Name: "/provider",
+		Args: "[name [model]]",
+		Help: "Show providers, or switch provider and optionally model",
+		Run:  providerCommand(cfg, h),
+	})
+	c.Register(Command{
+		Name: "/model",
+		Args: "[name]",
+		Help: "Show available models, or switch the model",
+		Run:  modelCommand(h),
+	})
+	c.Register(Command{
+		Name: "/history",
+		Args: "[n]",
+		Help: fmt.Sprintf("Show the last n stored messages (default %d)", historyDefaultCount),
+		Run:  historyCommand(h),
+	})
+	c.Register(Command{
+		Name: "/clear",
+		Args: "[summary]",
+		Help: "Start a fresh context, optionally keeping a summary (history stays stored)",
+		Run:  clearCommand(h),
+	})
+	c.Register(Command{
+		Name: "/save",
+		Args: "[path]",
+		Help: "Save the session transcript as Markdown (default: data dir)",
+		Run:  saveCommand(h),
+	})
+	c.Register(Command{
+		Name: "/share",
+		Args: "[path]",
+		Help: "Save a redacted, per-turn transcript to publish: HTML, or JSON unless path ends in .html",
+		Run:  shareCommand(h),
+	})
+	c.Register(Command{
+		Name: "/tools",
+		Args: "[filter]",
+		Help: "List MCP tools, local or upstream, and which are blocked",
+		Run:  toolsCommand(h),
+	})
+	c.Register(Command{
+		Name:    "/tool",
+		Args:    "<name> [json-args]",
+		Help:    "Call an MCP tool directly, without the LLM",
+		Run:     toolCommand(h),
+		RawArgs: true,
+	})
+	c.Register(Command{
+		Name: "/retry",
+		Args: "[provider] [temperature]",
+		Help: "Run the last turn again, optionally with another provider or temperature",
+		Run:  retryCommand(cfg, h),
+	})
+	c.Register(Command{
+		Name: "/undo",
+		Help: "Delete the last exchange from the context and the store",
+		Run:  undoCommand(h),
+	})
+	c.Register(Command{
+		Name:    "/system",
+		Args:    "[show] | set <prompt> | append <text>",
+		Help:    "Show or change the system prompt of the session",
+		Run:     systemCommand(h),
+		RawArgs: true,
+	})
+	c.Register(Command{
+		Name:    "/quit",
+		Aliases: []string{"/exit"},
+		Help:    "Exit the session",
+		Run: func(ctx context.Context, args []string) error {
+			return ErrQuit
+		},
+	})
+	c.RegisterPluginCommands(Plugins())
+	return c
+}
+
+// ParseCompactArgs parses the optional number of turns /compact keeps.
+func ParseCompactArgs(args []string) (int, error) {
+	if len(args) == 0 {
+		return llm.DefaultCompactKeepTurns, nil
+	}
+	keepTurns, err := strconv.Atoi(args[0])
+	if err != nil || keepTurns < 1 {
+		return 0, fmt.Errorf("usage: /compact [turns to keep]")
+	}
+	return keepTurns, nil
+}
@@ -0,0 +1,54 @@
+package features
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// clearCommand handles /clear [summary]: it starts a fresh context window
+// for the session. Stored messages stay in the database, marked as
+// compacted; with "summary" a summary of them is kept in the new context.
+func clearCommand(h CommandHandlers) CommandFunc {
+	if h.Context == nil || h.ReplaceContext == nil || h.Provider == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		summarize := false
+		switch {
+		case len(args) == 0:
+		case len(args) == 1 && args[0] == "summary":
+			summarize = true
+		default:
+			return fmt.Errorf("usage: /clear [summary]")
+		}
+		if h.AutoplayStatus != nil && h.AutoplayStatus().Enabled {
+			return fmt.Errorf("stop autoplay before clearing the context")
+		}
+
+		history := h.Context()
+		if summarize {
+			h.Print([]string{"Summarizing the conversation..."})
+		}
+		prov, _ := h.Provider()
+		cleared, err := llm.ClearHistory(ctx, llm.ClearOptions{
+			Provider:  prov,
+			History:   history,
+			Summarize: summarize,
+		})
+		if err != nil {
+			return err
+		}
+		if err := h.ReplaceContext(cleared); err != nil {
+			return err
+		}
+
+		line := fmt.Sprintf("Context cleared: %d messages set aside (still stored)", len(history)-len(cleared))
+		if summarize {
+			line = fmt.Sprintf("Context cleared: %d messages replaced by a summary (still stored)", len(history)-len(cleared)+1)
+		}
+		h.Print([]string{line})
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+const (
+	// historyDefaultCount is the number of messages /history shows by default.
+	historyDefaultCount = 20
+	// historyToolResultLen is the length tool results are truncated to.
+	historyToolResultLen = 200
+)
+
+// historyCommand handles /history [n], printing the last n stored messages.
+func historyCommand(h CommandHandlers) CommandFunc {
+	if h.History == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		count := historyDefaultCount
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || len(args) > 1 {
+				return fmt.Errorf("usage: /history [count]")
+			}
+			count = n
+		}
+
+		messages, err := h.History()
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			h.Print([]string{"No messages stored for this session"})
+			return nil
+		}
+		h.Print(HistoryLines(messages, count))
+		return nil
+	}
+}
+
+// HistoryLines formats the last count messages for display, with roles,
+// timestamps and truncated tool results.
+func HistoryLines(messages []provider.Message, count int) []string {
+	// Tool result messages only carry the call ID
+	toolNames := make(map[string]string)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+		}
+	}
+
+	start := max(len(messages)-count, 0)
+	lines := []string{fmt.Sprintf("Last %d of %d messages:", len(messages)-start, len(messages))}
+	for _, msg := range messages[start:] {
+		stamp := "                "
+		if !msg.CreatedAt.IsZero() {
+			stamp = msg.CreatedAt.Format("2006-01-02 15:04")
+		}
+
+		role := msg.Role
+		content := msg.Content
+		if msg.Role == "tool" {
+			if name := toolNames[msg.ToolCallID]; name != "" {
+				role = "tool " + name
+			}
+			content = truncateResult(content, historyToolResultLen)
+		}
+
+		prefix := fmt.Sprintf("[%s] %s: ", stamp, role)
+		indent := strings.Repeat(" ", len(prefix))
+		for i, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+			if i == 0 {
+				lines = append(lines, prefix+line)
+			} else {
+				lines = append(lines, indent+line)
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			lines = append(lines, fmt.Sprintf("%s-> %s(%s)", indent, tc.Name, string(tc.Arguments)))
+		}
+	}
+	return lines
+}
+
+// truncateResult flattens a tool result to one line of at most n runes.
+func truncateResult(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + fmt.Sprintf("... (%d more chars)", len(runes)-n)
+}
@@ -0,0 +1,103 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// providerCommand handles /provider [name [model]]: without arguments it
+// shows the current and configured providers, otherwise it switches.
+func providerCommand(cfg *config.Config, h CommandHandlers) CommandFunc {
+	if h.Provider == nil || h.SwitchProvider == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		current, model := h.Provider()
+		switch len(args) {
+		case 0:
+			names := make([]string, 0, len(cfg.Providers))
+			for name := range cfg.Providers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			lines := []string{fmt.Sprintf("Provider: %s (%s)", current.Name(), model), "Configured:"}
+			for _, name := range names {
+				marker := "  "
+				if name == current.Name() {
+					marker = "* "
+				}
+				lines = append(lines, fmt.Sprintf("%s%s (%s)", marker, name, cfg.Providers[name].Model))
+			}
+			h.Print(lines)
+			return nil
+		case 1, 2:
+			if _, ok := cfg.Providers[args[0]]; !ok {
+				return fmt.Errorf("provider '%s' not found in config", args[0])
+			}
+			newModel := ""
+			if len(args) == 2 {
+				newModel = args[1]
+			}
+			return switchAndReport(ctx, h, args[0], newModel)
+		default:
+			return fmt.Errorf("usage: /provider [name [model]]")
+		}
+	}
+}
+
+// modelCommand handles /model [name]: without arguments it shows the
+// current model and the models the provider serves, otherwise it switches
+// the model of the current provider.
+func modelCommand(h CommandHandlers) CommandFunc {
+	if h.Provider == nil || h.SwitchProvider == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		current, model := h.Provider()
+		switch len(args) {
+		case 0:
+			lines := []string{fmt.Sprintf("Model: %s (%s)", model, current.Name())}
+			if lister, ok := current.(provider.ModelLister); ok {
+				listCtx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+				models, err := lister.ListModels(listCtx)
+				cancel()
+				if err != nil {
+					lines = append(lines, "Could not list models: "+err.Error())
+				} else {
+					lines = append(lines, "Available:")
+					for _, m := range models {
+						marker := "  "
+						if m == model {
+							marker = "* "
+						}
+						lines = append(lines, marker+m)
+					}
+				}
+			}
+			h.Print(lines)
+			return nil
+		case 1:
+			return switchAndReport(ctx, h, current.Name(), args[0])
+		default:
+			return fmt.Errorf("usage: /model [name]")
+		}
+	}
+}
+
+// switchAndReport switches provider and prints the result.
+func switchAndReport(ctx context.Context, h CommandHandlers, name, model string) error {
+	from, fromModel := h.Provider()
+	fromName := from.Name()
+	if err := h.SwitchProvider(ctx, name, model); err != nil {
+		return err
+	}
+	to, toModel := h.Provider()
+	h.Print([]string{fmt.Sprintf("Switched from %s (%s) to %s (%s)", fromName, fromModel, to.Name(), toModel)})
+	return nil
+}
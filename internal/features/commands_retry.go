@@ -0,0 +1,126 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+const retryUsage = "usage: /retry [provider] [temperature]"
+
+// retryCommand handles /retry [provider] [temperature]: it drops the
+// messages after the last user message from the context (they stay stored)
+// and runs the turn again. A provider or temperature applies to the retried
+// turn only.
+func retryCommand(cfg *config.Config, h CommandHandlers) CommandFunc {
+	if h.Context == nil || h.ReplaceContext == nil || h.Provider == nil || h.RunTurn == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		name, temperature, err := parseRetryArgs(args)
+		if err != nil {
+			return err
+		}
+		if h.AutoplayStatus != nil && h.AutoplayStatus().Enabled {
+			return fmt.Errorf("stop autoplay before retrying a turn")
+		}
+
+		history := h.Context()
+		retry, err := RetryContext(history)
+		if err != nil {
+			return err
+		}
+
+		prov, model := h.Provider()
+		temporary := name != "" || temperature != nil
+		if temporary {
+			if prov, model, err = retryProvider(cfg, h, prov, model, name, temperature); err != nil {
+				return err
+			}
+		}
+
+		if err := h.ReplaceContext(retry); err != nil {
+			if temporary {
+				_ = prov.Close()
+			}
+			return err
+		}
+		line := fmt.Sprintf("Retrying with %s (%s)", prov.Name(), model)
+		if temperature != nil {
+			line += fmt.Sprintf(", temperature %v", *temperature)
+		}
+		if dropped := len(history) - len(retry); dropped > 0 {
+			line += fmt.Sprintf(" - %d messages set aside (still stored)", dropped)
+		}
+		h.Print([]string{line})
+		return h.RunTurn(ctx, prov)
+	}
+}
+
+// parseRetryArgs returns the provider name and temperature given to
+// /retry. Numbers are temperatures, anything else a provider.
+func parseRetryArgs(args []string) (string, *float64, error) {
+	if len(args) > 2 {
+		return "", nil, errors.New(retryUsage)
+	}
+	var name string
+	var temperature *float64
+	for _, arg := range args {
+		if t, err := strconv.ParseFloat(arg, 64); err == nil {
+			if temperature != nil || t < 0 || t > 2 {
+				return "", nil, fmt.Errorf("%s (temperature between 0.0 and 2.0)", retryUsage)
+			}
+			temperature = &t
+			continue
+		}
+		if name != "" {
+			return "", nil, errors.New(retryUsage)
+		}
+		name = arg
+	}
+	return name, temperature, nil
+}
+
+// RetryContext returns history up to and including the last user message,
+// the context a turn is retried from.
+func RetryContext(history []provider.Message) ([]provider.Message, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			return history[:i+1], nil
+		}
+	}
+	return nil, errors.New("nothing to retry: no user message in the context")
+}
+
+// retryProvider creates the provider of a retried turn: the named provider
+// (or the current one) with the current model when the provider is
+// unchanged, and the configured sampling options with temperature applied.
+func retryProvider(cfg *config.Config, h CommandHandlers, current provider.Provider, model, name string, temperature *float64) (provider.Provider, string, error) {
+	if h.Registry == nil {
+		return nil, "", errors.New("retrying with other settings is not supported here")
+	}
+	if name == "" {
+		name = current.Name()
+	}
+	provCfg, ok := cfg.Providers[name]
+	if !ok {
+		return nil, "", fmt.Errorf("provider '%s' not found in config", name)
+	}
+	if name != current.Name() {
+		model = provCfg.Model
+	}
+
+	opts := ProviderOptions(provCfg)
+	if temperature != nil {
+		opts.Temperature = *temperature
+	}
+	prov, err := h.Registry().Create(name, model, opts)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create provider: %w", err)
+	}
+	return prov, model, nil
+}
@@ -0,0 +1,64 @@
+package features
+
+import (
+	"context"
+	"fmt"
+)
+
+// saveCommand handles /save [path], writing the stored session history as
+// a Markdown transcript, by default into the data dir.
+func saveCommand(h CommandHandlers) CommandFunc {
+	if h.History == nil || h.SessionInfo == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("usage: /save [path]")
+		}
+
+		messages, err := h.History()
+		if err != nil {
+			return err
+		}
+
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if path, err = ExportTranscript(h.SessionInfo(), messages, TranscriptMarkdown, path); err != nil {
+			return err
+		}
+		h.Print([]string{fmt.Sprintf("Saved %d messages to %s", len(messages), path)})
+		return nil
+	}
+}
+
+// shareCommand handles /share [path], writing the stored session history
+// as a shareable transcript with secrets redacted: a web page, by default
+// into the data dir, or JSON for a path not ending in .html.
+func shareCommand(h CommandHandlers) CommandFunc {
+	if h.History == nil || h.SessionInfo == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("usage: /share [path]")
+		}
+
+		messages, err := h.History()
+		if err != nil {
+			return err
+		}
+
+		format, path := TranscriptHTML, ""
+		if len(args) == 1 {
+			path = args[0]
+			format = ShareFormatFor(path)
+		}
+		if path, err = ExportTranscript(h.SessionInfo(), messages, format, path); err != nil {
+			return err
+		}
+		h.Print([]string{"Shareable transcript saved to " + path + " (credentials and tokens redacted - review before publishing)"})
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package features
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// statsCommand handles /stats, printing session and run metrics.
+func statsCommand(h CommandHandlers) CommandFunc {
+	if h.Stats == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("usage: /stats")
+		}
+
+		stored, inContext := -1, -1
+		if h.History != nil {
+			messages, err := h.History()
+			if err != nil {
+				return err
+			}
+			stored = len(messages)
+		}
+		if h.Context != nil {
+			inContext = len(h.Context())
+		}
+		var autoplay AutoplayStatus
+		if h.AutoplayStatus != nil {
+			autoplay = h.AutoplayStatus()
+		}
+		h.Print(StatsLines(stored, inContext, h.Stats(), autoplay))
+		return nil
+	}
+}
+
+// StatsLines formats the /stats output. stored and inContext are message
+// counts, negative when unknown.
+func StatsLines(stored, inContext int, stats llm.StatsSnapshot, autoplay AutoplayStatus) []string {
+	var lines []string
+	switch {
+	case stored >= 0 && inContext >= 0:
+		lines = append(lines, fmt.Sprintf("Messages: %d stored, %d in context", stored, inContext))
+	case stored >= 0:
+		lines = append(lines, fmt.Sprintf("Messages: %d stored", stored))
+	}
+	lines = append(lines, stats.Lines()...)
+
+	line := fmt.Sprintf("Autoplay turns: %d", autoplay.TotalTurns)
+	if autoplay.Enabled {
+		line += fmt.Sprintf(" (%d in the current run)", autoplay.Turns)
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,50 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const systemUsage = "usage: /system [show] | set <prompt> | append <text>"
+
+// systemCommand handles /system show|set|append, which inspects and edits
+// the session system prompt. Changes apply from the next turn and are kept
+// for the session; a prompt file given with -f takes precedence on resume.
+func systemCommand(h CommandHandlers) CommandFunc {
+	if h.Context == nil || h.SetSystemPrompt == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		var action, text string
+		if len(args) > 0 {
+			action, text, _ = strings.Cut(args[0], " ")
+			text = strings.TrimSpace(text)
+		}
+		current, ok := SystemPrompt(h.Context())
+
+		switch {
+		case action == "" || action == "show" && text == "":
+			if !ok {
+				h.Print([]string{"No system prompt set - use /system set <prompt>"})
+				return nil
+			}
+			h.Print(append([]string{fmt.Sprintf("System prompt (%d chars):", len(current))}, strings.Split(current, "\n")...))
+			return nil
+		case action == "set" && text != "":
+		case action == "append" && text != "":
+			if ok {
+				text = current + "\n\n" + text
+			}
+		default:
+			return errors.New(systemUsage)
+		}
+
+		if err := h.SetSystemPrompt(text); err != nil {
+			return err
+		}
+		h.Print([]string{fmt.Sprintf("System prompt updated (%d chars), used from the next turn", len(text))})
+		return nil
+	}
+}
@@ -0,0 +1,381 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestCommandsRun(t *testing.T) {
+	var printed []string
+	var gotArgs []string
+	c := NewSessionCommands(&config.Config{}, CommandHandlers{
+		Print: func(lines []string) { printed = append(printed, lines...) },
+		Compact: func(ctx context.Context, args []string) error {
+			gotArgs = args
+			return nil
+		},
+	})
+
+	if err := c.Run(context.Background(), "/compact  5"); err != nil {
+		t.Fatalf("/compact: %v", err)
+	}
+	if strings.Join(gotArgs, " ") != "5" {
+		t.Errorf("args = %q, want [5]", gotArgs)
+	}
+
+	if err := c.Run(context.Background(), "/exit"); !errors.Is(err, ErrQuit) {
+		t.Errorf("/exit: got %v, want ErrQuit", err)
+	}
+
+	if err := c.Run(context.Background(), "/stats"); err == nil {
+		t.Error("/stats without a handler should be unknown")
+	}
+
+	if err := c.Run(context.Background(), "/help"); err != nil {
+		t.Fatalf("/help: %v", err)
+	}
+	help := strings.Join(printed, "\n")
+	for _, want := range []string{"/compact [turns]", "/help", "/quit, /exit"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help missing %q:\n%s", want, help)
+		}
+	}
+	if strings.Contains(help, "/stats") || strings.Contains(help, "/autoplay") {
+		t.Errorf("help lists commands without handlers:\n%s", help)
+	}
+}
+
+func TestParseCompactArgs(t *testing.T) {
+	if n, err := ParseCompactArgs(nil); err != nil || n <= 0 {
+		t.Errorf("default: got %d, %v", n, err)
+	}
+	if n, err := ParseCompactArgs([]string{"4"}); err != nil || n != 4 {
+		t.Errorf("4: got %d, %v", n, err)
+	}
+	if _, err := ParseCompactArgs([]string{"0"}); err == nil {
+		t.Error("0 should be rejected")
+	}
+}
+
+func TestProviderCommands(t *testing.T) {
+	cfg := &config.Config{Providers: map[string]config.ProviderConfig{
+		"local": {Model: "small"},
+		"cloud": {Model: "big"},
+	}}
+	registry := provider.NewRegistry()
+	registry.RegisterFactory("local", provider.NewMockFactory("local", "ok"))
+	registry.RegisterFactory("cloud", provider.NewMockFactory("cloud", "ok"))
+
+	var current provider.Provider = provider.NewMock("local", "ok")
+	model := "small"
+	var printed []string
+	c := NewSessionCommands(cfg, CommandHandlers{
+		Print:    func(lines []string) { printed = append(printed, lines...) },
+		Provider: func() (provider.Provider, string) { return current, model },
+		SwitchProvider: func(ctx context.Context, name, m string) error {
+			prov, m, err := SwitchProvider(ctx, cfg, registry, name, m)
+			if err != nil {
+				return err
+			}
+			current, model = prov, m
+			return nil
+		},
+	})
+	ctx := context.Background()
+
+	if err := c.Run(ctx, "/provider cloud"); err != nil {
+		t.Fatalf("/provider cloud: %v", err)
+	}
+	if current.Name() != "cloud" || model != "big" {
+		t.Errorf("after /provider cloud: %s (%s)", current.Name(), model)
+	}
+
+	if err := c.Run(ctx, "/model huge"); err != nil {
+		t.Fatalf("/model huge: %v", err)
+	}
+	if current.Name() != "cloud" || model != "huge" {
+		t.Errorf("after /model huge: %s (%s)", current.Name(), model)
+	}
+
+	if err := c.Run(ctx, "/provider nope"); err == nil {
+		t.Error("/provider nope should fail")
+	}
+
+	printed = nil
+	if err := c.Run(ctx, "/provider"); err != nil {
+		t.Fatalf("/provider: %v", err)
+	}
+	if got := strings.Join(printed, "\n"); !strings.Contains(got, "* cloud (big)") || !strings.Contains(got, "  local (small)") {
+		t.Errorf("/provider output:\n%s", got)
+	}
+}
+
+func TestHistoryLines(t *testing.T) {
+	at := time.Date(2026, 5, 1, 14, 3, 0, 0, time.UTC)
+	messages := []provider.Message{
+		{Role: "user", Content: "old", CreatedAt: at},
+		{Role: "user", Content: "mine please", CreatedAt: at},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "c1", Name: "mine", Arguments: []byte(`{}`)}}, CreatedAt: at},
+		{Role: "tool", ToolCallID: "c1", Content: strings.Repeat("ore ", 100), CreatedAt: at},
+	}
+
+	got := strings.Join(HistoryLines(messages, 3), "\n")
+	for _, want := range []string{
+		"Last 3 of 4 messages:",
+		"[2026-05-01 14:03] user: mine please",
+		"-> mine({})",
+		"[2026-05-01 14:03] tool mine: ore ore",
+		"(199 more chars)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "old") {
+		t.Errorf("older messages should be skipped:\n%s", got)
+	}
+}
+
+func TestClearCommand(t *testing.T) {
+	current := []provider.Message{
+		{Role: "system", Content: "You play SpaceMolt"},
+		{Role: "user", Content: "Mine ore"},
+		{Role: "assistant", Content: "Cargo is full"},
+	}
+	autoplay := true
+	c := NewSessionCommands(&config.Config{}, CommandHandlers{
+		Print:    func(lines []string) {},
+		Provider: func() (provider.Provider, string) { return provider.NewMock("mock", "Cargo full."), "m" },
+		Context:  func() []provider.Message { return current },
+		ReplaceContext: func(messages []provider.Message) error {
+			current = messages
+			return nil
+		},
+		AutoplayStatus: func() AutoplayStatus { return AutoplayStatus{Enabled: autoplay} },
+	})
+
+	if err := c.Run(t.Context(), "/clear"); err == nil {
+		t.Error("/clear during autoplay should fail")
+	}
+	autoplay = false
+
+	if err := c.Run(t.Context(), "/clear summary"); err != nil {
+		t.Fatalf("/clear summary: %v", err)
+	}
+	if len(current) != 2 || current[0].Content != "You play SpaceMolt" || !strings.HasSuffix(current[1].Content, "Cargo full.") {
+		t.Errorf("context after /clear summary = %v", current)
+	}
+}
+
+func TestRetryCommand(t *testing.T) {
+	cfg := &config.Config{Providers: map[string]config.ProviderConfig{
+		"local": {Model: "small"},
+		"cloud": {Model: "big"},
+	}}
+	registry := provider.NewRegistry()
+	registry.RegisterFactory("cloud", provider.NewMockFactory("cloud", "ok"))
+
+	session := provider.NewMock("local", "ok")
+	current := []provider.Message{
+		{Role: "user", Content: "Mine ore"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "1", Name: "mine"}}},
+		{Role: "tool", Content: "Cargo full", ToolCallID: "1"},
+		{Role: "assistant", Content: "Done"},
+	}
+	var ran provider.Provider
+	c := NewSessionCommands(cfg, CommandHandlers{
+		Print:    func(lines []string) {},
+		Provider: func() (provider.Provider, string) { return session, "small" },
+		Context:  func() []provider.Message { return current },
+		ReplaceContext: func(messages []provider.Message) error {
+			current = messages
+			return nil
+		},
+		Registry: func() *provider.Registry { return registry },
+		RunTurn: func(ctx context.Context, prov provider.Provider) error {
+			ran = prov
+			return nil
+		},
+	})
+
+	if err := c.Run(t.Context(), "/retry"); err != nil {
+		t.Fatalf("/retry: %v", err)
+	}
+	if len(current) != 1 || current[0].Content != "Mine ore" || ran != session {
+		t.Errorf("after /retry: context %v, provider %v", current, ran)
+	}
+
+	if err := c.Run(t.Context(), "/retry cloud 0.9"); err != nil {
+		t.Fatalf("/retry cloud 0.9: %v", err)
+	}
+	if ran == session || ran.Name() != "cloud" {
+		t.Errorf("/retry cloud ran on %s", ran.Name())
+	}
+
+	for _, input := range []string{"/retry 3", "/retry 0.5 0.7", "/retry nope"} {
+		if err := c.Run(t.Context(), input); err == nil {
+			t.Errorf("%s should fail", input)
+		}
+	}
+
+	current = []provider.Message{{Role: "system", Content: "You play SpaceMolt"}}
+	if err := c.Run(t.Context(), "/retry"); err == nil {
+		t.Error("/retry without a user message should fail")
+	}
+}
+
+func TestSystemCommand(t *testing.T) {
+	current := []provider.Message{
+		{Role: "system", Content: llm.CompactSummaryPrefix + " earlier turns"},
+		{Role: "user", Content: "Mine ore"},
+	}
+	var printed []string
+	c := NewSessionCommands(&config.Config{}, CommandHandlers{
+		Print:   func(lines []string) { printed = append(printed, lines...) },
+		Context: func() []provider.Message { return current },
+		SetSystemPrompt: func(prompt string) error {
+			current = SetSystemPrompt(current, prompt)
+			return nil
+		},
+	})
+
+	if err := c.Run(t.Context(), "/system"); err != nil || !strings.Contains(printed[0], "No system prompt") {
+		t.Errorf("/system without a prompt: %q, %v", printed, err)
+	}
+	if err := c.Run(t.Context(), "/system set You are a miner."); err != nil {
+		t.Fatalf("/system set: %v", err)
+	}
+	if err := c.Run(t.Context(), "/system append Never sell ore."); err != nil {
+		t.Fatalf("/system append: %v", err)
+	}
+	if len(current) != 3 || current[0].Content != "You are a miner.\n\nNever sell ore." {
+		t.Errorf("context after set and append: %+v", current)
+	}
+
+	if err := c.Run(t.Context(), "/system set Trade instead."); err != nil {
+		t.Fatalf("/system set: %v", err)
+	}
+	if prompt, _ := SystemPrompt(current); len(current) != 3 || prompt != "Trade instead." {
+		t.Errorf("second /system set: %+v", current)
+	}
+	for _, input := range []string{"/system set", "/system edit x", "/system show x"} {
+		if err := c.Run(t.Context(), input); err == nil {
+			t.Errorf("%s should fail", input)
+		}
+	}
+}
+
+func TestSaveCommand(t *testing.T) {
+	var printed []string
+	c := NewSessionCommands(&config.Config{}, CommandHandlers{
+		Print: func(lines []string) { printed = append(printed, lines...) },
+		History: func() ([]provider.Message, error) {
+			return []provider.Message{{Role: "user", Content: "Mine ore"}}, nil
+		},
+		SessionInfo: func() TranscriptInfo {
+			return TranscriptInfo{Session: "miner", Provider: "local", Model: "small"}
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "out", "miner.md")
+	if err := c.Run(t.Context(), "/save "+path); err != nil {
+		t.Fatalf("/save: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# Mysis transcript: miner") || !strings.Contains(string(data), "Mine ore") {
+		t.Errorf("transcript:\n%s", data)
+	}
+	if len(printed) != 1 || !strings.Contains(printed[0], "Saved 1 messages") {
+		t.Errorf("output = %q", printed)
+	}
+}
+
+func TestToolLines(t *testing.T) {
+	lines := ToolLines([]mcp.ToolInfo{
+		{Tool: mcp.Tool{Name: "captains_log_add", Description: "Add a log entry\nDetails"}, Local: true, Allowed: true},
+		{Tool: mcp.Tool{Name: "sell", Description: "Sell cargo"}, Allowed: false},
+	})
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"2 tools (1 local, 1 upstream), 1 blocked",
+		"[local]",
+		"Add a log entry",
+		"[upstream, blocked]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Details") {
+		t.Errorf("descriptions should be cut to one line:\n%s", got)
+	}
+}
+
+func TestStatsLines(t *testing.T) {
+	stats := llm.NewStats()
+	stats.RecordUsage(llm.Usage{InputTokens: 900, OutputTokens: 100, Cost: 0.5})
+	stats.RecordCompression(300)
+	stats.RecordToolCall("mine", false)
+	stats.RecordToolCall("mine", false)
+	stats.RecordToolCall("sell", true)
+
+	lines := StatsLines(12, 5, stats.Snapshot(), AutoplayStatus{Enabled: true, Turns: 2, TotalTurns: 7})
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"Messages: 12 stored, 5 in context",
+		"~1000 tokens (900 in, 100 out), ~$0.5000",
+		"Compression saved: ~300 tokens",
+		"Tool calls: 3 (mine 2, sell 1), 1 failed",
+		"Autoplay turns: 7 (2 in the current run)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestToolCommand(t *testing.T) {
+	proxy := mcp.NewProxy(nil)
+	var gotArgs string
+	proxy.RegisterTool(mcp.Tool{Name: "captains_log_add"}, func(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+		gotArgs = string(arguments)
+		return &mcp.ToolResult{Content: []mcp.ContentBlock{{Type: "text", Text: `{"ok":true}`}}}, nil
+	})
+
+	var printed []string
+	c := NewSessionCommands(&config.Config{}, CommandHandlers{
+		Print: func(lines []string) { printed = append(printed, lines...) },
+		Proxy: func() *mcp.Proxy { return proxy },
+	})
+
+	if err := c.Run(t.Context(), `/tool captains_log_add {"entry": "two  spaces"}`); err != nil {
+		t.Fatalf("/tool: %v", err)
+	}
+	if gotArgs != `{"entry":"two  spaces"}` {
+		t.Errorf("arguments = %s", gotArgs)
+	}
+	if got := strings.Join(printed, "\n"); !strings.Contains(got, ": ok") || !strings.Contains(got, `"ok": true`) {
+		t.Errorf("output:\n%s", got)
+	}
+
+	if err := c.Run(t.Context(), "/tool captains_log_add [1]"); err == nil {
+		t.Error("non-object arguments should fail")
+	}
+	if err := c.Run(t.Context(), "/tool"); err == nil {
+		t.Error("/tool without a name should fail")
+	}
+}
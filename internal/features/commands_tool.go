@@ -0,0 +1,69 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// toolCommand handles /tool <name> [json-args], calling an MCP tool
+// through the proxy without involving the LLM. The call is not added to
+// the conversation.
+func toolCommand(h CommandHandlers) CommandFunc {
+	if h.Proxy == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: /tool <name> [json-args]")
+		}
+		name, rawArgs, _ := strings.Cut(args[0], " ")
+		arguments, err := ParseToolArgs(rawArgs)
+		if err != nil {
+			return err
+		}
+
+		result, err := h.Proxy().CallTool(ctx, name, arguments)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", name, err)
+		}
+
+		var text strings.Builder
+		for _, block := range result.Content {
+			if block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		output := text.String()
+		var indented bytes.Buffer
+		if json.Indent(&indented, []byte(output), "", "  ") == nil {
+			output = indented.String()
+		}
+
+		status := "ok"
+		if result.IsError {
+			status = "error"
+		}
+		h.Print(append([]string{fmt.Sprintf("%s(%s): %s", name, string(arguments), status)}, strings.Split(output, "\n")...))
+		return nil
+	}
+}
+
+// ParseToolArgs parses the JSON arguments of /tool; empty means {}.
+func ParseToolArgs(raw string) (json.RawMessage, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return json.RawMessage(`{}`), nil
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return nil, fmt.Errorf("tool arguments must be a JSON object: %w", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(raw)); err != nil {
+		return nil, err
+	}
+	return compact.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/mcp"
+)
+
+// toolDescriptionLen is the length tool descriptions are cut to in /tools.
+const toolDescriptionLen = 70
+
+// toolsCommand handles /tools [filter], listing the MCP tools with where
+// they are handled and whether the tool filter blocks them.
+func toolsCommand(h CommandHandlers) CommandFunc {
+	if h.Proxy == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("usage: /tools [filter]")
+		}
+		infos, err := h.Proxy().ToolCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			var matched []mcp.ToolInfo
+			for _, info := range infos {
+				if strings.Contains(info.Name, args[0]) {
+					matched = append(matched, info)
+				}
+			}
+			infos = matched
+		}
+		h.Print(ToolLines(infos))
+		return nil
+	}
+}
+
+// ToolLines formats a tool catalog for display.
+func ToolLines(infos []mcp.ToolInfo) []string {
+	local, blocked, width := 0, 0, 0
+	for _, info := range infos {
+		if info.Local {
+			local++
+		}
+		if !info.Allowed {
+			blocked++
+		}
+		width = max(width, len(info.Name))
+	}
+
+	summary := fmt.Sprintf("%d tools (%d local, %d upstream)", len(infos), local, len(infos)-local)
+	if blocked > 0 {
+		summary += fmt.Sprintf(", %d blocked by the tool allowlist", blocked)
+	}
+	lines := []string{summary}
+
+	for _, info := range infos {
+		source := "upstream"
+		if info.Local {
+			source = "local"
+		}
+		if !info.Allowed {
+			source += ", blocked"
+		}
+		lines = append(lines, fmt.Sprintf("  %-*s  %-18s %s", width, info.Name, "["+source+"]", firstLine(info.Description, toolDescriptionLen)))
+	}
+	return lines
+}
+
+// firstLine returns the first line of s, cut to n runes.
+func firstLine(s string, n int) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package features
+
+import (
+	"context"
+	"fmt"
+)
+
+// undoCommand handles /undo: it deletes the last user message and the
+// response to it from the context and the store, to revert a bad
+// instruction. Unlike /retry and /clear nothing stays stored.
+func undoCommand(h CommandHandlers) CommandFunc {
+	if h.Undo == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("usage: /undo")
+		}
+		if h.AutoplayStatus != nil && h.AutoplayStatus().Enabled {
+			return fmt.Errorf("stop autoplay before undoing an exchange")
+		}
+
+		var instruction string
+		if h.Context != nil {
+			history := h.Context()
+			for i := len(history) - 1; i >= 0; i-- {
+				if history[i].Role == "user" {
+					instruction = firstLine(history[i].Content, 60)
+					break
+				}
+			}
+		}
+
+		removed, err := h.Undo()
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			h.Print([]string{"Nothing to undo"})
+			return nil
+		}
+		line := fmt.Sprintf("Undid the last exchange (%d messages deleted)", removed)
+		if instruction != "" {
+			line += ": " + instruction
+		}
+		h.Print([]string{line})
+		return nil
+	}
+}
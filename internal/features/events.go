@@ -0,0 +1,36 @@
+package features
+
+import (
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// sessionsDir is the data dir subdirectory for per-session files.
+const sessionsDir = "sessions"
+
+// EventLogPath returns the events.jsonl of a session in the data dir, see
+// llm.EventLog.
+func EventLogPath(sessionID string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionsDir, sessionFileName(sessionID), "events.jsonl"), nil
+}
+
+// OpenEventLog opens the event log of a session for appending. It returns
+// nil, recording nothing, when the log cannot be opened.
+func OpenEventLog(sessionID string) *llm.EventLog {
+	path, err := EventLogPath(sessionID)
+	if err == nil {
+		var events *llm.EventLog
+		if events, err = llm.OpenEventLog(path, sessionID); err == nil {
+			return events
+		}
+	}
+	log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to open event log - continuing without it")
+	return nil
+}
@@ -0,0 +1,48 @@
+package features
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// failover switches to the backup provider when the breaker trips because
+// the provider, not the game, keeps failing. It fails over at most once per
+// run and reports whether autoplay should continue on the backup provider.
+func (s *Service) failover(ctx context.Context) bool {
+	s.mu.Lock()
+	backup := s.breaker.Failover
+	eligible := backup != "" && s.failedOver == "" && s.providerErrors >= s.breaker.Threshold
+	message := s.message
+	s.mu.Unlock()
+
+	if !eligible || s.callbacks.OnFailover == nil {
+		return false
+	}
+
+	if err := s.callbacks.OnFailover(ctx, backup); err != nil {
+		log.Error().Err(err).Str("provider", backup).Msg("Provider failover failed")
+		return false
+	}
+
+	s.mu.Lock()
+	s.failedOver = backup
+	s.consecutiveErrors = 0
+	s.providerErrors = 0
+	s.mu.Unlock()
+
+	log.Warn().Str("provider", backup).Msg("Provider kept failing - autoplay failed over to backup provider")
+	s.webhooks.notify(WebhookPayload{
+		Event:    config.WebhookEventFailover,
+		Message:  message,
+		Provider: backup,
+	})
+	return true
+}
+
+// FailoverNote is the history entry recording a provider switch, so the
+// transcript shows where the backup provider took over.
+func FailoverNote(from, to, model string) string {
+	return "Autoplay switched provider from " + from + " to " + to + " (" + model + ") after repeated provider errors."
+}
@@ -2,9 +2,12 @@ package features
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/provider"
 )
 
 // Flags holds parsed command-line flags.
@@ -12,14 +15,40 @@ type Flags struct {
 	ShowHelp      bool
 	ShowVersion   bool
 	ConfigPath    string
+	Profile       string // Config profile applied, see config.LoadProfile
 	Debug         bool
 	ProviderName  string
+	Model         string  // Overrides the provider's configured model
+	Temperature   float64 // Sampling overrides; negative means not set
+	TopP          float64
+	MaxTokens     int
 	SessionName   string
+	Continue      bool // Resume the most recently active named session
 	ListSessions  bool
 	DeleteSession string
+	Since         string // Session list and usage filter, see session.ParseSince
+	NameContains  string // Session list filter
+	Format        string // Session list and usage output: text or json
+	By            string // Usage grouping: session, day or provider, see UsageReport
+	Anonymous     bool   // Prune: only anonymous sessions
+	OlderThan     string // Prune: sessions inactive since, see session.ParseSince
+	DryRun        bool   // Prune: list without deleting
+	Yes           bool   // Prune: skip confirmation
 	Autoplay      string
+	Playbook      string
 	SystemFile    string
+	Template      string   // System prompt template name, see LoadTemplate
+	TemplateVars  []string // NAME=VALUE template variables, see ParseTemplateVar
 	TUI           bool
+	Split         string   // TUI: session shown beside the main one, see tui.StartSplit
+	NoColor       bool     // Plain ASCII output, see styles.SetPlain
+	ScreenReader  bool     // Plain output for screen readers, see styles.SetScreenReader
+	ToolResults   string   // full, truncated or hidden, see llm.ParseToolResultDisplay
+	Errors        string   // Error output on stderr: text or json
+	Message       string   // One-shot message: run a single turn and exit
+	Quiet         bool     // One-shot: print only the final response
+	Stdin         bool     // One-shot: read the message from stdin
+	Args          []string // Positional arguments, e.g. "fleet run"
 }
 
 // ParseFlags parses command-line flags and returns the result.
@@ -34,28 +63,69 @@ func ParseFlags() *Flags {
 	flag.BoolVar(&f.ShowVersion, "v", false, "Show version and exit (shorthand)")
 	flag.StringVar(&f.ConfigPath, "config", "", "Path to config file")
 	flag.StringVar(&f.ConfigPath, "c", "", "Path to config file (shorthand)")
+	flag.StringVar(&f.Profile, "profile", "", "Apply the named [profiles.NAME] of the config file")
 	flag.BoolVar(&f.Debug, "debug", false, "Enable debug logging")
 	flag.BoolVar(&f.Debug, "d", false, "Enable debug logging (shorthand)")
 	flag.StringVar(&f.ProviderName, "provider", "", "Provider name (overrides default from config)")
 	flag.StringVar(&f.ProviderName, "p", "", "Provider name (shorthand)")
+	flag.StringVar(&f.Model, "model", "", "Model name (overrides the provider's configured model)")
+	flag.StringVar(&f.Model, "M", "", "Model name (shorthand)")
+	flag.Float64Var(&f.Temperature, "temperature", -1, "Sampling temperature (overrides provider config)")
+	flag.Float64Var(&f.TopP, "top-p", -1, "Nucleus sampling top_p (overrides provider config)")
+	flag.IntVar(&f.MaxTokens, "max-tokens", -1, "Response token limit (overrides provider config)")
 	flag.StringVar(&f.SessionName, "session", "", "Session name (resume or create named session)")
 	flag.StringVar(&f.SessionName, "s", "", "Session name (shorthand)")
+	flag.BoolVar(&f.Continue, "continue", false, "Resume the most recently active named session")
+	flag.BoolVar(&f.Continue, "C", false, "Resume the most recent session (shorthand)")
 	flag.BoolVar(&f.ListSessions, "list-sessions", false, "List recent sessions and exit")
 	flag.BoolVar(&f.ListSessions, "l", false, "List recent sessions and exit (shorthand)")
+	flag.StringVar(&f.Since, "since", "", "List sessions active, or report usage, since a duration ago (24h, 7d) or a date")
+	flag.StringVar(&f.NameContains, "name-contains", "", "List sessions whose name contains text")
+	flag.StringVar(&f.Format, "format", "text", "Session list and usage output: text or json")
+	flag.StringVar(&f.By, "by", UsageBySession, "Group usage by session, day or provider")
+	flag.BoolVar(&f.Anonymous, "anonymous", false, "Prune only anonymous sessions")
+	flag.StringVar(&f.OlderThan, "older-than", "", "Prune sessions inactive for a duration (7d) or since a date")
+	flag.BoolVar(&f.DryRun, "dry-run", false, "List the sessions prune would delete without deleting")
+	flag.BoolVar(&f.Yes, "yes", false, "Prune without asking for confirmation")
 	flag.StringVar(&f.DeleteSession, "delete-session", "", "Delete a session by name")
 	flag.StringVar(&f.DeleteSession, "D", "", "Delete a session by name (shorthand)")
 	flag.StringVar(&f.Autoplay, "autoplay", "", "Start autoplay immediately with given message")
 	flag.StringVar(&f.Autoplay, "a", "", "Start autoplay immediately (shorthand)")
+	flag.StringVar(&f.Playbook, "playbook", "", "Start autoplay immediately with goals from a playbook file")
 	flag.StringVar(&f.SystemFile, "file", "", "Load system prompt from markdown file")
 	flag.StringVar(&f.SystemFile, "f", "", "Load system prompt from markdown file (shorthand)")
+	flag.StringVar(&f.Template, "template", "", "Load the named system prompt template from the templates directory")
+	flag.Var((*stringList)(&f.TemplateVars), "var", "Template variable NAME=VALUE (repeatable)")
 	flag.BoolVar(&f.TUI, "tui", false, "Use terminal UI mode instead of CLI")
 	flag.BoolVar(&f.TUI, "t", false, "Use terminal UI mode (shorthand)")
+	flag.StringVar(&f.Split, "split", "", "With --tui, show the named session beside the main one")
+	flag.BoolVar(&f.NoColor, "no-color", false, "Disable colors and box drawing")
+	flag.BoolVar(&f.ScreenReader, "screen-reader", false, "Plain TUI for screen readers: no animations, borders or icons")
+	flag.StringVar(&f.Errors, "errors", "text", "Error output on stderr: text or json")
+	flag.StringVar(&f.ToolResults, "show-tool-results", "truncated", "Tool result output: full, truncated or hidden")
+	flag.StringVar(&f.Message, "message", "", "Run a single turn with the given message and exit")
+	flag.StringVar(&f.Message, "m", "", "Run a single turn and exit (shorthand)")
+	flag.BoolVar(&f.Quiet, "quiet", false, "With --message, print only the final response")
+	flag.BoolVar(&f.Quiet, "q", false, "Print only the final response (shorthand)")
+	flag.BoolVar(&f.Stdin, "stdin", false, "Read a one-shot message from stdin")
 
 	// Disable default help behavior - caller will handle it
 	flag.Usage = func() {}
 
 	flag.Parse()
 
+	// Flags may also follow subcommands: `mysis session list -c my.toml`
+	args := flag.Args()
+	for len(args) > 0 {
+		if len(args[0]) > 1 && args[0][0] == '-' {
+			_ = flag.CommandLine.Parse(args)
+			args = flag.Args()
+			continue
+		}
+		f.Args = append(f.Args, args[0])
+		args = args[1:]
+	}
+
 	// Resolve config path if not specified
 	if f.ConfigPath == "" {
 		if _, err := os.Stat("config.toml"); err == nil {
@@ -70,3 +140,33 @@ func ParseFlags() *Flags {
 
 	return &f
 }
+
+// ApplySampling overrides the sampling options with the flags that were set.
+func (f *Flags) ApplySampling(opts provider.Options) (provider.Options, error) {
+	if f.Temperature >= 0 {
+		if f.Temperature > 2.0 {
+			return opts, fmt.Errorf("--temperature=%v must be between 0.0 and 2.0", f.Temperature)
+		}
+		opts.Temperature = f.Temperature
+	}
+	if f.TopP >= 0 {
+		if f.TopP > 1.0 {
+			return opts, fmt.Errorf("--top-p=%v must be between 0.0 and 1.0", f.TopP)
+		}
+		opts.TopP = f.TopP
+	}
+	if f.MaxTokens >= 0 {
+		opts.MaxTokens = f.MaxTokens
+	}
+	return opts, nil
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
@@ -0,0 +1,192 @@
+package features
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// GameState is the player's state as last reported by get_status and
+// get_ship, for display. Fields the game did not report are zero.
+type GameState struct {
+	Ship      string // Ship name and class
+	Location  string // System and point of interest
+	Credits   Gauge
+	Hull      Gauge
+	Shield    Gauge
+	Fuel      Gauge
+	Cargo     Gauge
+	CargoList []CargoItem
+	UpdatedAt time.Time
+}
+
+// Gauge is a reported value and its maximum, if any.
+type Gauge struct {
+	Value float64
+	Max   float64 // 0 when unknown
+	Known bool
+}
+
+// CargoItem is an item in the ship's hold.
+type CargoItem struct {
+	Name     string
+	Quantity float64
+}
+
+// gameStateTools are the tools whose results update a GameState.
+var gameStateTools = map[string]bool{"get_status": true, "get_ship": true}
+
+// Field names the game uses, tried in order. get_status nests the ship
+// under "ship"; get_ship may return it at the top level.
+var (
+	creditsPaths   = []string{"player.credits", "credits"}
+	systemPaths    = []string{"player.current_system", "current_system", "system"}
+	poiPaths       = []string{"player.current_poi", "current_poi", "poi", "player.docked_at_base"}
+	shipNamePaths  = []string{"name"}
+	shipClassPaths = []string{"class", "class_id"}
+	hullPaths      = []string{"hull", "health"}
+	hullMaxPaths   = []string{"max_hull", "max_health", "hull_max"}
+	shieldPaths    = []string{"shield", "shields"}
+	shieldMaxPaths = []string{"max_shield", "max_shields"}
+	fuelPaths      = []string{"fuel"}
+	fuelMaxPaths   = []string{"max_fuel", "fuel_capacity"}
+	cargoPaths     = []string{"cargo_used"}
+	cargoMaxPaths  = []string{"cargo_capacity", "max_cargo"}
+)
+
+// UpdateGameState applies a tool result to state. Returns false if the tool
+// does not report game state or its result is not a JSON object.
+func UpdateGameState(state *GameState, tool, result string, now time.Time) bool {
+	if !gameStateTools[tool] {
+		return false
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(result), &doc); err != nil {
+		return false
+	}
+
+	if v, ok := firstNumber(doc, creditsPaths); ok {
+		state.Credits = Gauge{Value: v, Known: true}
+	}
+	system, _ := firstString(doc, systemPaths)
+	poi, _ := firstString(doc, poiPaths)
+	if location := strings.Trim(system+" / "+poi, " /"); location != "" {
+		state.Location = location
+	}
+
+	ship := doc
+	if nested, ok := doc["ship"].(map[string]any); ok {
+		ship = nested
+	} else if tool != "get_ship" {
+		ship = nil
+	}
+	if ship != nil {
+		applyShip(state, ship)
+	}
+	state.UpdatedAt = now
+	return true
+}
+
+// applyShip reads the ship fields of a get_status or get_ship result.
+func applyShip(state *GameState, ship map[string]any) {
+	name, _ := firstString(ship, shipNamePaths)
+	class, _ := firstString(ship, shipClassPaths)
+	if class != "" {
+		name = strings.TrimSpace(name + " (" + class + ")")
+	}
+	if name != "" {
+		state.Ship = name
+	}
+
+	updateGauge(&state.Hull, ship, hullPaths, hullMaxPaths)
+	updateGauge(&state.Shield, ship, shieldPaths, shieldMaxPaths)
+	updateGauge(&state.Fuel, ship, fuelPaths, fuelMaxPaths)
+	updateGauge(&state.Cargo, ship, cargoPaths, cargoMaxPaths)
+
+	items, ok := ship["cargo"].([]any)
+	if !ok {
+		return
+	}
+	state.CargoList = nil // Copies of state may share the old list
+	total := 0.0
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := firstString(obj, []string{"name", "item_id", "id"})
+		quantity, ok := firstNumber(obj, []string{"quantity", "amount"})
+		if !ok {
+			quantity = 1
+		}
+		state.CargoList = append(state.CargoList, CargoItem{Name: name, Quantity: quantity})
+		total += quantity
+	}
+	if _, ok := firstNumber(ship, cargoPaths); !ok {
+		state.Cargo.Value = total
+		state.Cargo.Known = true
+	}
+}
+
+// updateGauge sets g from the first value and maximum paths found in doc,
+// keeping a known maximum the result does not repeat.
+func updateGauge(g *Gauge, doc map[string]any, valuePaths, maxPaths []string) {
+	v, ok := firstNumber(doc, valuePaths)
+	if !ok {
+		return
+	}
+	g.Value = v
+	g.Known = true
+	if m, ok := firstNumber(doc, maxPaths); ok {
+		g.Max = m
+	}
+}
+
+// firstNumber returns the number at the first of paths present in doc.
+func firstNumber(doc map[string]any, paths []string) (float64, bool) {
+	for _, path := range paths {
+		if v, ok := lookupNumber(doc, path); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// firstString returns the non-empty string at the first of paths present
+// in doc.
+func firstString(doc map[string]any, paths []string) (string, bool) {
+	for _, path := range paths {
+		var v any = doc
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				v = nil
+				break
+			}
+			v = obj[key]
+		}
+		if s, ok := v.(string); ok && s != "" {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// GameStateFromHistory replays the game state tool results of messages,
+// e.g. of a resumed session. Returns false if there were none.
+func GameStateFromHistory(messages []provider.Message) (GameState, bool) {
+	var state GameState
+	found := false
+	toolNames := make(map[string]string)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+		}
+		if msg.Role == "tool" && UpdateGameState(&state, toolNames[msg.ToolCallID], msg.Content, msg.CreatedAt) {
+			found = true
+		}
+	}
+	return state, found
+}
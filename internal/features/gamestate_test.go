@@ -0,0 +1,66 @@
+package features
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestUpdateGameState(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var state GameState
+
+	if UpdateGameState(&state, "mine", `{"ship": {"hull": 1}}`, now) {
+		t.Error("UpdateGameState() applied a result of another tool")
+	}
+	if UpdateGameState(&state, "get_status", "not json", now) {
+		t.Error("UpdateGameState() applied a result that is not JSON")
+	}
+
+	status := `{"player": {"credits": 1200, "current_system": "Sol", "current_poi": "Earth Station"},
+		"ship": {"name": "Rustbucket", "hull": 80, "max_hull": 100, "fuel": 30, "max_fuel": 50, "cargo_used": 5, "cargo_capacity": 40}}`
+	if !UpdateGameState(&state, "get_status", status, now) {
+		t.Fatal("UpdateGameState(get_status) = false")
+	}
+	if state.Credits.Value != 1200 || state.Location != "Sol / Earth Station" || state.Ship != "Rustbucket" {
+		t.Errorf("state = %+v", state)
+	}
+	if state.Hull != (Gauge{Value: 80, Max: 100, Known: true}) || state.Cargo != (Gauge{Value: 5, Max: 40, Known: true}) {
+		t.Errorf("hull = %+v, cargo = %+v", state.Hull, state.Cargo)
+	}
+
+	// get_ship returns the ship at the top level, without maximums
+	ship := `{"name": "Rustbucket", "class": "scout", "hull": 70, "cargo": [{"name": "Iron Ore", "quantity": 4}, {"item_id": "ice"}]}`
+	if !UpdateGameState(&state, "get_ship", ship, now) {
+		t.Fatal("UpdateGameState(get_ship) = false")
+	}
+	if state.Ship != "Rustbucket (scout)" || state.Hull.Value != 70 || state.Hull.Max != 100 {
+		t.Errorf("ship = %q, hull = %+v", state.Ship, state.Hull)
+	}
+	want := []CargoItem{{Name: "Iron Ore", Quantity: 4}, {Name: "ice", Quantity: 1}}
+	if len(state.CargoList) != 2 || state.CargoList[0] != want[0] || state.CargoList[1] != want[1] {
+		t.Errorf("CargoList = %+v, want %+v", state.CargoList, want)
+	}
+	if state.Credits.Value != 1200 {
+		t.Errorf("get_ship reset credits to %v", state.Credits.Value)
+	}
+}
+
+func TestGameStateFromHistory(t *testing.T) {
+	if _, ok := GameStateFromHistory([]provider.Message{{Role: "user", Content: "hi"}}); ok {
+		t.Error("GameStateFromHistory() found state in a history without status results")
+	}
+
+	history := []provider.Message{
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "1", Name: "get_status", Arguments: json.RawMessage(`{}`)}}},
+		{Role: "tool", ToolCallID: "1", Content: `{"player": {"credits": 10}}`},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "2", Name: "get_status", Arguments: json.RawMessage(`{}`)}}},
+		{Role: "tool", ToolCallID: "2", Content: `{"player": {"credits": 25}}`},
+	}
+	state, ok := GameStateFromHistory(history)
+	if !ok || state.Credits.Value != 25 {
+		t.Errorf("GameStateFromHistory() = %+v, %v; want the latest credits 25", state.Credits, ok)
+	}
+}
@@ -0,0 +1,108 @@
+package features
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/mcp"
+)
+
+// imageDir is the data dir subdirectory tool result images are saved to.
+const imageDir = "images"
+
+// imageMarker matches the text ToolImage.Marker puts in tool results.
+var imageMarker = regexp.MustCompile(`\[image ([^\s,\]]+)(?:, (\d+) bytes)?(?:, saved to ([^\]]+))?\]`)
+
+// ToolImage is an image content block of a tool result. The model only
+// sees its marker; the TUI shows the saved file.
+type ToolImage struct {
+	MimeType string
+	Size     int    // Decoded bytes, 0 if unknown
+	Path     string // "" when not saved
+}
+
+// Marker returns the text standing for the image in the tool result, e.g.
+// "[image image/png, 5120 bytes, saved to /data/images/ab12.png]".
+func (img ToolImage) Marker() string {
+	text := "[image " + img.MimeType
+	if img.Size > 0 {
+		text += ", " + strconv.Itoa(img.Size) + " bytes"
+	}
+	if img.Path != "" {
+		text += ", saved to " + img.Path
+	}
+	return text + "]"
+}
+
+// FindToolImages returns the images marked in a tool result, in order.
+func FindToolImages(content string) []ToolImage {
+	var images []ToolImage
+	for _, m := range imageMarker.FindAllStringSubmatch(content, -1) {
+		size, _ := strconv.Atoi(m[2])
+		images = append(images, ToolImage{MimeType: m[1], Size: size, Path: m[3]})
+	}
+	return images
+}
+
+// SaveToolImage writes the base64 data of an image block to the data dir,
+// named after its hash so the same image is saved once.
+func SaveToolImage(mimeType, data string) (ToolImage, error) {
+	img := ToolImage{MimeType: mimeType}
+	if img.MimeType == "" {
+		img.MimeType = "image"
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return img, fmt.Errorf("decode image: %w", err)
+	}
+	img.Size = len(raw)
+	dir, err := config.DataDir()
+	if err != nil {
+		return img, err
+	}
+	sum := sha256.Sum256(raw)
+	path := filepath.Join(dir, imageDir, hex.EncodeToString(sum[:8])+imageExtension(mimeType))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return img, err
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		return img, err
+	}
+	img.Path = path
+	return img, nil
+}
+
+// ToolImageText saves an image block of a tool result and returns its
+// marker, see llm.ProcessTurnOptions.ImageText. An image that cannot be
+// saved is still marked.
+func ToolImageText(block mcp.ContentBlock) string {
+	img, err := SaveToolImage(block.MimeType, block.Data)
+	if err != nil {
+		log.Warn().Err(err).Str("mime", block.MimeType).Msg("Failed to save tool result image")
+	}
+	return img.Marker()
+}
+
+// imageExtension returns the file extension of an image MIME type.
+func imageExtension(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg", "image/jpg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".img"
+}
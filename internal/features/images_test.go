@@ -0,0 +1,21 @@
+package features
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindToolImages(t *testing.T) {
+	saved := ToolImage{MimeType: "image/png", Size: 5120, Path: "/data/images/my map.png"}
+	unsaved := ToolImage{MimeType: "image/jpeg"}
+	content := `{"system":"Sol"}` + saved.Marker() + "\n" + unsaved.Marker() + " [other text]"
+
+	got := FindToolImages(content)
+	want := []ToolImage{saved, unsaved}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindToolImages(%q) = %+v, want %+v", content, got, want)
+	}
+	if got := FindToolImages(`{"images":[]}`); got != nil {
+		t.Errorf("FindToolImages without markers = %+v", got)
+	}
+}
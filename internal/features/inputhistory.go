@@ -0,0 +1,76 @@
+package features
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// inputHistoryDir is the data dir subdirectory for saved input histories.
+const inputHistoryDir = "input"
+
+// InputHistory is the TUI input history of a session with its unsent
+// draft, saved so Up recalls messages across restarts like a shell.
+type InputHistory struct {
+	History []string `json:"history"` // Oldest first
+	Draft   string   `json:"draft,omitempty"`
+}
+
+// InputHistoryPath returns the file the input history of a session is
+// saved to in the data dir.
+func InputHistoryPath(sessionID string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, inputHistoryDir, sessionFileName(sessionID)+".json"), nil
+}
+
+// sessionFileName returns sessionID made safe as a file name.
+func sessionFileName(sessionID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, sessionID)
+	if name == "" {
+		name = "session"
+	}
+	return name
+}
+
+// LoadInputHistory reads an input history saved by SaveInputHistory. A
+// missing file is an empty history.
+func LoadInputHistory(path string) (InputHistory, error) {
+	var h InputHistory
+	//nolint:gosec // G304: Path from the data directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return InputHistory{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// SaveInputHistory writes h to path, creating its directory.
+func SaveInputHistory(path string, h InputHistory) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
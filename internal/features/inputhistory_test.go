@@ -0,0 +1,25 @@
+package features
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInputHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input", "abc.json")
+
+	h, err := LoadInputHistory(path)
+	if err != nil || len(h.History) != 0 || h.Draft != "" {
+		t.Fatalf("missing file: %+v, %v", h, err)
+	}
+
+	want := InputHistory{History: []string{"mine", "sell ore\nthen dock"}, Draft: "travel to"}
+	if err := SaveInputHistory(path, want); err != nil {
+		t.Fatalf("SaveInputHistory: %v", err)
+	}
+	got, err := LoadInputHistory(path)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadInputHistory() = %+v, %v; want %+v", got, err, want)
+	}
+}
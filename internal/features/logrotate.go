@@ -0,0 +1,150 @@
+package features
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// LogRotation is when the log files are rotated and how many rotated files
+// are kept: mysis.log becomes mysis.log.1, mysis.log.1 becomes mysis.log.2
+// and so on up to Keep.
+type LogRotation struct {
+	MaxSize int64         // Bytes; 0 never rotates by size
+	MaxAge  time.Duration // Since the file was started; 0 never rotates by age
+	Keep    int
+}
+
+// defaultLogRotation applies until SetLogRotation, see config.LogsConfig.
+var defaultLogRotation = LogRotation{MaxSize: 10 << 20, Keep: 5}
+
+// logFiles are the rotating files of SetupFileLogging.
+var (
+	logFilesMu sync.Mutex
+	logFiles   []*rotatingFile
+)
+
+// SetLogRotation applies the [logs] settings to the log files opened by
+// SetupFileLogging, which start with the defaults since logging is set up
+// before the config is loaded.
+func SetLogRotation(cfg config.LogsConfig) {
+	policy := LogRotation{MaxSize: int64(cfg.MaxSizeMB) << 20, MaxAge: cfg.MaxAge, Keep: cfg.Keep}
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	for _, f := range logFiles {
+		f.setPolicy(policy)
+	}
+}
+
+// rotatingFile is an append-only log file rotated by size and age. The age
+// of a file is counted from when it was created, or opened when it already
+// existed; a file last written longer than MaxAge ago is rotated on open.
+// Safe for concurrent use.
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	policy  LogRotation
+	file    *os.File
+	size    int64
+	started time.Time
+}
+
+// openRotatingFile opens the log file at path for appending with policy.
+func openRotatingFile(path string, policy LogRotation) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, policy: policy}
+	if info, err := os.Stat(path); err == nil && policy.MaxAge > 0 && time.Since(info.ModTime()) > policy.MaxAge {
+		if err := f.shift(); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens or creates the file at f.path.
+func (f *rotatingFile) open() error {
+	//nolint:gosec // G304: Path from the data directory
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size, f.started = file, info.Size(), time.Now()
+	return nil
+}
+
+// setPolicy replaces the rotation policy; it applies from the next write.
+func (f *rotatingFile) setPolicy(policy LogRotation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.policy = policy
+}
+
+// Write appends p, first rotating the file when p would take it past
+// MaxSize or it is older than MaxAge. A log entry is never split.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.size > 0 && f.due(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// due reports whether the file is to be rotated before writing n bytes.
+func (f *rotatingFile) due(n int) bool {
+	return (f.policy.MaxSize > 0 && f.size+int64(n) > f.policy.MaxSize) ||
+		(f.policy.MaxAge > 0 && time.Since(f.started) > f.policy.MaxAge)
+}
+
+// rotate closes the file, shifts it to path.1 and starts a new one.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if err := f.shift(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// shift renames path.N to path.N+1 down to path to path.1, dropping the
+// files past Keep.
+func (f *rotatingFile) shift() error {
+	oldest := f.path
+	if f.policy.Keep > 0 {
+		oldest = fmt.Sprintf("%s.%d", f.path, f.policy.Keep)
+	}
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := f.policy.Keep - 1; i >= 0; i-- {
+		from := f.path
+		if i > 0 {
+			from = fmt.Sprintf("%s.%d", f.path, i)
+		}
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
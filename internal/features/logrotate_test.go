@@ -0,0 +1,63 @@
+package features
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysis.log")
+	f, err := openRotatingFile(path, LogRotation{MaxSize: 10, Keep: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = f.file.Close()
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(name)
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(name), data, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("mysis.log.3 kept past keep = 2: %v", err)
+	}
+}
+
+func TestRotatingFileStaleOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysis.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := openRotatingFile(path, LogRotation{MaxAge: 24 * time.Hour, Keep: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.Write([]byte("new\n"))
+	_ = f.file.Close()
+
+	if data, _ := os.ReadFile(path + ".1"); !strings.Contains(string(data), "old") {
+		t.Errorf("mysis.log.1 = %q, want the stale log", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("mysis.log = %q, want a new log", data)
+	}
+}
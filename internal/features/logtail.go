@@ -0,0 +1,52 @@
+package features
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxLogTailLines is the number of log lines kept for the TUI log pane.
+const maxLogTailLines = 200
+
+// logTail keeps the last log lines for RecentLogs. Safe for concurrent use.
+type logTail struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string // Text after the last newline
+}
+
+// logs receives the file logger's output formatted for reading, see
+// SetupFileLogging.
+var logs = &logTail{}
+
+// Write appends the complete lines of p, dropping the oldest past
+// maxLogTailLines.
+func (t *logTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	text := t.partial + string(p)
+	parts := strings.Split(text, "\n")
+	t.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		if line = strings.TrimRight(line, "\r "); line != "" {
+			t.lines = append(t.lines, line)
+		}
+	}
+	if len(t.lines) > maxLogTailLines {
+		t.lines = append([]string(nil), t.lines[len(t.lines)-maxLogTailLines:]...)
+	}
+	return len(p), nil
+}
+
+// Lines returns a copy of the kept lines, oldest first.
+func (t *logTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.lines...)
+}
+
+// RecentLogs returns the last lines logged since SetupFileLogging, oldest
+// first, for the TUI log pane.
+func RecentLogs() []string {
+	return logs.Lines()
+}
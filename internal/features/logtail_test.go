@@ -0,0 +1,24 @@
+package features
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLogTail(t *testing.T) {
+	tail := &logTail{}
+	_, _ = tail.Write([]byte("first\nsec"))
+	_, _ = tail.Write([]byte("ond\n\nthird\n"))
+	if got, want := tail.Lines(), []string{"first", "second", "third"}; !slices.Equal(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+
+	for i := range maxLogTailLines {
+		_, _ = fmt.Fprintf(tail, "line %d\n", i)
+	}
+	lines := tail.Lines()
+	if len(lines) != maxLogTailLines || lines[0] != "line 0" {
+		t.Errorf("Lines() kept %d lines starting with %q, want %d starting with \"line 0\"", len(lines), lines[0], maxLogTailLines)
+	}
+}
@@ -0,0 +1,71 @@
+package features
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// ProviderModels are the models of a configured provider, for the TUI model
+// picker.
+type ProviderModels struct {
+	Name   string
+	Model  string   // Configured model, or the current one for the current provider
+	Models []string // Models the endpoint serves; nil when it cannot list them
+	Err    error    // Listing the models failed
+}
+
+// ListProviderModels lists the models of the configured providers, sorted by
+// name. The current provider is asked directly; the others are created for
+// the listing and closed. Providers are asked concurrently, each within
+// constants.DefaultTimeout.
+func ListProviderModels(ctx context.Context, cfg *config.Config, registry *provider.Registry, current provider.Provider, currentModel string) []ProviderModels {
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]ProviderModels, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		result[i] = ProviderModels{Name: name, Model: cfg.Providers[name].Model}
+		prov, created := current, false
+		if current == nil || current.Name() != name {
+			var err error
+			if prov, _, err = CreateProvider(cfg, registry, name); err != nil {
+				result[i].Err = err
+				continue
+			}
+			created = true
+		} else {
+			result[i].Model = currentModel
+		}
+
+		wg.Add(1)
+		go func(entry *ProviderModels, prov provider.Provider, created bool) {
+			defer wg.Done()
+			if created {
+				defer func() {
+					if err := prov.Close(); err != nil {
+						log.Debug().Err(err).Str("provider", entry.Name).Msg("Failed to close provider")
+					}
+				}()
+			}
+			lister, ok := prov.(provider.ModelLister)
+			if !ok {
+				return
+			}
+			listCtx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+			defer cancel()
+			entry.Models, entry.Err = lister.ListModels(listCtx)
+		}(&result[i], prov, created)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,44 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// listingMock is a mock provider that lists models.
+type listingMock struct {
+	*provider.MockProvider
+	models []string
+}
+
+func (p listingMock) ListModels(ctx context.Context) ([]string, error) {
+	return p.models, nil
+}
+
+func TestListProviderModels(t *testing.T) {
+	cfg := &config.Config{Providers: map[string]config.ProviderConfig{
+		"local": {Model: "small"},
+		"cloud": {Model: "big"},
+		"gone":  {Model: "old"},
+	}}
+	registry := provider.NewRegistry()
+	registry.RegisterFactory("cloud", provider.NewMockFactory("cloud", "ok"))
+
+	current := listingMock{provider.NewMock("local", "ok"), []string{"small", "tiny"}}
+	got := ListProviderModels(context.Background(), cfg, registry, current, "tiny")
+	if len(got) != 3 || got[0].Name != "cloud" || got[1].Name != "gone" || got[2].Name != "local" {
+		t.Fatalf("providers = %+v", got)
+	}
+	if got[0].Model != "big" || got[0].Models != nil || got[0].Err != nil {
+		t.Errorf("cloud = %+v", got[0])
+	}
+	if got[1].Err == nil {
+		t.Error("gone: expected an error for an unregistered provider")
+	}
+	if got[2].Model != "tiny" || len(got[2].Models) != 2 {
+		t.Errorf("local = %+v", got[2])
+	}
+}
@@ -0,0 +1,117 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Notification severities, from a notification's level or type.
+const (
+	NotificationInfo     = "info"
+	NotificationWarning  = "warning"
+	NotificationCritical = "critical"
+)
+
+// Notification is a game notification from a get_notifications result.
+type Notification struct {
+	Type       string // Game category, e.g. "combat"; "" when not reported
+	Text       string
+	Severity   string // NotificationInfo, NotificationWarning or NotificationCritical
+	ReceivedAt time.Time
+}
+
+// Words that set the severity of a notification without a level, checked in
+// its type and text.
+var (
+	criticalWords = []string{"combat", "attack", "destroyed", "killed", "hostile", "pirate", "died"}
+	warningWords  = []string{"warning", "damage", "fail", "insufficient", "expired", "lost", "error"}
+)
+
+// IsNotificationsTool reports whether tool returns game notifications.
+func IsNotificationsTool(tool string) bool {
+	return tool == notificationsTool
+}
+
+// ParseNotifications returns the notifications in a get_notifications
+// result. A non-JSON result is one notification when it is not empty.
+func ParseNotifications(result string, now time.Time) []Notification {
+	var resp struct {
+		Notifications []json.RawMessage `json:"notifications"`
+	}
+	if err := json.Unmarshal([]byte(result), &resp); err != nil {
+		text := strings.Join(strings.Fields(result), " ")
+		if text == "" {
+			return nil
+		}
+		return []Notification{{Text: text, Severity: notificationSeverity("", "", text), ReceivedAt: now}}
+	}
+
+	var notifications []Notification
+	for _, raw := range resp.Notifications {
+		n := Notification{ReceivedAt: now}
+		var obj map[string]any
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			var text string
+			if json.Unmarshal(raw, &text) != nil {
+				continue
+			}
+			n.Text = text
+		} else {
+			n.Type, _ = firstString(obj, []string{"type", "category", "kind"})
+			n.Text, _ = firstString(obj, []string{"message", "text", "content", "body"})
+			if n.Text == "" {
+				n.Text = string(raw)
+			}
+			level, _ := firstString(obj, []string{"severity", "level", "priority"})
+			n.Severity = notificationSeverity(level, n.Type, n.Text)
+		}
+		n.Text = strings.Join(strings.Fields(n.Text), " ")
+		if n.Severity == "" {
+			n.Severity = notificationSeverity("", "", n.Text)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications
+}
+
+// notificationSeverity maps a reported level to a severity, falling back to
+// the words of the type and text.
+func notificationSeverity(level, kind, text string) string {
+	switch strings.ToLower(level) {
+	case "critical", "urgent", "high", "error", "danger":
+		return NotificationCritical
+	case "warning", "warn", "medium":
+		return NotificationWarning
+	case "info", "low", "normal", "notice":
+		return NotificationInfo
+	}
+
+	lower := strings.ToLower(kind + " " + text)
+	for _, word := range criticalWords {
+		if strings.Contains(lower, word) {
+			return NotificationCritical
+		}
+	}
+	for _, word := range warningWords {
+		if strings.Contains(lower, word) {
+			return NotificationWarning
+		}
+	}
+	return NotificationInfo
+}
+
+// FormatNotifications formats notifications for the model, like the
+// notifications autoplay alerts queue for the next turn.
+func FormatNotifications(notifications []Notification) string {
+	lines := []string{"Game notifications received since your last turn:"}
+	for _, n := range notifications {
+		if n.Type != "" {
+			lines = append(lines, fmt.Sprintf("- [%s] %s", n.Type, n.Text))
+		} else {
+			lines = append(lines, "- "+n.Text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
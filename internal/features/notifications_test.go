@@ -0,0 +1,39 @@
+package features
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNotifications(t *testing.T) {
+	now := time.Now()
+	result := `{"count": 4, "notifications": [
+		{"type": "combat", "message": "You are UNDER ATTACK by pirate_42"},
+		{"type": "trade", "message": "Sold 10 ore\n for 500 credits"},
+		{"type": "system", "message": "Fuel warning", "severity": "info"},
+		"Cargo hold insufficient"
+	]}`
+
+	got := ParseNotifications(result, now)
+	want := []Notification{
+		{Type: "combat", Text: "You are UNDER ATTACK by pirate_42", Severity: NotificationCritical, ReceivedAt: now},
+		{Type: "trade", Text: "Sold 10 ore for 500 credits", Severity: NotificationInfo, ReceivedAt: now},
+		{Type: "system", Text: "Fuel warning", Severity: NotificationInfo, ReceivedAt: now},
+		{Text: "Cargo hold insufficient", Severity: NotificationWarning, ReceivedAt: now},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseNotifications() = %d notifications, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("notification %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := ParseNotifications(`{"count": 0, "notifications": []}`, now); len(got) != 0 {
+		t.Errorf("ParseNotifications(empty) = %+v, want none", got)
+	}
+	if got := ParseNotifications("Ship destroyed", now); len(got) != 1 || got[0].Severity != NotificationCritical {
+		t.Errorf("ParseNotifications(text) = %+v, want one critical notification", got)
+	}
+}
@@ -0,0 +1,91 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// Playbook is a sequence of autoplay goals run one after another.
+//
+// Example playbook.toml:
+//
+//	[[goal]]
+//	message = "Mine iron ore at the nearest belt"
+//	turns = 20
+//	stop_on = ["cargo full"]
+//
+//	[[goal]]
+//	message = "Sell all cargo at the nearest station"
+//	duration = "15m"
+type Playbook struct {
+	Goals []PlaybookGoal `toml:"goal"`
+}
+
+// PlaybookGoal is one phase of a playbook. The goal finishes when any of its
+// limits is reached or a stop condition matches; a goal without limits or
+// stop conditions runs until the run ends.
+type PlaybookGoal struct {
+	Message  string        `toml:"message"`  // Autoplay message sent each turn
+	Turns    int           `toml:"turns"`    // Turns before moving on; 0 means no limit
+	Duration time.Duration `toml:"duration"` // Time before moving on, checked after each turn; 0 means no limit
+	StopOn   []string      `toml:"stop_on"`  // Case-insensitive text that finishes the goal when it appears in a tool result
+}
+
+// LoadPlaybook reads and validates a playbook TOML file.
+func LoadPlaybook(path string) (*Playbook, error) {
+	var pb Playbook
+	if _, err := toml.DecodeFile(path, &pb); err != nil {
+		return nil, fmt.Errorf("failed to parse playbook: %w", err)
+	}
+	if err := pb.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid playbook %s: %w", path, err)
+	}
+	return &pb, nil
+}
+
+// Validate returns an error if the playbook is invalid.
+func (p *Playbook) Validate() error {
+	if len(p.Goals) == 0 {
+		return errors.New("at least one [[goal]] is required")
+	}
+
+	var errs []error
+	for i, g := range p.Goals {
+		if strings.TrimSpace(g.Message) == "" {
+			errs = append(errs, fmt.Errorf("goal %d: message is required", i+1))
+		}
+		if g.Turns < 0 {
+			errs = append(errs, fmt.Errorf("goal %d: turns=%d must not be negative", i+1, g.Turns))
+		}
+		if g.Duration < 0 {
+			errs = append(errs, fmt.Errorf("goal %d: duration=%s must not be negative", i+1, g.Duration))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchesStopCondition reports whether a tool result of the turn contains
+// one of the goal's stop conditions.
+func (g PlaybookGoal) matchesStopCondition(result *llm.TurnResult) bool {
+	if result == nil || len(g.StopOn) == 0 {
+		return false
+	}
+
+	for _, msg := range result.Messages {
+		if msg.Role != "tool" {
+			continue
+		}
+		content := strings.ToLower(msg.Content)
+		for _, cond := range g.StopOn {
+			if cond != "" && strings.Contains(content, strings.ToLower(cond)) {
+				return true
+			}
+		}
+	}
+	return false
+}
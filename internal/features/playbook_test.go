@@ -0,0 +1,97 @@
+package features
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestLoadPlaybook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playbook.toml")
+	content := `
+[[goal]]
+message = "Mine iron ore"
+turns = 20
+stop_on = ["cargo full"]
+
+[[goal]]
+message = "Sell cargo"
+duration = "15m"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pb, err := LoadPlaybook(path)
+	if err != nil {
+		t.Fatalf("LoadPlaybook() error = %v", err)
+	}
+	if len(pb.Goals) != 2 {
+		t.Fatalf("len(Goals) = %d, want 2", len(pb.Goals))
+	}
+	if pb.Goals[0].Turns != 20 || pb.Goals[0].StopOn[0] != "cargo full" {
+		t.Errorf("Goals[0] = %+v", pb.Goals[0])
+	}
+	if pb.Goals[1].Duration != 15*time.Minute {
+		t.Errorf("Goals[1].Duration = %v, want 15m", pb.Goals[1].Duration)
+	}
+
+	if err := (&Playbook{Goals: []PlaybookGoal{{Turns: 1}}}).Validate(); err == nil {
+		t.Error("Validate() should reject a goal without message")
+	}
+}
+
+func TestPlaybookAdvancesGoals(t *testing.T) {
+	var messages []string
+	stopped := make(chan AutoplaySummary, 1)
+
+	svc := NewAutoplayService(config.AutoplayConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+	}, AutoplayCallbacks{
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			messages = append(messages, message)
+			content := "cargo 10/50"
+			if len(messages) == 3 {
+				content = "Cargo FULL"
+			}
+			return &llm.TurnResult{Messages: []provider.Message{{Role: "tool", Content: content}}}, nil
+		},
+		OnStopped: func(summary AutoplaySummary) {
+			stopped <- summary
+		},
+	})
+
+	pb := &Playbook{Goals: []PlaybookGoal{
+		{Message: "undock", Turns: 1},
+		{Message: "mine", StopOn: []string{"cargo full"}},
+		{Message: "sell", Turns: 2},
+	}}
+	if err := svc.StartPlaybook(context.Background(), pb, AutoplayLimits{}); err != nil {
+		t.Fatalf("StartPlaybook() error = %v", err)
+	}
+
+	select {
+	case summary := <-stopped:
+		want := []string{"undock", "mine", "mine", "sell", "sell"}
+		if len(messages) != len(want) {
+			t.Fatalf("messages = %v, want %v", messages, want)
+		}
+		for i := range want {
+			if messages[i] != want[i] {
+				t.Errorf("messages[%d] = %q, want %q", i, messages[i], want[i])
+			}
+		}
+		if summary.Reason != StopReasonPlaybookDone || summary.GoalsCompleted != 3 {
+			t.Errorf("summary = %+v, want playbook finished with 3 goals", summary)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("playbook did not finish")
+	}
+}
@@ -0,0 +1,442 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// Plugins are executables in PluginsDir that extend mysis through a small
+// JSON protocol: mysis runs the plugin once per request, writes the request
+// to its stdin and reads the response from its stdout. A plugin can add
+// local tools, slash commands, turn hooks and a provider, see
+// documentation/guides/PLUGINS.md.
+
+// PluginProviderScheme is the endpoint scheme of providers served by a
+// plugin, e.g. endpoint = "plugin://myprovider".
+const PluginProviderScheme = "plugin://"
+
+// Plugin request types.
+const (
+	pluginDescribe = "describe"
+	pluginTool     = "tool"
+	pluginCommand  = "command"
+	pluginHook     = "hook"
+	pluginChat     = "chat"
+)
+
+// PluginHookTurn is the hook event sent after each turn.
+const PluginHookTurn = "turn"
+
+// Timeouts of plugin requests. Tools and chats may take long; describing
+// and hooks hold up startup and turns.
+const (
+	pluginDescribeTimeout = 10 * time.Second
+	pluginHookTimeout     = 10 * time.Second
+	pluginCallTimeout     = 2 * time.Minute
+)
+
+// PluginsDir returns the directory of plugins, plugins/ under the data
+// directory.
+func PluginsDir() (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("get data directory: %w", err)
+	}
+	return filepath.Join(dataDir, "plugins"), nil
+}
+
+// PluginManifest is a plugin's answer to the describe request: the
+// extension points it provides.
+type PluginManifest struct {
+	Tools    []mcp.Tool      `json:"tools"`
+	Commands []PluginCommand `json:"commands"`
+	Hooks    []string        `json:"hooks"` // Hook events, e.g. "turn"
+}
+
+// PluginCommand is a slash command provided by a plugin.
+type PluginCommand struct {
+	Name string `json:"name"` // Including the slash, e.g. "/market"
+	Args string `json:"args"` // Argument synopsis for /help
+	Help string `json:"help"`
+}
+
+// Plugin is a loaded plugin executable.
+type Plugin struct {
+	Name     string // File name
+	Path     string
+	Manifest PluginManifest
+}
+
+// PluginTurn is the payload of the turn hook.
+type PluginTurn struct {
+	Session      string  `json:"session"` // Empty for an anonymous session
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	Prompt       string  `json:"prompt"`   // The user message that started the turn
+	Response     string  `json:"response"` // The model's final text
+	ToolCalls    int     `json:"tool_calls"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// pluginRequest is the JSON written to a plugin's stdin. Type selects the
+// fields that are set.
+type pluginRequest struct {
+	Type      string          `json:"type"`
+	Name      string          `json:"name,omitempty"`      // tool, command
+	Arguments json.RawMessage `json:"arguments,omitempty"` // tool
+	Args      []string        `json:"args,omitempty"`      // command
+	Event     string          `json:"event,omitempty"`     // hook
+	Turn      *PluginTurn     `json:"turn,omitempty"`      // hook: turn
+	Model     string          `json:"model,omitempty"`     // chat
+	Messages  []pluginMessage `json:"messages,omitempty"`  // chat
+	Tools     []provider.Tool `json:"tools,omitempty"`     // chat
+
+	Temperature float64 `json:"temperature,omitempty"` // chat
+	TopP        float64 `json:"top_p,omitempty"`       // chat
+	MaxTokens   int     `json:"max_tokens,omitempty"`  // chat
+}
+
+// pluginMessage is a chat message in a chat request or response.
+type pluginMessage struct {
+	Role       string              `json:"role"`
+	Content    string              `json:"content"`
+	Reasoning  string              `json:"reasoning,omitempty"`
+	ToolCalls  []provider.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string              `json:"tool_call_id,omitempty"`
+}
+
+// pluginError is set in a response when the request failed.
+type pluginError struct {
+	Error string `json:"error"`
+}
+
+// LoadPlugins describes each executable in dir and returns the plugins
+// sorted by name. Plugins that fail to describe themselves are logged and
+// skipped; a missing dir has no plugins.
+func LoadPlugins(ctx context.Context, dir string) ([]*Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read plugins directory: %w", err)
+	}
+
+	var plugins []*Plugin
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue // Not executable, e.g. a README
+		}
+
+		p := &Plugin{Name: entry.Name(), Path: filepath.Join(dir, entry.Name())}
+		ctx, cancel := context.WithTimeout(ctx, pluginDescribeTimeout)
+		err = p.call(ctx, pluginRequest{Type: pluginDescribe}, &p.Manifest)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("plugin", p.Name).Msg("Skipping plugin")
+			continue
+		}
+		log.Debug().Str("plugin", p.Name).
+			Int("tools", len(p.Manifest.Tools)).
+			Int("commands", len(p.Manifest.Commands)).
+			Strs("hooks", p.Manifest.Hooks).
+			Msg("Loaded plugin")
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+var (
+	pluginsOnce sync.Once
+	plugins     []*Plugin
+)
+
+// Plugins returns the plugins of PluginsDir, loaded on first use.
+func Plugins() []*Plugin {
+	pluginsOnce.Do(func() {
+		dir, err := PluginsDir()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to locate plugins")
+			return
+		}
+		if plugins, err = LoadPlugins(context.Background(), dir); err != nil {
+			log.Warn().Err(err).Msg("Failed to load plugins")
+		}
+	})
+	return plugins
+}
+
+// RegisterPluginTools registers the tools of plugins with proxy. Tools named
+// like a registered local tool or a game auth tool are skipped, so a plugin
+// can't capture the account passwords passed to them.
+func RegisterPluginTools(proxy *mcp.Proxy, plugins []*Plugin) {
+	for _, p := range plugins {
+		for _, tool := range p.Manifest.Tools {
+			if proxy.HasLocalTool(tool.Name) || store.IsAuthTool(tool.Name) {
+				log.Warn().Str("plugin", p.Name).Str("tool", tool.Name).Msg("Skipping plugin tool named like a built-in tool")
+				continue
+			}
+			proxy.RegisterTool(tool, p.toolHandler(tool.Name))
+		}
+	}
+}
+
+// RegisterPluginCommands registers the slash commands of plugins. Their
+// output is printed like that of built-in commands, which plugins can't
+// replace.
+func (c *Commands) RegisterPluginCommands(plugins []*Plugin) {
+	for _, p := range plugins {
+		for _, cmd := range p.Manifest.Commands {
+			if !strings.HasPrefix(cmd.Name, "/") {
+				log.Warn().Str("plugin", p.Name).Str("command", cmd.Name).Msg("Skipping plugin command without a leading slash")
+				continue
+			}
+			if _, ok := c.Lookup(cmd.Name); ok {
+				log.Warn().Str("plugin", p.Name).Str("command", cmd.Name).Msg("Skipping plugin command named like a registered command")
+				continue
+			}
+			c.Register(Command{
+				Name: cmd.Name,
+				Args: cmd.Args,
+				Help: cmd.Help + " (" + p.Name + ")",
+				Run:  c.pluginCommand(p, cmd.Name),
+			})
+		}
+	}
+}
+
+func (c *Commands) pluginCommand(p *Plugin, name string) CommandFunc {
+	return func(ctx context.Context, args []string) error {
+		ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+		defer cancel()
+		var resp struct {
+			Output string `json:"output"`
+		}
+		if err := p.call(ctx, pluginRequest{Type: pluginCommand, Name: name, Args: args}, &resp); err != nil {
+			return err
+		}
+		if output := strings.TrimRight(resp.Output, "\n"); output != "" {
+			c.print(strings.Split(output, "\n"))
+		}
+		return nil
+	}
+}
+
+// NewPluginTurn returns the turn hook payload of a turn that ran on history
+// and ended with result and err.
+func NewPluginTurn(info TranscriptInfo, history []provider.Message, result *llm.TurnResult, err error) PluginTurn {
+	turn := PluginTurn{Session: info.Session, Provider: info.Provider, Model: info.Model}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			turn.Prompt = history[i].Content
+			break
+		}
+	}
+	if result != nil {
+		turn.Response = result.FinalResponse()
+		turn.ToolCalls = result.ToolCallCount()
+		turn.InputTokens = result.Usage.InputTokens
+		turn.OutputTokens = result.Usage.OutputTokens
+		turn.Cost = result.Usage.Cost
+	}
+	if err != nil {
+		turn.Error = err.Error()
+	}
+	return turn
+}
+
+// HasPluginHook reports whether any of plugins handles the hook event.
+func HasPluginHook(plugins []*Plugin, event string) bool {
+	return slices.ContainsFunc(plugins, func(p *Plugin) bool {
+		return slices.Contains(p.Manifest.Hooks, event)
+	})
+}
+
+// RunTurnHooks sends turn to the plugins handling the turn hook, in order.
+// Hook failures are logged and don't affect the session.
+func RunTurnHooks(ctx context.Context, plugins []*Plugin, turn PluginTurn) {
+	for _, p := range plugins {
+		if !slices.Contains(p.Manifest.Hooks, PluginHookTurn) {
+			continue
+		}
+		hookCtx, cancel := context.WithTimeout(ctx, pluginHookTimeout)
+		err := p.call(hookCtx, pluginRequest{Type: pluginHook, Event: PluginHookTurn, Turn: &turn}, nil)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("plugin", p.Name).Msg("Turn hook failed")
+		}
+	}
+}
+
+// toolHandler returns the handler running the plugin's tool name.
+func (p *Plugin) toolHandler(name string) mcp.ToolHandler {
+	return func(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+		defer cancel()
+		var result mcp.ToolResult
+		if err := p.call(ctx, pluginRequest{Type: pluginTool, Name: name, Arguments: arguments}, &result); err != nil {
+			return &mcp.ToolResult{
+				Content: []mcp.ContentBlock{{Type: "text", Text: fmt.Sprintf("Plugin %s failed: %v", p.Name, err)}},
+				IsError: true,
+			}, nil
+		}
+		return &result, nil
+	}
+}
+
+// call runs the plugin with req on stdin and decodes its stdout into resp,
+// if not nil. A non-zero exit or an "error" field in the response fails
+// the call.
+func (p *Plugin) call(ctx context.Context, req pluginRequest, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encode %s request: %w", req.Type, err)
+	}
+
+	cmd := exec.CommandContext(ctx, p.Path) //nolint:gosec // G204: Plugins are the user's own executables
+	cmd.Stdin = bytes.NewReader(body)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("plugin %s: %w: %s", p.Name, err, truncateResult(msg, 200))
+		}
+		return fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	if stderr.Len() > 0 {
+		log.Debug().Str("plugin", p.Name).Str("stderr", stderr.String()).Msg("Plugin output")
+	}
+	if resp == nil && len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil // Hooks need not answer
+	}
+
+	var failed pluginError
+	if err := json.Unmarshal(stdout.Bytes(), &failed); err != nil {
+		return fmt.Errorf("plugin %s: invalid %s response: %w", p.Name, req.Type, err)
+	}
+	if failed.Error != "" {
+		return fmt.Errorf("plugin %s: %s", p.Name, failed.Error)
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return fmt.Errorf("plugin %s: invalid %s response: %w", p.Name, req.Type, err)
+	}
+	return nil
+}
+
+// pluginProviderFactory creates providers served by a plugin.
+type pluginProviderFactory struct {
+	name   string
+	plugin *Plugin
+}
+
+// NewPluginProviderFactory returns the factory of provider name, served by
+// the plugin of an endpoint such as plugin://myprovider in PluginsDir.
+func NewPluginProviderFactory(name, endpoint string) (provider.ProviderFactory, error) {
+	file := strings.TrimPrefix(endpoint, PluginProviderScheme)
+	if file == "" || strings.ContainsAny(file, `/\`) || strings.HasPrefix(file, ".") {
+		return nil, fmt.Errorf("invalid plugin endpoint %q", endpoint)
+	}
+	dir, err := PluginsDir()
+	if err != nil {
+		return nil, err
+	}
+	return &pluginProviderFactory{name: name, plugin: &Plugin{Name: file, Path: filepath.Join(dir, file)}}, nil
+}
+
+func (f *pluginProviderFactory) Name() string { return f.name }
+
+func (f *pluginProviderFactory) Create(model string, opts provider.Options) provider.Provider {
+	return &pluginProvider{name: f.name, model: model, opts: opts, plugin: f.plugin}
+}
+
+// pluginProvider sends chat requests to a plugin. It doesn't stream: the
+// response arrives whole.
+type pluginProvider struct {
+	name   string
+	model  string
+	opts   provider.Options
+	plugin *Plugin
+}
+
+func (p *pluginProvider) Name() string { return p.name }
+
+func (p *pluginProvider) Chat(ctx context.Context, messages []provider.Message) (string, error) {
+	resp, err := p.ChatWithTools(ctx, messages, nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+func (p *pluginProvider) ChatWithTools(ctx context.Context, messages []provider.Message, tools []provider.Tool) (*provider.ChatResponse, error) {
+	req := pluginRequest{
+		Type:        pluginChat,
+		Model:       p.model,
+		Messages:    make([]pluginMessage, len(messages)),
+		Tools:       tools,
+		Temperature: p.opts.Temperature,
+		TopP:        p.opts.TopP,
+		MaxTokens:   p.opts.MaxTokens,
+	}
+	for i, msg := range messages {
+		req.Messages[i] = pluginMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Reasoning:  msg.Reasoning,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	var resp pluginMessage
+	if err := p.plugin.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &provider.ChatResponse{Content: resp.Content, ToolCalls: resp.ToolCalls, Reasoning: resp.Reasoning}, nil
+}
+
+func (p *pluginProvider) Stream(ctx context.Context, messages []provider.Message) (<-chan provider.StreamChunk, error) {
+	ch := make(chan provider.StreamChunk, 1)
+	go func() {
+		defer close(ch)
+		content, err := p.Chat(ctx, messages)
+		if err != nil {
+			ch <- provider.StreamChunk{Err: err, Done: true}
+			return
+		}
+		ch <- provider.StreamChunk{Content: content, Done: true}
+	}()
+	return ch, nil
+}
+
+func (p *pluginProvider) Close() error { return nil }
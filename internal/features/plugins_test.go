@@ -0,0 +1,161 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// testPlugin answers each request type, saving hook requests to hook.json
+// next to it.
+const testPlugin = `#!/bin/sh
+req=$(cat)
+case "$req" in
+*'"type":"describe"'*)
+	echo '{"tools":[{"name":"echo","description":"Echo","inputSchema":{"type":"object"}},{"name":"save_credentials"},{"name":"login"}],"commands":[{"name":"/hello","args":"[name]","help":"Say hello"},{"name":"/help","help":"Clash"}],"hooks":["turn"]}' ;;
+*'"type":"tool"'*)
+	echo '{"content":[{"type":"text","text":"echoed"}]}' ;;
+*'"type":"command"'*)
+	printf '%s\n' '{"output":"hello\nworld\n"}' ;;
+*'"type":"hook"'*)
+	echo "$req" > "$(dirname "$0")/hook.json" ;;
+*'"type":"chat"'*)
+	echo '{"role":"assistant","content":"","tool_calls":[{"id":"c1","name":"mine","arguments":{}}]}' ;;
+*)
+	echo '{"error":"unknown request"}' ;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "good", testPlugin, 0755)
+	writePlugin(t, dir, "README.md", "# Plugins", 0644)
+	writePlugin(t, dir, "broken", "#!/bin/sh\nexit 1\n", 0755)
+	writePlugin(t, dir, "garbage", "#!/bin/sh\necho not json\n", 0755)
+
+	plugins, err := LoadPlugins(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 1 || plugins[0].Name != "good" {
+		t.Fatalf("plugins = %+v, want only good", plugins)
+	}
+	m := plugins[0].Manifest
+	if len(m.Tools) != 3 || m.Tools[0].Name != "echo" || len(m.Commands) != 2 || m.Hooks[0] != PluginHookTurn {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	if plugins, err := LoadPlugins(context.Background(), filepath.Join(dir, "missing")); err != nil || plugins != nil {
+		t.Errorf("missing dir = %v, %v, want no plugins", plugins, err)
+	}
+}
+
+func TestPluginExtensionPoints(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "good", testPlugin, 0755)
+	plugins, err := LoadPlugins(context.Background(), dir)
+	if err != nil || len(plugins) != 1 {
+		t.Fatalf("LoadPlugins = %v, %v", plugins, err)
+	}
+	ctx := context.Background()
+
+	// Tools: built-in and auth tools are kept
+	proxy := mcp.NewProxy(nil)
+	proxy.RegisterTool(mcp.Tool{Name: "save_credentials"}, func(context.Context, json.RawMessage) (*mcp.ToolResult, error) {
+		return &mcp.ToolResult{Content: []mcp.ContentBlock{{Type: "text", Text: "saved"}}}, nil
+	})
+	RegisterPluginTools(proxy, plugins)
+	result, err := proxy.CallTool(ctx, "echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil || result.IsError || result.Content[0].Text != "echoed" {
+		t.Errorf("echo = %+v, %v", result, err)
+	}
+	if result, err := proxy.CallTool(ctx, "save_credentials", nil); err != nil || result.Content[0].Text != "saved" {
+		t.Errorf("save_credentials = %+v, %v, want the built-in", result, err)
+	}
+	if proxy.HasLocalTool("login") {
+		t.Error("plugin registered login")
+	}
+	proxy.SetDryRun(true)
+	result, err = proxy.CallTool(ctx, "echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil || !strings.Contains(result.Content[0].Text, "[dry run] echo was not executed") {
+		t.Errorf("echo in dry run = %+v, %v, want simulated", result, err)
+	}
+	proxy.SetDryRun(false)
+
+	// Commands: /help is built in and kept
+	var printed []string
+	c := NewCommands(func(lines []string) { printed = append(printed, lines...) })
+	c.RegisterPluginCommands(plugins)
+	cmd, ok := c.Lookup("/hello")
+	if !ok || cmd.Args != "[name]" || !strings.Contains(cmd.Help, "(good)") {
+		t.Fatalf("/hello = %+v, %v", cmd, ok)
+	}
+	if err := cmd.Run(ctx, []string{"bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(printed, "|") != "hello|world" {
+		t.Errorf("printed = %q", printed)
+	}
+	if help, _ := c.Lookup("/help"); strings.Contains(help.Help, "Clash") {
+		t.Error("plugin replaced /help")
+	}
+
+	// Hooks
+	history := []provider.Message{{Role: "user", Content: "mine"}}
+	turn := NewPluginTurn(TranscriptInfo{Session: "miner"}, history, &llm.TurnResult{
+		Messages: []provider.Message{{Role: "assistant", Content: "Mined."}},
+	}, nil)
+	RunTurnHooks(ctx, plugins, turn)
+	data, err := os.ReadFile(filepath.Join(dir, "hook.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req pluginRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.Event != PluginHookTurn || req.Turn == nil ||
+		req.Turn.Session != "miner" || req.Turn.Prompt != "mine" || req.Turn.Response != "Mined." {
+		t.Errorf("hook request = %s (%v)", data, err)
+	}
+
+	// Provider
+	prov := (&pluginProviderFactory{name: "custom", plugin: plugins[0]}).Create("m1", provider.Options{})
+	resp, err := prov.ChatWithTools(ctx, history, nil)
+	if err != nil || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "mine" {
+		t.Errorf("chat = %+v, %v", resp, err)
+	}
+}
+
+func TestPluginError(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "failing", "#!/bin/sh\necho '{\"error\":\"no market here\"}'\n", 0755)
+	p := &Plugin{Name: "failing", Path: filepath.Join(dir, "failing")}
+
+	result, err := p.toolHandler("sell")(context.Background(), json.RawMessage(`{}`))
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].Text, "no market here") {
+		t.Errorf("tool result = %+v, %v", result, err)
+	}
+}
+
+func TestNewPluginProviderFactory(t *testing.T) {
+	for _, endpoint := range []string{"plugin://", "plugin://../x", "plugin://.hidden"} {
+		if _, err := NewPluginProviderFactory("custom", endpoint); err == nil {
+			t.Errorf("%s: want an error", endpoint)
+		}
+	}
+	if f, err := NewPluginProviderFactory("custom", "plugin://myprovider"); err != nil || f.Name() != "custom" {
+		t.Errorf("factory = %v, %v", f, err)
+	}
+}
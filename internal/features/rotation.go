@@ -0,0 +1,54 @@
+package features
+
+import (
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// goalRotation picks which named goal to send on each autoplay turn.
+// Weighted rotation uses smooth weighted round-robin, so a 3:1 split plays
+// "mine, mine, trade, mine" rather than three mines in a row.
+type goalRotation struct {
+	goals    []config.AutoplayGoal
+	weights  []int
+	current  []int
+	position int // Round-robin position
+	weighted bool
+}
+
+func newGoalRotation(goals []config.AutoplayGoal, policy string) *goalRotation {
+	weights := make([]int, len(goals))
+	for i, g := range goals {
+		weights[i] = g.Weight
+		if weights[i] <= 0 {
+			weights[i] = 1
+		}
+	}
+
+	return &goalRotation{
+		goals:    goals,
+		weights:  weights,
+		current:  make([]int, len(goals)),
+		weighted: policy == config.RotationWeighted,
+	}
+}
+
+// next returns the goal for the next turn.
+func (r *goalRotation) next() config.AutoplayGoal {
+	if !r.weighted {
+		g := r.goals[r.position%len(r.goals)]
+		r.position++
+		return g
+	}
+
+	total := 0
+	best := 0
+	for i, w := range r.weights {
+		r.current[i] += w
+		total += w
+		if r.current[i] > r.current[best] {
+			best = i
+		}
+	}
+	r.current[best] -= total
+	return r.goals[best]
+}
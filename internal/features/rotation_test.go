@@ -0,0 +1,36 @@
+package features
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/config"
+)
+
+func TestGoalRotation(t *testing.T) {
+	goals := []config.AutoplayGoal{
+		{Name: "mine", Message: "Mine ore", Weight: 3},
+		{Name: "trade", Message: "Sell cargo", Weight: 1},
+	}
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{config.RotationRoundRobin, "mine trade mine trade mine trade mine trade"},
+		{config.RotationWeighted, "mine mine trade mine mine mine trade mine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			r := newGoalRotation(goals, tt.policy)
+			var got []string
+			for range 8 {
+				got = append(got, r.next().Name)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("rotation = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/provider"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Script hook functions, defined as globals by session scripts. Each is
+// optional.
+const (
+	scriptOnToolCall   = "on_tool_call"   // (call) -> false or a reason to veto it
+	scriptOnToolResult = "on_tool_result" // (call, result) -> replacement result
+	scriptShouldStop   = "should_stop"    // (turn) -> true or a reason to stop autoplay
+)
+
+// scriptTimeout bounds each hook call, so a runaway script can't stall the
+// session.
+const scriptTimeout = time.Second
+
+// Script is a session's Lua script, run on turn events: it can veto tool
+// calls, rewrite tool results and stop autoplay. Scripts run sandboxed,
+// without the io, os and package libraries. Hook errors are logged and the
+// session goes on as if the hook were missing. A nil *Script has no hooks.
+type Script struct {
+	path string
+
+	mu sync.Mutex // Lua states are not safe for concurrent use
+	L  *lua.LState
+}
+
+// SessionScript loads the script configured for session in [scripts], or
+// returns nil if it has none.
+func SessionScript(cfg *config.Config, session string) (*Script, error) {
+	path, ok := cfg.Scripts[session]
+	if !ok || session == "" {
+		return nil, nil
+	}
+	return LoadScript(path)
+}
+
+// LoadScript runs the Lua script at path, which defines its hooks.
+func LoadScript(path string) (*Script, error) {
+	source, err := os.ReadFile(path) //nolint:gosec // G304: Path is from the user's config
+	if err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int {
+		parts := make([]string, L.GetTop())
+		for i := range parts {
+			parts[i] = L.ToStringMeta(L.Get(i + 1)).String()
+		}
+		log.Info().Str("script", path).Msg(strings.Join(parts, " "))
+		return 0
+	}))
+	jsonLib := L.NewTable()
+	L.SetField(jsonLib, "decode", L.NewFunction(scriptJSONDecode))
+	L.SetField(jsonLib, "encode", L.NewFunction(scriptJSONEncode))
+	L.SetGlobal("json", jsonLib)
+
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	if err := L.DoString(string(source)); err != nil {
+		L.Close()
+		return nil, fmt.Errorf("load script %s: %w", path, err)
+	}
+	L.RemoveContext()
+	return &Script{path: path, L: L}, nil
+}
+
+// Close releases the script's interpreter.
+func (s *Script) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.L.Close()
+}
+
+// VetoTool runs on_tool_call(call) before a tool call. The call is vetoed
+// when the hook returns false or a reason.
+func (s *Script) VetoTool(call provider.ToolCall) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret, ok := s.call(scriptOnToolCall, s.toolCallTable(call))
+	if !ok {
+		return "", false
+	}
+	switch v := ret.(type) {
+	case lua.LBool:
+		if !v {
+			return "vetoed by the session script", true
+		}
+	case lua.LString:
+		return string(v), true
+	}
+	return "", false
+}
+
+// FilterResult runs on_tool_result(call, result) on a successful tool
+// result. A string it returns replaces the result.
+func (s *Script) FilterResult(call provider.ToolCall, result string) string {
+	if s == nil {
+		return result
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret, ok := s.call(scriptOnToolResult, s.toolCallTable(call), lua.LString(result))
+	if v, isString := ret.(lua.LString); ok && isString {
+		return string(v)
+	}
+	return result
+}
+
+// ShouldStop runs should_stop(turn) after an autoplay turn. Autoplay stops
+// when the hook returns true or a reason.
+func (s *Script) ShouldStop(result *llm.TurnResult) (string, bool) {
+	if s == nil || result == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	turn := s.L.NewTable()
+	s.L.SetField(turn, "response", lua.LString(result.FinalResponse()))
+	s.L.SetField(turn, "tool_calls", lua.LNumber(result.ToolCallCount()))
+	s.L.SetField(turn, "cost", lua.LNumber(result.Usage.Cost))
+	calls := make(map[string]provider.ToolCall)
+	results := s.L.NewTable()
+	for _, msg := range result.Messages {
+		for _, tc := range msg.ToolCalls {
+			calls[tc.ID] = tc
+		}
+		if msg.Role != "tool" {
+			continue
+		}
+		entry := s.toolCallTable(calls[msg.ToolCallID])
+		s.L.SetField(entry, "result", lua.LString(msg.Content))
+		results.Append(entry)
+	}
+	s.L.SetField(turn, "results", results)
+
+	ret, ok := s.call(scriptShouldStop, turn)
+	if !ok {
+		return "", false
+	}
+	switch v := ret.(type) {
+	case lua.LBool:
+		if v {
+			return "should_stop", true
+		}
+	case lua.LString:
+		return string(v), true
+	}
+	return "", false
+}
+
+// call calls the hook fn with args and returns its result; false if the
+// script doesn't define fn or the call failed. The caller holds s.mu.
+func (s *Script) call(fn string, args ...lua.LValue) (lua.LValue, bool) {
+	hook, ok := s.L.GetGlobal(fn).(*lua.LFunction)
+	if !ok {
+		return lua.LNil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+	s.L.SetContext(ctx)
+	defer s.L.RemoveContext()
+	if err := s.L.CallByParam(lua.P{Fn: hook, NRet: 1, Protect: true}, args...); err != nil {
+		log.Warn().Err(err).Str("script", s.path).Str("hook", fn).Msg("Script hook failed")
+		return lua.LNil, false
+	}
+	ret := s.L.Get(-1)
+	s.L.Pop(1)
+	return ret, true
+}
+
+// toolCallTable returns {name = ..., arguments = {...}} for call.
+func (s *Script) toolCallTable(call provider.ToolCall) *lua.LTable {
+	t := s.L.NewTable()
+	s.L.SetField(t, "name", lua.LString(call.Name))
+	var args any
+	if len(call.Arguments) > 0 && json.Unmarshal(call.Arguments, &args) == nil {
+		if v, err := toLua(s.L, args, 0); err == nil {
+			s.L.SetField(t, "arguments", v)
+			return t
+		}
+	}
+	s.L.SetField(t, "arguments", s.L.NewTable())
+	return t
+}
+
+// scriptJSONDecode is json.decode(s): the Lua value of a JSON document, or
+// nil and an error message.
+func scriptJSONDecode(L *lua.LState) int {
+	var v any
+	err := json.Unmarshal([]byte(L.CheckString(1)), &v)
+	var value lua.LValue
+	if err == nil {
+		value, err = toLua(L, v, 0)
+	}
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(value)
+	return 1
+}
+
+// scriptJSONEncode is json.encode(v): the JSON of a Lua value. Tables with
+// keys 1..n are arrays, other tables objects.
+func scriptJSONEncode(L *lua.LState) int {
+	v, err := fromLua(L.CheckAny(1), make(map[*lua.LTable]bool))
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(lua.LString(data))
+	return 1
+}
+
+// scriptMaxDepth bounds the nesting of values converted between JSON and
+// Lua, so deep values can't exhaust the stack.
+const scriptMaxDepth = 100
+
+// toLua converts a decoded JSON value, nested depth deep, to Lua. JSON null
+// is nil.
+func toLua(L *lua.LState, v any, depth int) (lua.LValue, error) {
+	if depth > scriptMaxDepth {
+		return lua.LNil, fmt.Errorf("value nested deeper than %d levels", scriptMaxDepth)
+	}
+	switch v := v.(type) {
+	case bool:
+		return lua.LBool(v), nil
+	case float64:
+		return lua.LNumber(v), nil
+	case string:
+		return lua.LString(v), nil
+	case []any:
+		t := L.NewTable()
+		for _, item := range v {
+			value, err := toLua(L, item, depth+1)
+			if err != nil {
+				return lua.LNil, err
+			}
+			t.Append(value)
+		}
+		return t, nil
+	case map[string]any:
+		t := L.NewTable()
+		for key, item := range v {
+			value, err := toLua(L, item, depth+1)
+			if err != nil {
+				return lua.LNil, err
+			}
+			t.RawSetString(key, value)
+		}
+		return t, nil
+	default:
+		return lua.LNil, nil
+	}
+}
+
+// fromLua converts a Lua value to one encoding/json can marshal. path holds
+// the tables being converted, to refuse cyclic ones.
+func fromLua(v lua.LValue, path map[*lua.LTable]bool) (any, error) {
+	switch v := v.(type) {
+	case lua.LBool:
+		return bool(v), nil
+	case lua.LNumber:
+		if f := float64(v); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return f, nil
+		}
+		return nil, nil
+	case lua.LString:
+		return string(v), nil
+	case *lua.LTable:
+		if path[v] {
+			return nil, fmt.Errorf("cyclic table")
+		}
+		if len(path) >= scriptMaxDepth {
+			return nil, fmt.Errorf("table nested deeper than %d levels", scriptMaxDepth)
+		}
+		path[v] = true
+		defer delete(path, v)
+
+		if n := v.MaxN(); n > 0 {
+			items := make([]any, n)
+			for i := range items {
+				item, err := fromLua(v.RawGetInt(i+1), path)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = item
+			}
+			return items, nil
+		}
+		obj := make(map[string]any)
+		var err error
+		v.ForEach(func(key, value lua.LValue) {
+			if err == nil {
+				obj[key.String()], err = fromLua(value, path)
+			}
+		})
+		return obj, err
+	default:
+		return nil, nil
+	}
+}
+
+// ScriptSessions returns the sessions with a script in [scripts], sorted.
+func ScriptSessions(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Scripts))
+	for name := range cfg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
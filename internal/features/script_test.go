@@ -0,0 +1,180 @@
+package features
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+const testScript = `
+function on_tool_call(call)
+	if call.name == "jettison" then
+		return "never jettison cargo"
+	end
+	if call.name == "sell" and call.arguments.quantity > 100 then
+		return false
+	end
+end
+
+function on_tool_result(call, result)
+	if call.name ~= "get_status" then
+		return nil
+	end
+	local status = json.decode(result)
+	return json.encode({credits = status.credits})
+end
+
+function should_stop(turn)
+	for _, r in ipairs(turn.results) do
+		if r.name == "get_status" and json.decode(r.result).credits >= 1000 then
+			return "rich enough"
+		end
+	end
+	return turn.tool_calls > 10
+end
+`
+
+func writeScript(t *testing.T, source string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.lua")
+	if err := os.WriteFile(path, []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScriptHooks(t *testing.T) {
+	script, err := LoadScript(writeScript(t, testScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	vetoTests := []struct {
+		call   provider.ToolCall
+		reason string
+		veto   bool
+	}{
+		{provider.ToolCall{Name: "jettison"}, "never jettison cargo", true},
+		{provider.ToolCall{Name: "sell", Arguments: json.RawMessage(`{"quantity":500}`)}, "vetoed by the session script", true},
+		{provider.ToolCall{Name: "sell", Arguments: json.RawMessage(`{"quantity":5}`)}, "", false},
+		{provider.ToolCall{Name: "mine"}, "", false},
+	}
+	for _, tt := range vetoTests {
+		if reason, veto := script.VetoTool(tt.call); reason != tt.reason || veto != tt.veto {
+			t.Errorf("VetoTool(%s %s) = %q, %v, want %q, %v", tt.call.Name, tt.call.Arguments, reason, veto, tt.reason, tt.veto)
+		}
+	}
+
+	status := provider.ToolCall{Name: "get_status"}
+	if got := script.FilterResult(status, `{"credits":50,"cargo":[1,2]}`); got != `{"credits":50}` {
+		t.Errorf("FilterResult(get_status) = %s", got)
+	}
+	if got := script.FilterResult(provider.ToolCall{Name: "mine"}, "5 ore"); got != "5 ore" {
+		t.Errorf("FilterResult(mine) = %s, want it unchanged", got)
+	}
+
+	turn := func(credits string) *llm.TurnResult {
+		return &llm.TurnResult{Messages: []provider.Message{
+			{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "c1", Name: "get_status"}}},
+			{Role: "tool", ToolCallID: "c1", Content: `{"credits":` + credits + `}`},
+		}}
+	}
+	if reason, ok := script.ShouldStop(turn("1500")); !ok || reason != "rich enough" {
+		t.Errorf("ShouldStop(1500 credits) = %q, %v", reason, ok)
+	}
+	if reason, ok := script.ShouldStop(turn("10")); ok {
+		t.Errorf("ShouldStop(10 credits) = %q, want no stop", reason)
+	}
+}
+
+func TestScriptSandbox(t *testing.T) {
+	script, err := LoadScript(writeScript(t, `
+function on_tool_call(call)
+	return os.exit(1)
+end
+
+function on_tool_result(call, result)
+	while true do end
+end
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	// Failing hooks behave as if missing
+	if reason, veto := script.VetoTool(provider.ToolCall{Name: "mine"}); veto {
+		t.Errorf("VetoTool with os = %q, want no veto", reason)
+	}
+	if got := script.FilterResult(provider.ToolCall{Name: "mine"}, "5 ore"); got != "5 ore" {
+		t.Errorf("FilterResult with a runaway loop = %q", got)
+	}
+}
+
+func TestScriptJSONCycles(t *testing.T) {
+	script, err := LoadScript(writeScript(t, `
+function on_tool_result(call, result)
+	local t = {}
+	t.self = t
+	local encoded, err = json.encode(t)
+	if encoded ~= nil then
+		return "encoded"
+	end
+	local shared = {1}
+	return err .. "; " .. json.encode({a = shared, b = shared})
+end
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	// A cyclic table is an error, a table referenced twice is not
+	got := script.FilterResult(provider.ToolCall{Name: "mine"}, "5 ore")
+	if got != `cyclic table; {"a":[1],"b":[1]}` {
+		t.Errorf("FilterResult = %q", got)
+	}
+	deep := strings.Repeat("[", scriptMaxDepth+2) + strings.Repeat("]", scriptMaxDepth+2)
+	if _, err := toLua(script.L, mustDecode(t, deep), 0); err == nil {
+		t.Error("toLua of a deep value: want an error")
+	}
+}
+
+func mustDecode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSessionScript(t *testing.T) {
+	cfg := &config.Config{Scripts: map[string]string{"miner": writeScript(t, testScript)}}
+	script, err := SessionScript(cfg, "miner")
+	if err != nil || script == nil {
+		t.Fatalf("SessionScript(miner) = %v, %v", script, err)
+	}
+	script.Close()
+
+	if script, err := SessionScript(cfg, "trader"); script != nil || err != nil {
+		t.Errorf("SessionScript(trader) = %v, %v, want none", script, err)
+	}
+	cfg.Scripts["broken"] = writeScript(t, "function (")
+	if _, err := SessionScript(cfg, "broken"); err == nil || !strings.Contains(err.Error(), "load script") {
+		t.Errorf("SessionScript(broken) error = %v", err)
+	}
+
+	// A nil script has no hooks
+	var none *Script
+	if _, veto := none.VetoTool(provider.ToolCall{Name: "mine"}); veto || none.FilterResult(provider.ToolCall{}, "x") != "x" {
+		t.Error("nil script has hooks")
+	}
+}
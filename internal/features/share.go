@@ -0,0 +1,278 @@
+package features
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// ShareFormat identifies the version of the shareable transcript format.
+const ShareFormat = "mysis-share/1"
+
+// Limits of a shareable transcript, keeping it readable.
+const (
+	shareSummaryLimit = 600 // Runes of a turn summary
+	shareResultLimit  = 300 // Runes of a notable tool result
+	shareNotableLimit = 3   // Notable tool results per turn
+)
+
+// routineTools are tool name globs whose results are not notable: status
+// queries, the captain's log and local tools. Auth and credential tools are
+// never included, see routineTool.
+var routineTools = []string{
+	"get_*", "view_*", "list_*", "search_*", "help", "captains_log_*",
+	"send_to_agent", "read_agent_inbox",
+}
+
+// ShareTranscript is a bot run prepared for publishing: one entry per turn
+// with the model's closing text and notable tool results, with secrets
+// redacted. The system prompt and system notes are left out.
+type ShareTranscript struct {
+	Format     string      `json:"format"` // ShareFormat
+	Session    string      `json:"session,omitempty"`
+	Provider   string      `json:"provider"`
+	Model      string      `json:"model"`
+	Goal       string      `json:"goal,omitempty"`
+	ExportedAt time.Time   `json:"exported_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	EndedAt    *time.Time  `json:"ended_at,omitempty"`
+	ToolCalls  int         `json:"tool_calls"`
+	Turns      []ShareTurn `json:"turns"`
+}
+
+// ShareTurn is a turn of a shareable transcript.
+type ShareTurn struct {
+	Number    int               `json:"number"`
+	Time      *time.Time        `json:"time,omitempty"`
+	Prompt    string            `json:"prompt,omitempty"` // Omitted when it is the goal
+	Summary   string            `json:"summary,omitempty"`
+	ToolCalls map[string]int    `json:"tool_calls,omitempty"` // Calls per tool
+	Notable   []ShareToolResult `json:"notable,omitempty"`
+}
+
+// ShareToolResult is a notable tool call of a turn and its result.
+type ShareToolResult struct {
+	Tool      string `json:"tool"`
+	Arguments string `json:"arguments,omitempty"`
+	Result    string `json:"result"`
+}
+
+// secretPatterns match secrets in text: hex tokens such as game passwords
+// and session IDs, API keys and bearer tokens.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`),
+}
+
+// secretField matches JSON string fields holding secrets.
+var secretField = regexp.MustCompile(`(?i)("(?:password|token|secret|api_?key)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// redactSecrets replaces the secrets in s with [redacted].
+func redactSecrets(s string) string {
+	s = secretField.ReplaceAllString(s, `$1"[redacted]"`)
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, "[redacted]")
+	}
+	return s
+}
+
+// BuildShareTranscript prepares a conversation for sharing. A turn starts
+// at each user message. Without info.Goal, the goal is the user message
+// sent most often, as autoplay repeats it every turn.
+func BuildShareTranscript(info TranscriptInfo, messages []provider.Message) ShareTranscript {
+	goal := info.Goal
+	if goal == "" {
+		goal = guessGoal(messages)
+	}
+	share := ShareTranscript{
+		Format:     ShareFormat,
+		Session:    info.Session,
+		Provider:   info.Provider,
+		Model:      info.Model,
+		Goal:       redactSecrets(goal),
+		ExportedAt: time.Now(),
+		Turns:      []ShareTurn{},
+	}
+
+	calls := make(map[string]provider.ToolCall)
+	var turn *ShareTurn
+	endTurn := func() {
+		if turn != nil {
+			share.Turns = append(share.Turns, *turn)
+			turn = nil
+		}
+	}
+	for _, msg := range messages {
+		if !msg.CreatedAt.IsZero() {
+			t := msg.CreatedAt
+			if share.StartedAt == nil {
+				share.StartedAt = &t
+			}
+			share.EndedAt = &t
+		}
+
+		switch msg.Role {
+		case "user":
+			endTurn()
+			turn = &ShareTurn{Number: len(share.Turns) + 1}
+			if !msg.CreatedAt.IsZero() {
+				t := msg.CreatedAt
+				turn.Time = &t
+			}
+			if msg.Content != goal {
+				turn.Prompt = truncateSummary(redactSecrets(msg.Content))
+			}
+		case "assistant":
+			if turn == nil {
+				continue
+			}
+			if strings.TrimSpace(msg.Content) != "" {
+				turn.Summary = truncateSummary(redactSecrets(msg.Content))
+			}
+			for _, tc := range msg.ToolCalls {
+				calls[tc.ID] = tc
+				if turn.ToolCalls == nil {
+					turn.ToolCalls = make(map[string]int)
+				}
+				turn.ToolCalls[tc.Name]++
+				share.ToolCalls++
+			}
+		case "tool":
+			tc, ok := calls[msg.ToolCallID]
+			if turn == nil || !ok || routineTool(tc.Name) || len(turn.Notable) >= shareNotableLimit {
+				continue
+			}
+			args := string(store.RedactArguments(tc.Name, tc.Arguments))
+			if args == "{}" || args == "null" {
+				args = ""
+			}
+			turn.Notable = append(turn.Notable, ShareToolResult{
+				Tool:      tc.Name,
+				Arguments: redactSecrets(args),
+				Result:    truncateResult(redactSecrets(msg.Content), shareResultLimit),
+			})
+		}
+	}
+	endTurn()
+	return share
+}
+
+// RenderShareJSON renders a conversation as an indented shareable JSON
+// transcript, see BuildShareTranscript.
+func RenderShareJSON(info TranscriptInfo, messages []provider.Message) ([]byte, error) {
+	return json.MarshalIndent(BuildShareTranscript(info, messages), "", "  ")
+}
+
+// RenderShareHTML renders a conversation as a standalone HTML page of its
+// shareable transcript, see BuildShareTranscript.
+func RenderShareHTML(info TranscriptInfo, messages []provider.Message) ([]byte, error) {
+	var b bytes.Buffer
+	if err := shareTemplate.Execute(&b, BuildShareTranscript(info, messages)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// routineTool reports whether the results of the tool are left out.
+func routineTool(name string) bool {
+	for _, pattern := range routineTools {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return store.IsAuthTool(name) || strings.HasSuffix(name, "_credentials")
+}
+
+// guessGoal returns the user message sent most often, the latest on ties.
+func guessGoal(messages []provider.Message) string {
+	counts := make(map[string]int)
+	goal, best := "", 0
+	for _, msg := range messages {
+		if msg.Role != "user" {
+			continue
+		}
+		counts[msg.Content]++
+		if n := counts[msg.Content]; n >= best {
+			goal, best = msg.Content, n
+		}
+	}
+	return goal
+}
+
+// truncateSummary shortens text to shareSummaryLimit runes.
+func truncateSummary(s string) string {
+	s = strings.TrimSpace(s)
+	if runes := []rune(s); len(runes) > shareSummaryLimit {
+		return strings.TrimSpace(string(runes[:shareSummaryLimit])) + "..."
+	}
+	return s
+}
+
+var shareTemplate = template.Must(template.New("share").Funcs(template.FuncMap{
+	"date": func(v any) string {
+		switch t := v.(type) {
+		case time.Time:
+			return t.Format("2006-01-02 15:04")
+		case *time.Time:
+			if t != nil {
+				return t.Format("2006-01-02 15:04")
+			}
+		}
+		return ""
+	},
+	"tools": func(calls map[string]int) string {
+		parts := make([]string, 0, len(calls))
+		for name, n := range calls {
+			if n > 1 {
+				name = fmt.Sprintf("%s ×%d", name, n)
+			}
+			parts = append(parts, name)
+		}
+		slices.Sort(parts)
+		return strings.Join(parts, ", ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Mysis run{{with .Session}}: {{.}}{{end}}</title>
+<style>
+body { background: #0d1117; color: #c9d1d9; font: 15px/1.5 system-ui, sans-serif; max-width: 860px; margin: 2em auto; padding: 0 1em; }
+h1 { color: #58a6ff; margin-bottom: 0.2em; }
+.meta, .time, .tools, footer { color: #8b949e; font-size: 0.9em; }
+.goal { border-left: 3px solid #58a6ff; padding: 0.3em 0.8em; margin: 1em 0; }
+section { border-top: 1px solid #30363d; padding: 0.6em 0; }
+h2 { font-size: 1.05em; margin: 0.3em 0; }
+.prompt { color: #d2a8ff; }
+.summary { white-space: pre-wrap; }
+details { margin: 0.3em 0; }
+summary { cursor: pointer; color: #7ee787; font-family: monospace; }
+pre { background: #161b22; padding: 0.6em; overflow-x: auto; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Mysis run{{with .Session}}: {{.}}{{end}}</h1>
+<div class="meta">{{.Provider}} ({{.Model}}){{with .StartedAt}} · {{date .}}{{end}}{{with .EndedAt}} to {{date .}}{{end}} · {{len .Turns}} turns, {{.ToolCalls}} tool calls</div>
+{{with .Goal}}<div class="goal">Goal: {{.}}</div>{{end}}
+{{range .Turns}}<section>
+<h2>Turn {{.Number}} <span class="time">{{date .Time}}</span></h2>
+{{with .Prompt}}<p class="prompt">{{.}}</p>{{end}}
+{{with .Summary}}<p class="summary">{{.}}</p>{{end}}
+{{with .ToolCalls}}<p class="tools">Tools: {{tools .}}</p>{{end}}
+{{range .Notable}}<details><summary>{{.Tool}}({{.Arguments}})</summary><pre>{{.Result}}</pre></details>
+{{end}}</section>
+{{end}}<footer>Exported {{date .ExportedAt}} with mysis. Credentials and tokens are redacted.</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,222 @@
+package features
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// StarMap is the map of the current system and the galaxy as last reported
+// by get_system and get_map, with the ship's location, for display.
+type StarMap struct {
+	System    string      // Current system
+	POI       string      // Current point of interest
+	POIs      []MapPoint  // Points of interest of System, from get_system
+	POISystem string      // System the POIs belong to
+	Systems   []MapPoint  // Systems of the galaxy, from get_map
+	Links     [][2]string // Jump connections between Systems
+	UpdatedAt time.Time
+}
+
+// MapPoint is a point of interest or system on the map.
+type MapPoint struct {
+	ID     string
+	Name   string
+	Type   string // e.g. "planet" or "station"; "" when not reported
+	X, Y   float64
+	HasPos bool // X and Y were reported
+}
+
+// Label returns the name of the point, or its ID without one.
+func (p MapPoint) Label() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// Matches reports whether name is the point's ID or name.
+func (p MapPoint) Matches(name string) bool {
+	return name != "" && (name == p.ID || name == p.Name)
+}
+
+// Map tools, whose results update the points of a StarMap.
+const (
+	systemTool = "get_system"
+	galaxyTool = "get_map"
+)
+
+// Tools reporting the ship's location, and the field names the map tools
+// use, tried in order.
+var (
+	mapLocationTools  = map[string]bool{"get_status": true, "travel": true, "jump": true, "dock": true, "undock": true}
+	pointListPaths    = []string{"pois", "points_of_interest", "locations", "bodies"}
+	systemListPaths   = []string{"systems", "map.systems", "galaxy.systems"}
+	pointIDPaths      = []string{"id", "poi_id", "system_id"}
+	pointNamePaths    = []string{"name", "label"}
+	pointTypePaths    = []string{"type", "kind", "class", "category"}
+	linkListPaths     = []string{"connections", "jump_targets", "links", "adjacent"}
+	positionPrefixes  = []string{"", "position.", "coordinates.", "pos.", "location."}
+	systemNamePaths   = []string{"name", "id", "system_id"}
+	currentSystemKeys = []string{"current_system", "player.current_system", "system_id"}
+)
+
+// IsMapTool reports whether results of tool update a StarMap.
+func IsMapTool(tool string) bool {
+	return tool == systemTool || tool == galaxyTool || mapLocationTools[tool]
+}
+
+// UpdateStarMap applies a tool result to m. Returns false if the tool does
+// not report map data or its result is not a JSON object.
+func UpdateStarMap(m *StarMap, tool, result string, now time.Time) bool {
+	if !IsMapTool(tool) {
+		return false
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(result), &doc); err != nil {
+		return false
+	}
+
+	switch tool {
+	case systemTool:
+		applySystem(m, doc)
+	case galaxyTool:
+		applyGalaxy(m, doc)
+	}
+	if system, ok := firstString(doc, systemPaths); ok {
+		m.System = system
+	}
+	if poi, ok := firstString(doc, poiPaths); ok {
+		m.POI = poi
+	}
+	m.UpdatedAt = now
+	return true
+}
+
+// applySystem reads the points of interest of a get_system result, which
+// may nest the system under "system".
+func applySystem(m *StarMap, doc map[string]any) {
+	system := doc
+	if nested, ok := doc["system"].(map[string]any); ok {
+		system = nested
+	}
+	name, _ := firstString(system, systemNamePaths)
+	items, ok := firstList(system, pointListPaths)
+	if !ok {
+		return
+	}
+
+	m.POIs = nil // Copies of m may share the old list
+	for _, item := range items {
+		if p, ok := parseMapPoint(item); ok {
+			m.POIs = append(m.POIs, p)
+		}
+	}
+	m.POISystem = name
+}
+
+// applyGalaxy reads the systems and their connections of a get_map result.
+func applyGalaxy(m *StarMap, doc map[string]any) {
+	items, ok := firstList(doc, systemListPaths)
+	if !ok {
+		return
+	}
+
+	m.Systems = nil
+	m.Links = nil
+	seen := make(map[[2]string]bool)
+	for _, item := range items {
+		p, ok := parseMapPoint(item)
+		if !ok {
+			continue
+		}
+		m.Systems = append(m.Systems, p)
+
+		obj, _ := item.(map[string]any)
+		links, _ := firstList(obj, linkListPaths)
+		for _, link := range links {
+			target, ok := link.(string)
+			if !ok {
+				linkObj, _ := link.(map[string]any)
+				target, _ = firstString(linkObj, systemNamePaths)
+			}
+			from := p.ID
+			if from == "" {
+				from = p.Name
+			}
+			key := [2]string{min(from, target), max(from, target)}
+			if target == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			m.Links = append(m.Links, [2]string{from, target})
+		}
+	}
+	if current, ok := firstString(doc, currentSystemKeys); ok {
+		m.System = current
+	}
+}
+
+// parseMapPoint reads a point of interest or system from a list item: an
+// object, or a bare name.
+func parseMapPoint(item any) (MapPoint, bool) {
+	if name, ok := item.(string); ok {
+		return MapPoint{ID: name, Name: name}, name != ""
+	}
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return MapPoint{}, false
+	}
+
+	var p MapPoint
+	p.ID, _ = firstString(obj, pointIDPaths)
+	p.Name, _ = firstString(obj, pointNamePaths)
+	p.Type, _ = firstString(obj, pointTypePaths)
+	for _, prefix := range positionPrefixes {
+		x, okX := lookupNumber(obj, prefix+"x")
+		y, okY := lookupNumber(obj, prefix+"y")
+		if okX && okY {
+			p.X, p.Y, p.HasPos = x, y, true
+			break
+		}
+	}
+	return p, p.Label() != ""
+}
+
+// firstList returns the list at the first of paths present in doc.
+func firstList(doc map[string]any, paths []string) ([]any, bool) {
+	for _, path := range paths {
+		var v any = doc
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				v = nil
+				break
+			}
+			v = obj[key]
+		}
+		if list, ok := v.([]any); ok {
+			return list, true
+		}
+	}
+	return nil, false
+}
+
+// StarMapFromHistory replays the map tool results of messages, e.g. of a
+// resumed session. Returns false if there were none.
+func StarMapFromHistory(messages []provider.Message) (StarMap, bool) {
+	var m StarMap
+	found := false
+	toolNames := make(map[string]string)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+		}
+		if msg.Role == "tool" && UpdateStarMap(&m, toolNames[msg.ToolCallID], msg.Content, msg.CreatedAt) {
+			found = true
+		}
+	}
+	return m, found
+}
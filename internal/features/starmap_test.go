@@ -0,0 +1,50 @@
+package features
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateStarMap(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var m StarMap
+
+	if UpdateStarMap(&m, "mine", `{"pois": []}`, now) {
+		t.Error("UpdateStarMap() applied a result of another tool")
+	}
+
+	system := `{"system": {"name": "Sol", "pois": [
+		{"id": "earth", "name": "Earth Station", "type": "station", "position": {"x": 1, "y": 2}},
+		{"id": "belt", "type": "asteroid_belt"},
+		"Comet"]}}`
+	if !UpdateStarMap(&m, "get_system", system, now) {
+		t.Fatal("UpdateStarMap(get_system) = false")
+	}
+	if m.POISystem != "Sol" || len(m.POIs) != 3 {
+		t.Fatalf("map = %+v", m)
+	}
+	if p := m.POIs[0]; p.Label() != "Earth Station" || p.Type != "station" || !p.HasPos || p.X != 1 || p.Y != 2 {
+		t.Errorf("POIs[0] = %+v", p)
+	}
+	if p := m.POIs[1]; p.Label() != "belt" || p.HasPos {
+		t.Errorf("POIs[1] = %+v", p)
+	}
+
+	// The ship's location comes from status and travel results
+	if !UpdateStarMap(&m, "travel", `{"current_poi": "earth", "current_system": "Sol"}`, now) {
+		t.Fatal("UpdateStarMap(travel) = false")
+	}
+	if m.System != "Sol" || m.POI != "earth" || !m.POIs[0].Matches(m.POI) {
+		t.Errorf("location = %q / %q", m.System, m.POI)
+	}
+
+	galaxy := `{"systems": [
+		{"id": "sol", "name": "Sol", "x": 0, "y": 0, "connections": ["alpha", "sirius"]},
+		{"id": "alpha", "name": "Alpha Centauri", "x": 4, "y": 1, "connections": [{"system_id": "sol"}]}]}`
+	if !UpdateStarMap(&m, "get_map", galaxy, now) {
+		t.Fatal("UpdateStarMap(get_map) = false")
+	}
+	if len(m.Systems) != 2 || len(m.Links) != 2 {
+		t.Errorf("systems = %+v, links = %v", m.Systems, m.Links)
+	}
+}
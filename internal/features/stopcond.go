@@ -0,0 +1,112 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// StopConditionMatch is a stop condition that held for a tool result.
+type StopConditionMatch struct {
+	Condition config.StopCondition
+	Tool      string  // Tool whose result matched
+	Value     float64 // Field value, or percentage when the condition has "of"
+}
+
+// String describes the match for display, e.g. `low hull: ship.hull 15 < 20`.
+func (m StopConditionMatch) String() string {
+	c := m.Condition
+	field := c.Field
+	if c.Of != "" {
+		field = fmt.Sprintf("%s (%% of %s)", c.Field, c.Of)
+	}
+	return fmt.Sprintf("%s: %s %g %s %g", c.Name, field, m.Value, c.Op, c.Value)
+}
+
+// matchStopConditions returns the first condition that holds for a tool
+// result of the turn. Results that are not JSON objects are skipped.
+func matchStopConditions(conditions []config.StopCondition, result *llm.TurnResult) (StopConditionMatch, bool) {
+	if result == nil || len(conditions) == 0 {
+		return StopConditionMatch{}, false
+	}
+
+	// Tool result messages only carry the call ID
+	toolNames := make(map[string]string)
+	for _, msg := range result.Messages {
+		for _, tc := range msg.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+		}
+	}
+
+	for _, msg := range result.Messages {
+		if msg.Role != "tool" {
+			continue
+		}
+		var doc any
+		if err := json.Unmarshal([]byte(msg.Content), &doc); err != nil {
+			continue
+		}
+		tool := toolNames[msg.ToolCallID]
+		for _, c := range conditions {
+			if c.Tool != "" && c.Tool != tool {
+				continue
+			}
+			if value, ok := evalStopCondition(c, doc); ok {
+				return StopConditionMatch{Condition: c, Tool: tool, Value: value}, true
+			}
+		}
+	}
+	return StopConditionMatch{}, false
+}
+
+// evalStopCondition is the predicate for a single condition. It returns
+// the compared value and whether the condition holds; a missing or
+// non-numeric field never matches.
+func evalStopCondition(c config.StopCondition, doc any) (float64, bool) {
+	value, ok := lookupNumber(doc, c.Field)
+	if !ok {
+		return 0, false
+	}
+	if c.Of != "" {
+		total, ok := lookupNumber(doc, c.Of)
+		if !ok || total == 0 {
+			return 0, false
+		}
+		value = value / total * 100
+	}
+
+	switch c.Op {
+	case "<":
+		return value, value < c.Value
+	case "<=":
+		return value, value <= c.Value
+	case ">":
+		return value, value > c.Value
+	case ">=":
+		return value, value >= c.Value
+	case "==":
+		return value, value == c.Value
+	case "!=":
+		return value, value != c.Value
+	}
+	return value, false
+}
+
+// lookupNumber follows a dotted path through JSON objects and returns the
+// number at its end.
+func lookupNumber(doc any, path string) (float64, bool) {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return 0, false
+		}
+		if doc, ok = obj[key]; !ok {
+			return 0, false
+		}
+	}
+	n, ok := doc.(float64)
+	return n, ok
+}
@@ -0,0 +1,43 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestMatchStopConditions(t *testing.T) {
+	result := &llm.TurnResult{Messages: []provider.Message{
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "1", Name: "get_status"}, {ID: "2", Name: "mine"}}},
+		{Role: "tool", ToolCallID: "1", Content: `{"player": {"credits": 52000}, "ship": {"hull": 15, "max_hull": 100, "cargo_used": 40, "cargo_capacity": 40}}`},
+		{Role: "tool", ToolCallID: "2", Content: "Mined 5 iron ore"},
+	}}
+
+	tests := []struct {
+		name      string
+		condition config.StopCondition
+		want      bool
+		value     float64
+	}{
+		{"low hull", config.StopCondition{Field: "ship.hull", Of: "ship.max_hull", Op: "<", Value: 20}, true, 15},
+		{"hull ok", config.StopCondition{Field: "ship.hull", Of: "ship.max_hull", Op: "<", Value: 10}, false, 0},
+		{"cargo full", config.StopCondition{Field: "ship.cargo_used", Of: "ship.cargo_capacity", Op: ">=", Value: 100}, true, 100},
+		{"rich", config.StopCondition{Tool: "get_status", Field: "player.credits", Op: ">", Value: 50000}, true, 52000},
+		{"other tool", config.StopCondition{Tool: "get_ship", Field: "player.credits", Op: ">", Value: 50000}, false, 0},
+		{"missing field", config.StopCondition{Field: "ship.fuel", Op: "<", Value: 10}, false, 0},
+		{"not a number", config.StopCondition{Field: "ship", Op: "==", Value: 0}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := matchStopConditions([]config.StopCondition{tt.condition}, result)
+			if ok != tt.want {
+				t.Fatalf("matched = %v, want %v", ok, tt.want)
+			}
+			if ok && match.Value != tt.value {
+				t.Errorf("Value = %v, want %v", match.Value, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// templateVar matches a template variable such as {{session}}.
+var templateVar = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplatesDir returns the directory of system prompt templates,
+// templates/ under the data directory.
+func TemplatesDir() (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("get data directory: %w", err)
+	}
+	return filepath.Join(dataDir, "templates"), nil
+}
+
+// TemplateNames returns the names of the templates in dir, sorted.
+func TemplateNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read templates directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".md"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadTemplate loads the system prompt template name.md from dir and fills
+// in its variables, see RenderTemplate.
+func LoadTemplate(dir, name string, vars map[string]string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	path := filepath.Join(dir, name+".md")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		names, _ := TemplateNames(dir)
+		if len(names) == 0 {
+			return "", fmt.Errorf("template %q not found: no templates in %s", name, dir)
+		}
+		return "", fmt.Errorf("template %q not found in %s (available: %s)", name, dir, strings.Join(names, ", "))
+	}
+
+	content, err := LoadSystemPromptFromFile(path)
+	if err != nil {
+		return "", err
+	}
+	return RenderTemplate(content, vars)
+}
+
+// TemplateVars returns the built-in template variables of a session:
+// session, provider, model and today's date. An unnamed session is
+// "anonymous".
+func TemplateVars(session, providerName, model string) map[string]string {
+	if session == "" {
+		session = "anonymous"
+	}
+	return map[string]string{
+		"session":  session,
+		"provider": providerName,
+		"model":    model,
+		"date":     time.Now().Format(time.DateOnly),
+	}
+}
+
+// RenderTemplate replaces each {{name}} in text with vars[name]. A variable
+// without a value is an error, so a typo does not reach the LLM.
+func RenderTemplate(text string, vars map[string]string) (string, error) {
+	var missing []string
+	rendered := templateVar.ReplaceAllStringFunc(text, func(match string) string {
+		name := templateVar.FindStringSubmatch(match)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("template variables without a value: %s (set them with --var NAME=VALUE)", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+// ParseTemplateVar parses a --var NAME=VALUE flag value.
+func ParseTemplateVar(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || !templateVar.MatchString("{{"+name+"}}") {
+		return "", "", fmt.Errorf("--var %q: expected NAME=VALUE", s)
+	}
+	return name, value, nil
+}
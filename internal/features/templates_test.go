@@ -0,0 +1,63 @@
+package features
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	content := "You are {{session}}, a miner flying with {{ model }}. Mine {{ore}}.\n"
+	if err := os.WriteFile(filepath.Join(dir, "miner.md"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "trader.md"), []byte("Trade."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]string{"session": "bot1", "model": "qwen3", "ore": "iron"}
+	got, err := LoadTemplate(dir, "miner", vars)
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	if want := "You are bot1, a miner flying with qwen3. Mine iron."; got != want {
+		t.Errorf("LoadTemplate() = %q, want %q", got, want)
+	}
+
+	delete(vars, "ore")
+	if _, err := LoadTemplate(dir, "miner", vars); err == nil || !strings.Contains(err.Error(), "ore") {
+		t.Errorf("missing variable error = %v", err)
+	}
+
+	_, err = LoadTemplate(dir, "pirate", vars)
+	if err == nil || !strings.Contains(err.Error(), "available: miner, trader") {
+		t.Errorf("unknown template error = %v", err)
+	}
+	if _, err := LoadTemplate(dir, "../miner", vars); err == nil {
+		t.Error("LoadTemplate() accepted a path as name")
+	}
+}
+
+func TestParseTemplateVar(t *testing.T) {
+	name, value, err := ParseTemplateVar("home=Sol = 3")
+	if err != nil || name != "home" || value != "Sol = 3" {
+		t.Errorf("ParseTemplateVar() = %q, %q, %v", name, value, err)
+	}
+	for _, s := range []string{"home", "=Sol", "my-home=Sol"} {
+		if _, _, err := ParseTemplateVar(s); err == nil {
+			t.Errorf("ParseTemplateVar(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestTemplateVars(t *testing.T) {
+	vars := TemplateVars("", "ollama", "qwen3")
+	if vars["session"] != "anonymous" || vars["provider"] != "ollama" || vars["model"] != "qwen3" || vars["date"] == "" {
+		t.Errorf("TemplateVars() = %v", vars)
+	}
+	if got := TemplateVars("bot1", "", "")["session"]; got != "bot1" {
+		t.Errorf("session = %q, want bot1", got)
+	}
+}
@@ -0,0 +1,229 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// transcriptDir is the data dir subdirectory for saved transcripts.
+const transcriptDir = "transcripts"
+
+// Transcript formats, see ExportTranscript.
+const (
+	TranscriptMarkdown = "markdown"
+	TranscriptJSON     = "json"
+	TranscriptShare    = "share" // Redacted per-turn JSON, see BuildShareTranscript
+	TranscriptHTML     = "html"  // TranscriptShare as a web page
+)
+
+// TranscriptInfo describes the session a transcript was exported from.
+type TranscriptInfo struct {
+	Session  string // Session name, or "" for anonymous sessions
+	Provider string
+	Model    string
+	Goal     string // Autoplay goal, if any, for shareable transcripts
+}
+
+// RenderMarkdown renders a conversation as a Markdown transcript.
+// Tool results are fenced so game JSON keeps its formatting.
+func RenderMarkdown(info TranscriptInfo, messages []provider.Message) string {
+	var b strings.Builder
+
+	title := info.Session
+	if title == "" {
+		title = "anonymous session"
+	}
+	fmt.Fprintf(&b, "# Mysis transcript: %s\n\n", title)
+	fmt.Fprintf(&b, "_Provider: %s (%s), exported %s_\n", info.Provider, info.Model, time.Now().Format("2006-01-02 15:04"))
+
+	// Tool result messages only carry the call ID
+	toolNames := make(map[string]string)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+		}
+	}
+
+	for _, msg := range messages {
+		b.WriteString("\n")
+		switch msg.Role {
+		case "user":
+			fmt.Fprintf(&b, "## User%s\n\n%s\n", transcriptTime(msg.CreatedAt), msg.Content)
+		case "assistant":
+			fmt.Fprintf(&b, "## Assistant%s\n\n", transcriptTime(msg.CreatedAt))
+			if msg.Content != "" {
+				b.WriteString(msg.Content + "\n")
+			}
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(&b, "\n- Tool call: `%s(%s)`\n", tc.Name, string(tc.Arguments))
+			}
+		case "tool":
+			name := toolNames[msg.ToolCallID]
+			if name == "" {
+				name = "tool"
+			}
+			fmt.Fprintf(&b, "### Result: %s\n\n```\n%s\n```\n", name, strings.TrimRight(msg.Content, "\n"))
+		default:
+			fmt.Fprintf(&b, "## %s%s\n\n", transcriptRole(msg.Role), transcriptTime(msg.CreatedAt))
+			for line := range strings.SplitSeq(msg.Content, "\n") {
+				b.WriteString("> " + line + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// jsonTranscript is the JSON transcript of a session.
+type jsonTranscript struct {
+	Session    string                  `json:"session,omitempty"`
+	Provider   string                  `json:"provider"`
+	Model      string                  `json:"model"`
+	ExportedAt time.Time               `json:"exported_at"`
+	Messages   []jsonTranscriptMessage `json:"messages"`
+}
+
+// jsonTranscriptMessage is a message of a JSON transcript. Tool results
+// carry the name of their tool call.
+type jsonTranscriptMessage struct {
+	Role       string              `json:"role"`
+	Content    string              `json:"content,omitempty"`
+	Reasoning  string              `json:"reasoning,omitempty"`
+	ToolCalls  []provider.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string              `json:"tool_call_id,omitempty"`
+	Tool       string              `json:"tool,omitempty"`
+	CreatedAt  *time.Time          `json:"created_at,omitempty"`
+}
+
+// RenderJSON renders a conversation as an indented JSON transcript.
+func RenderJSON(info TranscriptInfo, messages []provider.Message) ([]byte, error) {
+	toolNames := make(map[string]string)
+	out := jsonTranscript{
+		Session:    info.Session,
+		Provider:   info.Provider,
+		Model:      info.Model,
+		ExportedAt: time.Now(),
+		Messages:   make([]jsonTranscriptMessage, len(messages)),
+	}
+	for i, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+		}
+		m := jsonTranscriptMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Reasoning:  msg.Reasoning,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+			Tool:       toolNames[msg.ToolCallID],
+		}
+		if !msg.CreatedAt.IsZero() {
+			m.CreatedAt = &msg.CreatedAt
+		}
+		out.Messages[i] = m
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// transcriptExtensions are the file extensions of the transcript formats.
+var transcriptExtensions = map[string]string{
+	TranscriptMarkdown: ".md",
+	TranscriptJSON:     ".json",
+	TranscriptShare:    ".share.json",
+	TranscriptHTML:     ".html",
+}
+
+// ExportTranscript writes a conversation as a transcript in format,
+// TranscriptMarkdown, TranscriptJSON, TranscriptShare or TranscriptHTML, to
+// path, or by default to the TranscriptPath of the session with the
+// format's extension. Returns the path written.
+func ExportTranscript(info TranscriptInfo, messages []provider.Message, format, path string) (string, error) {
+	data, err := RenderTranscript(info, messages, format)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		if path, err = TranscriptPath(info, time.Now()); err != nil {
+			return "", err
+		}
+		path = strings.TrimSuffix(path, ".md") + transcriptExtensions[format]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("create transcript dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("write transcript: %w", err)
+	}
+	return path, nil
+}
+
+// RenderTranscript renders a conversation as a transcript in format, see
+// ExportTranscript.
+func RenderTranscript(info TranscriptInfo, messages []provider.Message, format string) ([]byte, error) {
+	switch format {
+	case TranscriptMarkdown:
+		return []byte(RenderMarkdown(info, messages)), nil
+	case TranscriptJSON:
+		return RenderJSON(info, messages)
+	case TranscriptShare:
+		return RenderShareJSON(info, messages)
+	case TranscriptHTML:
+		return RenderShareHTML(info, messages)
+	default:
+		return nil, fmt.Errorf("unknown transcript format %q", format)
+	}
+}
+
+// ShareFormatFor returns the shareable format of a file: TranscriptHTML for
+// .html and .htm files, TranscriptShare otherwise.
+func ShareFormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return TranscriptHTML
+	default:
+		return TranscriptShare
+	}
+}
+
+// TranscriptPath returns the default path of a saved transcript in the
+// data dir, named after the session and the time.
+func TranscriptPath(info TranscriptInfo, now time.Time) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	name := info.Session
+	if name == "" {
+		name = "session"
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	return filepath.Join(dir, transcriptDir, name+"-"+now.Format("20060102-150405")+".md"), nil
+}
+
+// transcriptTime formats a message timestamp as a heading suffix.
+func transcriptTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return " (" + t.Format("2006-01-02 15:04") + ")"
+}
+
+// transcriptRole capitalizes a role name for headings.
+func transcriptRole(role string) string {
+	if role == "" {
+		return "Message"
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
@@ -0,0 +1,72 @@
+package features
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	out := RenderMarkdown(TranscriptInfo{Session: "mybot", Provider: "ollama", Model: "qwen"}, []provider.Message{
+		{Role: "system", Content: "You are a miner.\nBe careful."},
+		{Role: "user", Content: "mine some ore"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "c1", Name: "mine", Arguments: json.RawMessage(`{"belt":1}`)}}},
+		{Role: "tool", ToolCallID: "c1", Content: `{"ore":5}`},
+		{Role: "assistant", Content: "Mined 5 ore."},
+	})
+
+	for _, want := range []string{
+		"# Mysis transcript: mybot",
+		"_Provider: ollama (qwen)",
+		"## System\n\n> You are a miner.\n> Be careful.\n",
+		"## User\n\nmine some ore\n",
+		"- Tool call: `mine({\"belt\":1})`",
+		"### Result: mine\n\n```\n{\"ore\":5}\n```\n",
+		"## Assistant\n\nMined 5 ore.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportTranscriptJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "mybot.json")
+	messages := []provider.Message{
+		{Role: "user", Content: "mine some ore"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "c1", Name: "mine", Arguments: json.RawMessage(`{"belt":1}`)}}},
+		{Role: "tool", ToolCallID: "c1", Content: `{"ore":5}`},
+	}
+	got, err := ExportTranscript(TranscriptInfo{Session: "mybot", Provider: "ollama", Model: "qwen"}, messages, TranscriptJSON, path)
+	if err != nil || got != path {
+		t.Fatalf("ExportTranscript() = %q, %v; want %q", got, err, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transcript struct {
+		Session  string
+		Messages []struct {
+			Role      string
+			Tool      string
+			ToolCalls []provider.ToolCall `json:"tool_calls"`
+		}
+	}
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if transcript.Session != "mybot" || len(transcript.Messages) != 3 ||
+		transcript.Messages[1].ToolCalls[0].Name != "mine" || transcript.Messages[2].Tool != "mine" {
+		t.Errorf("transcript = %+v", transcript)
+	}
+
+	if _, err := ExportTranscript(TranscriptInfo{}, messages, "pdf", path); err == nil {
+		t.Error("unknown format: want an error")
+	}
+}
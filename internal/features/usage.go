@@ -0,0 +1,134 @@
+package features
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// Usage report groupings, see UsageReport.
+const (
+	UsageBySession  = "session"
+	UsageByDay      = "day"
+	UsageByProvider = "provider"
+)
+
+// UsageRow is the usage of a group of LLM calls.
+type UsageRow struct {
+	Key          string  `json:"key"` // Session name, day (2006-01-02) or provider
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"` // USD, zero for providers without pricing
+}
+
+// Tokens returns input plus output tokens.
+func (r UsageRow) Tokens() int {
+	return r.InputTokens + r.OutputTokens
+}
+
+// ReadLLMCalls returns the llm_call events since the given time from the
+// event logs of all sessions, see EventLogPath. Unreadable lines are
+// skipped.
+func ReadLLMCalls(since time.Time) ([]llm.Event, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, sessionsDir, "*", "events.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var calls []llm.Event
+	for _, path := range paths {
+		if calls, err = appendLLMCalls(calls, path, since); err != nil {
+			return nil, err
+		}
+	}
+	return calls, nil
+}
+
+// appendLLMCalls appends the llm_call events since the given time of the
+// event log at path to calls.
+func appendLLMCalls(calls []llm.Event, path string, since time.Time) ([]llm.Event, error) {
+	//nolint:gosec // G304: Path from the data directory
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return calls, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	skipped := 0
+	for scanner.Scan() {
+		var e llm.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			skipped++
+			continue
+		}
+		if e.Type == llm.EventLLMCall && !e.Time.Before(since) {
+			calls = append(calls, e)
+		}
+	}
+	if skipped > 0 {
+		log.Warn().Int("lines", skipped).Str("path", path).Msg("Skipped unreadable event log lines")
+	}
+	return calls, scanner.Err()
+}
+
+// UsageReport sums the usage of LLM calls grouped by session, day or
+// provider: days in order and local time, others most expensive first,
+// then by tokens. sessionName names a session ID.
+func UsageReport(calls []llm.Event, by string, sessionName func(id string) string) ([]UsageRow, error) {
+	var key func(llm.Event) string
+	switch by {
+	case UsageBySession:
+		key = func(e llm.Event) string { return sessionName(e.Session) }
+	case UsageByDay:
+		key = func(e llm.Event) string { return e.Time.Local().Format("2006-01-02") }
+	case UsageByProvider:
+		key = func(e llm.Event) string { return e.Provider }
+	default:
+		return nil, fmt.Errorf("--by=%s must be %s, %s or %s", by, UsageBySession, UsageByDay, UsageByProvider)
+	}
+
+	rows := make(map[string]*UsageRow)
+	for _, e := range calls {
+		k := key(e)
+		row, ok := rows[k]
+		if !ok {
+			row = &UsageRow{Key: k}
+			rows[k] = row
+		}
+		row.Calls++
+		row.InputTokens += e.InputTokens
+		row.OutputTokens += e.OutputTokens
+		row.Cost += e.Cost
+	}
+
+	report := make([]UsageRow, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, *row)
+	}
+	slices.SortFunc(report, func(a, b UsageRow) int {
+		if by == UsageByDay {
+			return cmp.Compare(a.Key, b.Key)
+		}
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), cmp.Compare(b.Tokens(), a.Tokens()), cmp.Compare(a.Key, b.Key))
+	})
+	return report, nil
+}
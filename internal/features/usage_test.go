@@ -0,0 +1,78 @@
+package features
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+func TestUsageReport(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	calls := []llm.Event{
+		{Time: day1, Session: "a", Provider: "zen", InputTokens: 100, OutputTokens: 10, Cost: 0.01},
+		{Time: day2, Session: "a", Provider: "zen", InputTokens: 100, OutputTokens: 10, Cost: 0.01},
+		{Time: day2, Session: "b", Provider: "ollama", InputTokens: 500, OutputTokens: 50},
+	}
+	names := func(id string) string { return "bot-" + id }
+
+	bySession, err := UsageReport(calls, UsageBySession, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bySession) != 2 || bySession[0].Key != "bot-a" || bySession[0].Calls != 2 || bySession[0].Cost != 0.02 {
+		t.Errorf("by session = %+v, want bot-a first with 2 calls and $0.02", bySession)
+	}
+
+	byDay, err := UsageReport(calls, UsageByDay, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byDay) != 2 || byDay[0].Key != "2026-03-01" || byDay[1].Tokens() != 660 {
+		t.Errorf("by day = %+v, want 2026-03-01 first and 660 tokens on the second day", byDay)
+	}
+
+	if _, err := UsageReport(calls, "model", names); err == nil {
+		t.Error("UsageReport() by an unknown grouping succeeded")
+	}
+}
+
+func TestReadLLMCalls(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := EventLogPath("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := llm.OpenEventLog(path, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events.Record(llm.Event{Type: llm.EventTurnStarted})
+	events.Record(llm.Event{Type: llm.EventLLMCall, InputTokens: 10})
+	if err := events.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("{not json\n")
+	_ = f.Close()
+
+	calls, err := ReadLLMCalls(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0].InputTokens != 10 || calls[0].Session != "s1" {
+		t.Errorf("ReadLLMCalls() = %+v, want the one llm_call", calls)
+	}
+	if calls, _ := ReadLLMCalls(time.Now().Add(time.Hour)); len(calls) != 0 {
+		t.Errorf("ReadLLMCalls() since later = %d calls, want none", len(calls))
+	}
+	if filepath.Base(path) != "events.jsonl" {
+		t.Errorf("EventLogPath() = %s, want events.jsonl", path)
+	}
+}
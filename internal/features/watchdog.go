@@ -0,0 +1,145 @@
+package features
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+	"github.com/xonecas/mysis/internal/llm"
+)
+
+// Watchdog match kinds.
+const (
+	WatchdogRepeatedCalls = "repeated tool calls"
+	WatchdogNoChange      = "no state change"
+)
+
+// WatchdogMatch describes why the watchdog considers autoplay stuck.
+type WatchdogMatch struct {
+	Kind  string // WatchdogRepeatedCalls or WatchdogNoChange
+	Turns int    // Identical turns in a row
+	Calls string // Tool calls of the last turn, e.g. `mine({"belt":1})`
+}
+
+// String describes the match for display.
+func (m WatchdogMatch) String() string {
+	return fmt.Sprintf("%s for %d turns: %s", m.Kind, m.Turns, m.Calls)
+}
+
+// Nudge is the corrective system message sent to the model.
+func (m WatchdogMatch) Nudge() string {
+	return fmt.Sprintf("Watchdog: your last %d turns were identical (%s: %s). "+
+		"That approach is not working. Do not repeat it - check your current state "+
+		"with a query tool and try a different action, or explain what is blocking you.",
+		m.Turns, m.Kind, m.Calls)
+}
+
+// watchdog detects degenerate autoplay turns. It fingerprints the tool calls
+// and the tool results of each turn; a fingerprint repeated for `repeats`
+// turns in a row counts as stuck. Turns without tool calls reset it.
+type watchdog struct {
+	repeats     int
+	action      string
+	lastCalls   string
+	lastResults string
+	sameCalls   int // Turns in a row with lastCalls
+	sameResults int // Turns in a row with lastResults
+	nudged      bool
+}
+
+func newWatchdog(cfg config.WatchdogConfig) *watchdog {
+	w := &watchdog{repeats: cfg.Repeats, action: cfg.Action}
+	if w.repeats <= 0 {
+		w.repeats = constants.AutoplayWatchdogRepeats
+	}
+	if w.action == "" {
+		w.action = config.WatchdogActionNudge
+	}
+	return w
+}
+
+// reset forgets the previous turns, e.g. when a new run starts.
+func (w *watchdog) reset() {
+	*w = watchdog{repeats: w.repeats, action: w.action}
+}
+
+// observe records a turn and reports whether autoplay is stuck. After a
+// match the streak restarts, so a nudge gets `repeats` turns to take effect.
+func (w *watchdog) observe(result *llm.TurnResult) (WatchdogMatch, bool) {
+	if w.action == config.WatchdogActionOff {
+		return WatchdogMatch{}, false
+	}
+
+	calls, results := turnFingerprints(result)
+	if calls == "" {
+		w.reset()
+		return WatchdogMatch{}, false
+	}
+
+	if calls == w.lastCalls {
+		w.sameCalls++
+	} else {
+		w.lastCalls = calls
+		w.sameCalls = 1
+		w.nudged = false
+	}
+	if results == w.lastResults {
+		w.sameResults++
+	} else {
+		w.lastResults = results
+		w.sameResults = 1
+	}
+
+	var match WatchdogMatch
+	switch {
+	case w.sameCalls >= w.repeats:
+		match = WatchdogMatch{Kind: WatchdogRepeatedCalls, Turns: w.sameCalls, Calls: calls}
+	case w.sameResults >= w.repeats:
+		match = WatchdogMatch{Kind: WatchdogNoChange, Turns: w.sameResults, Calls: calls}
+	default:
+		return WatchdogMatch{}, false
+	}
+	w.sameCalls = 0
+	w.sameResults = 0
+	return match, true
+}
+
+// nextAction returns what to do about a match: nudging once per streak,
+// then stopping if the loop continues.
+func (w *watchdog) nextAction() string {
+	if w.action != config.WatchdogActionNudge {
+		return w.action
+	}
+	if w.nudged {
+		return config.StopActionStop
+	}
+	w.nudged = true
+	return config.WatchdogActionNudge
+}
+
+// turnFingerprints returns the tool calls of a turn with compacted
+// arguments, and its tool results, each joined into one string.
+func turnFingerprints(result *llm.TurnResult) (calls, results string) {
+	if result == nil {
+		return "", ""
+	}
+
+	var callParts, resultParts []string
+	for _, msg := range result.Messages {
+		for _, tc := range msg.ToolCalls {
+			args := tc.Arguments
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, args); err == nil {
+				args = compact.Bytes()
+			}
+			callParts = append(callParts, tc.Name+"("+string(args)+")")
+		}
+		if msg.Role == "tool" {
+			resultParts = append(resultParts, msg.Content)
+		}
+	}
+	return strings.Join(callParts, ", "), strings.Join(resultParts, "\x00")
+}
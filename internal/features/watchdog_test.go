@@ -0,0 +1,86 @@
+package features
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func watchdogTurn(name, args, output string) *llm.TurnResult {
+	return &llm.TurnResult{Messages: []provider.Message{
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "1", Name: name, Arguments: json.RawMessage(args)}}},
+		{Role: "tool", ToolCallID: "1", Content: output},
+	}}
+}
+
+func TestWatchdogRepeatedCalls(t *testing.T) {
+	w := newWatchdog(config.WatchdogConfig{Repeats: 3})
+
+	// Whitespace in the arguments does not hide a repeat
+	turns := []*llm.TurnResult{
+		watchdogTurn("mine", `{"belt": 1}`, "cargo full"),
+		watchdogTurn("mine", `{"belt":1}`, "cargo full"),
+		watchdogTurn("mine", `{ "belt":1 }`, "cargo full"),
+	}
+	for i, turn := range turns {
+		match, ok := w.observe(turn)
+		if i < 2 && ok {
+			t.Fatalf("turn %d: unexpected match %v", i+1, match)
+		}
+		if i == 2 {
+			if !ok || match.Kind != WatchdogRepeatedCalls || match.Turns != 3 {
+				t.Fatalf("turn 3: match = %+v, %v; want repeated calls for 3 turns", match, ok)
+			}
+			if match.Calls != `mine({"belt":1})` {
+				t.Errorf("Calls = %q", match.Calls)
+			}
+		}
+	}
+
+	// The first match nudges, a continued loop stops
+	if got := w.nextAction(); got != config.WatchdogActionNudge {
+		t.Errorf("first action = %q, want nudge", got)
+	}
+	for range 2 {
+		if _, ok := w.observe(turns[0]); ok {
+			t.Fatal("match before the streak repeated again")
+		}
+	}
+	if _, ok := w.observe(turns[0]); !ok {
+		t.Fatal("no match after the nudge was ignored")
+	}
+	if got := w.nextAction(); got != config.StopActionStop {
+		t.Errorf("second action = %q, want stop", got)
+	}
+}
+
+func TestWatchdogNoChange(t *testing.T) {
+	w := newWatchdog(config.WatchdogConfig{Repeats: 2})
+
+	if _, ok := w.observe(watchdogTurn("travel", `{"to":"a"}`, "error: not docked")); ok {
+		t.Fatal("unexpected match on first turn")
+	}
+	match, ok := w.observe(watchdogTurn("travel", `{"to":"b"}`, "error: not docked"))
+	if !ok || match.Kind != WatchdogNoChange {
+		t.Fatalf("match = %+v, %v; want no state change", match, ok)
+	}
+}
+
+func TestWatchdogResets(t *testing.T) {
+	w := newWatchdog(config.WatchdogConfig{Repeats: 2})
+	turn := watchdogTurn("mine", `{}`, "ok")
+
+	w.observe(turn)
+	w.observe(&llm.TurnResult{}) // Text-only turn breaks the streak
+	if _, ok := w.observe(turn); ok {
+		t.Error("match across a turn without tool calls")
+	}
+
+	off := newWatchdog(config.WatchdogConfig{Repeats: 1, Action: config.WatchdogActionOff})
+	if _, ok := off.observe(turn); ok {
+		t.Error("match with the watchdog off")
+	}
+}
@@ -0,0 +1,213 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/constants"
+)
+
+// WebhookPayload is the JSON body POSTed to autoplay webhooks.
+type WebhookPayload struct {
+	Event   string    `json:"event"` // One of config.WebhookEvents
+	Time    time.Time `json:"time"`
+	Session string    `json:"session,omitempty"` // Fleet bot session name
+	Message string    `json:"message,omitempty"` // Current autoplay goal, or the finished one on "goal"
+
+	// Set on "stopped"
+	Reason          string  `json:"reason,omitempty"`
+	Turns           int     `json:"turns,omitempty"`
+	FailedTurns     int     `json:"failed_turns,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	Tokens          int     `json:"tokens,omitempty"`
+	Cost            float64 `json:"cost,omitempty"`
+
+	// Set on "error" and "breaker"
+	Error             string     `json:"error,omitempty"`
+	ConsecutiveErrors int        `json:"consecutive_errors,omitempty"`
+	BreakerUntil      *time.Time `json:"breaker_until,omitempty"`
+
+	// Set on "failover"
+	Provider string `json:"provider,omitempty"`
+
+	// Set on "goal"
+	Goal  int `json:"goal,omitempty"` // Finished goal, from 1
+	Goals int `json:"goals,omitempty"`
+
+	// Set on "milestone", with Turns and Cost
+	Milestone string `json:"milestone,omitempty"` // e.g. "100 turns"
+}
+
+// chatMessageLimit is the length of a Discord message, also applied to Slack.
+const chatMessageLimit = 2000
+
+// Summary returns a one-line description of the event for chat webhooks.
+func (p WebhookPayload) Summary() string {
+	var text string
+	switch p.Event {
+	case config.WebhookEventStarted:
+		text = fmt.Sprintf("Autoplay started: %q", p.Message)
+	case config.WebhookEventStopped:
+		text = fmt.Sprintf("Autoplay stopped (%s) after %d turns", p.Reason, p.Turns)
+		if p.FailedTurns > 0 {
+			text += fmt.Sprintf(" (%d failed)", p.FailedTurns)
+		}
+		text += fmt.Sprintf(" in %s, %d tokens", time.Duration(p.DurationSeconds*float64(time.Second)).Round(time.Second), p.Tokens)
+		if p.Cost > 0 {
+			text += fmt.Sprintf(", $%.4f", p.Cost)
+		}
+	case config.WebhookEventError:
+		text = fmt.Sprintf("Autoplay turn failed (%d in a row): %s", p.ConsecutiveErrors, p.Error)
+	case config.WebhookEventBreaker:
+		text = fmt.Sprintf("Circuit breaker tripped after %d failed turns", p.ConsecutiveErrors)
+		if p.BreakerUntil != nil {
+			text += ", retrying at " + p.BreakerUntil.Local().Format("15:04:05")
+		} else {
+			text += ", autoplay stopped"
+		}
+	case config.WebhookEventAlert:
+		text = "Alert: " + p.Error
+	case config.WebhookEventFailover:
+		text = "Failed over to " + p.Provider
+	case config.WebhookEventGoal:
+		text = fmt.Sprintf("Goal %d/%d finished: %q", p.Goal, p.Goals, p.Message)
+	case config.WebhookEventMilestone:
+		text = fmt.Sprintf("Milestone reached: %s (%d turns", p.Milestone, p.Turns)
+		if p.Cost > 0 {
+			text += fmt.Sprintf(", $%.4f", p.Cost)
+		}
+		text += ")"
+	default:
+		text = p.Event
+	}
+	if p.Session != "" {
+		text = "[" + p.Session + "] " + text
+	}
+	if runes := []rune(text); len(runes) > chatMessageLimit {
+		text = string(runes[:chatMessageLimit-3]) + "..."
+	}
+	return text
+}
+
+// reachedMilestone returns the milestones of cfg crossed by a turn that
+// took the run to turns and its cost from prevCost to cost, or "".
+func reachedMilestone(cfg config.MilestoneConfig, turns int, prevCost, cost float64) string {
+	var reached []string
+	if cfg.Turns > 0 && turns > 0 && turns%cfg.Turns == 0 {
+		reached = append(reached, fmt.Sprintf("%d turns", turns))
+	}
+	if cfg.Cost > 0 {
+		if step := math.Floor(cost / cfg.Cost); step > math.Floor(prevCost/cfg.Cost) {
+			reached = append(reached, fmt.Sprintf("$%.2f", step*cfg.Cost))
+		}
+	}
+	return strings.Join(reached, ", ")
+}
+
+// webhookNotifier POSTs lifecycle events to the configured webhooks.
+// Delivery is best effort: failures are logged and never affect autoplay.
+type webhookNotifier struct {
+	hooks  []config.WebhookConfig
+	client *http.Client
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	session string // Stamped on every payload
+}
+
+func newWebhookNotifier(hooks []config.WebhookConfig) *webhookNotifier {
+	return &webhookNotifier{
+		hooks:  hooks,
+		client: &http.Client{Timeout: constants.DefaultTimeout},
+	}
+}
+
+func (n *webhookNotifier) setSession(name string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.session = name
+}
+
+// notify sends the payload to every webhook subscribed to its event in the
+// background. Call wait to block until delivery finishes.
+func (n *webhookNotifier) notify(payload WebhookPayload) {
+	if payload.Time.IsZero() {
+		payload.Time = time.Now()
+	}
+	n.mu.Lock()
+	payload.Session = n.session
+	n.mu.Unlock()
+
+	for _, hook := range n.hooks {
+		if !hook.Wants(payload.Event) {
+			continue
+		}
+		if payload.Event == config.WebhookEventError && payload.ConsecutiveErrors < hook.MinErrors {
+			continue
+		}
+		body, err := webhookBody(hook.Format, payload)
+		if err != nil {
+			log.Warn().Err(err).Str("url", hook.URL).Msg("Failed to encode webhook payload")
+			continue
+		}
+		n.wg.Add(1)
+		go func(hook config.WebhookConfig) {
+			defer n.wg.Done()
+			if err := n.post(hook, body); err != nil {
+				log.Warn().Err(err).Str("url", hook.URL).Str("event", payload.Event).Msg("Autoplay webhook failed")
+			}
+		}(hook)
+	}
+}
+
+// webhookBody encodes payload in a webhook's format.
+func webhookBody(format string, payload WebhookPayload) ([]byte, error) {
+	switch format {
+	case config.WebhookFormatDiscord:
+		return json.Marshal(map[string]string{"content": payload.Summary()})
+	case config.WebhookFormatSlack:
+		return json.Marshal(map[string]string{"text": payload.Summary()})
+	default:
+		return json.Marshal(payload)
+	}
+}
+
+// wait blocks until all pending deliveries finish.
+func (n *webhookNotifier) wait() {
+	n.wg.Wait()
+}
+
+func (n *webhookNotifier) post(hook config.WebhookConfig, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constants.AppName)
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
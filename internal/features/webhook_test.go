@@ -0,0 +1,107 @@
+package features
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/config"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		mu.Lock()
+		events = append(events, payload.Event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	n := newWebhookNotifier([]config.WebhookConfig{{
+		URL:     server.URL,
+		Events:  []string{config.WebhookEventStopped, config.WebhookEventBreaker},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}})
+	n.notify(WebhookPayload{Event: config.WebhookEventStarted})
+	n.notify(WebhookPayload{Event: config.WebhookEventBreaker})
+	n.notify(WebhookPayload{Event: config.WebhookEventStopped, Reason: StopReasonUser})
+	n.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("events = %v, want breaker and stopped only", events)
+	}
+}
+
+func TestWebhookNotifierChatFormats(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make(map[string]map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	n := newWebhookNotifier([]config.WebhookConfig{
+		{URL: server.URL + "/discord", Format: config.WebhookFormatDiscord, MinErrors: 3},
+		{URL: server.URL + "/slack", Format: config.WebhookFormatSlack, MinErrors: 3},
+	})
+	n.setSession("miner")
+	n.notify(WebhookPayload{Event: config.WebhookEventError, Error: "timeout", ConsecutiveErrors: 2})
+	n.wait()
+	if len(bodies) != 0 {
+		t.Fatalf("error below min_errors was sent: %v", bodies)
+	}
+
+	n.notify(WebhookPayload{Event: config.WebhookEventError, Error: "timeout", ConsecutiveErrors: 3})
+	n.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := "[miner] Autoplay turn failed (3 in a row): timeout"
+	if got := bodies["/discord"]["content"]; got != want {
+		t.Errorf("discord content = %q, want %q", got, want)
+	}
+	if got := bodies["/slack"]["text"]; got != want {
+		t.Errorf("slack text = %q, want %q", got, want)
+	}
+}
+
+func TestReachedMilestone(t *testing.T) {
+	cfg := config.MilestoneConfig{Turns: 50, Cost: 1}
+	tests := []struct {
+		turns          int
+		prevCost, cost float64
+		want           string
+	}{
+		{49, 0.2, 0.3, ""},
+		{50, 0.3, 0.4, "50 turns"},
+		{51, 0.9, 1.2, "$1.00"},
+		{100, 1.9, 3.1, "100 turns, $3.00"},
+		{101, 3.1, 3.1, ""},
+	}
+	for _, tt := range tests {
+		if got := reachedMilestone(cfg, tt.turns, tt.prevCost, tt.cost); got != tt.want {
+			t.Errorf("reachedMilestone(%d, %v, %v) = %q, want %q", tt.turns, tt.prevCost, tt.cost, got, tt.want)
+		}
+	}
+	if got := reachedMilestone(config.MilestoneConfig{}, 100, 0, 5); got != "" {
+		t.Errorf("disabled milestones = %q", got)
+	}
+}
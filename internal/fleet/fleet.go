@@ -0,0 +1,480 @@
+// Package fleet runs autoplay for several sessions concurrently in one process.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/session"
+)
+
+// EventFunc receives display-agnostic progress lines for a bot.
+type EventFunc func(session, text string)
+
+// Orchestrator runs the bots of config.FleetConfig, each with its own
+// session, provider instance, MCP proxy and autoplay service.
+type Orchestrator struct {
+	cfg        *config.Config
+	sessionMgr *session.Manager
+	registry   *provider.Registry
+	creds      mcp.CredentialStore
+	onEvent    EventFunc
+	stream     *Stream
+	scheduler  *scheduler
+
+	mu        sync.Mutex
+	bots      []*bot
+	startedAt time.Time
+	running   sync.WaitGroup // Bots whose autoplay has not stopped yet
+}
+
+// New creates an orchestrator. creds stores per-session game credentials
+// for the credential tools; onEvent may be nil.
+func New(cfg *config.Config, sessionMgr *session.Manager, registry *provider.Registry, creds mcp.CredentialStore, onEvent EventFunc) *Orchestrator {
+	if onEvent == nil {
+		onEvent = func(string, string) {}
+	}
+	return &Orchestrator{
+		cfg:        cfg,
+		sessionMgr: sessionMgr,
+		registry:   registry,
+		creds:      creds,
+		onEvent:    onEvent,
+		stream:     NewStream(),
+		scheduler:  newScheduler(cfg.Fleet.MaxConcurrent, cfg.Fleet.Stagger),
+	}
+}
+
+// Stream returns the stream of the bots' messages, tool calls and status.
+func (o *Orchestrator) Stream() *Stream {
+	return o.stream
+}
+
+// Start sets up every configured bot and starts its autoplay. A bot that
+// fails to start is reported as failed while the others keep running;
+// an error is returned only if no bot could be started.
+func (o *Orchestrator) Start(ctx context.Context) error {
+	if len(o.cfg.Fleet.Bots) == 0 {
+		return errors.New("no fleet bots configured - add [[fleet.bot]] entries to the config, or [[bot]] entries to the fleet file")
+	}
+
+	o.mu.Lock()
+	o.startedAt = time.Now()
+	o.mu.Unlock()
+
+	started := 0
+	for _, botCfg := range o.cfg.Fleet.Bots {
+		b := &bot{cfg: botCfg, orch: o, state: StateStarting}
+		o.mu.Lock()
+		o.bots = append(o.bots, b)
+		o.mu.Unlock()
+
+		if err := b.start(ctx); err != nil {
+			log.Error().Err(err).Str("session", botCfg.Session).Msg("Failed to start fleet bot")
+			b.fail(err)
+			b.event("failed to start: " + err.Error())
+			continue
+		}
+		started++
+	}
+
+	if started == 0 {
+		o.Close()
+		return errors.New("no fleet bot could be started")
+	}
+	return nil
+}
+
+// Status returns the state of all bots.
+func (o *Orchestrator) Status() Snapshot {
+	o.mu.Lock()
+	bots := append([]*bot(nil), o.bots...)
+	snapshot := Snapshot{
+		PID:       os.Getpid(),
+		StartedAt: o.startedAt,
+		UpdatedAt: time.Now(),
+	}
+	o.mu.Unlock()
+
+	for _, b := range bots {
+		snapshot.Bots = append(snapshot.Bots, b.status())
+	}
+	return snapshot
+}
+
+// Stop stops the autoplay of every bot.
+func (o *Orchestrator) Stop() {
+	o.mu.Lock()
+	bots := append([]*bot(nil), o.bots...)
+	o.mu.Unlock()
+
+	for _, b := range bots {
+		if b.svc != nil {
+			_ = b.svc.Stop()
+		}
+	}
+}
+
+// Wait blocks until the autoplay of every bot has stopped.
+func (o *Orchestrator) Wait() {
+	o.running.Wait()
+}
+
+// Close releases the providers and game connections of all bots.
+func (o *Orchestrator) Close() {
+	o.mu.Lock()
+	bots := append([]*bot(nil), o.bots...)
+	o.mu.Unlock()
+
+	for _, b := range bots {
+		b.close()
+	}
+}
+
+// bot is one fleet session.
+type bot struct {
+	cfg  config.FleetBot
+	orch *Orchestrator
+
+	providerName string
+	model        string
+	sessionID    string
+	prov         provider.Provider
+	proxy        *mcp.Proxy
+	tools        []mcp.Tool
+	svc          *features.Service
+	events       *llm.EventLog
+	failures     *llm.FailureTracker // Repeated tool failures across turns
+	script       *features.Script    // From [scripts], nil for none
+
+	mu         sync.Mutex
+	history    []provider.Message
+	state      string
+	stopReason string
+	lastError  string
+}
+
+// start creates the bot's session, provider and proxy and starts autoplay.
+func (b *bot) start(ctx context.Context) error {
+	o := b.orch
+	name := b.cfg.Session
+
+	selected, err := o.sessionMgr.SelectProvider(o.cfg, name, b.cfg.Provider)
+	if err != nil {
+		return err
+	}
+	providerCfg, ok := o.cfg.Providers[selected.Provider]
+	if !ok {
+		return fmt.Errorf("provider '%s' not found in config", selected.Provider)
+	}
+	b.providerName = selected.Provider
+	b.model = selected.Model
+
+	b.prov, err = o.registry.Create(selected.Provider, selected.Model, features.ProviderOptions(providerCfg))
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	b.proxy = mcp.NewProxy(mcp.NewUpstream(o.cfg.MCP.Upstream))
+	if err := b.proxy.Initialize(ctx); err != nil {
+		log.Warn().Err(err).Str("session", name).Msg("Failed to initialize MCP - continuing without game tools")
+	}
+
+	result, err := o.sessionMgr.Initialize(name, selected.Provider, selected.Model)
+	if err != nil {
+		return err
+	}
+	b.sessionID = result.SessionID
+	b.events = features.OpenEventLog(b.sessionID)
+	b.failures = llm.NewFailureTracker()
+
+	b.proxy.RegisterTool(mcp.NewSaveCredentialsTool(), mcp.MakeSaveCredentialsHandler(o.creds, b.sessionID))
+	b.proxy.RegisterTool(mcp.NewGetCredentialsTool(), mcp.MakeGetCredentialsHandler(o.creds, b.sessionID))
+	b.proxy.RegisterTool(mcp.NewSendToAgentTool(), mcp.MakeSendToAgentHandler(o.sessionMgr, b.sessionID))
+	b.proxy.RegisterTool(mcp.NewReadAgentInboxTool(), mcp.MakeReadAgentInboxHandler(o.sessionMgr, b.sessionID))
+	features.RegisterPluginTools(b.proxy, features.Plugins())
+
+	b.tools, err = b.proxy.ListTools(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("session", name).Msg("Failed to list tools - continuing without tools")
+	}
+
+	history, err := o.sessionMgr.LoadHistory(b.sessionID)
+	if err != nil {
+		return err
+	}
+	if b.cfg.SystemFile != "" {
+		prompt, err := features.LoadSystemPromptFromFile(b.cfg.SystemFile)
+		if err != nil {
+			return err
+		}
+		if !features.HistoryHasSystemPrompt(history, prompt) {
+			history = features.PrependSystemPrompt(history, prompt)
+		}
+	} else if _, ok := features.SystemPrompt(history); !ok {
+		// Like system_file, the configured default applies on every run
+		prompt, err := features.DefaultSystemPrompt(o.cfg, selected.Provider)
+		if err != nil {
+			return err
+		}
+		if prompt != "" {
+			history = features.PrependSystemPrompt(history, prompt)
+		}
+	}
+	b.history = history
+
+	if b.script, err = features.SessionScript(o.cfg, name); err != nil {
+		return err
+	}
+
+	var playbook *features.Playbook
+	if b.cfg.Playbook != "" {
+		if playbook, err = features.LoadPlaybook(b.cfg.Playbook); err != nil {
+			return err
+		}
+	}
+
+	b.svc = features.NewAutoplayService(o.cfg.Autoplay, b.callbacks())
+	b.svc.SetToolGate(b.proxy)
+	b.svc.SetScript(b.script)
+	b.svc.SetSession(name)
+	b.svc.SetDefaultLimits(features.AutoplayLimits{
+		MaxTurns:    b.cfg.MaxTurns,
+		MaxDuration: b.cfg.MaxDuration,
+		MaxTokens:   b.cfg.MaxTokens,
+		MaxCost:     b.cfg.MaxCost,
+	})
+
+	o.running.Add(1)
+	if err := b.svc.StartFromFlags(ctx, b.cfg.Autoplay, playbook); err != nil {
+		o.running.Done()
+		return err
+	}
+	return nil
+}
+
+// callbacks wires the bot's autoplay service to its own history and proxy.
+func (b *bot) callbacks() features.AutoplayCallbacks {
+	event := b.event
+
+	return features.AutoplayCallbacks{
+		OnStarted: func(status features.AutoplayStatus) {
+			b.setState(StateRunning)
+			event(fmt.Sprintf("started: %q (%s/%s, limits: %s)", status.Message, b.providerName, b.model, status.Limits))
+		},
+		OnStopped: func(summary features.AutoplaySummary) {
+			b.mu.Lock()
+			b.state = StateStopped
+			b.stopReason = summary.Reason
+			b.mu.Unlock()
+			event(summary.Lines()[0])
+			b.orch.running.Done()
+		},
+		OnPaused: func() {
+			b.setState(StatePaused)
+			event("paused")
+		},
+		OnResumed: func() {
+			b.setState(StateRunning)
+			event("resumed")
+		},
+		OnGoalChanged: func(status features.AutoplayStatus) {
+			event(fmt.Sprintf("goal %d/%d: %q", status.Goal, status.Goals, status.Message))
+		},
+		OnGoalRefined: func(status features.AutoplayStatus) {
+			event(fmt.Sprintf("goal refined: %q", status.Message))
+		},
+		OnBreaker: func(open bool, until time.Time) {
+			if open {
+				event("circuit breaker open until " + until.Format("15:04:05"))
+				return
+			}
+			event("circuit breaker closed")
+		},
+		OnFailover: func(ctx context.Context, providerName string) error {
+			prov, model, err := features.CreateProvider(b.orch.cfg, b.orch.registry, providerName)
+			if err != nil {
+				return err
+			}
+			old := b.prov
+			b.prov = prov
+			if err := old.Close(); err != nil {
+				log.Warn().Err(err).Str("session", b.cfg.Session).Msg("Failed to close provider")
+			}
+
+			b.mu.Lock()
+			b.providerName = providerName
+			b.model = model
+			b.mu.Unlock()
+
+			if err := b.orch.sessionMgr.SetProvider(b.sessionID, providerName, model); err != nil {
+				log.Warn().Err(err).Str("session", b.cfg.Session).Msg("Failed to record provider switch")
+			}
+			b.addMessage(provider.Message{
+				Role:      "system",
+				Content:   features.FailoverNote(old.Name(), providerName, model),
+				CreatedAt: time.Now(),
+			})
+			event("failed over to " + providerName)
+			return nil
+		},
+		OnWatchdog: func(match features.WatchdogMatch, action string) {
+			event("watchdog: " + match.String() + " - " + action)
+		},
+		OnNudge: func(message string) {
+			b.addMessage(provider.Message{Role: "system", Content: message, CreatedAt: time.Now()})
+		},
+		OnStopCondition: func(match features.StopConditionMatch) {
+			event("stop condition met - " + match.String())
+		},
+		OnAlert: func(alert features.AlertMatch) {
+			event("alert - " + alert.String())
+		},
+		OnInterjection: func(message string) {
+			b.addMessage(provider.Message{Role: "user", Content: message, CreatedAt: time.Now()})
+		},
+		OnRefine: func(ctx context.Context, goal string) (string, error) {
+			return llm.RefineGoal(ctx, llm.RefineOptions{
+				Provider: b.prov,
+				History:  b.snapshot(),
+				Goal:     goal,
+			})
+		},
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
+			release, err := b.orch.scheduler.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+
+			b.addMessage(provider.Message{Role: "user", Content: message, CreatedAt: time.Now()})
+
+			history := b.snapshot()
+			result, err := llm.ProcessTurn(ctx, llm.ProcessTurnOptions{
+				Provider:           b.prov,
+				Proxy:              b.proxy,
+				Tools:              b.tools,
+				History:            history,
+				OnMessage:          b.addMessage,
+				ToolHooks:          b.script,
+				Events:             b.events,
+				Failures:           b.failures,
+				Pricing:            features.PricingFor(b.orch.cfg, b.providerName),
+				MaxToolRounds:      20,
+				HistoryKeepLast:    b.orch.cfg.History.KeepTurns,
+				HistoryTokenBudget: b.orch.cfg.History.TokenWindow(),
+				SuppressOutput:     true,
+				ImageText:          features.ToolImageText,
+			})
+			if plugins := features.Plugins(); features.HasPluginHook(plugins, features.PluginHookTurn) {
+				info := features.TranscriptInfo{Session: b.cfg.Session, Provider: b.providerName, Model: b.model}
+				features.RunTurnHooks(ctx, plugins, features.NewPluginTurn(info, history, result, err))
+			}
+			return result, err
+		},
+		OnError: func(err error) {
+			b.mu.Lock()
+			b.lastError = err.Error()
+			b.mu.Unlock()
+			event("error: " + err.Error())
+		},
+	}
+}
+
+// event reports a progress line, and streams it with the bot's state.
+func (b *bot) event(text string) {
+	b.orch.onEvent(b.cfg.Session, text)
+	b.mu.Lock()
+	state := b.state
+	b.mu.Unlock()
+	b.orch.stream.Publish(StreamEvent{Session: b.cfg.Session, Type: StreamStatus, State: state, Text: text})
+}
+
+// addMessage appends a message to the bot's history, saves and streams it.
+func (b *bot) addMessage(msg provider.Message) {
+	b.mu.Lock()
+	b.history = append(b.history, msg)
+	b.mu.Unlock()
+	b.orch.stream.publishMessage(b.cfg.Session, msg)
+
+	if err := b.orch.sessionMgr.SaveMessage(b.sessionID, msg); err != nil {
+		log.Warn().Err(err).Str("session", b.cfg.Session).Msg("Failed to save message")
+	}
+}
+
+// snapshot returns a copy of the bot's history.
+func (b *bot) snapshot() []provider.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	history := make([]provider.Message, len(b.history))
+	copy(history, b.history)
+	return history
+}
+
+func (b *bot) setState(state string) {
+	b.mu.Lock()
+	b.state = state
+	b.mu.Unlock()
+}
+
+func (b *bot) fail(err error) {
+	b.mu.Lock()
+	b.state = StateFailed
+	b.lastError = err.Error()
+	b.mu.Unlock()
+}
+
+// status returns the bot's current state.
+func (b *bot) status() BotStatus {
+	b.mu.Lock()
+	st := BotStatus{
+		Session:    b.cfg.Session,
+		Provider:   b.providerName,
+		Model:      b.model,
+		State:      b.state,
+		StopReason: b.stopReason,
+		LastError:  b.lastError,
+	}
+	b.mu.Unlock()
+
+	if b.svc == nil {
+		return st
+	}
+	autoplay := b.svc.Status()
+	st.Goal = autoplay.Message
+	st.Turns = autoplay.Turns
+	st.FailedTurns = autoplay.FailedTurns
+	st.ConsecutiveErrors = autoplay.ConsecutiveErrors
+	st.Tokens = autoplay.Usage.Tokens()
+	st.Cost = autoplay.Usage.Cost
+	st.NextTurnAt = autoplay.NextTurnAt
+	return st
+}
+
+// close releases the bot's provider and game connection.
+func (b *bot) close() {
+	if b.proxy != nil {
+		if err := b.proxy.Close(); err != nil {
+			log.Error().Err(err).Str("session", b.cfg.Session).Msg("Failed to close MCP proxy")
+		}
+	}
+	if b.prov != nil {
+		if err := b.prov.Close(); err != nil {
+			log.Error().Err(err).Str("session", b.cfg.Session).Msg("Failed to close provider")
+		}
+	}
+	b.script.Close()
+	if err := b.events.Close(); err != nil {
+		log.Error().Err(err).Str("session", b.cfg.Session).Msg("Failed to close event log")
+	}
+}
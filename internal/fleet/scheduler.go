@@ -0,0 +1,66 @@
+package fleet
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// scheduler is shared by the bots of a fleet: it bounds how many turns run
+// at once and spaces out their starts, so the bots don't hit the provider
+// and the game server together. A nil *scheduler lets every turn run.
+type scheduler struct {
+	slots   chan struct{} // nil for no concurrency limit
+	stagger time.Duration
+
+	mu   sync.Mutex
+	next time.Time // Earliest start of the next turn
+}
+
+// newScheduler returns a scheduler, or nil when neither limit is set.
+func newScheduler(maxConcurrent int, stagger time.Duration) *scheduler {
+	if maxConcurrent <= 0 && stagger <= 0 {
+		return nil
+	}
+	s := &scheduler{stagger: stagger}
+	if maxConcurrent > 0 {
+		s.slots = make(chan struct{}, maxConcurrent)
+	}
+	return s
+}
+
+// acquire blocks until a turn may start and returns the function ending it.
+func (s *scheduler) acquire(ctx context.Context) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	release := func() {}
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+			release = func() { <-s.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	start := time.Now()
+	if s.next.After(start) {
+		start = s.next
+	}
+	s.next = start.Add(s.stagger)
+	s.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
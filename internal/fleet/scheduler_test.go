@@ -0,0 +1,56 @@
+package fleet
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSchedulerConcurrency(t *testing.T) {
+	s := newScheduler(1, 0)
+	release, err := s.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx); err == nil {
+		t.Fatal("second turn started while the first was running")
+	}
+
+	release()
+	release, err = s.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	release()
+}
+
+func TestSchedulerStagger(t *testing.T) {
+	const stagger = 30 * time.Millisecond
+	s := newScheduler(0, stagger)
+	start := time.Now()
+	for range 3 {
+		release, err := s.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 2*stagger {
+		t.Errorf("3 turns started within %s, want at least %s", elapsed, 2*stagger)
+	}
+}
+
+func TestSchedulerDisabled(t *testing.T) {
+	s := newScheduler(0, 0)
+	if s != nil {
+		t.Fatalf("newScheduler(0, 0) = %+v, want nil", s)
+	}
+	release, err := s.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
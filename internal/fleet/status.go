@@ -0,0 +1,100 @@
+package fleet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// Bot states reported in BotStatus.
+const (
+	StateStarting = "starting"
+	StateRunning  = "running"
+	StatePaused   = "paused"
+	StateStopped  = "stopped"
+	StateFailed   = "failed" // Could not be set up; see LastError
+)
+
+// statusFile is the name of the fleet status file in the data directory.
+const statusFile = "fleet-status.json"
+
+// StaleAfter is how old a status file may be before `mysis fleet status`
+// reports the fleet as not running. The orchestrator rewrites it every
+// StatusInterval.
+const (
+	StatusInterval = 5 * time.Second
+	StaleAfter     = 3 * StatusInterval
+)
+
+// Snapshot is the state of all bots of a fleet, written to the status file.
+type Snapshot struct {
+	PID       int         `json:"pid"`
+	StartedAt time.Time   `json:"started_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Bots      []BotStatus `json:"bots"`
+}
+
+// BotStatus is the state of one bot.
+type BotStatus struct {
+	Session           string    `json:"session"`
+	Provider          string    `json:"provider"`
+	Model             string    `json:"model"`
+	State             string    `json:"state"`
+	Goal              string    `json:"goal,omitempty"`
+	Turns             int       `json:"turns"`
+	FailedTurns       int       `json:"failed_turns"`
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	Tokens            int       `json:"tokens"`
+	Cost              float64   `json:"cost"`
+	NextTurnAt        time.Time `json:"next_turn_at,omitzero"`
+	StopReason        string    `json:"stop_reason,omitempty"`
+	LastError         string    `json:"last_error,omitempty"`
+}
+
+// Stale reports whether the snapshot is too old to belong to a running fleet.
+func (s Snapshot) Stale(now time.Time) bool {
+	return now.Sub(s.UpdatedAt) > StaleAfter
+}
+
+// StatusPath returns the path of the fleet status file.
+func StatusPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, statusFile), nil
+}
+
+// WriteStatus atomically replaces the status file at path.
+func WriteStatus(path string, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode fleet status: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write fleet status: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write fleet status: %w", err)
+	}
+	return nil
+}
+
+// ReadStatus reads the status file at path.
+func ReadStatus(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("parse fleet status %s: %w", path, err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,49 @@
+package fleet
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteReadStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), statusFile)
+	now := time.Now().Truncate(time.Second)
+
+	want := Snapshot{
+		PID:       42,
+		StartedAt: now.Add(-time.Hour),
+		UpdatedAt: now,
+		Bots: []BotStatus{
+			{Session: "miner", Provider: "ollama", Model: "qwen", State: StateRunning, Turns: 3, NextTurnAt: now.Add(time.Minute)},
+			{Session: "scout", State: StateFailed, LastError: "provider not found"},
+		},
+	}
+	if err := WriteStatus(path, want); err != nil {
+		t.Fatalf("WriteStatus: %v", err)
+	}
+
+	got, err := ReadStatus(path)
+	if err != nil {
+		t.Fatalf("ReadStatus: %v", err)
+	}
+	if got.PID != want.PID || len(got.Bots) != 2 {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if got.Bots[0].Turns != 3 || !got.Bots[0].NextTurnAt.Equal(want.Bots[0].NextTurnAt) {
+		t.Errorf("bot 0 = %+v", got.Bots[0])
+	}
+	if !got.Bots[1].NextTurnAt.IsZero() || got.Bots[1].LastError != "provider not found" {
+		t.Errorf("bot 1 = %+v", got.Bots[1])
+	}
+}
+
+func TestSnapshotStale(t *testing.T) {
+	now := time.Now()
+	if (Snapshot{UpdatedAt: now.Add(-StatusInterval)}).Stale(now) {
+		t.Error("recent snapshot reported stale")
+	}
+	if !(Snapshot{UpdatedAt: now.Add(-StaleAfter - time.Second)}).Stale(now) {
+		t.Error("old snapshot not reported stale")
+	}
+}
@@ -0,0 +1,317 @@
+package fleet
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // G505: Required by the WebSocket handshake (RFC 6455)
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// Stream event types, mirroring the TUI's message, tool call and autoplay
+// status updates.
+const (
+	StreamMessage  = "message"
+	StreamToolCall = "tool_call"
+	StreamStatus   = "status"
+)
+
+// StreamEvent is a JSON text frame of the fleet event stream.
+type StreamEvent struct {
+	Time       time.Time       `json:"time"`
+	Session    string          `json:"session"`
+	Type       string          `json:"type"`
+	Role       string          `json:"role,omitempty"`    // message
+	Content    string          `json:"content,omitempty"` // message
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	Tool       string          `json:"tool,omitempty"` // tool_call
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	State      string          `json:"state,omitempty"` // status: the bot's state
+	Text       string          `json:"text,omitempty"`  // status: progress line, as printed by fleet run
+}
+
+// streamBuffer is how many events a slow client may fall behind before it
+// is disconnected.
+const streamBuffer = 256
+
+// websocketGUID is appended to the client key of the WebSocket handshake.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by the stream.
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xA
+)
+
+// Stream broadcasts the events of all bots to WebSocket clients. Events
+// published with no client connected are dropped. Safe for concurrent use.
+type Stream struct {
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+// NewStream creates a stream with no clients.
+func NewStream() *Stream {
+	return &Stream{clients: make(map[chan []byte]struct{})}
+}
+
+// Publish sends e to every connected client, stamping its time.
+func (s *Stream) Publish(e StreamEvent) {
+	e.Time = time.Now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Debug().Err(err).Str("type", e.Type).Msg("Failed to encode stream event")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.clients {
+		select {
+		case ch <- data:
+		default:
+			// Too slow: closing the channel ends its connection
+			delete(s.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// publishMessage publishes msg, and a tool_call event per tool call with
+// the passwords of auth tools redacted.
+func (s *Stream) publishMessage(session string, msg provider.Message) {
+	s.Publish(StreamEvent{
+		Session:    session,
+		Type:       StreamMessage,
+		Role:       msg.Role,
+		Content:    msg.Content,
+		ToolCallID: msg.ToolCallID,
+	})
+	for _, tc := range msg.ToolCalls {
+		s.Publish(StreamEvent{
+			Session:    session,
+			Type:       StreamToolCall,
+			ToolCallID: tc.ID,
+			Tool:       tc.Name,
+			Arguments:  store.RedactArguments(tc.Name, tc.Arguments),
+		})
+	}
+}
+
+func (s *Stream) subscribe() chan []byte {
+	ch := make(chan []byte, streamBuffer)
+	s.mu.Lock()
+	s.clients[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *Stream) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[ch]; ok {
+		delete(s.clients, ch)
+		close(ch)
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams events to it
+// as JSON text frames until either side closes the connection. Upgrades
+// from web pages of another origin are refused, so a page open in the
+// operator's browser can't read the stream.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || key == "" || !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin WebSocket upgrade refused", http.StatusForbidden)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to upgrade event stream connection")
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Subscribed before the handshake completes, so no event is missed
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	// Control frames from the reader are written by this goroutine only
+	control := make(chan []byte, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readFrames(rw.Reader, control)
+	}()
+
+	for {
+		var frame []byte
+		select {
+		case data, ok := <-ch:
+			if !ok {
+				_, _ = conn.Write(encodeFrame(wsClose, closePayload(1008, "too slow")))
+				return
+			}
+			frame = encodeFrame(wsText, data)
+		case frame = <-control:
+		case <-done:
+			return
+		}
+		if _, err := conn.Write(frame); err != nil {
+			return
+		}
+	}
+}
+
+// readFrames reads client frames until the connection closes, answering
+// pings and echoing a close; client messages are otherwise ignored.
+func readFrames(r *bufio.Reader, control chan<- []byte) {
+	for {
+		opcode, payload, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsPing:
+			select {
+			case control <- encodeFrame(wsPong, payload):
+			default:
+			}
+		case wsClose:
+			select {
+			case control <- encodeFrame(wsClose, payload):
+			default:
+			}
+			return
+		}
+	}
+}
+
+// maxClientFrame bounds the payload of a client frame.
+const maxClientFrame = 1 << 16
+
+// readFrame reads a client frame and unmasks its payload. Fragments are
+// returned as they come, which is enough to skip them.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxClientFrame {
+		return 0, nil, errors.New("websocket frame too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+// encodeFrame returns an unmasked final frame, as sent by a server.
+func encodeFrame(opcode byte, payload []byte) []byte {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	return append(frame, payload...)
+}
+
+// closePayload returns the payload of a close frame.
+func closePayload(code uint16, reason string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, code), reason...)
+}
+
+// websocketAccept returns the Sec-WebSocket-Accept value for a client key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID)) //nolint:gosec // G401: Required by RFC 6455
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// sameOrigin reports whether r has no Origin header, as sent by non-browser
+// clients, or one naming the host it was sent to.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// headerContains reports whether a comma-separated header has token,
+// ignoring case.
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package fleet
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestWebsocketAccept(t *testing.T) {
+	// Example from RFC 6455, section 1.3
+	if got := websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("websocketAccept = %q", got)
+	}
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 125, 126, 70000} {
+		payload := []byte(strings.Repeat("x", n))
+		opcode, got, err := readFrame(bufio.NewReader(strings.NewReader(string(encodeFrame(wsText, payload)))))
+		if n > maxClientFrame {
+			if err == nil {
+				t.Errorf("%d bytes: want error", n)
+			}
+			continue
+		}
+		if err != nil || opcode != wsText || len(got) != n {
+			t.Errorf("%d bytes: opcode %d, %d bytes, err %v", n, opcode, len(got), err)
+		}
+	}
+}
+
+func TestStreamServeHTTP(t *testing.T) {
+	stream := NewStream()
+	server := httptest.NewServer(stream)
+	defer server.Close()
+
+	// A plain request is not upgraded
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain GET: status %d", resp.StatusCode)
+	}
+
+	// Web pages of another origin are refused
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "https://evil.example")
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin upgrade: status %d", resp.StatusCode)
+	}
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = conn.Write([]byte("GET /events HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: status %d, headers %v", resp.StatusCode, resp.Header)
+	}
+
+	stream.publishMessage("miner", provider.Message{
+		Role:    "assistant",
+		Content: "Undocking",
+		ToolCalls: []provider.ToolCall{
+			{ID: "c1", Name: "undock", Arguments: json.RawMessage(`{}`)},
+			{ID: "c2", Name: "login", Arguments: json.RawMessage(`{"password":"hunter2"}`)},
+		},
+	})
+
+	var got []StreamEvent
+	for range 3 {
+		opcode, payload, err := readFrame(r)
+		if err != nil || opcode != wsText {
+			t.Fatalf("frame: opcode %d, err %v", opcode, err)
+		}
+		var e StreamEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	if got[0].Type != StreamMessage || got[0].Session != "miner" || got[0].Content != "Undocking" {
+		t.Errorf("message event = %+v", got[0])
+	}
+	if got[1].Type != StreamToolCall || got[1].Tool != "undock" || got[1].ToolCallID != "c1" {
+		t.Errorf("tool call event = %+v", got[1])
+	}
+	if strings.Contains(string(got[2].Arguments), "hunter2") {
+		t.Errorf("login arguments = %s, want them redacted", got[2].Arguments)
+	}
+}
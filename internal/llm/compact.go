@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// CompactSummaryPrefix marks system messages created by compaction.
+const CompactSummaryPrefix = "Summary of earlier conversation (compacted):"
+
+// DefaultCompactKeepTurns is the number of recent turns kept verbatim by compaction.
+const DefaultCompactKeepTurns = 2
+
+// ErrNothingToCompact is returned when the history is too short to compact.
+var ErrNothingToCompact = errors.New("not enough history to compact")
+
+// compactPrompt instructs the model how to summarize the compacted transcript.
+const compactPrompt = `You are compacting the history of a SpaceMolt game session so it can be resumed with a small context.
+Summarize the transcript below for your own future reference. Keep:
+- the player's current location, ship, cargo, and credits as last known
+- standing goals and instructions from the user
+- unfinished tasks and plans
+- lessons learned from failed tool calls
+Omit raw tool output and pleasantries. Be concise: at most 300 words, plain text.`
+
+// CompactOptions holds configuration for compacting a history.
+type CompactOptions struct {
+	Provider  provider.Provider
+	History   []provider.Message
+	KeepTurns int // Recent turns kept verbatim (default: DefaultCompactKeepTurns)
+}
+
+// CompactHistory replaces older turns of a history with an LLM-written summary.
+// System prompts are preserved, previous summaries are folded into the new one,
+// and the last KeepTurns turns are kept unchanged.
+func CompactHistory(ctx context.Context, opts CompactOptions) ([]provider.Message, error) {
+	if opts.KeepTurns <= 0 {
+		opts.KeepTurns = DefaultCompactKeepTurns
+	}
+
+	cutoff := turnStartIndex(opts.History, opts.KeepTurns)
+	if cutoff <= 0 {
+		return nil, ErrNothingToCompact
+	}
+
+	systemPrompts, older := splitSystemPrompts(opts.History[:cutoff])
+	if len(older) == 0 {
+		return nil, ErrNothingToCompact
+	}
+
+	summary, err := summarize(ctx, opts.Provider, older)
+	if err != nil {
+		return nil, err
+	}
+
+	compacted := make([]provider.Message, 0, len(systemPrompts)+1+len(opts.History)-cutoff)
+	compacted = append(compacted, systemPrompts...)
+	compacted = append(compacted, summary)
+	compacted = append(compacted, opts.History[cutoff:]...)
+
+	return compacted, nil
+}
+
+// ClearOptions holds configuration for clearing a history.
+type ClearOptions struct {
+	Provider  provider.Provider // Writes the summary; unused without Summarize
+	History   []provider.Message
+	Summarize bool // Replace the cleared messages with a summary
+}
+
+// ClearHistory starts a fresh context window. System prompts are kept and
+// everything else is dropped, or folded into a summary with Summarize.
+func ClearHistory(ctx context.Context, opts ClearOptions) ([]provider.Message, error) {
+	systemPrompts, older := splitSystemPrompts(opts.History)
+	if !opts.Summarize || len(older) == 0 {
+		return systemPrompts, nil
+	}
+
+	summary, err := summarize(ctx, opts.Provider, older)
+	if err != nil {
+		return nil, err
+	}
+	return append(systemPrompts, summary), nil
+}
+
+// splitSystemPrompts separates system prompts from the other messages.
+// Previous compaction summaries count as other messages.
+func splitSystemPrompts(messages []provider.Message) (systemPrompts, rest []provider.Message) {
+	for _, msg := range messages {
+		if msg.Role == "system" && !strings.HasPrefix(msg.Content, CompactSummaryPrefix) {
+			systemPrompts = append(systemPrompts, msg)
+			continue
+		}
+		rest = append(rest, msg)
+	}
+	return systemPrompts, rest
+}
+
+// summarize asks the provider for a summary of messages and returns it as
+// a compaction summary message.
+func summarize(ctx context.Context, prov provider.Provider, messages []provider.Message) (provider.Message, error) {
+	summary, err := prov.Chat(ctx, []provider.Message{
+		{Role: "system", Content: compactPrompt},
+		{Role: "user", Content: renderTranscript(messages)},
+	})
+	if err != nil {
+		return provider.Message{}, fmt.Errorf("summarize history: %w", err)
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return provider.Message{}, errors.New("summarize history: empty summary")
+	}
+	return provider.Message{
+		Role:      "system",
+		Content:   CompactSummaryPrefix + "\n" + summary,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// turnStartIndex returns the index of the first user message of the
+// keepTurns-th turn from the end, or -1 if there are not enough turns.
+func turnStartIndex(messages []provider.Message, keepTurns int) int {
+	turns := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			turns++
+			if turns == keepTurns {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// renderTranscript formats messages as plain text for summarization.
+func renderTranscript(messages []provider.Message) string {
+	const maxToolResult = 500
+
+	var b strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case "tool":
+			content := msg.Content
+			if len(content) > maxToolResult {
+				content = content[:maxToolResult] + "... [truncated]"
+			}
+			fmt.Fprintf(&b, "tool result: %s\n", content)
+		case "assistant":
+			if msg.Content != "" {
+				fmt.Fprintf(&b, "assistant: %s\n", msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(&b, "assistant called %s %s\n", tc.Name, string(tc.Arguments))
+			}
+		default:
+			fmt.Fprintf(&b, "%s: %s\n", msg.Role, msg.Content)
+		}
+	}
+	return b.String()
+}
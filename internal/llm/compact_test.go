@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestClearHistory(t *testing.T) {
+	history := []provider.Message{
+		{Role: "system", Content: "You play SpaceMolt"},
+		{Role: "system", Content: CompactSummaryPrefix + "\nEarlier we mined."},
+		{Role: "user", Content: "Mine ore"},
+		{Role: "assistant", Content: "Cargo is full"},
+	}
+
+	cleared, err := ClearHistory(context.Background(), ClearOptions{History: history})
+	if err != nil {
+		t.Fatalf("ClearHistory() error = %v", err)
+	}
+	if len(cleared) != 1 || cleared[0].Content != "You play SpaceMolt" {
+		t.Errorf("ClearHistory() = %v, want only the system prompt", cleared)
+	}
+
+	cleared, err = ClearHistory(context.Background(), ClearOptions{
+		Provider:  provider.NewMock("mock", "Cargo full at Sol."),
+		History:   history,
+		Summarize: true,
+	})
+	if err != nil {
+		t.Fatalf("ClearHistory(Summarize) error = %v", err)
+	}
+	if len(cleared) != 2 || !strings.HasSuffix(cleared[1].Content, "Cargo full at Sol.") {
+		t.Errorf("ClearHistory(Summarize) = %v, want system prompt and summary", cleared)
+	}
+}
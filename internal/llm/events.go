@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Event types of the event log.
+const (
+	EventTurnStarted = "turn_started"
+	EventLLMCall     = "llm_call"
+	EventToolCall    = "tool_call"
+	EventCompression = "compression"
+	EventError       = "error"
+	EventTurnEnded   = "turn_ended"
+)
+
+// Event is a line of a session's event log. Payloads are recorded by their
+// SHA-256 hash and size rather than in full; the messages themselves are in
+// the session database.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+	Type    string    `json:"type"`
+	Round   int       `json:"round,omitempty"` // LLM call of the turn, from 1
+
+	Provider     string  `json:"provider,omitempty"`
+	Messages     int     `json:"messages,omitempty"` // Messages sent, after compression
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+	ToolCalls    int     `json:"tool_calls,omitempty"`
+	RequestHash  string  `json:"request_hash,omitempty"`
+	ResponseHash string  `json:"response_hash,omitempty"`
+
+	Tool        string          `json:"tool,omitempty"`
+	ToolCallID  string          `json:"tool_call_id,omitempty"`
+	Arguments   json.RawMessage `json:"arguments,omitempty"`
+	ResultHash  string          `json:"result_hash,omitempty"`
+	ResultBytes int             `json:"result_bytes,omitempty"`
+	Denied      bool            `json:"denied,omitempty"`
+
+	OriginalMessages int `json:"original_messages,omitempty"`
+	OriginalTokens   int `json:"original_tokens,omitempty"`
+	SavedTokens      int `json:"saved_tokens,omitempty"`
+
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Failed     bool   `json:"failed,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// EventLog appends the events of a session's turns to a JSONL file, one
+// Event per line, independently of the application log, for offline
+// analysis and replay. A nil *EventLog records nothing. Safe for
+// concurrent use.
+type EventLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	session string
+	failed  bool // A write failed; later failures are not logged
+}
+
+// OpenEventLog opens the event log at path for appending, creating it and
+// its directory.
+func OpenEventLog(path, sessionID string) (*EventLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	//nolint:gosec // G304: Path from the data directory
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLog{file: file, session: sessionID}, nil
+}
+
+// Record appends e, stamped with the time and session.
+func (l *EventLog) Record(e Event) {
+	if l == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	e.Session = l.session
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Debug().Err(err).Str("type", e.Type).Msg("Failed to encode event")
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil && !l.failed {
+		l.failed = true
+		log.Warn().Err(err).Str("path", l.file.Name()).Msg("Failed to write event log")
+	}
+}
+
+// Close closes the file; later events are dropped.
+func (l *EventLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// withUsage returns the event with the tokens and cost of u.
+func (e Event) withUsage(u Usage) Event {
+	e.InputTokens, e.OutputTokens, e.Cost = u.InputTokens, u.OutputTokens, u.Cost
+	return e
+}
+
+// payloadHash returns "sha256:<hex>" of v as JSON, or of v itself when it
+// is a string or bytes.
+func payloadHash(v any) string {
+	var data []byte
+	switch v := v.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return ""
+		}
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
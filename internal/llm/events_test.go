@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestToolCallEventRedactsPasswords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	events, err := OpenEventLog(path, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := mcp.NewProxy(nil)
+	proxy.RegisterTool(mcp.Tool{Name: "login"}, func(context.Context, json.RawMessage) (*mcp.ToolResult, error) {
+		return &mcp.ToolResult{Content: []mcp.ContentBlock{{Type: "text", Text: "logged in"}}}, nil
+	})
+
+	_, _ = ProcessTurn(context.Background(), ProcessTurnOptions{
+		Provider: provider.NewMock("mock", "").WithToolCalls([]provider.ToolCall{
+			{ID: "c1", Name: "login", Arguments: json.RawMessage(`{"username":"miner","password":"hunter2"}`)},
+		}),
+		Proxy:          proxy,
+		History:        []provider.Message{{Role: "user", Content: "Log in"}},
+		OnMessage:      func(provider.Message) {},
+		Events:         events,
+		MaxToolRounds:  1,
+		SuppressOutput: true,
+	})
+	if err := events.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"tool":"login"`) || strings.Contains(string(data), "hunter2") {
+		t.Errorf("event log = %s, want the login call without its password", data)
+	}
+}
+
+// readEvents returns the events of the log at path.
+func readEvents(t *testing.T, path string) []Event {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestProcessTurnEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions", "s1", "events.jsonl")
+	events, err := OpenEventLog(path, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = events.Close() }()
+	history := []provider.Message{{Role: "user", Content: "Undock"}}
+
+	if _, err := ProcessTurn(context.Background(), ProcessTurnOptions{
+		Provider:       provider.NewMock("mock", "Undocked."),
+		History:        history,
+		OnMessage:      func(provider.Message) {},
+		Events:         events,
+		SuppressOutput: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ProcessTurn(context.Background(), ProcessTurnOptions{
+		Provider:       provider.NewMock("mock", "").WithChatError(errors.New("offline")),
+		History:        history,
+		OnMessage:      func(provider.Message) {},
+		Events:         events,
+		SuppressOutput: true,
+	})
+	if err == nil {
+		t.Fatal("ProcessTurn() with a failing provider succeeded")
+	}
+
+	got := readEvents(t, path)
+	var types []string
+	for _, e := range got {
+		types = append(types, e.Type)
+		if e.Session != "s1" || e.Time.IsZero() {
+			t.Errorf("event %s has session %q, time %v", e.Type, e.Session, e.Time)
+		}
+	}
+	want := []string{
+		EventTurnStarted, EventLLMCall, EventTurnEnded,
+		EventTurnStarted, EventLLMCall, EventError, EventTurnEnded,
+	}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("event types = %q, want %q", types, want)
+	}
+	if call := got[1]; call.RequestHash == "" || call.ResponseHash == "" || call.OutputTokens == 0 {
+		t.Errorf("llm_call = %+v, want hashes and tokens", call)
+	}
+	if failed := got[4]; !failed.Failed || failed.Error == "" {
+		t.Errorf("failed llm_call = %+v, want the error", failed)
+	}
+	if e := got[5]; e.RequestHash != got[4].RequestHash {
+		t.Errorf("error request hash = %q, want the failed call's %q", e.RequestHash, got[4].RequestHash)
+	}
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// repeatedFailureThreshold is how many consecutive identical failures of a
+// tool trigger a strategy hint.
+const repeatedFailureThreshold = 2
+
+// failureNumbers matches the numbers in an error, which vary between calls
+// failing the same way (a quantity, a balance).
+var failureNumbers = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// toolFailure is the failure streak of a tool.
+type toolFailure struct {
+	errKey string // normalizeError of the error
+	count  int
+}
+
+// FailureTracker detects a tool failing repeatedly with the same error so
+// the loop can nudge the model into changing strategy. Failures count per
+// tool and error, whatever the arguments, so selling a different quantity
+// that fails with insufficient_items again is caught; kept across turns, a
+// call failing once per turn is caught too. Safe for concurrent use.
+type FailureTracker struct {
+	mu       sync.Mutex
+	failures map[string]*toolFailure // By tool
+	hints    []string
+}
+
+// NewFailureTracker creates a tracker with no failures.
+func NewFailureTracker() *FailureTracker {
+	return &FailureTracker{failures: make(map[string]*toolFailure)}
+}
+
+// normalizeError reduces an error to what stays the same between calls
+// failing the same way: case, spacing and numbers are ignored.
+func normalizeError(errText string) string {
+	errText = failureNumbers.ReplaceAllString(strings.ToLower(errText), "#")
+	return strings.Join(strings.Fields(errText), " ")
+}
+
+// record registers the outcome of a tool call. An empty errText means success.
+func (t *FailureTracker) record(tool string, arguments json.RawMessage, errText string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if errText == "" {
+		delete(t.failures, tool)
+		return
+	}
+
+	errKey := normalizeError(errText)
+	f, ok := t.failures[tool]
+	if !ok || f.errKey != errKey {
+		f = &toolFailure{errKey: errKey}
+		t.failures[tool] = f
+	}
+	f.count++
+
+	if f.count >= repeatedFailureThreshold {
+		t.hints = append(t.hints, failureHint(tool, arguments, errText))
+		delete(t.failures, tool)
+	}
+}
+
+// takeHint returns a system message with pending hints and clears them.
+// Returns false if there are no pending hints.
+func (t *FailureTracker) takeHint() (provider.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.hints) == 0 {
+		return provider.Message{}, false
+	}
+
+	content := strings.Join(t.hints, "\n")
+	t.hints = nil
+
+	return provider.Message{
+		Role:      "system",
+		Content:   content,
+		CreatedAt: time.Now(),
+	}, true
+}
+
+// failureHint describes a repeated failure and asks for a different
+// approach. The arguments of auth tools are redacted.
+func failureHint(tool string, arguments json.RawMessage, errText string) string {
+	if runes := []rune(errText); len(runes) > 200 {
+		errText = string(runes[:197]) + "..."
+	}
+
+	return fmt.Sprintf(
+		"Hint: %s failed %d times in a row with the same error: %q (last arguments: %s). "+
+			"Do not repeat this call unchanged. Check the relevant game state first "+
+			"(status, cargo, location) and fix the arguments or choose a different action.",
+		tool, repeatedFailureThreshold, errText, string(store.RedactArguments(tool, arguments)))
+}
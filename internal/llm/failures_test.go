@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToolFailureTracker(t *testing.T) {
+	args := json.RawMessage(`{"item_id":"ore_iron","quantity":50}`)
+
+	t.Run("same error twice triggers hint", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		tracker.record("sell", args, "insufficient_items")
+		if _, ok := tracker.takeHint(); ok {
+			t.Fatal("hint after a single failure")
+		}
+
+		tracker.record("sell", args, "insufficient_items")
+		hint, ok := tracker.takeHint()
+		if !ok {
+			t.Fatal("expected hint after repeated failure")
+		}
+		if hint.Role != "system" {
+			t.Errorf("hint role = %q, want system", hint.Role)
+		}
+		if !strings.Contains(hint.Content, "sell") || !strings.Contains(hint.Content, "insufficient_items") {
+			t.Errorf("hint does not describe the failure: %s", hint.Content)
+		}
+		if _, ok := tracker.takeHint(); ok {
+			t.Error("hint should be cleared after takeHint")
+		}
+	})
+
+	t.Run("different errors do not trigger hint", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		tracker.record("sell", args, "insufficient_items")
+		tracker.record("sell", args, "not_docked")
+		if _, ok := tracker.takeHint(); ok {
+			t.Error("unexpected hint for different errors")
+		}
+	})
+
+	t.Run("same error with different arguments triggers hint", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		tracker.record("sell", args, "insufficient_items: have 3, need 50")
+		tracker.record("sell", json.RawMessage(`{"item_id":"ore_iron","quantity":20}`), "insufficient_items: have 3, need 20")
+		hint, ok := tracker.takeHint()
+		if !ok || !strings.Contains(hint.Content, `"quantity":20`) {
+			t.Errorf("hint = %q, %v, want one with the last arguments", hint.Content, ok)
+		}
+	})
+
+	t.Run("failures count per tool", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		tracker.record("sell", args, "insufficient_items")
+		tracker.record("jettison", args, "insufficient_items")
+		if _, ok := tracker.takeHint(); ok {
+			t.Error("unexpected hint for failures of different tools")
+		}
+	})
+
+	t.Run("long errors are truncated by rune", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		errText := strings.Repeat("é", 250)
+		tracker.record("sell", args, errText)
+		tracker.record("sell", args, errText)
+		hint, ok := tracker.takeHint()
+		if !ok || !utf8.ValidString(hint.Content) || !strings.Contains(hint.Content, strings.Repeat("é", 197)+"...") {
+			t.Errorf("hint = %q, %v, want the error cut at 197 runes", hint.Content, ok)
+		}
+	})
+
+	t.Run("auth arguments are redacted", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		login := json.RawMessage(`{"username":"miner","password":"hunter2"}`)
+		tracker.record("login", login, "invalid_password")
+		tracker.record("login", login, "invalid_password")
+		hint, ok := tracker.takeHint()
+		if !ok || strings.Contains(hint.Content, "hunter2") {
+			t.Errorf("hint = %q, %v, want one without the password", hint.Content, ok)
+		}
+	})
+
+	t.Run("success resets streak", func(t *testing.T) {
+		tracker := NewFailureTracker()
+		tracker.record("mine", args, "cargo_full")
+		tracker.record("mine", args, "")
+		tracker.record("mine", args, "cargo_full")
+		if _, ok := tracker.takeHint(); ok {
+			t.Error("unexpected hint after success reset")
+		}
+	})
+}
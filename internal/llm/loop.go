@@ -1,8 +1,10 @@
 package llm
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -14,12 +16,33 @@ import (
 	"github.com/xonecas/mysis/internal/styles"
 )
 
+// ErrLLMCall wraps errors returned by the provider, as opposed to tool or
+// game errors, so callers can tell a failing provider apart.
+var ErrLLMCall = errors.New("LLM call failed")
+
+// ErrTooManyRounds is returned when the model keeps calling tools past
+// MaxToolRounds without giving a final response.
+var ErrTooManyRounds = errors.New("too many tool call rounds")
+
 // MessageCallback is called when a message should be added to history and saved.
 type MessageCallback func(msg provider.Message)
 
 // ToolCallCallback is called when tool calls are about to be executed.
 type ToolCallCallback func()
 
+// ToolApprover is called before each tool call and reports whether it may
+// run, e.g. after asking the user. It should return false when ctx ends.
+type ToolApprover func(ctx context.Context, call provider.ToolCall) bool
+
+// ToolHooks can veto tool calls before they run and rewrite their results,
+// e.g. a session script.
+type ToolHooks interface {
+	// VetoTool reports whether call must not run, and why.
+	VetoTool(call provider.ToolCall) (reason string, veto bool)
+	// FilterResult returns the result the model sees for a successful call.
+	FilterResult(call provider.ToolCall, result string) string
+}
+
 // ProcessTurnOptions holds configuration for processing a turn.
 type ProcessTurnOptions struct {
 	Provider        provider.Provider
@@ -28,14 +51,101 @@ type ProcessTurnOptions struct {
 	History         []provider.Message
 	OnMessage       MessageCallback
 	OnToolCall      ToolCallCallback // Optional: called before executing tool calls
+	OnRequest       func()           // Optional: called before each LLM call
+	OnDelta         func(string)     // Optional: receives assistant text as it streams, if the provider streams
+	ApproveTool     ToolApprover     // Optional: denied tool calls are not executed
+	ToolHooks       ToolHooks        // Optional: vetoes calls before ApproveTool and filters results
+	Stats           *Stats           // Optional: accumulates per-run metrics
+	Events          *EventLog        // Optional: records the turn's events
+	Failures        *FailureTracker  // Optional: counts repeated tool failures across turns; else within the turn
+	Pricing         Pricing          // Optional: used to estimate the cost in TurnResult.Usage
 	MaxToolRounds   int
 	HistoryKeepLast int
-	SuppressOutput  bool // If true, suppress fmt.Println output (for TUI mode)
+	// HistoryTokenBudget selects the uncompressed window by estimated tokens
+	// instead of turns when positive (HistoryKeepLast is then ignored).
+	HistoryTokenBudget int
+	SuppressOutput     bool              // If true, suppress fmt.Println output (for TUI mode)
+	ToolResults        ToolResultDisplay // How tool results are printed; "" means ToolResultsTruncated
+	// ImageText returns the text standing for an image block of a tool
+	// result, e.g. features.ToolImageText; "[image <type>]" when nil.
+	ImageText func(mcp.ContentBlock) string
+}
+
+// ToolResultDisplay controls how much of each tool result CLI output shows.
+type ToolResultDisplay string
+
+// Tool result display modes, see --show-tool-results.
+const (
+	ToolResultsTruncated ToolResultDisplay = "truncated" // First 100 characters
+	ToolResultsFull      ToolResultDisplay = "full"      // Everything, JSON indented
+	ToolResultsHidden    ToolResultDisplay = "hidden"    // Only the tool name and outcome
+)
+
+// ParseToolResultDisplay parses a --show-tool-results value.
+func ParseToolResultDisplay(s string) (ToolResultDisplay, error) {
+	switch mode := ToolResultDisplay(s); mode {
+	case ToolResultsTruncated, ToolResultsFull, ToolResultsHidden:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("--show-tool-results=%s must be full, truncated or hidden", s)
+	}
+}
+
+// TurnResult summarizes a processed turn.
+type TurnResult struct {
+	Messages []provider.Message // Messages produced during the turn (assistant and tool results)
+	Rounds   int                // LLM calls made
+	Usage    Usage              // Estimated tokens and cost of the turn's LLM calls
+}
+
+// FinalResponse returns the text of the last assistant message of the turn,
+// or "" if the model gave none.
+func (r *TurnResult) FinalResponse() string {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == "assistant" {
+			return r.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// ToolCallCount returns the number of tool calls made during the turn.
+func (r *TurnResult) ToolCallCount() int {
+	count := 0
+	for _, msg := range r.Messages {
+		count += len(msg.ToolCalls)
+	}
+	return count
 }
 
 // ProcessTurn handles one conversation turn, which may involve tool calls.
 // It returns an error if the LLM call fails or max rounds are exceeded.
-func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
+// The returned result is never nil and covers whatever happened before an error.
+func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) (result *TurnResult, err error) {
+	result = &TurnResult{}
+	var requestHash string // Of the last LLM request, for the error event
+	opts.Events.Record(Event{Type: EventTurnStarted, Provider: opts.Provider.Name(), Messages: len(opts.History)})
+	defer func() {
+		if err != nil {
+			opts.Events.Record(Event{Type: EventError, Round: result.Rounds, RequestHash: requestHash, Error: err.Error()})
+		}
+		opts.Events.Record(Event{
+			Type:         EventTurnEnded,
+			Round:        result.Rounds,
+			InputTokens:  result.Usage.InputTokens,
+			OutputTokens: result.Usage.OutputTokens,
+			Cost:         result.Usage.Cost,
+			ToolCalls:    result.ToolCallCount(),
+			Failed:       err != nil,
+		})
+	}()
+
+	onMessage := opts.OnMessage
+	opts.OnMessage = func(msg provider.Message) {
+		result.Messages = append(result.Messages, msg)
+		onMessage(msg)
+	}
+
 	if opts.MaxToolRounds == 0 {
 		opts.MaxToolRounds = 20
 	}
@@ -43,10 +153,19 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 		opts.HistoryKeepLast = 10
 	}
 
+	if opts.Failures == nil {
+		opts.Failures = NewFailureTracker()
+	}
+
 	for round := 0; round < opts.MaxToolRounds; round++ {
 		// Compress history before sending to LLM
-		// Keep last N turns full, compress older state queries
-		compressedHistory := store.CompressHistory(opts.History, opts.HistoryKeepLast)
+		// Keep last N turns (or last N tokens) full, compress older state queries
+		var compressedHistory []provider.Message
+		if opts.HistoryTokenBudget > 0 {
+			compressedHistory = store.CompressHistoryByTokens(opts.History, opts.HistoryTokenBudget)
+		} else {
+			compressedHistory = store.CompressHistory(opts.History, opts.HistoryKeepLast)
+		}
 
 		// Log compression stats
 		if len(compressedHistory) < len(opts.History) {
@@ -59,11 +178,39 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 				Int("compressed_tokens", compressedTokens).
 				Int("saved_tokens", originalTokens-compressedTokens).
 				Msg("History compressed")
+			if opts.Stats != nil {
+				opts.Stats.RecordCompression(originalTokens - compressedTokens)
+			}
+			opts.Events.Record(Event{
+				Type:             EventCompression,
+				Round:            round + 1,
+				OriginalMessages: len(opts.History),
+				Messages:         len(compressedHistory),
+				OriginalTokens:   originalTokens,
+				SavedTokens:      originalTokens - compressedTokens,
+			})
 		}
 
-		// Convert MCP tools to provider format
-		providerTools := make([]provider.Tool, len(opts.Tools))
-		for i, t := range opts.Tools {
+		// Log per-role context breakdown for this request
+		roleTokens := store.EstimateTokensByRole(compressedHistory)
+		log.Debug().
+			Int("system_tokens", roleTokens.System).
+			Int("user_tokens", roleTokens.User).
+			Int("assistant_tokens", roleTokens.Assistant).
+			Int("tool_tokens", roleTokens.Tool).
+			Int("total_tokens", roleTokens.Total()).
+			Msg("Request context by role")
+		if opts.Stats != nil {
+			opts.Stats.RecordRequest(roleTokens)
+		}
+
+		// Convert MCP tools to provider format, hiding tools outside the proxy's filter
+		tools := opts.Tools
+		if opts.Proxy != nil {
+			tools = opts.Proxy.FilterTools(tools)
+		}
+		providerTools := make([]provider.Tool, len(tools))
+		for i, t := range tools {
 			providerTools[i] = provider.Tool{
 				Name:        t.Name,
 				Description: t.Description,
@@ -72,9 +219,34 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 		}
 
 		// Call LLM with compressed history
-		resp, err := opts.Provider.ChatWithTools(ctx, compressedHistory, providerTools)
+		result.Rounds++
+		if opts.OnRequest != nil {
+			opts.OnRequest()
+		}
+		if opts.Events != nil {
+			requestHash = payloadHash(struct {
+				Messages []provider.Message `json:"messages"`
+				Tools    []provider.Tool    `json:"tools"`
+			}{compressedHistory, providerTools})
+		}
+		start := time.Now()
+		resp, err := chatWithTools(ctx, opts, compressedHistory, providerTools)
+		call := Event{
+			Type:        EventLLMCall,
+			Round:       result.Rounds,
+			Provider:    opts.Provider.Name(),
+			Messages:    len(compressedHistory),
+			RequestHash: requestHash,
+			DurationMS:  time.Since(start).Milliseconds(),
+		}
 		if err != nil {
-			return fmt.Errorf("LLM call failed: %w", err)
+			call.Failed, call.Error = true, err.Error()
+			opts.Events.Record(call)
+			return result, fmt.Errorf("%w: %w", ErrLLMCall, err)
+		}
+		if opts.Events != nil {
+			call.ResponseHash = payloadHash(resp)
+			call.ToolCalls = len(resp.ToolCalls)
 		}
 
 		// Display reasoning if present (CLI mode only)
@@ -95,10 +267,16 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 				Reasoning: resp.Reasoning,
 				CreatedAt: time.Now(),
 			}
+			usage := requestUsage(opts.Pricing, roleTokens.Total(), assistantMsg)
+			result.Usage = result.Usage.Add(usage)
+			if opts.Stats != nil {
+				opts.Stats.RecordUsage(usage)
+			}
+			opts.Events.Record(call.withUsage(usage))
 			opts.OnMessage(assistantMsg)
 			opts.History = append(opts.History, assistantMsg)
 
-			return nil
+			return result, nil
 		}
 
 		// Tool calls present - add assistant message with tool calls to history
@@ -109,6 +287,12 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 			ToolCalls: resp.ToolCalls,
 			CreatedAt: time.Now(),
 		}
+		usage := requestUsage(opts.Pricing, roleTokens.Total(), assistantMsg)
+		result.Usage = result.Usage.Add(usage)
+		if opts.Stats != nil {
+			opts.Stats.RecordUsage(usage)
+		}
+		opts.Events.Record(call.withUsage(usage))
 		opts.OnMessage(assistantMsg)
 		opts.History = append(opts.History, assistantMsg)
 
@@ -118,13 +302,32 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 		}
 
 		// Execute each tool call and update history
-		toolResults := executeToolCalls(ctx, opts.Proxy, resp.ToolCalls, opts.OnMessage, opts.SuppressOutput)
+		toolResults := executeToolCalls(ctx, opts, resp.ToolCalls)
 		opts.History = append(opts.History, toolResults...)
 
+		// Nudge the model if a tool keeps failing the same way.
+		// The hint only lives in this turn's working history.
+		if hint, ok := opts.Failures.takeHint(); ok {
+			if !opts.SuppressOutput {
+				fmt.Println(styles.Muted.Render("! " + hint.Content))
+			}
+			log.Debug().Str("hint", hint.Content).Msg("Injected repeated tool failure hint")
+			opts.History = append(opts.History, hint)
+		}
+
 		// Continue loop to let LLM process tool results
 	}
 
-	return fmt.Errorf("too many tool call rounds (limit: %d)", opts.MaxToolRounds)
+	return result, fmt.Errorf("%w (limit: %d)", ErrTooManyRounds, opts.MaxToolRounds)
+}
+
+// chatWithTools calls the provider, streaming the response to opts.OnDelta
+// when it is set and the provider can stream.
+func chatWithTools(ctx context.Context, opts ProcessTurnOptions, history []provider.Message, tools []provider.Tool) (*provider.ChatResponse, error) {
+	if streamer, ok := opts.Provider.(provider.ToolStreamer); ok && opts.OnDelta != nil {
+		return streamer.StreamWithTools(ctx, history, tools, opts.OnDelta)
+	}
+	return opts.Provider.ChatWithTools(ctx, history, tools)
 }
 
 // displayReasoning shows the LLM's reasoning in a compact format.
@@ -138,28 +341,80 @@ func displayReasoning(reasoning string) {
 		reasoning = "..." + reasoning[len(reasoning)-197:]
 	}
 
-	fmt.Println(styles.Muted.Render("∴ " + reasoning))
+	fmt.Println(styles.Muted.Render(styles.SymbolReasoning + " " + reasoning))
 }
 
-// executeToolCalls executes a list of tool calls and adds results to history.
-// Returns the list of tool result messages that were added.
-func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provider.ToolCall, onMessage MessageCallback, suppressOutput bool) []provider.Message {
+// executeToolCalls executes a list of tool calls with the proxy, hooks and
+// callbacks of opts and returns the tool result messages.
+func executeToolCalls(ctx context.Context, opts ProcessTurnOptions, toolCalls []provider.ToolCall) []provider.Message {
 	toolResults := make([]provider.Message, 0, len(toolCalls))
 
 	for _, toolCall := range toolCalls {
-		if !suppressOutput {
-			fmt.Print(styles.Secondary.Render(fmt.Sprintf("⚙ %s", toolCall.Name)))
+		start := time.Now()
+		recordCall := func(toolMsg provider.Message, failed, denied bool) {
+			if opts.Events == nil {
+				return
+			}
+			opts.Events.Record(Event{
+				Type:        EventToolCall,
+				Tool:        toolCall.Name,
+				ToolCallID:  toolCall.ID,
+				Arguments:   store.RedactArguments(toolCall.Name, toolCall.Arguments),
+				ResultHash:  payloadHash(toolMsg.Content),
+				ResultBytes: len(toolMsg.Content),
+				Denied:      denied,
+				Failed:      failed,
+				DurationMS:  time.Since(start).Milliseconds(),
+			})
+		}
+
+		if !opts.SuppressOutput {
+			fmt.Print(styles.Secondary.Render(fmt.Sprintf("%s %s", styles.SymbolTool, toolCall.Name)))
 		}
 
 		// Show arguments (truncated if long)
-		displayToolArguments(toolCall.Arguments, suppressOutput)
+		displayToolArguments(toolCall.Arguments, opts.SuppressOutput)
+
+		if opts.ToolHooks != nil {
+			if reason, veto := opts.ToolHooks.VetoTool(toolCall); veto {
+				if !opts.SuppressOutput {
+					fmt.Println(styles.Error.Render(" " + styles.SymbolFail + " vetoed: " + reason))
+				}
+				toolMsg := provider.Message{
+					Role:       "tool",
+					Content:    vetoedResult(toolCall.Name, reason),
+					ToolCallID: toolCall.ID,
+					CreatedAt:  time.Now(),
+				}
+				opts.OnMessage(toolMsg)
+				toolResults = append(toolResults, toolMsg)
+				recordCall(toolMsg, false, true)
+				continue
+			}
+		}
+
+		if opts.ApproveTool != nil && !opts.ApproveTool(ctx, toolCall) {
+			if !opts.SuppressOutput {
+				fmt.Println(styles.Error.Render(" " + styles.SymbolFail + " denied"))
+			}
+			toolMsg := provider.Message{
+				Role:       "tool",
+				Content:    deniedResult(toolCall.Name),
+				ToolCallID: toolCall.ID,
+				CreatedAt:  time.Now(),
+			}
+			opts.OnMessage(toolMsg)
+			toolResults = append(toolResults, toolMsg)
+			recordCall(toolMsg, false, true)
+			continue
+		}
 
 		// Execute tool via MCP proxy
-		result, err := proxy.CallTool(ctx, toolCall.Name, toolCall.Arguments)
+		result, err := opts.Proxy.CallTool(ctx, toolCall.Name, toolCall.Arguments)
 
 		if err != nil {
-			if !suppressOutput {
-				fmt.Println(styles.Error.Render(" ✗"))
+			if !opts.SuppressOutput {
+				fmt.Println(styles.Error.Render(" " + styles.SymbolFail))
 				fmt.Println(styles.Error.Render("  Error: " + err.Error()))
 			}
 
@@ -170,18 +425,23 @@ func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provide
 				ToolCallID: toolCall.ID,
 				CreatedAt:  time.Now(),
 			}
-			onMessage(toolMsg)
+			opts.OnMessage(toolMsg)
 			toolResults = append(toolResults, toolMsg)
+			opts.Failures.record(toolCall.Name, toolCall.Arguments, toolMsg.Content)
+			if opts.Stats != nil {
+				opts.Stats.RecordToolCall(toolCall.Name, true)
+			}
+			recordCall(toolMsg, true, false)
 			continue
 		}
 
 		// Check if result is an error
 		if result.IsError {
-			if !suppressOutput {
-				fmt.Println(styles.Error.Render(" ✗"))
+			if !opts.SuppressOutput {
+				fmt.Println(styles.Error.Render(" " + styles.SymbolFail))
 			}
-			errText := extractTextFromContent(result.Content)
-			if errText != "" && !suppressOutput {
+			errText := extractTextFromContent(result.Content, opts.ImageText)
+			if errText != "" && !opts.SuppressOutput {
 				fmt.Println(styles.Error.Render("  " + errText))
 			}
 
@@ -192,19 +452,27 @@ func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provide
 				ToolCallID: toolCall.ID,
 				CreatedAt:  time.Now(),
 			}
-			onMessage(toolMsg)
+			opts.OnMessage(toolMsg)
 			toolResults = append(toolResults, toolMsg)
+			opts.Failures.record(toolCall.Name, toolCall.Arguments, errText)
+			if opts.Stats != nil {
+				opts.Stats.RecordToolCall(toolCall.Name, true)
+			}
+			recordCall(toolMsg, true, false)
 			continue
 		}
 
 		// Success
-		if !suppressOutput {
-			fmt.Println(styles.Success.Render(" ✓"))
+		if !opts.SuppressOutput {
+			fmt.Println(styles.Success.Render(" " + styles.SymbolOK))
 		}
 
 		// Extract and display result
-		resultText := extractTextFromContent(result.Content)
-		displayToolResult(resultText, suppressOutput)
+		resultText := extractTextFromContent(result.Content, opts.ImageText)
+		if opts.ToolHooks != nil {
+			resultText = opts.ToolHooks.FilterResult(toolCall, resultText)
+		}
+		displayToolResult(resultText, opts.SuppressOutput, opts.ToolResults)
 
 		// Add tool result to history
 		toolMsg := provider.Message{
@@ -213,13 +481,29 @@ func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provide
 			ToolCallID: toolCall.ID,
 			CreatedAt:  time.Now(),
 		}
-		onMessage(toolMsg)
+		opts.OnMessage(toolMsg)
 		toolResults = append(toolResults, toolMsg)
+		opts.Failures.record(toolCall.Name, toolCall.Arguments, "")
+		if opts.Stats != nil {
+			opts.Stats.RecordToolCall(toolCall.Name, false)
+		}
+		recordCall(toolMsg, false, false)
 	}
 
 	return toolResults
 }
 
+// deniedResult is the tool result of a call the user did not approve.
+func deniedResult(name string) string {
+	return fmt.Sprintf("Denied: the user did not approve %s, so it was not executed. "+
+		"Do not repeat the call unless the user asks for it.", name)
+}
+
+// vetoedResult is the tool result of a call vetoed by a ToolHooks.
+func vetoedResult(name, reason string) string {
+	return fmt.Sprintf("Blocked: the session script vetoed %s (%s), so it was not executed.", name, reason)
+}
+
 // displayToolArguments shows tool arguments in a truncated format.
 func displayToolArguments(arguments json.RawMessage, suppressOutput bool) {
 	if suppressOutput {
@@ -237,9 +521,21 @@ func displayToolArguments(arguments json.RawMessage, suppressOutput bool) {
 	}
 }
 
-// displayToolResult shows tool result in a truncated format.
-func displayToolResult(resultText string, suppressOutput bool) {
-	if suppressOutput {
+// displayToolResult shows a tool result as a preview, in full or not at
+// all, depending on mode.
+func displayToolResult(resultText string, suppressOutput bool, mode ToolResultDisplay) {
+	if suppressOutput || mode == ToolResultsHidden {
+		return
+	}
+
+	if mode == ToolResultsFull {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(resultText), "  ", "  "); err == nil {
+			resultText = indented.String()
+		}
+		if resultText != "" {
+			fmt.Println(styles.Muted.Render("  " + resultText))
+		}
 		return
 	}
 
@@ -251,12 +547,19 @@ func displayToolResult(resultText string, suppressOutput bool) {
 	}
 }
 
-// extractTextFromContent extracts text from MCP content blocks.
-func extractTextFromContent(content []mcp.ContentBlock) string {
+// extractTextFromContent extracts text from MCP content blocks. Images
+// are replaced by the text of imageText and other blocks by their type, so
+// that the model knows they were returned.
+func extractTextFromContent(content []mcp.ContentBlock, imageText func(mcp.ContentBlock) string) string {
 	var text string
 	for _, block := range content {
-		if block.Type == "text" {
+		switch {
+		case block.Type == "text":
 			text += block.Text
+		case block.Type == "image" && imageText != nil:
+			text += imageText(block)
+		default:
+			text += "[" + strings.TrimSpace(block.Type+" "+block.MimeType) + "]"
 		}
 	}
 	return text
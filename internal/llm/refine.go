@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// DefaultRefineTurns is the number of recent turns shown to the model when
+// refining an autoplay goal.
+const DefaultRefineTurns = 3
+
+// refinePrompt instructs the model how to restate an autoplay goal.
+const refinePrompt = `You are steering an unattended SpaceMolt game session. The same goal message is sent to you at the start of every turn.
+Given the current goal and a transcript of the latest turns, restate the goal so it better fits the current game state:
+keep the user's original intent, drop steps that are done, and make the next concrete objective explicit.
+Reply with the new goal only: one to three sentences, plain text, no preamble.`
+
+// RefineOptions holds configuration for refining an autoplay goal.
+type RefineOptions struct {
+	Provider provider.Provider
+	History  []provider.Message
+	Goal     string
+	Turns    int // Recent turns shown to the model (default: DefaultRefineTurns)
+}
+
+// RefineGoal asks the model to restate an autoplay goal based on the
+// progress visible in the most recent turns of the history.
+func RefineGoal(ctx context.Context, opts RefineOptions) (string, error) {
+	if opts.Turns <= 0 {
+		opts.Turns = DefaultRefineTurns
+	}
+
+	recent := opts.History
+	if start := turnStartIndex(opts.History, opts.Turns); start > 0 {
+		recent = opts.History[start:]
+	}
+
+	var transcript []provider.Message
+	for _, msg := range recent {
+		if msg.Role != "system" {
+			transcript = append(transcript, msg)
+		}
+	}
+
+	refined, err := opts.Provider.Chat(ctx, []provider.Message{
+		{Role: "system", Content: refinePrompt},
+		{Role: "user", Content: fmt.Sprintf("Current goal: %s\n\nLatest turns:\n%s", opts.Goal, renderTranscript(transcript))},
+	})
+	if err != nil {
+		return "", fmt.Errorf("refine goal: %w", err)
+	}
+	refined = strings.TrimSpace(refined)
+	if refined == "" {
+		return "", errors.New("refine goal: empty response")
+	}
+	return refined, nil
+}
@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestRefineGoal(t *testing.T) {
+	history := []provider.Message{
+		{Role: "system", Content: "You play SpaceMolt"},
+		{Role: "user", Content: "Mine ore"},
+		{Role: "assistant", Content: "Cargo is full"},
+	}
+
+	refined, err := RefineGoal(context.Background(), RefineOptions{
+		Provider: provider.NewMock("mock", "  Sell the ore at the nearest station, then mine again.\n"),
+		History:  history,
+		Goal:     "Mine ore",
+	})
+	if err != nil {
+		t.Fatalf("RefineGoal() error = %v", err)
+	}
+	if want := "Sell the ore at the nearest station, then mine again."; refined != want {
+		t.Errorf("RefineGoal() = %q, want %q", refined, want)
+	}
+
+	_, err = RefineGoal(context.Background(), RefineOptions{
+		Provider: provider.NewMock("mock", "").WithChatError(errors.New("offline")),
+		History:  history,
+		Goal:     "Mine ore",
+	})
+	if err == nil {
+		t.Error("RefineGoal() with failing provider should return an error")
+	}
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// Stats accumulates metrics for the current run. Safe for concurrent use.
+type Stats struct {
+	mu            sync.Mutex
+	requests      int
+	lastContext   store.RoleTokens
+	contextTotals store.RoleTokens
+	usage         Usage
+	savedTokens   int
+	toolCalls     map[string]int
+	toolErrors    int
+}
+
+// StatsSnapshot is a point-in-time copy of Stats.
+type StatsSnapshot struct {
+	Requests      int              // LLM requests sent this run
+	LastContext   store.RoleTokens // Per-role tokens of the last request
+	ContextTotals store.RoleTokens // Per-role tokens summed over all requests
+	Usage         Usage            // Estimated tokens and cost of this run
+	SavedTokens   int              // Tokens removed by history compression, summed over requests
+	ToolCalls     map[string]int   // Calls per tool name
+	ToolErrors    int              // Tool calls that failed
+}
+
+// NewStats creates an empty stats tracker.
+func NewStats() *Stats {
+	return &Stats{toolCalls: make(map[string]int)}
+}
+
+// RecordRequest records the per-role context size of one LLM request.
+func (s *Stats) RecordRequest(context store.RoleTokens) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.lastContext = context
+	s.contextTotals = s.contextTotals.Add(context)
+}
+
+// RecordUsage adds the estimated usage of one LLM request.
+func (s *Stats) RecordUsage(u Usage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = s.usage.Add(u)
+}
+
+// RecordCompression records the tokens history compression removed from
+// one request.
+func (s *Stats) RecordCompression(saved int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.savedTokens += saved
+}
+
+// RecordToolCall records one tool call and whether it failed.
+func (s *Stats) RecordToolCall(name string, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolCalls[name]++
+	if failed {
+		s.toolErrors++
+	}
+}
+
+// Snapshot returns a copy of the current stats.
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StatsSnapshot{
+		Requests:      s.requests,
+		LastContext:   s.lastContext,
+		ContextTotals: s.contextTotals,
+		Usage:         s.usage,
+		SavedTokens:   s.savedTokens,
+		ToolCalls:     maps.Clone(s.toolCalls),
+		ToolErrors:    s.toolErrors,
+	}
+}
+
+// Lines formats the snapshot as display-agnostic text lines.
+func (s StatsSnapshot) Lines() []string {
+	return []string{
+		fmt.Sprintf("LLM requests: %d", s.Requests),
+		"Estimated usage: " + s.Usage.String(),
+		"Last request context: " + formatRoleTokens(s.LastContext),
+		"Run context total:    " + formatRoleTokens(s.ContextTotals),
+		fmt.Sprintf("Compression saved: ~%d tokens", s.SavedTokens),
+		"Tool calls: " + formatToolCalls(s.ToolCalls, s.ToolErrors),
+	}
+}
+
+// formatToolCalls formats the call total and per-tool counts, most used
+// first.
+func formatToolCalls(calls map[string]int, errors int) string {
+	total := 0
+	names := make([]string, 0, len(calls))
+	for name, n := range calls {
+		total += n
+		names = append(names, name)
+	}
+	if total == 0 {
+		return "0"
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if calls[names[i]] != calls[names[j]] {
+			return calls[names[i]] > calls[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, calls[name])
+	}
+	text := fmt.Sprintf("%d (%s)", total, strings.Join(parts, ", "))
+	if errors > 0 {
+		text += fmt.Sprintf(", %d failed", errors)
+	}
+	return text
+}
+
+// formatRoleTokens formats a per-role breakdown with each role's share.
+func formatRoleTokens(r store.RoleTokens) string {
+	total := r.Total()
+	if total == 0 {
+		return "~0 tokens"
+	}
+
+	share := func(n int) int { return n * 100 / total }
+	return fmt.Sprintf("~%d tokens (system %d%%, user %d%%, assistant %d%%, tool %d%%)",
+		total, share(r.System), share(r.User), share(r.Assistant), share(r.Tool))
+}
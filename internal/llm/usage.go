@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"fmt"
+
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/store"
+)
+
+// Pricing is the cost of a model in USD per million tokens.
+type Pricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// Cost returns the estimated cost of the given token counts.
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1_000_000
+}
+
+// Usage is the estimated token usage and cost of LLM requests.
+// Providers do not report usage, so tokens are estimated from message sizes.
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+	Cost         float64 // USD, zero when the provider has no pricing configured
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:  u.InputTokens + other.InputTokens,
+		OutputTokens: u.OutputTokens + other.OutputTokens,
+		Cost:         u.Cost + other.Cost,
+	}
+}
+
+// Tokens returns input plus output tokens.
+func (u Usage) Tokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// String formats the usage for display.
+func (u Usage) String() string {
+	text := fmt.Sprintf("~%d tokens (%d in, %d out)", u.Tokens(), u.InputTokens, u.OutputTokens)
+	if u.Cost > 0 {
+		text += fmt.Sprintf(", ~$%.4f", u.Cost)
+	}
+	return text
+}
+
+// requestUsage estimates the usage of one LLM request.
+func requestUsage(pricing Pricing, inputTokens int, response provider.Message) Usage {
+	outputTokens := store.EstimateTokenCount([]provider.Message{response})
+	return Usage{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		Cost:         pricing.Cost(inputTokens, outputTokens),
+	}
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AgentMessenger defines the interface for messages between sessions,
+// addressed by session name.
+type AgentMessenger interface {
+	SendAgentMessage(sessionID, to, content string) error
+	ReadAgentInbox(sessionID string) ([]AgentMessage, error)
+}
+
+// AgentMessage is a message read from a session's inbox.
+type AgentMessage struct {
+	From    string    `json:"from"`
+	Message string    `json:"message"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// SendToAgentArgs represents arguments for send_to_agent tool.
+type SendToAgentArgs struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// NewSendToAgentTool creates the send_to_agent tool definition.
+func NewSendToAgentTool() Tool {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"to": map[string]interface{}{
+				"type":        "string",
+				"description": "Session name of the agent to message, e.g. a fleet bot's session",
+			},
+			"message": map[string]interface{}{
+				"type":        "string",
+				"description": "Message to leave in the agent's inbox",
+			},
+		},
+		"required": []string{"to", "message"},
+	}
+
+	schemaJSON, _ := json.Marshal(schema)
+
+	return Tool{
+		Name:        "send_to_agent",
+		Description: "Leave a message for another mysis agent, addressed by its session name, to coordinate roles such as mining and trading. The agent reads it with read_agent_inbox.",
+		InputSchema: schemaJSON,
+	}
+}
+
+// NewReadAgentInboxTool creates the read_agent_inbox tool definition.
+func NewReadAgentInboxTool() Tool {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+
+	schemaJSON, _ := json.Marshal(schema)
+
+	return Tool{
+		Name:        "read_agent_inbox",
+		Description: "Read the messages other mysis agents sent to this session with send_to_agent since the last read, oldest first. Each message is returned once.",
+		InputSchema: schemaJSON,
+	}
+}
+
+// MakeSendToAgentHandler creates a handler for send_to_agent tool.
+func MakeSendToAgentHandler(messenger AgentMessenger, sessionID string) ToolHandler {
+	return func(ctx context.Context, arguments json.RawMessage) (*ToolResult, error) {
+		var args SendToAgentArgs
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return &ToolResult{
+				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Invalid arguments: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		args.To = strings.TrimSpace(args.To)
+		if args.To == "" || strings.TrimSpace(args.Message) == "" {
+			return &ToolResult{
+				Content: []ContentBlock{{Type: "text", Text: "Both to and message are required"}},
+				IsError: true,
+			}, nil
+		}
+
+		if err := messenger.SendAgentMessage(sessionID, args.To, args.Message); err != nil {
+			return &ToolResult{
+				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Failed to send message: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Message left for '%s'", args.To)}},
+			IsError: false,
+		}, nil
+	}
+}
+
+// MakeReadAgentInboxHandler creates a handler for read_agent_inbox tool.
+func MakeReadAgentInboxHandler(messenger AgentMessenger, sessionID string) ToolHandler {
+	return func(ctx context.Context, arguments json.RawMessage) (*ToolResult, error) {
+		messages, err := messenger.ReadAgentInbox(sessionID)
+		if err != nil {
+			return &ToolResult{
+				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Failed to read inbox: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		if len(messages) == 0 {
+			return &ToolResult{
+				Content: []ContentBlock{{Type: "text", Text: "No new messages"}},
+				IsError: false,
+			}, nil
+		}
+
+		resultJSON, err := json.Marshal(messages)
+		if err != nil {
+			return &ToolResult{
+				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Failed to format messages: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: string(resultJSON)}},
+			IsError: false,
+		}, nil
+	}
+}
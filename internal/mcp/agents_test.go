@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// mockMessenger keeps inboxes by session ID, naming each session by its ID.
+type mockMessenger struct {
+	inboxes map[string][]AgentMessage
+}
+
+func (m *mockMessenger) SendAgentMessage(sessionID, to, content string) error {
+	m.inboxes[to] = append(m.inboxes[to], AgentMessage{From: sessionID, Message: content})
+	return nil
+}
+
+func (m *mockMessenger) ReadAgentInbox(sessionID string) ([]AgentMessage, error) {
+	messages := m.inboxes[sessionID]
+	delete(m.inboxes, sessionID)
+	return messages, nil
+}
+
+func TestAgentMessagingTools(t *testing.T) {
+	messenger := &mockMessenger{inboxes: make(map[string][]AgentMessage)}
+	send := MakeSendToAgentHandler(messenger, "miner")
+	read := MakeReadAgentInboxHandler(messenger, "trader")
+
+	result, err := send(context.Background(), json.RawMessage(`{"to": " ", "message": "hi"}`))
+	if err != nil || !result.IsError {
+		t.Errorf("empty recipient: result %+v, err %v; want a tool error", result, err)
+	}
+
+	args, _ := json.Marshal(SendToAgentArgs{To: "trader", Message: "hauling 40 ore to Sol station"})
+	result, err = send(context.Background(), args)
+	if err != nil || result.IsError || result.Content[0].Text != "Message left for 'trader'" {
+		t.Fatalf("send: result %+v, err %v", result, err)
+	}
+
+	result, err = read(context.Background(), json.RawMessage(`{}`))
+	if err != nil || result.IsError {
+		t.Fatalf("read: result %+v, err %v", result, err)
+	}
+	var messages []AgentMessage
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &messages); err != nil {
+		t.Fatalf("read result is not JSON: %v", err)
+	}
+	if len(messages) != 1 || messages[0].From != "miner" || messages[0].Message != "hauling 40 ore to Sol station" {
+		t.Errorf("messages = %+v", messages)
+	}
+
+	result, _ = read(context.Background(), json.RawMessage(`{}`))
+	if result.Content[0].Text != "No new messages" {
+		t.Errorf("second read = %q, want no new messages", result.Content[0].Text)
+	}
+}
+
+func TestAgentMessagingToolDefinitions(t *testing.T) {
+	for _, tool := range []Tool{NewSendToAgentTool(), NewReadAgentInboxTool()} {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil {
+			t.Errorf("%s: invalid schema: %v", tool.Name, err)
+		}
+		if tool.Description == "" {
+			t.Errorf("%s: missing description", tool.Name)
+		}
+	}
+}
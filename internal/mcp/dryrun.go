@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// readOnlyToolPrefixes mark game tools that only query state.
+var readOnlyToolPrefixes = []string{"get_", "view_", "list_", "search_"}
+
+// readOnlyTools are game tools without a read-only prefix that are safe to
+// run in dry-run mode. Login only restores the session.
+var readOnlyTools = map[string]bool{
+	"captains_log_list": true,
+	"help":              true,
+	"login":             true,
+}
+
+// IsReadOnlyTool reports whether a game tool only queries state.
+func IsReadOnlyTool(name string) bool {
+	name = strings.ToLower(name)
+	if readOnlyTools[name] {
+		return true
+	}
+	for _, prefix := range readOnlyToolPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDryRun enables or disables simulation mode. In simulation mode
+// tools that change state, upstream, local and plugin alike, are not run;
+// they return a simulated result instead. State queries still run.
+func (p *Proxy) SetDryRun(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dryRun = enabled
+}
+
+// DryRun reports whether simulation mode is enabled.
+func (p *Proxy) DryRun() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.dryRun
+}
+
+// simulatedResult is returned for mutating tools in dry-run mode.
+func simulatedResult(name string, arguments []byte) *ToolResult {
+	args := string(arguments)
+	if args == "" {
+		args = "{}"
+	}
+	text := fmt.Sprintf("[dry run] %s was not executed (arguments: %s). "+
+		"This is a simulation: assume the action succeeded and continue planning, "+
+		"but note that game state has not changed.", name, args)
+
+	return &ToolResult{
+		Content: []ContentBlock{{Type: "text", Text: text}},
+	}
+}
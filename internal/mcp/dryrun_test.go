@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProxyDryRun(t *testing.T) {
+	proxy := NewProxy(NewStubClient())
+	proxy.SetDryRun(true)
+	ctx := context.Background()
+
+	// State queries still reach upstream
+	result, err := proxy.CallTool(ctx, "get_status", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("CallTool(get_status) error = %v", err)
+	}
+	if strings.Contains(result.Content[0].Text, "[dry run]") {
+		t.Errorf("get_status was simulated: %s", result.Content[0].Text)
+	}
+
+	// Mutating tools are simulated
+	result, err = proxy.CallTool(ctx, "mine", json.RawMessage(`{"target":"ore"}`))
+	if err != nil {
+		t.Fatalf("CallTool(mine) error = %v", err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "[dry run] mine was not executed") {
+		t.Errorf("mine result = %+v, want simulated success", result)
+	}
+
+	// So are local tools, but not local state queries
+	ran := map[string]bool{}
+	for _, name := range []string{"send_to_agent", "get_credentials"} {
+		proxy.RegisterTool(Tool{Name: name}, func(context.Context, json.RawMessage) (*ToolResult, error) {
+			ran[name] = true
+			return &ToolResult{Content: []ContentBlock{{Type: "text", Text: "ran"}}}, nil
+		})
+		if _, err := proxy.CallTool(ctx, name, json.RawMessage(`{}`)); err != nil {
+			t.Fatalf("CallTool(%s) error = %v", name, err)
+		}
+	}
+	if ran["send_to_agent"] || !ran["get_credentials"] {
+		t.Errorf("local tools run = %v, want only get_credentials", ran)
+	}
+
+	proxy.SetDryRun(false)
+	if proxy.DryRun() {
+		t.Error("DryRun() = true after disabling")
+	}
+}
+
+func TestIsReadOnlyTool(t *testing.T) {
+	for name, want := range map[string]bool{
+		"get_cargo":         true,
+		"captains_log_list": true,
+		"login":             true,
+		"mine":              false,
+		"sell":              false,
+		"register":          false,
+	} {
+		if got := IsReadOnlyTool(name); got != want {
+			t.Errorf("IsReadOnlyTool(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"fmt"
+	"path"
+)
+
+// DefaultSafeTools is the conservative allowlist used by autoplay safe mode
+// when none is configured: state queries, the captain's log and mining.
+var DefaultSafeTools = []string{
+	"get_*", "view_*", "list_*", "search_*",
+	"captains_log_*", "help", "login",
+	"mine",
+}
+
+// SetToolFilter restricts the tools listed and callable through the proxy
+// to names matching one of the glob patterns (e.g. "get_*"). A nil or
+// empty allowlist removes the restriction.
+func (p *Proxy) SetToolFilter(allow []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.allow = allow
+}
+
+// ToolFilter returns the current allowlist, or nil if tools are unrestricted.
+func (p *Proxy) ToolFilter() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.allow
+}
+
+// ToolAllowed reports whether the tool filter permits calling name.
+func (p *Proxy) ToolAllowed(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.allowedLocked(name)
+}
+
+// FilterTools returns the tools permitted by the tool filter.
+func (p *Proxy) FilterTools(tools []Tool) []Tool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.filterToolsLocked(tools)
+}
+
+func (p *Proxy) filterToolsLocked(tools []Tool) []Tool {
+	if len(p.allow) == 0 {
+		return tools
+	}
+
+	allowed := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		if p.allowedLocked(t.Name) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
+func (p *Proxy) allowedLocked(name string) bool {
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedResult is returned for tools outside the tool filter.
+func blockedResult(name string) *ToolResult {
+	return &ToolResult{
+		Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf(
+			"Error: tool %s is not allowed in safe mode. Use only the tools you were given.", name)}},
+		IsError: true,
+	}
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestProxyToolFilter(t *testing.T) {
+	proxy := NewProxy(NewStubClient())
+	proxy.SetToolFilter(DefaultSafeTools)
+	ctx := context.Background()
+
+	tools, err := proxy.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	for _, tool := range tools {
+		if !proxy.ToolAllowed(tool.Name) {
+			t.Errorf("ListTools() returned blocked tool %q", tool.Name)
+		}
+	}
+
+	result, err := proxy.CallTool(ctx, "sell", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("CallTool(sell) error = %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("CallTool(sell) = %+v, want blocked error", result)
+	}
+
+	result, err = proxy.CallTool(ctx, "get_status", json.RawMessage(`{}`))
+	if err != nil || result.IsError {
+		t.Errorf("CallTool(get_status) = %+v, %v; want allowed", result, err)
+	}
+
+	proxy.SetToolFilter(nil)
+	if !proxy.ToolAllowed("sell") {
+		t.Error("ToolAllowed(sell) = false after clearing the filter")
+	}
+}
+
+func TestProxyToolCatalog(t *testing.T) {
+	proxy := NewProxy(NewStubClient())
+	proxy.RegisterTool(Tool{Name: "captains_log_add"}, func(ctx context.Context, arguments json.RawMessage) (*ToolResult, error) {
+		return &ToolResult{}, nil
+	})
+	proxy.SetToolFilter([]string{"get_status", "captains_log_*"})
+
+	infos, err := proxy.ToolCatalog(context.Background())
+	if err != nil {
+		t.Fatalf("ToolCatalog() error = %v", err)
+	}
+
+	byName := make(map[string]ToolInfo)
+	for _, info := range infos {
+		byName[info.Name] = info
+	}
+	if info := byName["captains_log_add"]; !info.Local || !info.Allowed {
+		t.Errorf("captains_log_add = %+v, want local and allowed", info)
+	}
+	if info := byName["get_status"]; info.Local || !info.Allowed {
+		t.Errorf("get_status = %+v, want upstream and allowed", info)
+	}
+	if info, ok := byName["get_ship"]; !ok || info.Allowed {
+		t.Errorf("get_ship = %+v (listed %v), want listed and blocked", info, ok)
+	}
+}
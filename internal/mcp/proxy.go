@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +24,8 @@ type Proxy struct {
 	upstream      UpstreamClient
 	localTools    map[string]Tool
 	localHandlers map[string]ToolHandler
+	dryRun        bool     // Simulate mutating upstream tools (see SetDryRun)
+	allow         []string // Tool name patterns allowed (see SetToolFilter); empty allows all
 }
 
 var (
@@ -81,6 +84,14 @@ func (p *Proxy) RegisterTool(tool Tool, handler ToolHandler) {
 	p.localHandlers[tool.Name] = handler
 }
 
+// HasLocalTool reports whether a local tool named name is registered.
+func (p *Proxy) HasLocalTool(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.localTools[name]
+	return ok
+}
+
 // ListTools returns all available tools (local + upstream).
 func (p *Proxy) ListTools(ctx context.Context) ([]Tool, error) {
 	p.mu.RLock()
@@ -104,15 +115,62 @@ func (p *Proxy) ListTools(ctx context.Context) ([]Tool, error) {
 		}
 	}
 
-	return tools, nil
+	return p.filterToolsLocked(tools), nil
+}
+
+// ToolInfo describes a tool for display: where it is handled and whether
+// the tool filter permits it.
+type ToolInfo struct {
+	Tool
+	Local   bool // Handled by the proxy rather than the upstream server
+	Allowed bool // Permitted by the tool filter
+}
+
+// ToolCatalog returns all tools, including those the tool filter blocks,
+// sorted by name.
+func (p *Proxy) ToolCatalog(ctx context.Context) ([]ToolInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var infos []ToolInfo
+	for _, t := range p.localTools {
+		infos = append(infos, ToolInfo{Tool: t, Local: true, Allowed: p.allowedLocked(t.Name)})
+	}
+	if p.upstream != nil {
+		upstreamTools, err := p.upstream.ListTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list upstream tools: %w", err)
+		}
+		for _, t := range upstreamTools {
+			if _, ok := p.localTools[t.Name]; ok {
+				continue // Local handlers take precedence
+			}
+			infos = append(infos, ToolInfo{Tool: t, Allowed: p.allowedLocked(t.Name)})
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
 }
 
 // CallTool invokes a tool, checking local handlers first then upstream.
 func (p *Proxy) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*ToolResult, error) {
 	p.mu.RLock()
 	handler, isLocal := p.localHandlers[name]
+	dryRun := p.dryRun
+	allowed := p.allowedLocked(name)
 	p.mu.RUnlock()
 
+	if !allowed {
+		log.Info().Str("tool", name).Msg("Tool blocked by filter")
+		return blockedResult(name), nil
+	}
+
+	if dryRun && !IsReadOnlyTool(name) && (isLocal || p.upstream != nil) {
+		log.Info().Str("tool", name).Msg("Dry run - simulated tool call")
+		return simulatedResult(name, arguments), nil
+	}
+
 	// Try local handler first
 	if isLocal {
 		return handler(ctx, arguments)
@@ -6,9 +6,22 @@ import (
 	"fmt"
 )
 
+// StubUpstream is the mcp.upstream value that selects StubClient, for
+// offline demos and scripted regression runs.
+const StubUpstream = "stub"
+
 // StubClient is an offline MCP client that returns mock data.
 type StubClient struct{}
 
+// NewUpstream returns the client for an mcp.upstream setting: StubClient
+// for StubUpstream, otherwise an HTTP client for the URL.
+func NewUpstream(upstream string) UpstreamClient {
+	if upstream == StubUpstream {
+		return NewStubClient()
+	}
+	return NewClient(upstream)
+}
+
 // NewStubClient creates a new stub MCP client.
 func NewStubClient() *StubClient {
 	return &StubClient{}
@@ -49,8 +49,10 @@ type ToolResult struct {
 
 // ContentBlock represents a content block in tool results.
 type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`     // Base64 data of image and audio blocks
+	MimeType string `json:"mimeType,omitempty"` // Of image and audio blocks
 }
 
 // ListToolsResult is the result of tools/list.
@@ -14,8 +14,8 @@ func NewOllamaFactory(name string, endpoint string) *OllamaFactory {
 
 func (f *OllamaFactory) Name() string { return f.name }
 
-func (f *OllamaFactory) Create(model string, temperature float64) Provider {
-	return NewOllamaWithTemp(f.name, f.endpoint, model, temperature)
+func (f *OllamaFactory) Create(model string, opts Options) Provider {
+	return NewOllamaWithOptions(f.name, f.endpoint, model, opts)
 }
 
 type OpenCodeFactory struct {
@@ -34,6 +34,6 @@ func NewOpenCodeFactory(name string, endpoint, apiKey string) *OpenCodeFactory {
 
 func (f *OpenCodeFactory) Name() string { return f.name }
 
-func (f *OpenCodeFactory) Create(model string, temperature float64) Provider {
-	return NewOpenCodeWithTemp(f.name, f.endpoint, model, f.apiKey, temperature)
+func (f *OpenCodeFactory) Create(model string, opts Options) Provider {
+	return NewOpenCodeWithOptions(f.name, f.endpoint, model, f.apiKey, opts)
 }
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 )
@@ -38,7 +39,7 @@ func NewMockFactory(name, response string) *MockFactory {
 
 func (f *MockFactory) Name() string { return f.name }
 
-func (f *MockFactory) Create(model string, temperature float64) Provider {
+func (f *MockFactory) Create(model string, opts Options) Provider {
 	return NewMock(f.name, f.response)
 }
 
@@ -125,6 +126,21 @@ func (p *MockProvider) ChatWithTools(ctx context.Context, messages []Message, to
 	}, nil
 }
 
+// StreamWithTools returns the same as ChatWithTools, passing the response
+// to onDelta one word at a time.
+func (p *MockProvider) StreamWithTools(ctx context.Context, messages []Message, tools []Tool, onDelta func(content string)) (*ChatResponse, error) {
+	resp, err := p.ChatWithTools(ctx, messages, tools)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range strings.SplitAfter(resp.Content, " ") {
+		if word != "" {
+			onDelta(word)
+		}
+	}
+	return resp, nil
+}
+
 // Stream returns the predefined response as a single chunk.
 func (p *MockProvider) Stream(ctx context.Context, messages []Message) (<-chan StreamChunk, error) {
 	if err := p.waitDelay(ctx); err != nil {
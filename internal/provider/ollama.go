@@ -23,6 +23,8 @@ type OllamaProvider struct {
 	httpClient  *http.Client
 	model       string
 	temperature float64
+	topP        float64
+	maxTokens   int
 }
 
 var ollamaRetryDelays = []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second}
@@ -34,6 +36,11 @@ func NewOllama(endpoint, model string) *OllamaProvider {
 }
 
 func NewOllamaWithTemp(name string, endpoint, model string, temperature float64) *OllamaProvider {
+	return NewOllamaWithOptions(name, endpoint, model, Options{Temperature: temperature})
+}
+
+// NewOllamaWithOptions creates an Ollama provider with the given sampling options.
+func NewOllamaWithOptions(name string, endpoint, model string, opts Options) *OllamaProvider {
 	config := openai.DefaultConfig("")
 	baseURL := strings.TrimRight(endpoint, "/") + "/v1"
 	config.BaseURL = baseURL
@@ -44,7 +51,9 @@ func NewOllamaWithTemp(name string, endpoint, model string, temperature float64)
 		baseURL:     baseURL,
 		httpClient:  &http.Client{},
 		model:       model,
-		temperature: temperature,
+		temperature: opts.Temperature,
+		topP:        opts.TopP,
+		maxTokens:   opts.MaxTokens,
 	}
 }
 
@@ -53,12 +62,19 @@ func (p *OllamaProvider) Name() string {
 	return p.name
 }
 
+// ListModels returns the models pulled on the Ollama server.
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]string, error) {
+	return listOpenAIModels(ctx, p.client)
+}
+
 // Chat sends messages and returns the complete response.
 func (p *OllamaProvider) Chat(ctx context.Context, messages []Message) (string, error) {
 	resp, err := p.createChatCompletion(ctx, ollamaChatRequest{
 		Model:       p.model,
 		Messages:    mergeConsecutiveSystemMessagesOllama(toOllamaMessages(messages)),
 		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
 	})
 	if err != nil {
 		return "", err
@@ -78,6 +94,8 @@ func (p *OllamaProvider) ChatWithTools(ctx context.Context, messages []Message,
 		Messages:    mergeConsecutiveSystemMessagesOllama(toOllamaMessages(messages)),
 		Tools:       toOllamaTools(tools),
 		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
 	})
 	if err != nil {
 		return nil, err
@@ -144,6 +162,8 @@ type ollamaChatRequest struct {
 	Messages    []ollamaReqMessage `json:"messages"`
 	Tools       []ollamaReqTool    `json:"tools,omitempty"`
 	Temperature float32            `json:"temperature,omitempty"`
+	TopP        float32            `json:"top_p,omitempty"`
+	MaxTokens   int                `json:"max_tokens,omitempty"`
 }
 
 type ollamaReqMessage struct {
@@ -316,6 +336,8 @@ func (p *OllamaProvider) Stream(ctx context.Context, messages []Message) (<-chan
 		Model:       p.model,
 		Messages:    toOpenAIMessages(messages),
 		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
 	})
 	if err != nil {
 		return nil, err
@@ -350,6 +372,30 @@ func (p *OllamaProvider) Stream(ctx context.Context, messages []Message) (<-chan
 	return ch, nil
 }
 
+// StreamWithTools streams a tool-enabled response, calling onDelta with each
+// piece of content. If the stream cannot be started it falls back to
+// ChatWithTools, which retries transient errors.
+func (p *OllamaProvider) StreamWithTools(ctx context.Context, messages []Message, tools []Tool, onDelta func(content string)) (*ChatResponse, error) {
+	openaiTools, err := toOpenAITools(tools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool schema: %w", err)
+	}
+
+	resp, err := streamOpenAIChat(ctx, p.client, openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    toOpenAIMessages(messages),
+		Tools:       openaiTools,
+		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
+	}, onDelta)
+	if errors.Is(err, errStreamNotStarted) && ctx.Err() == nil {
+		log.Warn().Err(err).Str("provider", p.name).Msg("Streaming failed - retrying without streaming")
+		return p.ChatWithTools(ctx, messages, tools)
+	}
+	return resp, err
+}
+
 // toOllamaMessages converts provider messages to Ollama's custom request format.
 //
 // OLLAMA-SPECIFIC: Uses custom ollamaReqMessage type instead of OpenAI SDK types.
@@ -432,3 +432,24 @@ func TestOllama_SystemMessagesAtEnd(t *testing.T) {
 		t.Errorf("expected response to acknowledge final instruction, got %q", resp)
 	}
 }
+
+// TestOllama_ListModels tests that models are read from the OpenAI-compatible /v1/models endpoint.
+func TestOllama_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen3:8b","object":"model"},{"id":"llama3.2:3b","object":"model"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewOllama(server.URL, "qwen3:8b")
+	models, err := p.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0] != "qwen3:8b" || models[1] != "llama3.2:3b" {
+		t.Errorf("ListModels() = %v", models)
+	}
+}
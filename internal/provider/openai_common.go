@@ -1,8 +1,11 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -201,3 +204,95 @@ func toOpenAITools(tools []Tool) ([]openai.Tool, error) {
 	}
 	return result, nil
 }
+
+// listOpenAIModels returns the model IDs of an OpenAI-compatible /models endpoint.
+func listOpenAIModels(ctx context.Context, client *openai.Client) ([]string, error) {
+	list, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+
+	models := make([]string, len(list.Models))
+	for i, m := range list.Models {
+		models[i] = m.ID
+	}
+	return models, nil
+}
+
+// errStreamNotStarted is returned by streamOpenAIChat when the stream fails
+// before any content arrived, so the caller can retry without streaming.
+var errStreamNotStarted = errors.New("stream not started")
+
+// streamOpenAIChat streams a chat completion, calling onDelta with each
+// piece of content, and assembles the response. Tool call fragments are
+// joined by their index.
+func streamOpenAIChat(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest, onDelta func(string)) (*ChatResponse, error) {
+	req.Stream = true
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errStreamNotStarted, err)
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close stream")
+		}
+	}()
+
+	var content, reasoning strings.Builder
+	var calls []openai.ToolCall
+	received := false
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if !received {
+				return nil, fmt.Errorf("%w: %w", errStreamNotStarted, err)
+			}
+			return nil, err
+		}
+		received = true
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta
+		reasoning.WriteString(delta.ReasoningContent)
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			onDelta(delta.Content)
+		}
+		for _, tc := range delta.ToolCalls {
+			index := len(calls)
+			if tc.Index != nil {
+				index = *tc.Index
+			}
+			for len(calls) <= index {
+				calls = append(calls, openai.ToolCall{})
+			}
+			call := &calls[index]
+			if tc.ID != "" {
+				call.ID = tc.ID
+			}
+			call.Function.Name += tc.Function.Name
+			call.Function.Arguments += tc.Function.Arguments
+		}
+	}
+
+	result := &ChatResponse{
+		Content:   content.String(),
+		Reasoning: reasoning.String(),
+	}
+	for _, tc := range calls {
+		if tc.Function.Name == "" {
+			continue
+		}
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: json.RawMessage(tc.Function.Arguments),
+		})
+	}
+	return result, nil
+}
@@ -22,6 +22,8 @@ type openCodeRequest struct {
 	Messages    []openai.ChatCompletionMessage `json:"messages"`
 	Tools       []openai.Tool                  `json:"tools,omitempty"`
 	Temperature float32                        `json:"temperature,omitempty"`
+	TopP        float32                        `json:"top_p,omitempty"`
+	MaxTokens   int                            `json:"max_tokens,omitempty"`
 	Stream      bool                           `json:"stream"` // NO omitempty - always serialize
 }
 
@@ -34,6 +36,8 @@ type OpenCodeProvider struct {
 	httpClient  *http.Client
 	model       string
 	temperature float64
+	topP        float64
+	maxTokens   int
 }
 
 var opencodeRetryDelays = []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second}
@@ -61,6 +65,11 @@ func NewOpenCode(endpoint, model, apiKey string) *OpenCodeProvider {
 }
 
 func NewOpenCodeWithTemp(name string, endpoint, model, apiKey string, temperature float64) *OpenCodeProvider {
+	return NewOpenCodeWithOptions(name, endpoint, model, apiKey, Options{Temperature: temperature})
+}
+
+// NewOpenCodeWithOptions creates an OpenCode Zen provider with the given sampling options.
+func NewOpenCodeWithOptions(name string, endpoint, model, apiKey string, opts Options) *OpenCodeProvider {
 	config := openai.DefaultConfig(apiKey)
 	baseURL := strings.TrimRight(endpoint, "/")
 	config.BaseURL = baseURL
@@ -72,7 +81,9 @@ func NewOpenCodeWithTemp(name string, endpoint, model, apiKey string, temperatur
 		apiKey:      apiKey,
 		httpClient:  &http.Client{},
 		model:       model,
-		temperature: temperature,
+		temperature: opts.Temperature,
+		topP:        opts.TopP,
+		maxTokens:   opts.MaxTokens,
 	}
 }
 
@@ -81,12 +92,19 @@ func (p *OpenCodeProvider) Name() string {
 	return p.name
 }
 
+// ListModels returns the models offered by the OpenCode Zen endpoint.
+func (p *OpenCodeProvider) ListModels(ctx context.Context) ([]string, error) {
+	return listOpenAIModels(ctx, p.client)
+}
+
 // Chat sends messages and returns the complete response.
 func (p *OpenCodeProvider) Chat(ctx context.Context, messages []Message) (string, error) {
 	resp, err := p.createChatCompletion(ctx, openai.ChatCompletionRequest{
 		Model:       p.model,
 		Messages:    mergeSystemMessagesOpenAI(toOpenAIMessages(messages)),
 		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
 		Stream:      false,
 	})
 	if err != nil {
@@ -113,6 +131,8 @@ func (p *OpenCodeProvider) ChatWithTools(ctx context.Context, messages []Message
 		Messages:    mergeSystemMessagesOpenAI(toOpenAIMessages(messages)),
 		Tools:       openaiTools,
 		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
 		Stream:      false,
 	})
 	if err != nil {
@@ -166,6 +186,8 @@ func (p *OpenCodeProvider) createChatCompletion(ctx context.Context, req openai.
 		Messages:    req.Messages,
 		Tools:       req.Tools,
 		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		MaxTokens:   req.MaxTokens,
 		Stream:      req.Stream,
 	}
 	body, err := json.Marshal(customReq)
@@ -336,6 +358,8 @@ func (p *OpenCodeProvider) Stream(ctx context.Context, messages []Message) (<-ch
 		Model:       p.model,
 		Messages:    mergeSystemMessagesOpenAI(toOpenAIMessages(messages)),
 		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
 	})
 	if err != nil {
 		return nil, err
@@ -370,6 +394,34 @@ func (p *OpenCodeProvider) Stream(ctx context.Context, messages []Message) (<-ch
 	return ch, nil
 }
 
+// StreamWithTools streams a tool-enabled response, calling onDelta with each
+// piece of content. Models outside the chat completions endpoint, and
+// streams that cannot be started, fall back to ChatWithTools.
+func (p *OpenCodeProvider) StreamWithTools(ctx context.Context, messages []Message, tools []Tool, onDelta func(content string)) (*ChatResponse, error) {
+	if opencodeEndpointForModel(p.model) != opencodeChatCompletionsEndpoint {
+		return p.ChatWithTools(ctx, messages, tools)
+	}
+
+	openaiTools, err := toOpenAITools(tools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool schema: %w", err)
+	}
+
+	resp, err := streamOpenAIChat(ctx, p.client, openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    mergeSystemMessagesOpenAI(toOpenAIMessages(messages)),
+		Tools:       openaiTools,
+		Temperature: float32(p.temperature),
+		TopP:        float32(p.topP),
+		MaxTokens:   p.maxTokens,
+	}, onDelta)
+	if errors.Is(err, errStreamNotStarted) && ctx.Err() == nil {
+		log.Warn().Err(err).Str("provider", p.name).Msg("Streaming failed - retrying without streaming")
+		return p.ChatWithTools(ctx, messages, tools)
+	}
+	return resp, err
+}
+
 func opencodeEndpointForModel(model string) string {
 	if endpoint, ok := opencodeModelEndpoints[model]; ok {
 		return endpoint
@@ -445,3 +445,42 @@ func TestOpenCode_StreamParameterSetCorrectly(t *testing.T) {
 		t.Fatalf("Chat failed: %v", err)
 	}
 }
+
+// TestOpenCode_SamplingOptions tests that top_p and max_tokens reach the
+// request body.
+func TestOpenCode_SamplingOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Temperature float64 `json:"temperature"`
+			TopP        float64 `json:"top_p"`
+			MaxTokens   int     `json:"max_tokens"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		if req.Temperature < 0.49 || req.Temperature > 0.51 {
+			t.Errorf("temperature = %v, want 0.5", req.Temperature)
+		}
+		if req.TopP < 0.89 || req.TopP > 0.91 {
+			t.Errorf("top_p = %v, want 0.9", req.TopP)
+		}
+		if req.MaxTokens != 256 {
+			t.Errorf("max_tokens = %d, want 256", req.MaxTokens)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]interface{}{"role": "assistant", "content": "ok"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOpenCodeWithOptions("opencode_zen", server.URL, "test-model", "test-key",
+		Options{Temperature: 0.5, TopP: 0.9, MaxTokens: 256})
+
+	messages := []Message{{Role: "user", Content: "Test"}}
+	if _, err := provider.Chat(context.Background(), messages); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+}
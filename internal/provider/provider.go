@@ -60,9 +60,30 @@ type Provider interface {
 	Close() error
 }
 
+// ModelLister is implemented by providers that can list the models their
+// endpoint serves.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// ToolStreamer is implemented by providers that can stream a response to a
+// tool-enabled request as it is generated. onDelta receives each piece of
+// text content; the returned response is the same as ChatWithTools'.
+type ToolStreamer interface {
+	StreamWithTools(ctx context.Context, messages []Message, tools []Tool, onDelta func(content string)) (*ChatResponse, error)
+}
+
+// Options are the sampling settings of a provider instance. Zero TopP and
+// MaxTokens leave the server defaults.
+type Options struct {
+	Temperature float64
+	TopP        float64
+	MaxTokens   int // Limit on generated tokens per response
+}
+
 type ProviderFactory interface {
 	Name() string
-	Create(model string, temperature float64) Provider
+	Create(model string, opts Options) Provider
 }
 
 // StreamChunk represents a chunk of streamed response.
@@ -88,12 +109,12 @@ func (r *Registry) RegisterFactory(name string, f ProviderFactory) {
 	r.factories[name] = f
 }
 
-func (r *Registry) Create(name, model string, temperature float64) (Provider, error) {
+func (r *Registry) Create(name, model string, opts Options) (Provider, error) {
 	f, ok := r.factories[name]
 	if !ok {
 		return nil, ErrProviderNotFound
 	}
-	return f.Create(model, temperature), nil
+	return f.Create(model, opts), nil
 }
 
 // List returns all registered provider names.
@@ -65,7 +65,7 @@ func TestProviderNameFromConfig(t *testing.T) {
 			}
 
 			// Create a provider instance and verify it also returns the correct name
-			provider := factory.Create("test-model", 0.7)
+			provider := factory.Create("test-model", Options{Temperature: 0.7})
 			if provider.Name() != tt.expectedName {
 				t.Errorf("Provider.Name() = %q, want %q", provider.Name(), tt.expectedName)
 			}
@@ -85,8 +85,8 @@ func TestProviderNameNotHardcoded(t *testing.T) {
 	}
 
 	// Create providers from the factories
-	provider1 := factory1.Create("qwen2.5:7b", 0.7)
-	provider2 := factory2.Create("llama3.2:3b", 0.7)
+	provider1 := factory1.Create("qwen2.5:7b", Options{Temperature: 0.7})
+	provider2 := factory2.Create("llama3.2:3b", Options{Temperature: 0.7})
 
 	if provider1.Name() == provider2.Name() {
 		t.Errorf("Different config names should produce different provider names, both returned %q", provider1.Name())
@@ -108,8 +108,8 @@ func TestProviderNameNotHardcoded(t *testing.T) {
 		t.Errorf("Different config names should produce different factory names, both returned %q", factory3.Name())
 	}
 
-	provider3 := factory3.Create("gpt-5-nano", 0.7)
-	provider4 := factory4.Create("big-pickle", 0.7)
+	provider3 := factory3.Create("gpt-5-nano", Options{Temperature: 0.7})
+	provider4 := factory4.Create("big-pickle", Options{Temperature: 0.7})
 
 	if provider3.Name() == provider4.Name() {
 		t.Errorf("Different config names should produce different provider names, both returned %q", provider3.Name())
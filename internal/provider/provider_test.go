@@ -17,7 +17,7 @@ func TestRegistry(t *testing.T) {
 	reg.RegisterFactory("provider2", NewMockFactory("provider2", "response2"))
 
 	// Get existing provider
-	p, err := reg.Create("provider1", "model", 0.7)
+	p, err := reg.Create("provider1", "model", Options{Temperature: 0.7})
 	if err != nil {
 		t.Fatalf("Create() error: %v", err)
 	}
@@ -26,7 +26,7 @@ func TestRegistry(t *testing.T) {
 	}
 
 	// Get non-existent provider
-	_, err = reg.Create("nonexistent", "model", 0.7)
+	_, err = reg.Create("nonexistent", "model", Options{Temperature: 0.7})
 	if !errors.Is(err, ErrProviderNotFound) {
 		t.Errorf("expected ErrProviderNotFound, got %v", err)
 	}
@@ -408,12 +408,12 @@ func TestRegistry_MultipleProvidersWithSameFactory(t *testing.T) {
 	registry.RegisterFactory("provider-two", factory2)
 
 	// Should be able to create both
-	p1, err := registry.Create("provider-one", "model1", 0.7)
+	p1, err := registry.Create("provider-one", "model1", Options{Temperature: 0.7})
 	if err != nil {
 		t.Fatalf("Create provider-one failed: %v", err)
 	}
 
-	p2, err := registry.Create("provider-two", "model2", 0.7)
+	p2, err := registry.Create("provider-two", "model2", Options{Temperature: 0.7})
 	if err != nil {
 		t.Fatalf("Create provider-two failed: %v", err)
 	}
@@ -453,7 +453,7 @@ func TestRegistry_ConfigKeyOverridesFactoryName(t *testing.T) {
 	registry.RegisterFactory("ollama-llama", factory)
 
 	// Should be accessible by config key, not factory name
-	p, err := registry.Create("ollama-llama", "llama3.1:8b", 0.7)
+	p, err := registry.Create("ollama-llama", "llama3.1:8b", Options{Temperature: 0.7})
 	if err != nil {
 		t.Fatalf("Create ollama-llama failed: %v", err)
 	}
@@ -470,7 +470,7 @@ func TestRegistry_ConfigKeyOverridesFactoryName(t *testing.T) {
 	}
 
 	// Should NOT be accessible by factory name
-	_, err = registry.Create("ollama", "model", 0.7)
+	_, err = registry.Create("ollama", "model", Options{Temperature: 0.7})
 	if !errors.Is(err, ErrProviderNotFound) {
 		t.Errorf("expected ErrProviderNotFound for factory name, got %v", err)
 	}
@@ -56,7 +56,7 @@ func TestZenNanoRegistration(t *testing.T) {
 	}
 
 	// Create provider using registry
-	_, err := registry.Create("zen-nano", "gpt-5-nano", 0.7)
+	_, err := registry.Create("zen-nano", "gpt-5-nano", Options{Temperature: 0.7})
 	if err != nil {
 		t.Fatalf("REPRODUCTION: zen-nano provider creation failed: %v\nThis reproduces the production bug.", err)
 	}
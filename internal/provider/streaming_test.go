@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -417,3 +418,76 @@ func TestProvider_Stream_Interface(t *testing.T) {
 		})
 	}
 }
+
+// TestOllamaProvider_StreamWithTools tests that content deltas are passed on
+// and tool call fragments are joined by index
+func TestOllamaProvider_StreamWithTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`data: {"choices":[{"index":0,"delta":{"content":"Mining"}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"content":" now."}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"mine","arguments":"{\"ore\":"}}]}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"iron\"}"}}]}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_status","arguments":"{}"}}]}}]}`,
+			`data: {"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+			`data: [DONE]`,
+		}
+		for _, chunk := range chunks {
+			_, _ = fmt.Fprintf(w, "%s\n\n", chunk)
+		}
+	}))
+	defer server.Close()
+
+	p := NewOllama(server.URL, "test-model")
+	var deltas []string
+	resp, err := p.StreamWithTools(context.Background(), []Message{{Role: "user", Content: "mine"}}, nil, func(content string) {
+		deltas = append(deltas, content)
+	})
+	if err != nil {
+		t.Fatalf("StreamWithTools() failed: %v", err)
+	}
+
+	if strings.Join(deltas, "|") != "Mining| now." {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if resp.Content != "Mining now." {
+		t.Errorf("Content = %q, want %q", resp.Content, "Mining now.")
+	}
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("got %d tool calls, want 2", len(resp.ToolCalls))
+	}
+	if tc := resp.ToolCalls[0]; tc.ID != "call_1" || tc.Name != "mine" || string(tc.Arguments) != `{"ore":"iron"}` {
+		t.Errorf("first tool call = %+v", tc)
+	}
+	if tc := resp.ToolCalls[1]; tc.ID != "call_2" || tc.Name != "get_status" {
+		t.Errorf("second tool call = %+v", tc)
+	}
+}
+
+// TestOllamaProvider_StreamWithTools_Fallback tests that a stream that cannot
+// be started falls back to a regular request
+func TestOllamaProvider_StreamWithTools_Fallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"stream":true`) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"streaming not supported"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Done."}}]}`))
+	}))
+	defer server.Close()
+
+	p := NewOllama(server.URL, "test-model")
+	resp, err := p.StreamWithTools(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, func(string) {
+		t.Error("onDelta called without a stream")
+	})
+	if err != nil {
+		t.Fatalf("StreamWithTools() failed: %v", err)
+	}
+	if resp.Content != "Done." {
+		t.Errorf("Content = %q, want %q", resp.Content, "Done.")
+	}
+}
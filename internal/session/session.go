@@ -2,11 +2,14 @@ package session
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/mcp"
 	"github.com/xonecas/mysis/internal/provider"
 	"github.com/xonecas/mysis/internal/store"
 )
@@ -79,6 +82,12 @@ func (m *Manager) LoadHistory(sessionID string) ([]provider.Message, error) {
 	return history, nil
 }
 
+// SearchHistory returns up to limit messages of a session containing text,
+// ignoring case, including or limited to compacted messages.
+func (m *Manager) SearchHistory(sessionID, text string, compactedOnly bool, limit int) ([]provider.Message, error) {
+	return m.db.SearchMessages(sessionID, text, compactedOnly, limit)
+}
+
 // SaveMessage saves a message to the session history.
 func (m *Manager) SaveMessage(sessionID string, msg provider.Message) error {
 	if err := m.db.SaveMessage(sessionID, msg); err != nil {
@@ -88,6 +97,116 @@ func (m *Manager) SaveMessage(sessionID string, msg provider.Message) error {
 	return nil
 }
 
+// SetProvider records a provider switch on the session, so resuming it
+// uses the new provider.
+func (m *Manager) SetProvider(sessionID, providerName, model string) error {
+	return m.db.UpdateSessionProvider(sessionID, providerName, model)
+}
+
+// SystemPrompt returns the system prompt set for a session with /system,
+// or "" if none is set.
+func (m *Manager) SystemPrompt(sessionID string) (string, error) {
+	return m.db.GetSessionSystemPrompt(sessionID)
+}
+
+// SetSystemPrompt records the system prompt of a session, so resuming it
+// uses the prompt.
+func (m *Manager) SetSystemPrompt(sessionID, prompt string) error {
+	return m.db.SetSessionSystemPrompt(sessionID, prompt)
+}
+
+// SaveCredentials stores the game credentials of a session, so the
+// Manager serves the credential tools (mcp.CredentialStore).
+func (m *Manager) SaveCredentials(sessionID, username, password string) error {
+	return m.db.SaveCredentials(sessionID, username, password)
+}
+
+// GetCredentials returns the game credentials saved for a session.
+func (m *Manager) GetCredentials(sessionID string) (string, string, error) {
+	return m.db.GetCredentials(sessionID)
+}
+
+// SendAgentMessage leaves a message from a session for the session named
+// to, so the Manager serves the agent messaging tools (mcp.AgentMessenger).
+// Anonymous senders are named by their ID.
+func (m *Manager) SendAgentMessage(sessionID, to, content string) error {
+	recipient, err := m.db.GetSessionByName(to)
+	if err != nil {
+		return err
+	}
+	if recipient == nil {
+		return fmt.Errorf("no session named '%s'", to)
+	}
+	if recipient.ID == sessionID {
+		return fmt.Errorf("cannot send a message to this session itself")
+	}
+
+	from, err := m.Name(sessionID)
+	if err != nil {
+		return err
+	}
+	if from == "" {
+		from = "anonymous-" + sessionID[:min(len(sessionID), 8)]
+	}
+	return m.db.SendAgentMessage(from, to, content)
+}
+
+// ReadAgentInbox returns the unread messages sent to a session and marks
+// them read. Only named sessions have an inbox.
+func (m *Manager) ReadAgentInbox(sessionID string) ([]mcp.AgentMessage, error) {
+	name, err := m.Name(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("this session has no name - only named sessions (mysis -s NAME) receive messages")
+	}
+
+	stored, err := m.db.ReadAgentInbox(name)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]mcp.AgentMessage, len(stored))
+	for i, msg := range stored {
+		messages[i] = mcp.AgentMessage{From: msg.Sender, Message: msg.Content, SentAt: msg.CreatedAt}
+	}
+	return messages, nil
+}
+
+// ReplaceHistory rewrites the stored active history of a session.
+// Replaced messages remain in the database marked as compacted.
+func (m *Manager) ReplaceHistory(sessionID string, history []provider.Message) error {
+	if err := m.db.ReplaceMessages(sessionID, history); err != nil {
+		return fmt.Errorf("replace history: %w", err)
+	}
+	log.Info().Str("session_id", sessionID).Int("count", len(history)).Msg("Replaced message history")
+	return nil
+}
+
+// UndoExchange deletes the last user message of a session, the messages
+// after it and orphaned tool results from the store. Returns the number of
+// messages deleted.
+func (m *Manager) UndoExchange(sessionID string) (int, error) {
+	removed, err := m.db.DeleteLastExchange(sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("undo exchange: %w", err)
+	}
+	log.Info().Str("session_id", sessionID).Int("count", removed).Msg("Undid last exchange")
+	return removed, nil
+}
+
+// DeleteMessage deletes a message of the active history of a session, with
+// the tool results or call that go with it, from the store. Returns the
+// number of messages deleted.
+func (m *Manager) DeleteMessage(sessionID string, msg provider.Message) (int, error) {
+	removed, err := m.db.DeleteMessage(sessionID, msg)
+	if err != nil {
+		return 0, fmt.Errorf("delete messages: %w", err)
+	}
+	log.Info().Str("session_id", sessionID).Int("count", removed).Msg("Deleted messages")
+	return removed, nil
+}
+
 // SelectProviderResult holds the result of provider selection.
 type SelectProviderResult struct {
 	Provider string
@@ -153,15 +272,39 @@ func (m *Manager) SelectProvider(cfg *config.Config, sessionName, providerFlag s
 	}, nil
 }
 
-// List returns recent sessions.
-func (m *Manager) List(limit int) ([]store.Session, error) {
-	sessions, err := m.db.ListSessions(limit)
+// List returns the sessions matching filter, most recent first.
+func (m *Manager) List(filter store.SessionFilter) ([]store.Session, error) {
+	sessions, err := m.db.ListSessions(filter)
 	if err != nil {
 		return nil, fmt.Errorf("list sessions: %w", err)
 	}
 	return sessions, nil
 }
 
+// LatestName returns the name of the most recently active named session, or
+// "" if there is none.
+func (m *Manager) LatestName() (string, error) {
+	sess, err := m.db.LatestNamedSession()
+	if err != nil {
+		return "", err
+	}
+	if sess == nil {
+		return "", nil
+	}
+	return *sess.Name, nil
+}
+
+// DeleteSessions deletes sessions by id with their messages, e.g. the
+// sessions of a prune. Returns the number deleted.
+func (m *Manager) DeleteSessions(ids []string) (int, error) {
+	deleted, err := m.db.DeleteSessions(ids)
+	if err != nil {
+		return 0, fmt.Errorf("delete sessions: %w", err)
+	}
+	log.Info().Int("count", deleted).Msg("Deleted sessions")
+	return deleted, nil
+}
+
 // DeleteByName deletes a session by name.
 func (m *Manager) DeleteByName(name string) error {
 	// Get session to verify it exists
@@ -181,6 +324,30 @@ func (m *Manager) DeleteByName(name string) error {
 	return nil
 }
 
+// Rename changes the name of a session.
+func (m *Manager) Rename(oldName, newName string) error {
+	existing, err := m.db.GetSessionByName(newName)
+	if err != nil {
+		return fmt.Errorf("get session: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("session '%s' already exists", newName)
+	}
+	return m.db.RenameSession(oldName, newName)
+}
+
+// Name returns the name of a session, or "" for anonymous sessions.
+func (m *Manager) Name(sessionID string) (string, error) {
+	sess, err := m.db.GetSession(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if sess == nil || sess.Name == nil {
+		return "", nil
+	}
+	return *sess.Name, nil
+}
+
 // GetByName retrieves a session by name.
 func (m *Manager) GetByName(name string) (*store.Session, error) {
 	sess, err := m.db.GetSessionByName(name)
@@ -215,3 +382,21 @@ func FormatDuration(d time.Duration) string {
 	}
 	return fmt.Sprintf("%d days", days)
 }
+
+// ParseSince parses a point in time relative to now, as given to --since
+// and --older-than: a duration such as "90m", "24h" or "7d" ago, or a date
+// such as "2006-01-02".
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q: expected a duration (24h, 7d) or a date (2006-01-02)", s)
+}
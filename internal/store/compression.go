@@ -47,8 +47,8 @@ func isStateQueryTool(toolName string) bool {
 	return false
 }
 
-// isAuthTool returns true if the tool is authentication-related (never compress).
-func isAuthTool(toolName string) bool {
+// IsAuthTool returns true if the tool is authentication-related (never compress).
+func IsAuthTool(toolName string) bool {
 	authTools := []string{
 		"login",
 		"register",
@@ -64,6 +64,16 @@ func isAuthTool(toolName string) bool {
 	return false
 }
 
+// RedactArguments returns the arguments of a call to toolName, replaced by
+// "[redacted]" for the auth tools and save_credentials, whose arguments
+// carry account passwords.
+func RedactArguments(toolName string, args json.RawMessage) json.RawMessage {
+	if IsAuthTool(toolName) || strings.EqualFold(toolName, "save_credentials") {
+		return json.RawMessage(`"[redacted]"`)
+	}
+	return args
+}
+
 // CompressHistory compresses old tool results while preserving recent context.
 func CompressHistory(messages []provider.Message, keepFullTurns int) []provider.Message {
 	if len(messages) == 0 {
@@ -99,50 +109,92 @@ func CompressHistory(messages []provider.Message, keepFullTurns int) []provider.
 		}
 	}
 
-	if cutoffIndex == -1 || cutoffIndex == 0 {
+	return compressBefore(messages, cutoffIndex)
+}
+
+// CompressHistoryByTokens compresses old tool results while keeping the most
+// recent messages that fit within tokenBudget (estimated) uncompressed.
+// The most recent turn is always kept in full, even if it exceeds the budget.
+func CompressHistoryByTokens(messages []provider.Message, tokenBudget int) []provider.Message {
+	if len(messages) == 0 || EstimateTokenCount(messages) <= tokenBudget {
+		return messages
+	}
+
+	// The window never starts after the last user message
+	lastTurn := len(messages)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			lastTurn = i
+			break
+		}
+	}
+
+	// Walk backwards adding messages while they fit the budget
+	cutoffIndex := len(messages)
+	used := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		used += estimateMessageTokens(messages[i])
+		if used > tokenBudget {
+			break
+		}
+		cutoffIndex = i
+	}
+
+	if cutoffIndex > lastTurn {
+		cutoffIndex = lastTurn
+	}
+
+	// Don't start the window on a tool result - its tool call would be compressed away
+	for cutoffIndex < lastTurn && messages[cutoffIndex].Role == "tool" {
+		cutoffIndex++
+	}
+
+	return compressBefore(messages, cutoffIndex)
+}
+
+// compressBefore compresses messages before cutoffIndex and keeps the rest unchanged.
+func compressBefore(messages []provider.Message, cutoffIndex int) []provider.Message {
+	if cutoffIndex <= 0 || cutoffIndex >= len(messages) {
 		return messages
 	}
 
 	// Build compressed history
 	compressed := make([]provider.Message, 0, len(messages))
 
-	// Second pass: compress old messages
+	// Compress old messages
 	for i := 0; i < cutoffIndex; i++ {
 		msg := messages[i]
 
 		// Keep user messages and assistant messages (they're small)
-		if msg.Role == "user" || msg.Role == "assistant" {
+		if msg.Role != "tool" {
 			compressed = append(compressed, msg)
 			continue
 		}
 
-		// Handle tool results
-		if msg.Role == "tool" {
-			// Find the tool name from the assistant message
-			toolName := findToolNameForResult(messages, i)
+		// Find the tool name from the assistant message
+		toolName := findToolNameForResult(messages, i)
 
-			// Never compress auth tools
-			if isAuthTool(toolName) {
-				compressed = append(compressed, msg)
-				continue
-			}
+		// Never compress auth tools
+		if IsAuthTool(toolName) {
+			compressed = append(compressed, msg)
+			continue
+		}
 
-			// For state queries in old section, always compress
-			if isStateQueryTool(toolName) {
-				compressedMsg := msg
-				compressedMsg.Content = compressedToolResult
-				compressed = append(compressed, compressedMsg)
-				continue
-			}
+		// For state queries in old section, always compress
+		if isStateQueryTool(toolName) {
+			compressedMsg := msg
+			compressedMsg.Content = compressedToolResult
+			compressed = append(compressed, compressedMsg)
+			continue
+		}
 
-			// For action tools, compress if result is too long
-			if len(msg.Content) > 500 {
-				compressedMsg := msg
-				compressedMsg.Content = msg.Content[:200] + "... [truncated]"
-				compressed = append(compressed, compressedMsg)
-			} else {
-				compressed = append(compressed, msg)
-			}
+		// For action tools, compress if result is too long
+		if len(msg.Content) > 500 {
+			compressedMsg := msg
+			compressedMsg.Content = msg.Content[:200] + "... [truncated]"
+			compressed = append(compressed, compressedMsg)
+		} else {
+			compressed = append(compressed, msg)
 		}
 	}
 
@@ -181,17 +233,64 @@ func findToolNameForResult(messages []provider.Message, resultIndex int) string
 func EstimateTokenCount(messages []provider.Message) int {
 	total := 0
 	for _, msg := range messages {
-		// Rough estimate: ~4 characters per token
-		total += len(msg.Content) / 4
+		total += estimateMessageTokens(msg)
+	}
+	return total
+}
+
+// RoleTokens holds estimated token counts per message role.
+type RoleTokens struct {
+	System    int
+	User      int
+	Assistant int
+	Tool      int
+}
+
+// Total returns the sum across all roles.
+func (r RoleTokens) Total() int {
+	return r.System + r.User + r.Assistant + r.Tool
+}
+
+// Add returns the per-role sum of two breakdowns.
+func (r RoleTokens) Add(other RoleTokens) RoleTokens {
+	return RoleTokens{
+		System:    r.System + other.System,
+		User:      r.User + other.User,
+		Assistant: r.Assistant + other.Assistant,
+		Tool:      r.Tool + other.Tool,
+	}
+}
 
-		// Add tool calls
-		if len(msg.ToolCalls) > 0 {
-			data, _ := json.Marshal(msg.ToolCalls)
-			total += len(data) / 4
+// EstimateTokensByRole estimates how many tokens each role contributes to messages.
+func EstimateTokensByRole(messages []provider.Message) RoleTokens {
+	var r RoleTokens
+	for _, msg := range messages {
+		tokens := estimateMessageTokens(msg)
+		switch msg.Role {
+		case "system":
+			r.System += tokens
+		case "user":
+			r.User += tokens
+		case "assistant":
+			r.Assistant += tokens
+		case "tool":
+			r.Tool += tokens
 		}
+	}
+	return r
+}
 
-		// Add role overhead
-		total += 4
+// estimateMessageTokens estimates the token count of a single message.
+func estimateMessageTokens(msg provider.Message) int {
+	// Rough estimate: ~4 characters per token
+	tokens := len(msg.Content) / 4
+
+	// Add tool calls
+	if len(msg.ToolCalls) > 0 {
+		data, _ := json.Marshal(msg.ToolCalls)
+		tokens += len(data) / 4
 	}
-	return total
+
+	// Add role overhead
+	return tokens + 4
 }
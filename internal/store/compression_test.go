@@ -1,6 +1,8 @@
 package store
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/xonecas/mysis/internal/provider"
@@ -175,9 +177,83 @@ func TestIsAuthTool(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := isAuthTool(tt.name)
+		got := IsAuthTool(tt.name)
 		if got != tt.want {
-			t.Errorf("isAuthTool(%q) = %v, want %v", tt.name, got, tt.want)
+			t.Errorf("IsAuthTool(%q) = %v, want %v", tt.name, got, tt.want)
 		}
 	}
 }
+
+func TestRedactArguments(t *testing.T) {
+	args := json.RawMessage(`{"username":"miner","password":"hunter2"}`)
+	for _, name := range []string{"login", "register", "save_credentials"} {
+		if got := string(RedactArguments(name, args)); got != `"[redacted]"` {
+			t.Errorf("RedactArguments(%q) = %s", name, got)
+		}
+	}
+	if got := RedactArguments("travel", json.RawMessage(`{"to":"Sol"}`)); string(got) != `{"to":"Sol"}` {
+		t.Errorf("RedactArguments(travel) = %s, want it unchanged", got)
+	}
+}
+
+func TestCompressHistoryByTokens(t *testing.T) {
+	bigResult := strings.Repeat("x", 800) // ~200 tokens
+	messages := []provider.Message{
+		{Role: "system", Content: "system prompt"},
+		{Role: "user", Content: "status?"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "call1", Name: "get_status"}}},
+		{Role: "tool", Content: bigResult, ToolCallID: "call1"},
+		{Role: "user", Content: "status again?"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "call2", Name: "get_status"}}},
+		{Role: "tool", Content: bigResult, ToolCallID: "call2"},
+		{Role: "assistant", Content: "done"},
+	}
+
+	t.Run("under budget", func(t *testing.T) {
+		compressed := CompressHistoryByTokens(messages, 10000)
+		if compressed[3].Content != bigResult {
+			t.Errorf("expected no compression under budget")
+		}
+	})
+
+	t.Run("over budget keeps recent window", func(t *testing.T) {
+		compressed := CompressHistoryByTokens(messages, 300)
+		if len(compressed) != len(messages) {
+			t.Fatalf("got %d messages, want %d", len(compressed), len(messages))
+		}
+		if compressed[0].Content != "system prompt" {
+			t.Errorf("system prompt should be kept, got %q", compressed[0].Content)
+		}
+		if compressed[3].Content != compressedToolResult {
+			t.Errorf("old state result not compressed, got %d chars", len(compressed[3].Content))
+		}
+		if compressed[6].Content != bigResult {
+			t.Errorf("recent state result should be kept full")
+		}
+	})
+
+	t.Run("last turn kept even when over budget", func(t *testing.T) {
+		compressed := CompressHistoryByTokens(messages, 10)
+		if compressed[6].Content != bigResult {
+			t.Errorf("last turn should never be compressed")
+		}
+	})
+}
+
+func TestEstimateTokensByRole(t *testing.T) {
+	messages := []provider.Message{
+		{Role: "system", Content: strings.Repeat("s", 40)},
+		{Role: "user", Content: strings.Repeat("u", 8)},
+		{Role: "assistant", Content: strings.Repeat("a", 16)},
+		{Role: "tool", Content: strings.Repeat("t", 400)},
+	}
+
+	got := EstimateTokensByRole(messages)
+	want := RoleTokens{System: 14, User: 6, Assistant: 8, Tool: 104}
+	if got != want {
+		t.Errorf("EstimateTokensByRole() = %+v, want %+v", got, want)
+	}
+	if got.Total() != EstimateTokenCount(messages) {
+		t.Errorf("Total() = %d, want %d", got.Total(), EstimateTokenCount(messages))
+	}
+}
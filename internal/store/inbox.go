@@ -0,0 +1,70 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// AgentMessage is a message left by one session for another, addressed by
+// session name, for the agent messaging tools.
+type AgentMessage struct {
+	ID        int64
+	Sender    string
+	Recipient string
+	Content   string
+	CreatedAt time.Time
+}
+
+// SendAgentMessage leaves a message in the recipient's inbox.
+func (s *Store) SendAgentMessage(sender, recipient, content string) error {
+	query := `INSERT INTO agent_messages (sender, recipient, content, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, sender, recipient, content, time.Now()); err != nil {
+		return fmt.Errorf("send agent message: %w", err)
+	}
+	return nil
+}
+
+// ReadAgentInbox returns the unread messages of the recipient, oldest
+// first, and marks them read.
+func (s *Store) ReadAgentInbox(recipient string) ([]AgentMessage, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query(`
+		SELECT id, sender, recipient, content, created_at
+		FROM agent_messages
+		WHERE recipient = ? AND read_at IS NULL
+		ORDER BY id ASC
+	`, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("query agent inbox: %w", err)
+	}
+	var messages []AgentMessage
+	for rows.Next() {
+		var msg AgentMessage
+		if err := rows.Scan(&msg.ID, &msg.Sender, &msg.Recipient, &msg.Content, &msg.CreatedAt); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan agent message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, msg := range messages {
+		if _, err := tx.Exec(`UPDATE agent_messages SET read_at = ? WHERE id = ?`, time.Now(), msg.ID); err != nil {
+			return nil, fmt.Errorf("mark agent message read: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return messages, nil
+}
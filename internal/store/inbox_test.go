@@ -0,0 +1,37 @@
+package store
+
+import "testing"
+
+func TestAgentInbox(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	recipient := "test-inbox-trader"
+	defer func() { _, _ = store.db.Exec(`DELETE FROM agent_messages WHERE recipient = ?`, recipient) }()
+
+	for _, content := range []string{"ore at Sol 4", "sell at the station"} {
+		if err := store.SendAgentMessage("test-inbox-miner", recipient, content); err != nil {
+			t.Fatalf("SendAgentMessage: %v", err)
+		}
+	}
+
+	messages, err := store.ReadAgentInbox(recipient)
+	if err != nil {
+		t.Fatalf("ReadAgentInbox: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Content != "ore at Sol 4" || messages[1].Sender != "test-inbox-miner" {
+		t.Fatalf("messages = %+v, want both in order", messages)
+	}
+
+	// Messages are returned once
+	messages, err = store.ReadAgentInbox(recipient)
+	if err != nil {
+		t.Fatalf("ReadAgentInbox: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("second read = %+v, want none", messages)
+	}
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestReplaceMessages(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionID := "test-replace-session"
+	if err := store.CreateSession(sessionID, "ollama", "test-model", nil); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer func() { _ = store.DeleteSession(sessionID) }()
+
+	for _, msg := range []provider.Message{
+		{Role: "user", Content: "old question"},
+		{Role: "assistant", Content: "old answer"},
+		{Role: "user", Content: "recent question"},
+		{Role: "assistant", Content: "recent answer"},
+	} {
+		if err := store.SaveMessage(sessionID, msg); err != nil {
+			t.Fatalf("failed to save message: %v", err)
+		}
+	}
+
+	recent := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	replacement := []provider.Message{
+		{Role: "system", Content: "summary of old turns"},
+		{Role: "user", Content: "recent question", CreatedAt: recent},
+		{Role: "assistant", Content: "recent answer", CreatedAt: recent},
+	}
+	if err := store.ReplaceMessages(sessionID, replacement); err != nil {
+		t.Fatalf("ReplaceMessages() error = %v", err)
+	}
+
+	loaded, err := store.LoadMessages(sessionID)
+	if err != nil {
+		t.Fatalf("LoadMessages() error = %v", err)
+	}
+
+	if len(loaded) != len(replacement) {
+		t.Fatalf("loaded %d messages, want %d", len(loaded), len(replacement))
+	}
+	for i, msg := range loaded {
+		if msg.Role != replacement[i].Role || msg.Content != replacement[i].Content {
+			t.Errorf("message %d = %s %q, want %s %q", i, msg.Role, msg.Content, replacement[i].Role, replacement[i].Content)
+		}
+	}
+	if !loaded[1].CreatedAt.Equal(recent) {
+		t.Errorf("CreatedAt = %v, want original timestamp %v", loaded[1].CreatedAt, recent)
+	}
+
+	var compacted int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE session_id = ? AND compacted = 1`, sessionID).Scan(&compacted); err != nil {
+		t.Fatalf("count compacted: %v", err)
+	}
+	if compacted != 4 {
+		t.Errorf("compacted rows = %d, want 4", compacted)
+	}
+}
+
+func TestDeleteLastExchange(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionID := "test-undo-session"
+	if err := store.CreateSession(sessionID, "ollama", "test-model", nil); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer func() { _ = store.DeleteSession(sessionID) }()
+
+	history := []provider.Message{
+		{Role: "tool", Content: "orphaned result", ToolCallID: "gone"},
+		{Role: "user", Content: "mine"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "1", Name: "mine"}}},
+		{Role: "tool", Content: "mined", ToolCallID: "1"},
+		{Role: "assistant", Content: "Mined ore"},
+		{Role: "user", Content: "sell everything"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{ID: "2", Name: "sell"}}},
+		{Role: "tool", Content: "sold", ToolCallID: "2"},
+		{Role: "assistant", Content: "Sold it all"},
+	}
+	for _, msg := range history {
+		if err := store.SaveMessage(sessionID, msg); err != nil {
+			t.Fatalf("failed to save message: %v", err)
+		}
+	}
+
+	removed, err := store.DeleteLastExchange(sessionID)
+	if err != nil {
+		t.Fatalf("DeleteLastExchange() error = %v", err)
+	}
+	if removed != 5 {
+		t.Errorf("removed = %d, want 5", removed)
+	}
+
+	loaded, err := store.LoadMessages(sessionID)
+	if err != nil {
+		t.Fatalf("LoadMessages() error = %v", err)
+	}
+	want := RemoveLastExchange(history)
+	if len(loaded) != 4 || len(want) != 4 {
+		t.Fatalf("loaded %d messages, RemoveLastExchange kept %d, want 4", len(loaded), len(want))
+	}
+	for i, msg := range loaded {
+		if msg.Content != want[i].Content || msg.Role != want[i].Role {
+			t.Errorf("message %d = %s %q, want %s %q", i, msg.Role, msg.Content, want[i].Role, want[i].Content)
+		}
+	}
+	if loaded[0].Content != "mine" || loaded[3].Content != "Mined ore" {
+		t.Errorf("kept %q ... %q", loaded[0].Content, loaded[3].Content)
+	}
+
+	if removed, err := store.DeleteLastExchange(sessionID); err != nil || removed != 4 {
+		t.Errorf("second undo removed %d (%v), want 4", removed, err)
+	}
+	if removed, err := store.DeleteLastExchange(sessionID); err != nil || removed != 0 {
+		t.Errorf("undo of empty history removed %d (%v), want 0", removed, err)
+	}
+}
+
+func TestDeleteMessage(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionID := "test-delete-messages-session"
+	if err := store.CreateSession(sessionID, "ollama", "test-model", nil); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer func() { _ = store.DeleteSession(sessionID) }()
+
+	history := []provider.Message{
+		{Role: "user", Content: "mine"},
+		{Role: "assistant", Content: "Mining", ToolCalls: []provider.ToolCall{{ID: "1", Name: "mine"}, {ID: "2", Name: "scan"}}},
+		{Role: "tool", Content: "mined", ToolCallID: "1"},
+		{Role: "tool", Content: "scanned", ToolCallID: "2"},
+		{Role: "assistant", Content: "Mined ore"},
+	}
+	for _, msg := range history {
+		if err := store.SaveMessage(sessionID, msg); err != nil {
+			t.Fatalf("failed to save message: %v", err)
+		}
+	}
+
+	// A tool result goes with its call and the other results
+	group := MessageGroup(history, 3)
+	if len(group) != 3 || group[0] != 1 || group[2] != 3 {
+		t.Fatalf("MessageGroup(3) = %v, want [1 2 3]", group)
+	}
+	if g := MessageGroup(history, 4); len(g) != 1 || g[0] != 4 {
+		t.Errorf("MessageGroup(4) = %v, want [4]", g)
+	}
+
+	// The in-memory history starts with the session's system prompt, which
+	// has no row: the group is found from the stored rows
+	withPrompt := append([]provider.Message{{Role: "system", Content: "You are a miner"}}, history...)
+	removed, err := store.DeleteMessage(sessionID, withPrompt[4])
+	if err != nil || removed != 3 {
+		t.Fatalf("DeleteMessage() = %d, %v; want 3", removed, err)
+	}
+	loaded, err := store.LoadMessages(sessionID)
+	if err != nil {
+		t.Fatalf("LoadMessages() error = %v", err)
+	}
+	want := RemoveMessages(history, group)
+	if len(loaded) != 2 || len(want) != 2 || loaded[0].Content != "mine" || loaded[1].Content != "Mined ore" {
+		t.Errorf("loaded %+v, want %+v", loaded, want)
+	}
+
+	if _, err := store.DeleteMessage(sessionID, withPrompt[0]); err == nil {
+		t.Error("DeleteMessage() of the system prompt: expected an error")
+	}
+	if removed, err := store.DeleteMessage(sessionID, withPrompt[5]); err != nil || removed != 1 {
+		t.Errorf("DeleteMessage() = %d, %v; want 1", removed, err)
+	}
+	if loaded, _ := store.LoadMessages(sessionID); len(loaded) != 1 || loaded[0].Content != "mine" {
+		t.Errorf("loaded %+v, want only the user message", loaded)
+	}
+}
+
+func TestSearchMessages(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionID := "test-search-session"
+	if err := store.CreateSession(sessionID, "ollama", "test-model", nil); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer func() { _ = store.DeleteSession(sessionID) }()
+
+	for _, msg := range []provider.Message{
+		{Role: "user", Content: "mine Iron ore"},
+		{Role: "assistant", Content: "mined 10 iron"},
+	} {
+		if err := store.SaveMessage(sessionID, msg); err != nil {
+			t.Fatalf("failed to save message: %v", err)
+		}
+	}
+	if err := store.ReplaceMessages(sessionID, []provider.Message{
+		{Role: "system", Content: "summary: mined iron"},
+		{Role: "user", Content: "sell copper"},
+	}); err != nil {
+		t.Fatalf("ReplaceMessages() error = %v", err)
+	}
+
+	all, err := store.SearchMessages(sessionID, "IRON", false, 10)
+	if err != nil {
+		t.Fatalf("SearchMessages() error = %v", err)
+	}
+	if len(all) != 3 || all[0].Content != "mine Iron ore" {
+		t.Errorf("SearchMessages(all) = %v, want the 3 iron messages oldest first", all)
+	}
+
+	compacted, err := store.SearchMessages(sessionID, "iron", true, 10)
+	if err != nil {
+		t.Fatalf("SearchMessages() error = %v", err)
+	}
+	if len(compacted) != 2 {
+		t.Errorf("SearchMessages(compacted) returned %d messages, want 2", len(compacted))
+	}
+
+	limited, err := store.SearchMessages(sessionID, "iron", false, 1)
+	if err != nil {
+		t.Fatalf("SearchMessages() error = %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("SearchMessages(limit 1) returned %d messages", len(limited))
+	}
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+func TestRenameSession(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionID := "test-rename-session"
+	name := "test-rename-old"
+	if err := store.CreateSession(sessionID, "ollama", "test-model", &name); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer func() { _ = store.DeleteSession(sessionID) }()
+
+	if err := store.RenameSession(name, "test-rename-new"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+	sess, err := store.GetSessionByName("test-rename-new")
+	if err != nil || sess == nil || sess.ID != sessionID {
+		t.Fatalf("GetSessionByName() = %+v, %v; want renamed session", sess, err)
+	}
+	if err := store.RenameSession(name, "other"); err == nil {
+		t.Error("RenameSession() of a missing session should fail")
+	}
+}
+
+func TestBackup(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := store.Backup(path); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if err := store.Backup(path); err == nil {
+		t.Error("Backup() over an existing file should fail")
+	}
+}
+
+func TestSessionSystemPrompt(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionID := "test-system-prompt-session"
+	if err := store.CreateSession(sessionID, "ollama", "test-model", nil); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer func() { _ = store.DeleteSession(sessionID) }()
+
+	if prompt, err := store.GetSessionSystemPrompt(sessionID); err != nil || prompt != "" {
+		t.Fatalf("GetSessionSystemPrompt() = %q, %v; want empty", prompt, err)
+	}
+	if err := store.SetSessionSystemPrompt(sessionID, "Be a trader"); err != nil {
+		t.Fatalf("SetSessionSystemPrompt() error = %v", err)
+	}
+	if prompt, err := store.GetSessionSystemPrompt(sessionID); err != nil || prompt != "Be a trader" {
+		t.Errorf("GetSessionSystemPrompt() = %q, %v; want %q", prompt, err, "Be a trader")
+	}
+}
+
+func TestListSessionsFilter(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	name := "Test-Filter-Miner"
+	for id, name := range map[string]*string{"test-filter-named": &name, "test-filter-anonymous": nil} {
+		if err := store.CreateSession(id, "test-filter-provider", "test-model", name); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		defer func() { _ = store.DeleteSession(id) }()
+	}
+
+	sessions, err := store.ListSessions(SessionFilter{Provider: "test-filter-provider"})
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("ListSessions(provider) = %d sessions, %v; want 2", len(sessions), err)
+	}
+	sessions, err = store.ListSessions(SessionFilter{Provider: "test-filter-provider", NameContains: "filter-min"})
+	if err != nil || len(sessions) != 1 || sessions[0].ID != "test-filter-named" {
+		t.Fatalf("ListSessions(name) = %+v, %v; want the named session", sessions, err)
+	}
+	sessions, err = store.ListSessions(SessionFilter{Provider: "test-filter-provider", Anonymous: true})
+	if err != nil || len(sessions) != 1 || sessions[0].ID != "test-filter-anonymous" {
+		t.Fatalf("ListSessions(anonymous) = %+v, %v; want the anonymous session", sessions, err)
+	}
+	sessions, err = store.ListSessions(SessionFilter{Provider: "test-filter-provider", Before: time.Now().Add(-time.Hour)})
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("ListSessions(before) = %+v, %v; want none", sessions, err)
+	}
+	sessions, err = store.ListSessions(SessionFilter{Provider: "test-filter-provider", Since: time.Now().Add(time.Hour)})
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("ListSessions(since) = %+v, %v; want none", sessions, err)
+	}
+	sessions, err = store.ListSessions(SessionFilter{Provider: "test-filter-provider", Since: time.Now().Add(-time.Hour), Limit: 1})
+	if err != nil || len(sessions) != 1 {
+		t.Errorf("ListSessions(since, limit) = %+v, %v; want 1 session", sessions, err)
+	}
+}
+
+func TestDeleteSessions(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ids := []string{"test-delete-sessions-1", "test-delete-sessions-2"}
+	for _, id := range ids {
+		if err := store.CreateSession(id, "ollama", "test-model", nil); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		if err := store.SaveMessage(id, provider.Message{Role: "user", Content: "hi"}); err != nil {
+			t.Fatalf("failed to save message: %v", err)
+		}
+	}
+
+	deleted, err := store.DeleteSessions(append(ids, "test-delete-sessions-missing"))
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteSessions() = %d, %v; want 2", deleted, err)
+	}
+	for _, id := range ids {
+		if sess, err := store.GetSession(id); err != nil || sess != nil {
+			t.Errorf("GetSession(%s) = %+v, %v; want deleted", id, sess, err)
+		}
+		if messages, err := store.LoadMessages(id); err != nil || len(messages) != 0 {
+			t.Errorf("LoadMessages(%s) = %d messages, %v; want none", id, len(messages), err)
+		}
+	}
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	store, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	problems, err := store.IntegrityCheck()
+	if err != nil || len(problems) != 0 {
+		t.Errorf("IntegrityCheck() = %v, %v; want no problems", problems, err)
+	}
+}
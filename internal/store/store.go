@@ -77,6 +77,7 @@ func (s *Store) initSchema() error {
 			name TEXT UNIQUE,
 			provider TEXT NOT NULL,
 			model TEXT NOT NULL,
+			system_prompt TEXT,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			last_active_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
@@ -89,6 +90,7 @@ func (s *Store) initSchema() error {
 			tool_call_id TEXT,
 			tool_calls TEXT,
 			reasoning TEXT,
+			compacted INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
 		);
@@ -102,10 +104,78 @@ func (s *Store) initSchema() error {
 			FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
 		);
 
+		CREATE TABLE IF NOT EXISTS agent_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sender TEXT NOT NULL,
+			recipient TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			read_at DATETIME
+		);
+
 		CREATE INDEX IF NOT EXISTS idx_messages_session 
 		ON messages(session_id, created_at);
+
+		CREATE INDEX IF NOT EXISTS idx_agent_messages_recipient
+		ON agent_messages(recipient, read_at);
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return s.migrateSchema()
+}
+
+// migrateSchema adds columns introduced after a database was first created.
+func (s *Store) migrateSchema() error {
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"messages", "compacted", "INTEGER NOT NULL DEFAULT 0"},
+		{"sessions", "system_prompt", "TEXT"},
+	}
+
+	for _, col := range columns {
+		exists, err := s.hasColumn(col.table, col.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		//nolint:gosec // G201: Table and column names come from the static list above
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", col.table, col.name, col.definition)
+		if _, err := s.db.Exec(query); err != nil {
+			return fmt.Errorf("add column %s.%s: %w", col.table, col.name, err)
+		}
+		log.Info().Str("table", col.table).Str("column", col.name).Msg("Migrated database schema")
+	}
+
+	return nil
+}
+
+// hasColumn reports whether a table has the named column.
+func (s *Store) hasColumn(table, column string) (bool, error) {
+	rows, err := s.db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return false, fmt.Errorf("table info %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, fmt.Errorf("scan table info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
 }
 
 // CreateSession creates a new session.
@@ -121,6 +191,44 @@ func (s *Store) CreateSession(id, provider, model string, name *string) error {
 	return nil
 }
 
+// UpdateSessionProvider changes the provider and model of a session.
+func (s *Store) UpdateSessionProvider(id, provider, model string) error {
+	query := `UPDATE sessions SET provider = ?, model = ? WHERE id = ?`
+	if _, err := s.db.Exec(query, provider, model, id); err != nil {
+		return fmt.Errorf("update session provider: %w", err)
+	}
+	return nil
+}
+
+// SetSessionSystemPrompt stores the system prompt set for a session at
+// runtime. An empty prompt clears it.
+func (s *Store) SetSessionSystemPrompt(id, prompt string) error {
+	var value interface{}
+	if prompt != "" {
+		value = prompt
+	}
+	query := `UPDATE sessions SET system_prompt = ? WHERE id = ?`
+	if _, err := s.db.Exec(query, value, id); err != nil {
+		return fmt.Errorf("update session system prompt: %w", err)
+	}
+	return nil
+}
+
+// GetSessionSystemPrompt returns the system prompt stored for a session, or
+// "" if none is set.
+func (s *Store) GetSessionSystemPrompt(id string) (string, error) {
+	var prompt sql.NullString
+	query := `SELECT system_prompt FROM sessions WHERE id = ?`
+	err := s.db.QueryRow(query, id).Scan(&prompt)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get session system prompt: %w", err)
+	}
+	return prompt.String, nil
+}
+
 // GetSession retrieves a session by ID.
 func (s *Store) GetSession(id string) (*Session, error) {
 	query := `
@@ -185,16 +293,86 @@ func (s *Store) GetSessionByName(name string) (*Session, error) {
 	return &sess, nil
 }
 
-// ListSessions returns all sessions ordered by most recent.
-func (s *Store) ListSessions(limit int) ([]Session, error) {
+// LatestNamedSession returns the most recently active named session, or nil
+// if there is none.
+func (s *Store) LatestNamedSession() (*Session, error) {
 	query := `
 		SELECT id, name, provider, model, created_at, last_active_at
 		FROM sessions
+		WHERE name IS NOT NULL
 		ORDER BY last_active_at DESC
-		LIMIT ?
+		LIMIT 1
 	`
 
-	rows, err := s.db.Query(query, limit)
+	var sess Session
+	var nameVal sql.NullString
+	err := s.db.QueryRow(query).Scan(
+		&sess.ID,
+		&nameVal,
+		&sess.Provider,
+		&sess.Model,
+		&sess.CreatedAt,
+		&sess.LastActiveAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest named session: %w", err)
+	}
+
+	if nameVal.Valid {
+		sess.Name = &nameVal.String
+	}
+
+	return &sess, nil
+}
+
+// SessionFilter selects the sessions ListSessions returns. Zero fields
+// match every session.
+type SessionFilter struct {
+	Provider     string
+	NameContains string    // Case-insensitive; excludes anonymous sessions
+	Anonymous    bool      // Only anonymous sessions
+	Since        time.Time // Active at or after
+	Before       time.Time // Last active before
+	Limit        int       // 0 for no limit
+}
+
+// ListSessions returns the sessions matching filter ordered by most recent.
+func (s *Store) ListSessions(filter SessionFilter) ([]Session, error) {
+	query := `
+		SELECT id, name, provider, model, created_at, last_active_at
+		FROM sessions
+		WHERE 1 = 1`
+	var args []any
+	if filter.Provider != "" {
+		query += ` AND provider = ?`
+		args = append(args, filter.Provider)
+	}
+	if filter.NameContains != "" {
+		query += ` AND instr(lower(name), lower(?)) > 0`
+		args = append(args, filter.NameContains)
+	}
+	if filter.Anonymous {
+		query += ` AND name IS NULL`
+	}
+	// Timestamps are stored as CURRENT_TIMESTAMP text in UTC
+	if !filter.Since.IsZero() {
+		query += ` AND last_active_at >= ?`
+		args = append(args, filter.Since.UTC().Format(time.DateTime))
+	}
+	if !filter.Before.IsZero() {
+		query += ` AND last_active_at < ?`
+		args = append(args, filter.Before.UTC().Format(time.DateTime))
+	}
+	query += ` ORDER BY last_active_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list sessions: %w", err)
 	}
@@ -234,6 +412,59 @@ func (s *Store) TouchSession(id string) error {
 
 // SaveMessage stores a message in the database.
 func (s *Store) SaveMessage(sessionID string, msg provider.Message) error {
+	if err := insertMessage(s.db, sessionID, msg, nil); err != nil {
+		return err
+	}
+
+	// Touch session to update last_active_at
+	return s.TouchSession(sessionID)
+}
+
+// ReplaceMessages rewrites the active history of a session.
+// Existing messages are kept in the database but marked as compacted, so they
+// no longer load into the session's context. The replacement messages keep
+// their original timestamps.
+func (s *Store) ReplaceMessages(sessionID string, messages []provider.Message) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `UPDATE messages SET compacted = 1 WHERE session_id = ? AND compacted = 0`
+	if _, err := tx.Exec(query, sessionID); err != nil {
+		return fmt.Errorf("mark compacted messages: %w", err)
+	}
+
+	for _, msg := range messages {
+		createdAt := msg.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		timestamp := createdAt.UTC().Format(sqliteTimeFormat)
+		if err := insertMessage(tx, sessionID, msg, &timestamp); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return s.TouchSession(sessionID)
+}
+
+// sqliteTimeFormat matches the format SQLite uses for CURRENT_TIMESTAMP.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertMessage inserts a single message row.
+// A nil createdAt uses the database default (CURRENT_TIMESTAMP).
+func insertMessage(db execer, sessionID string, msg provider.Message, createdAt *string) error {
 	// Marshal tool calls to JSON if present
 	var toolCallsJSON *string
 	if len(msg.ToolCalls) > 0 {
@@ -260,43 +491,83 @@ func (s *Store) SaveMessage(sessionID string, msg provider.Message) error {
 	}
 
 	query := `
-		INSERT INTO messages (session_id, role, content, tool_call_id, tool_calls, reasoning)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO messages (session_id, role, content, tool_call_id, tool_calls, reasoning, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))
 	`
-	_, err := s.db.Exec(query, sessionID, msg.Role, msg.Content, toolCallID, toolCallsJSON, reasoning)
+	_, err := db.Exec(query, sessionID, msg.Role, msg.Content, toolCallID, toolCallsJSON, reasoning, createdAt)
 	if err != nil {
 		return fmt.Errorf("save message: %w", err)
 	}
 
-	// Touch session to update last_active_at
-	return s.TouchSession(sessionID)
+	return nil
 }
 
-// LoadMessages retrieves all messages for a session.
+// LoadMessages retrieves the active (non-compacted) messages for a session.
 func (s *Store) LoadMessages(sessionID string) ([]provider.Message, error) {
+	messages, _, err := loadMessages(s.db, sessionID)
+	return messages, err
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadMessages returns the active messages of a session and their row IDs.
+func loadMessages(db querier, sessionID string) ([]provider.Message, []int64, error) {
+	query := `
+		SELECT id, role, content, tool_call_id, tool_calls, reasoning, created_at
+		FROM messages
+		WHERE session_id = ? AND compacted = 0
+		ORDER BY id ASC
+	`
+
+	rows, err := db.Query(query, sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load messages: %w", err)
+	}
+	return scanMessages(rows)
+}
+
+// SearchMessages returns up to limit messages of a session whose content
+// contains text, ignoring ASCII case, oldest first. Messages replaced by a
+// compaction summary are searched too; they are the only ones searched if
+// compactedOnly is set.
+func (s *Store) SearchMessages(sessionID, text string, compactedOnly bool, limit int) ([]provider.Message, error) {
 	query := `
-		SELECT role, content, tool_call_id, tool_calls, reasoning, created_at
+		SELECT id, role, content, tool_call_id, tool_calls, reasoning, created_at
 		FROM messages
-		WHERE session_id = ?
-		ORDER BY created_at ASC
+		WHERE session_id = ? AND instr(lower(content), lower(?)) > 0
 	`
+	if compactedOnly {
+		query += " AND compacted = 1"
+	}
+	query += " ORDER BY id ASC LIMIT ?"
 
-	rows, err := s.db.Query(query, sessionID)
+	rows, err := s.db.Query(query, sessionID, text, limit)
 	if err != nil {
-		return nil, fmt.Errorf("load messages: %w", err)
+		return nil, fmt.Errorf("search messages: %w", err)
 	}
+	messages, _, err := scanMessages(rows)
+	return messages, err
+}
+
+// scanMessages reads message rows and closes them.
+func scanMessages(rows *sql.Rows) ([]provider.Message, []int64, error) {
 	defer func() { _ = rows.Close() }()
 
 	var messages []provider.Message
+	var ids []int64
 	for rows.Next() {
 		var msg provider.Message
+		var id int64
 		var toolCallID sql.NullString
 		var toolCallsJSON sql.NullString
 		var reasoning sql.NullString
 		var createdAt string
 
-		if err := rows.Scan(&msg.Role, &msg.Content, &toolCallID, &toolCallsJSON, &reasoning, &createdAt); err != nil {
-			return nil, fmt.Errorf("scan message: %w", err)
+		if err := rows.Scan(&id, &msg.Role, &msg.Content, &toolCallID, &toolCallsJSON, &reasoning, &createdAt); err != nil {
+			return nil, nil, fmt.Errorf("scan message: %w", err)
 		}
 
 		// Parse timestamp (SQLite CURRENT_TIMESTAMP uses ISO 8601 / RFC3339)
@@ -312,7 +583,7 @@ func (s *Store) LoadMessages(sessionID string) ([]provider.Message, error) {
 
 		if toolCallsJSON.Valid {
 			if err := json.Unmarshal([]byte(toolCallsJSON.String), &msg.ToolCalls); err != nil {
-				return nil, fmt.Errorf("unmarshal tool calls: %w", err)
+				return nil, nil, fmt.Errorf("unmarshal tool calls: %w", err)
 			}
 		}
 
@@ -321,9 +592,68 @@ func (s *Store) LoadMessages(sessionID string) ([]provider.Message, error) {
 		}
 
 		messages = append(messages, msg)
+		ids = append(ids, id)
+	}
+
+	return messages, ids, rows.Err()
+}
+
+// DeleteLastExchange deletes the messages LastExchange selects from the
+// active history of a session. Returns the number of messages deleted.
+func (s *Store) DeleteLastExchange(sessionID string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	messages, ids, err := loadMessages(tx, sessionID)
+	if err != nil {
+		return 0, err
+	}
+	remove := LastExchange(messages)
+	for _, i := range remove {
+		if _, err := tx.Exec(`DELETE FROM messages WHERE id = ?`, ids[i]); err != nil {
+			return 0, fmt.Errorf("delete message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return len(remove), nil
+}
+
+// DeleteMessage deletes msg from the active history of a session with the
+// messages MessageGroup selects for it. The group is computed from the
+// stored rows, as a caller's history may hold messages without a row, e.g.
+// the session's system prompt. Returns the number of messages deleted.
+func (s *Store) DeleteMessage(sessionID string, msg provider.Message) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	messages, ids, err := loadMessages(tx, sessionID)
+	if err != nil {
+		return 0, err
+	}
+	i := IndexOfMessage(messages, msg)
+	if i < 0 {
+		return 0, fmt.Errorf("delete message: the %s message is not stored", msg.Role)
+	}
+	remove := MessageGroup(messages, i)
+	for _, i := range remove {
+		if _, err := tx.Exec(`DELETE FROM messages WHERE id = ?`, ids[i]); err != nil {
+			return 0, fmt.Errorf("delete message: %w", err)
+		}
 	}
 
-	return messages, rows.Err()
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return len(remove), nil
 }
 
 // DeleteSession deletes a session and all its messages.
@@ -333,6 +663,82 @@ func (s *Store) DeleteSession(id string) error {
 	return err
 }
 
+// DeleteSessions deletes the sessions with the given ids and their messages
+// in one transaction. Returns the number of sessions deleted.
+func (s *Store) DeleteSessions(ids []string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	deleted := 0
+	for _, id := range ids {
+		result, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+		if err != nil {
+			return 0, fmt.Errorf("delete session: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("get rows affected: %w", err)
+		}
+		deleted += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return deleted, nil
+}
+
+// RenameSession changes the name of a session.
+func (s *Store) RenameSession(oldName, newName string) error {
+	query := `UPDATE sessions SET name = ? WHERE name = ?`
+	result, err := s.db.Exec(query, newName, oldName)
+	if err != nil {
+		return fmt.Errorf("rename session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("session '%s' not found", oldName)
+	}
+	return nil
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns the problems it
+// reports, none for a healthy database.
+func (s *Store) IntegrityCheck() ([]string, error) {
+	rows, err := s.db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("scan integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// Backup writes a consistent copy of the database to path, which must not
+// exist yet. It is safe while other processes use the database.
+func (s *Store) Backup(path string) error {
+	if _, err := s.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("backup database: %w", err)
+	}
+	return nil
+}
+
 // DeleteSessionByName deletes a session by name and all its messages.
 func (s *Store) DeleteSessionByName(name string) error {
 	query := `DELETE FROM sessions WHERE name = ?`
@@ -0,0 +1,113 @@
+package store
+
+import "github.com/xonecas/mysis/internal/provider"
+
+// LastExchange returns the indexes, ascending, of the messages undoing the
+// last exchange removes: the last user message and everything after it,
+// plus tool results whose tool call is no longer in the history. Returns
+// nil when there is no user message.
+func LastExchange(messages []provider.Message) []int {
+	cut := -1
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			cut = i
+			break
+		}
+	}
+	if cut < 0 {
+		return nil
+	}
+
+	calls := make(map[string]bool)
+	var remove []int
+	for i, msg := range messages[:cut] {
+		for _, tc := range msg.ToolCalls {
+			calls[tc.ID] = true
+		}
+		if msg.Role == "tool" && !calls[msg.ToolCallID] {
+			remove = append(remove, i)
+		}
+	}
+	for i := cut; i < len(messages); i++ {
+		remove = append(remove, i)
+	}
+	return remove
+}
+
+// MessageGroup returns the indexes, ascending, of the messages deleting the
+// message at i removes: the message and, for an assistant message with tool
+// calls, the results answering them. A tool result selects the assistant
+// message calling it with all its results, as providers reject tool calls
+// without results.
+func MessageGroup(messages []provider.Message, i int) []int {
+	if i < 0 || i >= len(messages) {
+		return nil
+	}
+	if messages[i].Role == "tool" {
+		for j := i - 1; j >= 0; j-- {
+			for _, tc := range messages[j].ToolCalls {
+				if tc.ID == messages[i].ToolCallID {
+					return MessageGroup(messages, j)
+				}
+			}
+		}
+		return []int{i}
+	}
+
+	calls := make(map[string]bool, len(messages[i].ToolCalls))
+	for _, tc := range messages[i].ToolCalls {
+		calls[tc.ID] = true
+	}
+	group := []int{i}
+	for j := i + 1; j < len(messages) && len(calls) > 0; j++ {
+		if messages[j].Role == "tool" && calls[messages[j].ToolCallID] {
+			group = append(group, j)
+		}
+	}
+	return group
+}
+
+// IndexOfMessage returns the index of the last message in messages with the
+// role, content, reasoning and tool calls of msg, or -1. Timestamps are not
+// compared: stored ones only keep seconds.
+func IndexOfMessage(messages []provider.Message, msg provider.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if m := messages[i]; m.Role == msg.Role && m.Content == msg.Content &&
+			m.ToolCallID == msg.ToolCallID && m.Reasoning == msg.Reasoning && sameToolCalls(m.ToolCalls, msg.ToolCalls) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameToolCalls reports whether a and b call the same tool call IDs.
+func sameToolCalls(a, b []provider.ToolCall) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// RemoveLastExchange returns messages without those LastExchange selects.
+func RemoveLastExchange(messages []provider.Message) []provider.Message {
+	return RemoveMessages(messages, LastExchange(messages))
+}
+
+// RemoveMessages returns messages without those at the indexes in remove,
+// ascending.
+func RemoveMessages(messages []provider.Message, remove []int) []provider.Message {
+	kept := make([]provider.Message, 0, len(messages)-len(remove))
+	for i, msg := range messages {
+		if len(remove) > 0 && remove[0] == i {
+			remove = remove[1:]
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	return kept
+}
@@ -0,0 +1,98 @@
+package styles
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Symbols in CLI output. SetPlain replaces them with ASCII.
+var (
+	SymbolReasoning = "∴"
+	SymbolTool      = "⚙"
+	SymbolOK        = "✓"
+	SymbolFail      = "✗"
+	SymbolArrow     = "→"
+	SymbolRule      = "─"
+	SymbolCursor    = "▍"
+	SymbolCollapsed = "▸"
+	SymbolExpanded  = "▾"
+)
+
+// bannerWidth is the inner width of the box drawn by Banner.
+const bannerWidth = 38
+
+var plain, screenReader, reducedMotion bool
+
+// SetPlain turns off colors, text attributes and box drawing, for
+// --no-color, NO_COLOR and dumb terminals. Call it before any output.
+func SetPlain() {
+	plain = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+	SymbolReasoning = "~"
+	SymbolTool = "*"
+	SymbolOK = "ok"
+	SymbolFail = "failed"
+	SymbolArrow = "->"
+	SymbolRule = "-"
+	SymbolCursor = "_"
+	SymbolCollapsed = "+"
+	SymbolExpanded = "-"
+}
+
+// Plain reports whether SetPlain was called.
+func Plain() bool {
+	return plain
+}
+
+// SetScreenReader turns on plain mode for screen readers: on top of
+// SetPlain, the TUI holds its animations, draws no borders, labels its
+// icons in words and announces new messages on one line.
+func SetScreenReader() {
+	SetPlain()
+	screenReader = true
+}
+
+// ScreenReader reports whether SetScreenReader was called.
+func ScreenReader() bool {
+	return screenReader
+}
+
+// SetReducedMotion holds the TUI animations: static status bar icons, no
+// spinner or countdown ticking and a cursor that does not blink.
+func SetReducedMotion() {
+	reducedMotion = true
+}
+
+// ReducedMotion reports whether SetReducedMotion or SetScreenReader was
+// called.
+func ReducedMotion() bool {
+	return reducedMotion || screenReader
+}
+
+// PlainFromEnv reports whether the environment asks for plain output:
+// NO_COLOR is set to a non-empty value (see no-color.org) or TERM is dumb.
+func PlainFromEnv() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// Banner returns title centered in a double-line box, or as a single
+// ASCII line in plain mode.
+func Banner(title string) string {
+	if plain {
+		return "== " + title + " =="
+	}
+	pad := max(bannerWidth-lipgloss.Width(title), 0)
+	left := pad / 2
+	return Brand.Render("╔"+strings.Repeat("═", bannerWidth)+"╗") + "\n" +
+		Brand.Render("║") + strings.Repeat(" ", left) + title + strings.Repeat(" ", pad-left) + Brand.Render("║") + "\n" +
+		Brand.Render("╚"+strings.Repeat("═", bannerWidth)+"╝")
+}
+
+// Rule returns title between horizontal rules, e.g. "─── Autoplay Turn ───".
+func Rule(title string) string {
+	line := strings.Repeat(SymbolRule, 3)
+	return line + " " + title + " " + line
+}
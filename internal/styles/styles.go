@@ -7,6 +7,7 @@ import (
 
 // Colors - Retro-futuristic aesthetic based on Zoea Nova brand
 // Brand colors from logo: #9D00FF (electric purple), #00FFCC (bright teal)
+// These are the default theme; ApplyTheme replaces them.
 var (
 	// Brand colors
 	ColorBrand    = lipgloss.Color("#9D00FF") // Electric purple (from logo)
@@ -32,34 +33,52 @@ var (
 	ColorTool      = ColorBrandDim
 )
 
-// Base styles
+// Base styles, built from the palette by buildStyles
 var (
+	BaseStyle    lipgloss.Style
+	TitleStyle   lipgloss.Style
+	ErrorStyle   lipgloss.Style
+	SuccessStyle lipgloss.Style
+	Brand        lipgloss.Style // Primary UI elements
+	BrandBold    lipgloss.Style
+	Secondary    lipgloss.Style // Secondary color (teal)
+	Muted        lipgloss.Style
+	Error        lipgloss.Style
+	Success      lipgloss.Style
+)
+
+func init() {
+	buildStyles()
+}
+
+// buildStyles builds the base styles from the palette, see ApplyTheme.
+func buildStyles() {
 	BaseStyle = lipgloss.NewStyle().
-			Background(ColorBg)
+		Background(ColorBg)
 
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorBrand)
+		Bold(true).
+		Foreground(ColorBrand)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(ColorError).
-			Bold(true)
+		Foreground(ColorError).
+		Bold(true)
 
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(ColorSuccess).
-			Bold(true)
+		Foreground(ColorSuccess).
+		Bold(true)
 
 	// Brand styles for primary UI elements
 	Brand = lipgloss.NewStyle().
 		Foreground(ColorBrand)
 
 	BrandBold = lipgloss.NewStyle().
-			Foreground(ColorBrand).
-			Bold(true)
+		Foreground(ColorBrand).
+		Bold(true)
 
 	// Secondary color (teal)
 	Secondary = lipgloss.NewStyle().
-			Foreground(ColorTeal)
+		Foreground(ColorTeal)
 
 	// Muted text
 	Muted = lipgloss.NewStyle().
@@ -71,4 +90,4 @@ var (
 
 	Success = lipgloss.NewStyle().
 		Foreground(ColorSuccess)
-)
+}
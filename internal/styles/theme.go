@@ -0,0 +1,169 @@
+package styles
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a color palette, selected with [theme] in the config.
+type Theme struct {
+	Brand    lipgloss.Color
+	Teal     lipgloss.Color
+	BrandDim lipgloss.Color
+	TealDim  lipgloss.Color
+	Error    lipgloss.Color
+	Success  lipgloss.Color
+	Muted    lipgloss.Color
+	Bg       lipgloss.Color
+	BgAlt    lipgloss.Color
+	BgPanel  lipgloss.Color
+	Border   lipgloss.Color
+	Light    bool // For light terminal backgrounds
+}
+
+// DefaultTheme is the theme used when the config names none.
+const DefaultTheme = "default"
+
+// themes are the named themes.
+var themes = map[string]Theme{
+	DefaultTheme: {
+		Brand:    "#9D00FF",
+		Teal:     "#00FFCC",
+		BrandDim: "#6B00B3",
+		TealDim:  "#00AA99",
+		Error:    "#FF3366",
+		Success:  "#00FF66",
+		Muted:    "#5555AA",
+		Bg:       "#08080F",
+		BgAlt:    "#101018",
+		BgPanel:  "#14141F",
+		Border:   "#2A2A55",
+	},
+	"light": {
+		Brand:    "#7A00CC",
+		Teal:     "#007A66",
+		BrandDim: "#9B59D0",
+		TealDim:  "#2B7A70",
+		Error:    "#C8102E",
+		Success:  "#1A7F37",
+		Muted:    "#6E6E99",
+		Bg:       "#FAFAFC",
+		BgAlt:    "#F0F0F5",
+		BgPanel:  "#E6E6F0",
+		Border:   "#B8B8D6",
+		Light:    true,
+	},
+	"high-contrast": {
+		Brand:    "#FF00FF",
+		Teal:     "#00FFFF",
+		BrandDim: "#FF80FF",
+		TealDim:  "#80FFFF",
+		Error:    "#FF0000",
+		Success:  "#00FF00",
+		Muted:    "#C0C0C0",
+		Bg:       "#000000",
+		BgAlt:    "#101010",
+		BgPanel:  "#202020",
+		Border:   "#FFFFFF",
+	},
+}
+
+// colorFields maps the color names of [theme.colors] to theme fields.
+var colorFields = map[string]func(*Theme) *lipgloss.Color{
+	"brand":     func(t *Theme) *lipgloss.Color { return &t.Brand },
+	"teal":      func(t *Theme) *lipgloss.Color { return &t.Teal },
+	"brand_dim": func(t *Theme) *lipgloss.Color { return &t.BrandDim },
+	"teal_dim":  func(t *Theme) *lipgloss.Color { return &t.TealDim },
+	"error":     func(t *Theme) *lipgloss.Color { return &t.Error },
+	"success":   func(t *Theme) *lipgloss.Color { return &t.Success },
+	"muted":     func(t *Theme) *lipgloss.Color { return &t.Muted },
+	"bg":        func(t *Theme) *lipgloss.Color { return &t.Bg },
+	"bg_alt":    func(t *Theme) *lipgloss.Color { return &t.BgAlt },
+	"bg_panel":  func(t *Theme) *lipgloss.Color { return &t.BgPanel },
+	"border":    func(t *Theme) *lipgloss.Color { return &t.Border },
+}
+
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ThemeNames returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveTheme returns the named theme ("" for the default) with colors
+// overridden by hex values, e.g. {"brand": "#FF8800"}.
+func ResolveTheme(name string, colors map[string]string) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme.name=%q must be one of %v", name, ThemeNames())
+	}
+
+	names := make([]string, 0, len(colors))
+	for color := range colors {
+		names = append(names, color)
+	}
+	sort.Strings(names)
+	for _, color := range names {
+		field, ok := colorFields[color]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme.colors.%s is not a theme color (%v)", color, colorNames())
+		}
+		value := colors[color]
+		if !hexColor.MatchString(value) {
+			return Theme{}, fmt.Errorf("theme.colors.%s=%q must be a hex color like #RRGGBB", color, value)
+		}
+		*field(&theme) = lipgloss.Color(value)
+	}
+	return theme, nil
+}
+
+// colorNames returns the color names of [theme.colors], sorted.
+func colorNames() []string {
+	names := make([]string, 0, len(colorFields))
+	for name := range colorFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// light is set by ApplyTheme for light themes.
+var light bool
+
+// ApplyTheme sets the palette and rebuilds the styles from it. Call it
+// before any output; packages with their own styles rebuild them too.
+func ApplyTheme(t Theme) {
+	ColorBrand = t.Brand
+	ColorTeal = t.Teal
+	ColorBrandDim = t.BrandDim
+	ColorTealDim = t.TealDim
+	ColorError = t.Error
+	ColorSuccess = t.Success
+	ColorMuted = t.Muted
+	ColorBg = t.Bg
+	ColorBgAlt = t.BgAlt
+	ColorBgPanel = t.BgPanel
+	ColorBorder = t.Border
+	ColorUser = ColorTeal
+	ColorAssistant = ColorTealDim
+	ColorSystem = ColorBorder
+	ColorTool = ColorBrandDim
+	light = t.Light
+	buildStyles()
+}
+
+// LightTheme reports whether the applied theme is for light backgrounds.
+func LightTheme() bool {
+	return light
+}
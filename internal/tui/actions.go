@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// Message browsing key bindings. Up/Down select messages as they select
+// tool results, see toolKeys.
+var messageKeys = struct {
+	Browse key.Binding
+	Menu   key.Binding
+}{
+	Browse: key.NewBinding(key.WithKeys("alt+s"), key.WithHelp("alt+s", "select messages (esc returns to the input)")),
+	Menu:   key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter/space", "open the actions of the selected message")),
+}
+
+// Action menu key bindings; each action also has its own key, see
+// actionItem.
+var actionKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Choose key.Binding
+	Close  key.Binding
+}{
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("up/k", "previous action")),
+	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("down/j", "next action")),
+	Choose: key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter", "run the selected action")),
+	Close:  key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "close the actions")),
+}
+
+// messageAction is an action of the message action menu.
+type messageAction int
+
+const (
+	actionCopy messageAction = iota
+	actionRetry
+	actionDelete
+	actionRaw
+	actionExpand
+)
+
+// actionItem is an entry of the action menu.
+type actionItem struct {
+	action messageAction
+	key    string // Key running the action from the menu
+	label  string
+}
+
+// ActionMenu is the dialog of actions on the message selected in message
+// browsing mode, shown in place of the conversation.
+type ActionMenu struct {
+	active  bool
+	msg     provider.Message
+	items   []actionItem
+	cursor  int
+	confirm bool // Delete was chosen once and waits for confirmation
+}
+
+// Open shows the actions for msg. Display-only messages can only be copied
+// or viewed; retry and delete apply to the context.
+func (a *ActionMenu) Open(msg provider.Message) {
+	a.active = true
+	a.msg = msg
+	a.cursor = 0
+	a.confirm = false
+	a.items = []actionItem{{actionCopy, "c", "Copy"}}
+	if msg.Role != commandRole {
+		a.items = append(a.items,
+			actionItem{actionRetry, "r", "Retry from here"},
+			actionItem{actionDelete, "d", "Delete"})
+	}
+	a.items = append(a.items, actionItem{actionRaw, "v", "View raw JSON"})
+	if msg.Role == "tool" {
+		a.items = append(a.items, actionItem{actionExpand, "e", "Expand or collapse the result"})
+	}
+}
+
+// Close hides the menu.
+func (a *ActionMenu) Close() {
+	a.active = false
+}
+
+// Active reports whether the menu is shown.
+func (a ActionMenu) Active() bool {
+	return a.active
+}
+
+// updateMessageBrowsing handles keys while selecting messages: Up/Down
+// select a message, Enter opens its actions and Esc returns to the input.
+func (m Model) updateMessageBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	switch {
+	case key.Matches(msg, toolKeys.Up):
+		m.conversation.SelectMessage(-1)
+	case key.Matches(msg, toolKeys.Down):
+		m.conversation.SelectMessage(1)
+	case key.Matches(msg, messageKeys.Menu):
+		if selected, ok := m.conversation.SelectedMessage(); ok {
+			m.actions.Open(selected)
+		}
+	case isScrollKey(msg, true, true):
+		m.conversation.Scroll(msg)
+	case key.Matches(msg, messageKeys.Browse), key.Matches(msg, keys.Escape):
+		m.browsingMessages = false
+		m.conversation.ClearSelection()
+		return m, m.input.Focus()
+	}
+	return m, nil
+}
+
+// updateActionMenu handles the keys of the action menu.
+func (m Model) updateActionMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, actionKeys.Up):
+		m.actions.cursor = max(m.actions.cursor-1, 0)
+		m.actions.confirm = false
+		return m, nil
+	case key.Matches(msg, actionKeys.Down):
+		m.actions.cursor = min(m.actions.cursor+1, len(m.actions.items)-1)
+		m.actions.confirm = false
+		return m, nil
+	case key.Matches(msg, actionKeys.Choose):
+		return m.runAction(m.actions.items[m.actions.cursor].action)
+	case key.Matches(msg, actionKeys.Close):
+		m.actions.Close()
+		return m, nil
+	}
+	for i, item := range m.actions.items {
+		if msg.String() == item.key {
+			if i != m.actions.cursor {
+				m.actions.confirm = false
+			}
+			m.actions.cursor = i
+			return m.runAction(item.action)
+		}
+	}
+	return m, nil
+}
+
+// runAction runs an action on the message of the menu. Delete asks to be
+// chosen twice; retry and delete leave message browsing, as they replace
+// the conversation.
+func (m Model) runAction(action messageAction) (tea.Model, tea.Cmd) {
+	msg := m.actions.msg
+	switch action {
+	case actionCopy:
+		m.actions.Close()
+		return m, tea.Batch(copyToClipboard(messageText(msg)), m.statusBar.SetWarning("Copied the message to the clipboard"))
+
+	case actionRaw:
+		m.actions.Close()
+		m.overlay.Open(rawMessageContent(msg, m.width), helpKeys.Close, m.width, m.conversationHeight())
+		m.overlay.SetCopy(rawMessage(msg), errorKeys.Copy)
+		return m, nil
+
+	case actionExpand:
+		m.actions.Close()
+		m.historyMu.Lock()
+		m.conversation.ToggleSelected()
+		m.historyMu.Unlock()
+		return m, nil
+
+	case actionDelete:
+		if !m.actions.confirm {
+			m.actions.confirm = true
+			return m, nil
+		}
+		m.actions.Close()
+		m.browsingMessages = false
+		onDelete := m.onDeleteMessage
+		return m, tea.Batch(m.input.Focus(), func() tea.Msg {
+			if onDelete == nil {
+				return ErrorMsg{Error: "deleting messages is not supported here"}
+			}
+			if err := onDelete(msg); err != nil {
+				return ErrorMsg{Error: err.Error()}
+			}
+			return nil
+		})
+
+	case actionRetry:
+		m.actions.Close()
+		m.browsingMessages = false
+		onRetry := m.onRetryFrom
+		return m, tea.Batch(m.input.Focus(), func() tea.Msg {
+			if onRetry == nil {
+				return ErrorMsg{Error: "retrying is not supported here"}
+			}
+			if err := onRetry(msg); err != nil {
+				return ErrorMsg{Error: err.Error()}
+			}
+			return nil
+		})
+	}
+	return m, nil
+}
+
+// messageText returns the text of a message to copy: its content, or its
+// tool calls as JSON without content.
+func messageText(msg provider.Message) string {
+	if msg.Content != "" || len(msg.ToolCalls) == 0 {
+		return msg.Content
+	}
+	data, err := json.MarshalIndent(msg.ToolCalls, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// rawMessage returns a message as indented JSON.
+func rawMessage(msg provider.Message) string {
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// rawMessageContent renders the raw JSON overlay of a message at width.
+func rawMessageContent(msg provider.Message, width int) string {
+	lineStyle := lipgloss.NewStyle().Background(LogStyle.GetBackground()).Width(width)
+	lines := []string{lineStyle.Render(BrandTitleStyle.Render("Raw message") + LogStyle.Render("  ") +
+		ButtonActiveStyle.Render("Copy ("+errorKeys.Copy.Help().Key+")")), lineStyle.Render("")}
+	wrap := lipgloss.NewStyle().Width(max(width-2, 10))
+	for _, line := range strings.Split(wrap.Render(rawMessage(msg)), "\n") {
+		lines = append(lines, lineStyle.Render(InputTextStyle.Render(strings.TrimRight(line, " "))))
+	}
+	lines = append(lines, lineStyle.Render(""), lineStyle.Render(DimmedStyle.Render("up/down to scroll, y to copy, esc to close")))
+	return strings.Join(lines, "\n")
+}
+
+// View renders the menu centered at width and height.
+func (a ActionMenu) View(width, height int) string {
+	inner := min(width-8, 56)
+
+	role := a.msg.Role
+	if role == commandRole {
+		role = "command output"
+	}
+	preview := strings.Join(strings.Fields(messageText(a.msg)), " ")
+	lines := []string{
+		BrandTitleStyle.Render("Message actions"),
+		"",
+		DimmedStyle.Render(truncate(role+": "+preview, inner)),
+		"",
+	}
+	for i, item := range a.items {
+		label := item.key + "  " + item.label
+		if item.action == actionDelete && a.confirm {
+			label += " - press again to confirm"
+		}
+		switch {
+		case i == a.cursor && item.action == actionDelete && a.confirm:
+			lines = append(lines, ToolErrorStyle.Render(truncate("> "+label, inner)))
+		case i == a.cursor:
+			lines = append(lines, ToolSelectedStyle.Render(truncate("> "+label, inner)))
+		default:
+			lines = append(lines, AssistantStyle.Render(truncate("  "+label, inner)))
+		}
+	}
+	lines = append(lines, "", DimmedStyle.Render(truncate("↑/↓ select · enter run · esc close", inner)))
+
+	lineStyle := lipgloss.NewStyle().Background(LogStyle.GetBackground()).Width(inner)
+	for i, line := range lines {
+		lines[i] = lineStyle.Render(line)
+	}
+	dialog := DialogStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, dialog,
+		lipgloss.WithWhitespaceBackground(LogStyle.GetBackground()))
+}
@@ -6,28 +6,57 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/provider"
 	"github.com/xonecas/mysis/internal/styles"
 )
 
 // Model is the main TUI model.
 type Model struct {
-	conversation Conversation
-	input        Input
-	statusBar    StatusBar
+	conversation  Conversation
+	input         Input
+	search        SearchBar
+	autoplay      AutoplayPanel // Shown above the game state panel
+	panel         GamePanel
+	starMap       MapPanel          // Shown under the game state panel
+	notifications NotificationPanel // Shown under the other side panels
+	overlay       Overlay           // Help or reasoning, shown in place of the conversation
+	approval      ApprovalDialog    // Tool calls waiting for approval, shown in place of the conversation
+	models        ModelPicker       // Provider and model switcher, shown in place of the conversation
+	actions       ActionMenu        // Actions on the selected message, shown in place of the conversation
+	newSession    NewSessionDialog  // Creates a session, shown in place of the conversation
+	statusBar     StatusBar
+	panes         config.TUILayout // Pane sizes and visibility, see SetLayout
 
 	width  int
 	height int
 
 	// State
-	autoplayActive  bool
-	autoplayMessage string
-	lastError       string
+	autoplayActive   bool
+	autoplayPaused   bool
+	autoplayMessage  string
+	countdownTicks   bool     // An AutoplayCountdownMsg tick is scheduled
+	timestampTicks   bool     // A TimestampTickMsg tick is scheduled
+	logTicks         bool     // A LogTickMsg tick is scheduled
+	browsingTools    bool     // Keys select and expand tool results instead of editing input
+	browsingMessages bool     // Keys select messages for the action menu instead of editing input
+	errors           ErrorLog // Recent errors, for the error overlay
+	olderSearched    string   // Last query listed from compacted history
+	split            bool     // A pane of the split view, see SplitModel
+	thinking         bool     // A turn is in progress, for the terminal title
+	unfocused        bool     // The terminal reported losing focus
+	sessionName      string   // Shown in the terminal title
+	showTitle        bool     // Set the terminal title, see windowTitle
+	title            string   // Last terminal title set
+	notify           string   // Alert when unfocused, see config.TUIConfig.Notify
 
 	// Callback to send messages
 	onSendMessage func(string) error
@@ -35,6 +64,35 @@ type Model struct {
 	// Callback to execute commands
 	onCommand func(string) error
 
+	// Callback to search compacted history
+	onSearch func(string) ([]provider.Message, error)
+
+	// Callback for the session configuration and commands the help shows
+	onHelp func() HelpInfo
+
+	// Callback saving the layout after a layout key
+	onLayout func(config.TUILayout)
+
+	// Callback saving the input history after a message is sent
+	onInputHistory func(features.InputHistory)
+
+	// Callbacks deleting a message and retrying the turn of a message,
+	// from the message action menu
+	onDeleteMessage func(provider.Message) error
+	onRetryFrom     func(provider.Message) error
+
+	// Callback listing the models of the configured providers
+	onListModels func() []features.ProviderModels
+
+	// Callbacks listing the choices of the new-session dialog and creating
+	// the session
+	onNewSessionOptions func() NewSessionOptions
+	onNewSession        func(NewSessionRequest) error
+
+	// Callback exporting the session transcript in a format, returning
+	// the path written
+	onExport func(format string) (string, error)
+
 	// Synchronization for conversation history access
 	// Shared with Runner to protect concurrent access from background goroutines
 	historyMu *sync.Mutex
@@ -49,6 +107,7 @@ func NewModel(ctx context.Context) Model {
 		ctx:          ctx,
 		conversation: NewConversation(80, 20),
 		input:        NewInput(80),
+		search:       NewSearchBar(80),
 		statusBar:    NewStatusBar(80),
 	}
 }
@@ -63,45 +122,244 @@ func (m *Model) SetOnCommand(fn func(string) error) {
 	m.onCommand = fn
 }
 
+// SetOnSearch sets the callback for searching compacted history.
+func (m *Model) SetOnSearch(fn func(string) ([]provider.Message, error)) {
+	m.onSearch = fn
+}
+
+// SetInputHistory restores the input history and unsent draft of a
+// previous run.
+func (m *Model) SetInputHistory(h features.InputHistory) {
+	m.input.SetHistory(h.History)
+	if h.Draft != "" {
+		m.input.SetValue(h.Draft)
+	}
+}
+
+// InputHistory returns the input history and unsent draft, to save on exit.
+func (m Model) InputHistory() features.InputHistory {
+	return features.InputHistory{History: m.input.History(), Draft: m.input.Draft()}
+}
+
+// SetOnInputHistory sets the callback saving the input history after a
+// message or command is sent.
+func (m *Model) SetOnInputHistory(fn func(features.InputHistory)) {
+	m.onInputHistory = fn
+}
+
+// SetOnMessageActions sets the callbacks of the delete and retry actions of
+// the message action menu.
+func (m *Model) SetOnMessageActions(onDelete, onRetry func(provider.Message) error) {
+	m.onDeleteMessage = onDelete
+	m.onRetryFrom = onRetry
+}
+
+// SetOnListModels sets the callback listing models for the model picker.
+func (m *Model) SetOnListModels(fn func() []features.ProviderModels) {
+	m.onListModels = fn
+}
+
+// SetOnNewSession sets the callbacks of the new-session dialog.
+func (m *Model) SetOnNewSession(options func() NewSessionOptions, create func(NewSessionRequest) error) {
+	m.onNewSessionOptions = options
+	m.onNewSession = create
+}
+
+// SetOnExport sets the callback of the transcript export keys.
+func (m *Model) SetOnExport(fn func(format string) (string, error)) {
+	m.onExport = fn
+}
+
+// SetProvider sets the provider and model shown in the status bar.
+func (m *Model) SetProvider(name, model string) {
+	m.statusBar.SetProvider(name, model)
+}
+
+// SetSessionName sets the session name shown in the terminal title.
+func (m *Model) SetSessionName(name string) {
+	m.sessionName = name
+}
+
+// SetTerminal sets whether the terminal title shows the session state and
+// how to alert at the end of a turn or on errors while unfocused.
+func (m *Model) SetTerminal(showTitle bool, notify string) {
+	m.showTitle = showTitle
+	m.notify = notify
+}
+
+// SetOnHelp sets the callback providing the help overlay contents.
+func (m *Model) SetOnHelp(fn func() HelpInfo) {
+	m.onHelp = fn
+}
+
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		m.statusBar.Init(),
-		m.input.Focus(),
-	)
+	cmds := []tea.Cmd{m.statusBar.Init(), m.input.Focus()}
+	if m.logTicks {
+		cmds = append(cmds, logTick())
+	}
+	return tea.Batch(cmds...)
 }
 
-// Update handles messages and updates the model.
+// Update handles messages and updates the model, then the terminal title
+// and the images it draws.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	m = updated.(Model)
+	cmds := []tea.Cmd{cmd, m.updateTitle()}
+	if images := m.conversation.FlushImages(); images != "" {
+		cmds = append(cmds, writeTerminal(images))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// update handles messages and updates the model.
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-
-		// Calculate component heights
-		// Layout: Conversation (fills) + Input (3 lines) + Status (2 lines)
-		inputHeight := 3
-		statusHeight := 1
-		conversationHeight := m.height - inputHeight - statusHeight
-		if conversationHeight < 5 {
-			conversationHeight = 5
-		}
-
-		m.conversation.SetSize(m.width, conversationHeight)
-		m.input.SetWidth(m.width)
+		m.input.SetWidth(m.width) // Before layout, the input height depends on it
+		m.layout()
+		m.search.SetWidth(m.width)
 		m.statusBar.SetWidth(m.width)
 
 		m.ready = true
 
+	case tea.FocusMsg:
+		m.unfocused = false
+
+	case tea.BlurMsg:
+		m.unfocused = true
+
 	case tea.KeyMsg:
 		// Global keys
 		switch {
 		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
 
-		case key.Matches(msg, keys.Escape):
+		case m.approval.Active():
+			m.approval = m.approval.Update(msg)
+			return m, nil
+
+		case m.models.Active():
+			return m.updateModelPicker(msg)
+
+		case m.actions.Active():
+			return m.updateActionMenu(msg)
+
+		case m.newSession.Active():
+			return m.updateNewSession(msg)
+
+		case m.overlay.visible:
+			var cmd tea.Cmd
+			m.overlay, cmd = m.overlay.Update(msg)
+			return m, cmd
+
+		case m.search.active:
+			return m.updateSearch(msg)
+
+		case m.browsingTools:
+			return m.updateToolBrowsing(msg)
+
+		case m.browsingMessages:
+			return m.updateMessageBrowsing(msg)
+
+		case key.Matches(msg, messageKeys.Browse):
+			m.historyMu.Lock()
+			m.browsingMessages = m.conversation.SelectMessage(0)
+			m.historyMu.Unlock()
+			if m.browsingMessages {
+				m.input.Blur()
+			}
+			return m, nil
+
+		case m.conversation.Copying():
+			return m.updateCopy(msg)
+
+		case key.Matches(msg, modelKeys.Open):
+			return m, m.openModelPicker()
+
+		case key.Matches(msg, newSessionKeys.Open):
+			return m, m.openNewSession()
+
+		case key.Matches(msg, exportKeys.Markdown), key.Matches(msg, exportKeys.JSON):
+			return m, m.exportTranscript(exportFormat(msg))
+
+		case key.Matches(msg, copyKeys.Open):
+			m.historyMu.Lock()
+			m.conversation.StartCopy()
+			m.historyMu.Unlock()
+			return m, tea.DisableMouse
+
+		case key.Matches(msg, toolKeys.Browse):
+			m.historyMu.Lock()
+			m.browsingTools = m.conversation.SelectToolResult(0)
+			m.historyMu.Unlock()
+			if m.browsingTools {
+				m.input.Blur()
+			}
+			return m, nil
+
+		case key.Matches(msg, searchKeys.Open):
+			return m, m.search.Open()
+
+		case key.Matches(msg, helpKeys.Open) && (msg.String() != "?" || m.input.Value() == ""):
+			// "?" opens the help only on an empty input, so it can still be typed
+			var info HelpInfo
+			if m.onHelp != nil {
+				info = m.onHelp()
+			}
+			m.overlay.Open(helpContent(info, m.width), helpKeys.Close, m.width, m.conversationHeight())
+			return m, nil
+
+		case key.Matches(msg, errorKeys.Open):
+			m.overlay.Open(errorsContent(m.errors, m.width), errorKeys.Close, m.width, m.conversationHeight())
+			m.overlay.SetCopy(m.errors.Last(), errorKeys.Copy)
+			return m, nil
+
+		case key.Matches(msg, keys.ShowReasoning):
+			m.historyMu.Lock()
+			content := reasoningContent(m.conversation.messages, m.width)
+			m.historyMu.Unlock()
+			m.overlay.Open(content, reasoningKeys.Close, m.width, m.conversationHeight())
+			m.overlay.viewport.GotoBottom() // The latest reasoning first
+			return m, nil
+
+		case key.Matches(msg, keys.ToggleReasoning):
+			m.historyMu.Lock()
+			m.conversation.SetShowReasoning(!m.conversation.ShowReasoning())
+			m.historyMu.Unlock()
+			return m, nil
+
+		case isScrollKey(msg, m.input.Value() == "", false):
+			m.historyMu.Lock()
+			m.conversation.Scroll(msg)
+			m.historyMu.Unlock()
+			return m, nil
+
+		case key.Matches(msg, timestampKeys.Cycle):
+			m.historyMu.Lock()
+			mode := (m.conversation.TimestampMode() + 1) % (TimestampHidden + 1)
+			m.conversation.SetTimestampMode(mode)
+			m.historyMu.Unlock()
+			if mode == TimestampRelative && !m.timestampTicks {
+				m.timestampTicks = true
+				return m, timestampTick()
+			}
+			return m, nil
+
+		case isLayoutKey(msg):
+			return m, m.updateLayout(msg)
+
+		case key.Matches(msg, keys.ClearNotifications):
+			m.notifications.Clear()
+			m.layout()
+			return m, nil
+
+		case key.Matches(msg, keys.Escape, keys.StopAutoplay):
 			// ESC stops autoplay if active
 			if m.autoplayActive {
 				// Stop autoplay in backend
@@ -110,7 +368,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				// Update local state
 				m.autoplayActive = false
+				m.autoplayPaused = false
 				m.statusBar.ClearAutoplayText()
+				m.autoplay.Clear()
+				m.layout()
+			}
+			return m, nil
+
+		case key.Matches(msg, keys.PauseAutoplay):
+			// Ctrl+P toggles autoplay pause, keeping the goal
+			if m.autoplayActive && m.onCommand != nil {
+				cmd := "/autoplay pause"
+				if m.autoplayPaused {
+					cmd = "/autoplay resume"
+				}
+				return m, m.executeCommand(cmd)
 			}
 			return m, nil
 
@@ -120,24 +392,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if value != "" {
 				m.input.AddToHistory(value)
 				m.input.Reset()
+				m.layout()
+				if m.onInputHistory != nil {
+					m.onInputHistory(m.InputHistory())
+				}
 
 				// Check if it's a command
 				if strings.HasPrefix(value, "/") {
 					return m, m.executeCommand(value)
 				}
 
-				// Regular message
+				// Regular message, following the reply from the bottom
+				m.historyMu.Lock()
+				m.conversation.GotoBottom()
+				m.historyMu.Unlock()
 				return m, m.sendMessage(value)
 			}
 			return m, nil
 		}
 
-		// Pass to input for editing
+		// Pass to input for editing, making room as it grows or shrinks
+		height := m.input.Height()
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
 		cmds = append(cmds, cmd)
+		if m.input.Height() != height {
+			m.layout()
+		}
 
 	case tea.MouseMsg:
+		// A click on the provider in the status bar opens the model picker
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft &&
+			msg.Y == m.height-1 && m.statusBar.ProviderHit(msg.X) && !m.models.Active() {
+			return m, m.openModelPicker()
+		}
+
 		// Pass mouse events to conversation for scrolling
 		var updated bool
 		m.conversation, updated = m.conversation.Update(msg)
@@ -154,11 +443,102 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case MessageReceivedMsg:
 		// Add message to conversation (protected by mutex for concurrent access)
 		m.historyMu.Lock()
+		if msg.Message.Role == "assistant" {
+			m.conversation.EndStream() // The complete message replaces the streamed text
+			m.statusBar.StopLLMWait()
+		}
 		m.conversation.AddMessage(msg.Message)
+		if styles.ScreenReader() {
+			m.statusBar.Announce(m.conversation.Announcement())
+		}
 		m.historyMu.Unlock()
 		cmds = append(cmds, m.statusBar.AnimateInfo())
 		m.statusBar.ClearError()
 
+	case StreamDeltaMsg:
+		if styles.ScreenReader() {
+			break // The complete message is shown and announced at once
+		}
+		m.historyMu.Lock()
+		m.conversation.AppendStream(msg.Content)
+		m.historyMu.Unlock()
+		m.statusBar.StopLLMWait() // The response is arriving
+		cmds = append(cmds, m.statusBar.AnimateLLM())
+
+	case StreamEndedMsg:
+		m.historyMu.Lock()
+		m.conversation.EndStream()
+		m.historyMu.Unlock()
+		m.statusBar.StopLLMWait()
+		m.thinking = false
+		if !msg.Failed {
+			cmds = append(cmds, m.alert("mysis", m.sessionLabel()+": turn complete"))
+		}
+
+	case TimestampTickMsg:
+		m.historyMu.Lock()
+		relative := m.conversation.TimestampMode() == TimestampRelative
+		if relative {
+			m.conversation.Refresh()
+		}
+		m.historyMu.Unlock()
+		m.timestampTicks = relative
+		if relative {
+			cmds = append(cmds, timestampTick())
+		}
+
+	case LogTickMsg:
+		m.logTicks = m.panes.ShowLogs
+		if m.logTicks {
+			cmds = append(cmds, logTick())
+		}
+
+	case UsageMsg:
+		m.statusBar.SetUsage(msg.Usage, msg.Context, msg.Window)
+
+	case GameStateMsg:
+		shown := m.sideShown()
+		m.panel.SetState(msg.State)
+		if m.sideShown() != shown {
+			m.layout()
+		}
+
+	case ApprovalRequestMsg:
+		m.approval.Add(msg)
+
+	case StarMapMsg:
+		shown := m.sideShown()
+		m.starMap.SetMap(msg.Map)
+		if m.sideShown() != shown {
+			m.layout()
+		}
+
+	case NotificationsMsg:
+		shown := m.sideShown()
+		m.notifications.Add(msg.Notifications)
+		if m.sideShown() != shown {
+			m.layout()
+		}
+		for _, n := range msg.Notifications {
+			if n.Severity == features.NotificationCritical {
+				cmds = append(cmds, m.statusBar.SetWarning(truncate("Notification: "+n.Text, 100)))
+			}
+		}
+
+	case SearchResultsMsg:
+		// List matches the conversation no longer shows, so they can be browsed too
+		if len(msg.Messages) > 0 && msg.Query == m.search.Value() && msg.Query != m.olderSearched {
+			m.olderSearched = msg.Query
+			m.historyMu.Lock()
+			m.conversation.AddMessage(provider.Message{
+				Role:      commandRole,
+				Content:   formatOlderMatches(msg.Query, msg.Messages),
+				CreatedAt: time.Now(),
+			})
+			m.conversation.SetSearch(msg.Query)
+			m.historyMu.Unlock()
+		}
+
 	case ConversationUpdateMsg:
 		// Trigger re-render of conversation (messages already added elsewhere)
 		m.historyMu.Lock()
@@ -167,11 +547,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, m.statusBar.AnimateInfo())
 		m.statusBar.ClearError()
 
+	case CommandOutputMsg:
+		// Display-only output; not part of the conversation history
+		m.historyMu.Lock()
+		m.conversation.AddMessage(provider.Message{
+			Role:      commandRole,
+			Content:   msg.Output,
+			CreatedAt: time.Now(),
+		})
+		m.historyMu.Unlock()
+		m.conversation.GotoBottom()
+
+	case HistoryReplacedMsg:
+		// Replace the displayed conversation (e.g. after /compact)
+		m.historyMu.Lock()
+		m.conversation.SetMessages(msg.Messages)
+		m.historyMu.Unlock()
+		m.conversation.GotoBottom()
+		cmds = append(cmds, m.statusBar.AnimateInfo())
+
 	case ErrorMsg:
 		// Show error in status bar
-		m.lastError = msg.Error
+		m.errors.Add(msg.Error, msg.Detail, time.Now())
 		cmds = append(cmds, m.statusBar.SetError(truncate(msg.Error, 100)))
 		m.statusBar.ClearWarning() // Error takes priority
+		cmds = append(cmds, m.alert("mysis error", m.sessionLabel()+": "+msg.Error))
+
+	case ProviderMsg:
+		m.statusBar.SetProvider(msg.Name, msg.Model)
+
+	case TranscriptExportedMsg:
+		cmds = append(cmds, m.statusBar.SetWarning("Exported the transcript to "+msg.Path))
+
+	case SessionSwitchedMsg:
+		m.sessionName = msg.Name
+		m.historyMu.Lock()
+		m.conversation.SetMessages(msg.Messages)
+		m.historyMu.Unlock()
+		m.conversation.GotoBottom()
+		m.input.Reset()
+		m.input.SetHistory(nil)
+		m.layout()
+		cmds = append(cmds, m.statusBar.AnimateInfo())
+
+	case newSessionDoneMsg:
+		m.newSession.creating = false
+		if msg.Err != nil {
+			m.newSession.err = msg.Err.Error()
+		} else {
+			m.newSession.Close()
+			cmds = append(cmds, m.input.Focus())
+		}
+
+	case ModelsListedMsg:
+		if m.models.Active() {
+			m.models.SetProviders(msg.Providers)
+		}
 
 	case WarningMsg:
 		// Show warning in status bar
@@ -184,11 +615,73 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case AutoplayStoppedMsg:
 		m.autoplayActive = false
+		m.autoplayPaused = false
 		m.statusBar.ClearAutoplayText()
+		m.autoplay.Clear()
+		m.layout()
+
+	case AutoplayStatusMsg:
+		shown := m.sideShown()
+		m.autoplay.SetStatus(msg.Status)
+		if m.sideShown() != shown {
+			m.layout()
+		}
+
+	case AutoplayPausedMsg:
+		m.autoplayPaused = true
+		m.statusBar.SetAutoplayPaused(true)
+
+	case AutoplayScheduleMsg:
+		if msg.InWindow {
+			m.statusBar.SetAutoplayWait(time.Time{})
+			cmds = append(cmds, m.statusBar.AnimateAutoplay())
+		} else {
+			m.statusBar.SetAutoplayWait(msg.NextWindow)
+		}
+
+	case AutoplayProgressMsg:
+		m.statusBar.SetAutoplayProgress(msg.Turns, msg.ConsecutiveErrors, msg.NextTurnAt)
+		if !m.countdownTicks && !styles.ReducedMotion() {
+			m.countdownTicks = true
+			cmds = append(cmds, autoplayCountdownTick())
+		}
+
+	case AutoplayQueueMsg:
+		m.statusBar.SetAutoplayQueued(msg.Pending)
+		m.autoplay.status.Queued = msg.Pending
+
+	case AutoplayCountdownMsg:
+		// Redraw once a second while a next turn is pending
+		if m.autoplayActive && time.Now().Before(m.statusBar.autoplayNext) {
+			cmds = append(cmds, autoplayCountdownTick())
+		} else {
+			m.countdownTicks = false
+		}
+
+	case AutoplayBreakerMsg:
+		m.statusBar.SetAutoplayBreaker(msg.Until)
+		if !msg.Open {
+			cmds = append(cmds, m.statusBar.AnimateAutoplay())
+		}
+
+	case AutoplayResumedMsg:
+		m.autoplayPaused = false
+		m.statusBar.SetAutoplayPaused(false)
+		cmds = append(cmds, m.statusBar.AnimateAutoplay())
 
 	case LLMActivityMsg:
 		// Animate LLM connection icon
-		cmds = append(cmds, m.statusBar.AnimateLLM())
+		m.thinking = true
+		cmds = append(cmds, m.statusBar.AnimateLLM(), m.statusBar.StartLLMWait())
+
+	case LLMDoneMsg:
+		m.statusBar.StopLLMWait()
+		m.thinking = false
+
+	case LLMWaitTickMsg:
+		var cmd tea.Cmd
+		m.statusBar, cmd = m.statusBar.Update(msg)
+		cmds = append(cmds, cmd)
 
 	case MCPActivityMsg:
 		// Animate MCP connection icon
@@ -205,8 +698,11 @@ func (m Model) View() string {
 	}
 
 	// Check minimum terminal size
-	const minWidth = 80
 	const minHeight = 20
+	minWidth := 80
+	if m.split {
+		minWidth = splitMinWidth
+	}
 	if m.width < minWidth || m.height < minHeight {
 		warning := fmt.Sprintf(
 			"Terminal too small!\n\nMinimum: %dx%d\nCurrent: %dx%d\n\nPlease resize.",
@@ -217,7 +713,31 @@ func (m Model) View() string {
 
 	// Build UI content first
 	conversation := m.conversation.View()
+	if m.sideShown() {
+		conversation = lipgloss.JoinHorizontal(lipgloss.Top, conversation, m.sideView(m.conversationHeight()))
+	}
+	if m.overlay.visible {
+		conversation = m.overlay.View()
+	}
+	if m.models.Active() {
+		conversation = m.models.View(m.width, m.conversationHeight())
+	}
+	if m.actions.Active() {
+		conversation = m.actions.View(m.width, m.conversationHeight())
+	}
+	if m.newSession.Active() {
+		conversation = m.newSession.View(m.width, m.conversationHeight())
+	}
+	if m.approval.Active() {
+		conversation = m.approval.View(m.width, m.conversationHeight())
+	}
+	if m.panes.ShowLogs {
+		conversation += "\n" + logsView(m.width, m.logsHeight())
+	}
 	input := m.input.View()
+	if m.search.active {
+		input = m.search.View(m.conversation.SearchPosition())
+	}
 	status := m.statusBar.View()
 
 	// Join all sections
@@ -233,6 +753,166 @@ func (m Model) View() string {
 	return baseStyle.Render(content)
 }
 
+// layout sizes the conversation to the space left by the input, the status
+// bar, the log pane and the side panels.
+func (m *Model) layout() {
+	width := m.width
+	if m.sideShown() {
+		width -= m.sideWidth()
+	}
+	m.historyMu.Lock()
+	m.conversation.SetSize(width, m.conversationHeight())
+	m.historyMu.Unlock()
+	m.overlay.viewport.Width = m.width
+	m.overlay.viewport.Height = m.conversationHeight()
+}
+
+// sideShown reports whether the side column with the autoplay, game state,
+// map and notification panels is shown.
+func (m Model) sideShown() bool {
+	if m.split && m.width < 80 {
+		return false // The conversation needs the width of the pane
+	}
+	return m.autoplayShown() || m.gameShown() || m.mapShown() || m.notificationsShown()
+}
+
+// sideView renders the side column at height: the autoplay, game state and
+// map panels at the rows they need, keeping minPanelRows for each panel
+// below them, and the notifications filling the rest.
+func (m Model) sideView(height int) string {
+	const minPanelRows = 6
+	type sidePanel struct {
+		rows int // Rows wanted, 0 to fill
+		view func(width, height int) string
+	}
+
+	width := m.sideWidth()
+	var panels []sidePanel
+	if m.autoplayShown() {
+		panels = append(panels, sidePanel{m.autoplay.Height(width), m.autoplay.View})
+	}
+	if m.gameShown() {
+		panels = append(panels, sidePanel{m.panel.Height(width) + 1, m.panel.View}) // A blank row below
+	}
+	if m.mapShown() {
+		panels = append(panels, sidePanel{m.starMap.Height(width), m.starMap.View})
+	}
+	if m.notificationsShown() {
+		panels = append(panels, sidePanel{0, m.notifications.View})
+	}
+
+	views := make([]string, 0, len(panels))
+	left := height
+	for i, panel := range panels {
+		if left < 1 {
+			break
+		}
+		rows := left
+		if i < len(panels)-1 {
+			rows = max(min(panel.rows, left-minPanelRows*(len(panels)-1-i)), 1)
+		}
+		views = append(views, panel.view(width, rows))
+		left -= rows
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+// conversationHeight returns the height of the conversation.
+// Layout: Conversation (fills) + Logs (title and rows, when shown) + Input
+// (border and 1 to maxInputLines lines) + Status (2 lines)
+func (m Model) conversationHeight() int {
+	const inputBorder = 1
+	const statusHeight = 2
+	logs := 0
+	if m.panes.ShowLogs {
+		logs = 1 + m.logsHeight()
+	}
+	return max(m.height-logs-inputBorder-m.input.Height()-statusHeight, 5)
+}
+
+// updateToolBrowsing handles keys while browsing tool results: Up/Down
+// select a result, Enter/Space expand or collapse it and Tab/Esc return to
+// the input.
+func (m Model) updateToolBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	switch {
+	case key.Matches(msg, toolKeys.Up):
+		m.conversation.SelectToolResult(-1)
+	case key.Matches(msg, toolKeys.Down):
+		m.conversation.SelectToolResult(1)
+	case key.Matches(msg, toolKeys.Toggle):
+		m.conversation.ToggleSelected()
+	case isScrollKey(msg, true, true):
+		m.conversation.Scroll(msg)
+	case key.Matches(msg, toolKeys.Browse), key.Matches(msg, keys.Escape):
+		m.browsingTools = false
+		m.conversation.ClearSelection()
+		return m, m.input.Focus()
+	}
+	return m, nil
+}
+
+// updateSearch handles keys in search mode: editing the query highlights
+// matches as it changes, Enter browses them and Esc ends the search.
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, keys.Escape) {
+		m.search.Close()
+		m.historyMu.Lock()
+		m.conversation.SetSearch("")
+		m.historyMu.Unlock()
+		return m, nil
+	}
+
+	if m.search.browsing {
+		m.historyMu.Lock()
+		defer m.historyMu.Unlock()
+		switch {
+		case key.Matches(msg, searchKeys.Next):
+			m.conversation.NextMatch(1)
+		case key.Matches(msg, searchKeys.Prev):
+			m.conversation.NextMatch(-1)
+		case key.Matches(msg, searchKeys.Edit), key.Matches(msg, searchKeys.Open):
+			return m, m.search.Open()
+		case isScrollKey(msg, true, true):
+			m.conversation.Scroll(msg)
+		}
+		return m, nil
+	}
+
+	if key.Matches(msg, keys.Enter) {
+		query := m.search.Value()
+		if query == "" {
+			m.search.Close()
+			return m, nil
+		}
+		m.search.Submit()
+		return m, m.searchOlder(query)
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	m.historyMu.Lock()
+	m.conversation.SetSearch(m.search.Value())
+	m.historyMu.Unlock()
+	return m, cmd
+}
+
+// searchOlder searches compacted history through the callback.
+func (m Model) searchOlder(query string) tea.Cmd {
+	if m.onSearch == nil || query == m.olderSearched {
+		return nil
+	}
+	return func() tea.Msg {
+		messages, err := m.onSearch(query)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to search history")
+			return WarningMsg{Warning: "Search of compacted history failed: " + err.Error()}
+		}
+		return SearchResultsMsg{Query: query, Messages: messages}
+	}
+}
+
 // sendMessage sends a message through the callback.
 func (m Model) sendMessage(content string) tea.Cmd {
 	return func() tea.Msg {
@@ -257,29 +937,16 @@ func (m Model) executeCommand(cmd string) tea.Cmd {
 		if len(parts) == 0 {
 			return nil
 		}
-
-		switch parts[0] {
-		case "/autoplay":
-			// Pass to runner for backend execution
-			if m.onCommand != nil {
-				if err := m.onCommand(cmd); err != nil {
-					return ErrorMsg{Error: err.Error()}
-				}
-			}
-			return nil
-
-		case "/exit", "/quit":
+		if parts[0] == "/exit" || parts[0] == "/quit" {
 			return tea.Quit()
+		}
 
-		default:
-			// Pass to external command handler
-			if m.onCommand != nil {
-				if err := m.onCommand(cmd); err != nil {
-					return ErrorMsg{Error: err.Error()}
-				}
+		// Pass to the runner's command registry
+		if m.onCommand != nil {
+			if err := m.onCommand(cmd); err != nil {
+				return ErrorMsg{Error: err.Error()}
 			}
 		}
-
 		return nil
 	}
 }
@@ -289,6 +956,31 @@ func (m *Model) AddMessage(msg provider.Message) {
 	m.conversation.AddMessage(msg)
 }
 
+// SetShowReasoning shows or hides the reasoning lines of assistant messages.
+func (m *Model) SetShowReasoning(show bool) {
+	m.conversation.SetShowReasoning(show)
+}
+
+// SetImages sets how tool result images are shown, a tui.images value.
+func (m *Model) SetImages(setting string) {
+	m.conversation.SetImages(imageProtocol(setting))
+}
+
+// SetMarkdown turns Markdown rendering of assistant messages on or off.
+func (m *Model) SetMarkdown(enabled bool) {
+	m.conversation.SetMarkdown(enabled)
+}
+
+// SetGameState sets the game state shown in the side panel.
+func (m *Model) SetGameState(state features.GameState) {
+	m.panel.SetState(state)
+}
+
+// SetStarMap sets the map shown in the side panel.
+func (m *Model) SetStarMap(starMap features.StarMap) {
+	m.starMap.SetMap(starMap)
+}
+
 // SetMessages sets all conversation messages.
 func (m *Model) SetMessages(messages []provider.Message) {
 	m.conversation.SetMessages(messages)
@@ -296,13 +988,36 @@ func (m *Model) SetMessages(messages []provider.Message) {
 
 // Key bindings
 var keys = struct {
-	Quit   key.Binding
-	Escape key.Binding
-	Enter  key.Binding
+	Quit               key.Binding
+	Escape             key.Binding
+	Enter              key.Binding
+	PauseAutoplay      key.Binding
+	StopAutoplay       key.Binding
+	ToggleReasoning    key.Binding
+	ShowReasoning      key.Binding
+	ClearNotifications key.Binding
+}{
+	Quit:               key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+	Escape:             key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "stop autoplay, close search or browsing")),
+	Enter:              key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "send the message or command")),
+	PauseAutoplay:      key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "pause or resume autoplay")),
+	StopAutoplay:       key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "stop autoplay")),
+	ClearNotifications: key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "mark notifications read")),
+	ToggleReasoning:    key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "show or hide reasoning")),
+	ShowReasoning:      key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "show the full reasoning")),
+}
+
+// Tool result browsing key bindings
+var toolKeys = struct {
+	Browse key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
 }{
-	Quit:   key.NewBinding(key.WithKeys("ctrl+c")),
-	Escape: key.NewBinding(key.WithKeys("esc")),
-	Enter:  key.NewBinding(key.WithKeys("enter")),
+	Browse: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "browse tool results")),
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("up/k", "previous tool result")),
+	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("down/j", "next tool result")),
+	Toggle: key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter/space", "expand or collapse the result")),
 }
 
 // Message types for external communication
@@ -312,12 +1027,65 @@ type (
 		Message provider.Message
 	}
 
+	// CommandOutputMsg carries the output of a slash command for display.
+	CommandOutputMsg struct {
+		Output string
+	}
+
+	// HistoryReplacedMsg is sent when the conversation history is rewritten.
+	HistoryReplacedMsg struct {
+		Messages []provider.Message
+	}
+
+	// StreamDeltaMsg carries assistant text as it streams in.
+	StreamDeltaMsg struct {
+		Content string
+	}
+
+	// StreamEndedMsg is sent when a turn ends, dropping any streamed text
+	// that did not become a message (e.g. after an error).
+	StreamEndedMsg struct {
+		Failed bool // The turn failed, an ErrorMsg follows
+	}
+
+	// UsageMsg is sent after each LLM response with the run's usage and
+	// the size of the last request.
+	UsageMsg struct {
+		Usage   llm.Usage
+		Context int // Estimated tokens of the last request
+		Window  int // Model context window, 0 when unknown
+	}
+
+	// NotificationsMsg is sent with game notifications from a
+	// get_notifications result or poll.
+	NotificationsMsg struct {
+		Notifications []features.Notification
+	}
+
+	// GameStateMsg is sent when a get_status or get_ship result updated the
+	// game state.
+	GameStateMsg struct {
+		State features.GameState
+	}
+
+	// StarMapMsg is sent when a map or location result updated the map.
+	StarMapMsg struct {
+		Map features.StarMap
+	}
+
+	// SearchResultsMsg carries compacted messages matching a search.
+	SearchResultsMsg struct {
+		Query    string
+		Messages []provider.Message
+	}
+
 	// ConversationUpdateMsg triggers a re-render without adding messages (already added).
 	ConversationUpdateMsg struct{}
 
 	// ErrorMsg is sent when an error occurs.
 	ErrorMsg struct {
-		Error string
+		Error  string
+		Detail string // Stack trace or context lines, shown in the error overlay
 	}
 
 	// WarningMsg is sent when a warning occurs.
@@ -330,12 +1098,57 @@ type (
 		Message string
 	}
 
+	// AutoplayStatusMsg is sent with the autoplay status when autoplay
+	// starts, pauses, resumes or finishes a turn.
+	AutoplayStatusMsg struct {
+		Status features.AutoplayStatus
+	}
+
 	// AutoplayStoppedMsg is sent when autoplay stops.
 	AutoplayStoppedMsg struct{}
 
-	// LLMActivityMsg is sent when LLM activity occurs.
+	// AutoplayPausedMsg is sent when autoplay is paused.
+	AutoplayPausedMsg struct{}
+
+	// AutoplayResumedMsg is sent when autoplay is resumed.
+	AutoplayResumedMsg struct{}
+
+	// AutoplayScheduleMsg is sent when autoplay waits for, or enters, a schedule window.
+	AutoplayScheduleMsg struct {
+		InWindow   bool
+		NextWindow time.Time
+	}
+
+	// AutoplayProgressMsg is sent after each autoplay turn.
+	AutoplayProgressMsg struct {
+		Turns             int
+		ConsecutiveErrors int
+		NextTurnAt        time.Time
+	}
+
+	// AutoplayQueueMsg reports user messages queued for the next autoplay turn.
+	AutoplayQueueMsg struct {
+		Pending int
+	}
+
+	// AutoplayCountdownMsg redraws the next-turn countdown.
+	AutoplayCountdownMsg struct{}
+
+	// AutoplayBreakerMsg is sent when the autoplay circuit breaker trips into
+	// cooldown (Open) or closes after a successful turn.
+	AutoplayBreakerMsg struct {
+		Open  bool
+		Until time.Time
+	}
+
+	// LLMActivityMsg is sent when LLM activity occurs, e.g. before each
+	// provider call. The status bar shows the wait until the response.
 	LLMActivityMsg struct{}
 
+	// LLMDoneMsg is sent when a provider call outside a turn returns, e.g.
+	// of /compact. Turns end with StreamEndedMsg.
+	LLMDoneMsg struct{}
+
 	// MCPActivityMsg is sent when MCP activity occurs.
 	MCPActivityMsg struct{}
 )
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/provider"
+)
+
+// maxApprovalArgLines is the number of argument lines the approval dialog
+// shows.
+const maxApprovalArgLines = 12
+
+// ApprovalRequestMsg asks the user to approve a tool call in tool-approval
+// mode, see config.TUIConfig.ApproveTools. The decision is sent on Reply.
+type ApprovalRequestMsg struct {
+	Call  provider.ToolCall
+	Reply chan<- bool // Buffered; receives exactly one decision
+}
+
+// ApprovalDialog is the modal shown in place of the conversation while a
+// tool call waits for approval. Requests arriving while it is open queue.
+type ApprovalDialog struct {
+	pending []ApprovalRequestMsg // The first is shown
+	deny    bool                 // The Deny button is selected
+}
+
+// Approval dialog key bindings
+var approvalKeys = struct {
+	Approve key.Binding
+	Deny    key.Binding
+	Switch  key.Binding
+	Confirm key.Binding
+}{
+	Approve: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "approve the tool call")),
+	Deny:    key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "deny the tool call")),
+	Switch:  key.NewBinding(key.WithKeys("left", "right", "tab", "shift+tab", "h", "l"), key.WithHelp("←/→", "select a button")),
+	Confirm: key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter", "choose the selected button")),
+}
+
+// Active reports whether a tool call waits for approval.
+func (d ApprovalDialog) Active() bool {
+	return len(d.pending) > 0
+}
+
+// Add queues a request.
+func (d *ApprovalDialog) Add(req ApprovalRequestMsg) {
+	if !d.Active() {
+		d.deny = false
+	}
+	d.pending = append(d.pending, req)
+}
+
+// Update handles the keys of the dialog, answering the shown request.
+func (d ApprovalDialog) Update(msg tea.KeyMsg) ApprovalDialog {
+	switch {
+	case key.Matches(msg, approvalKeys.Approve):
+		d.answer(true)
+	case key.Matches(msg, approvalKeys.Deny):
+		d.answer(false)
+	case key.Matches(msg, approvalKeys.Switch):
+		d.deny = !d.deny
+	case key.Matches(msg, approvalKeys.Confirm):
+		d.answer(!d.deny)
+	}
+	return d
+}
+
+// answer sends the decision for the shown request and shows the next.
+func (d *ApprovalDialog) answer(approved bool) {
+	select {
+	case d.pending[0].Reply <- approved:
+	default: // Already answered, e.g. the turn was canceled
+	}
+	d.pending = d.pending[1:]
+	d.deny = false
+}
+
+// View renders the dialog for the shown request, centered at width and
+// height.
+func (d ApprovalDialog) View(width, height int) string {
+	call := d.pending[0].Call
+	inner := min(width-8, 72)
+
+	lines := []string{
+		BrandTitleStyle.Render("Approve tool call?"),
+		"",
+		ToolStyle.Render(truncate(call.Name, inner)),
+	}
+	for _, line := range approvalArguments(call.Arguments, inner) {
+		lines = append(lines, InputTextStyle.Render(line))
+	}
+
+	approve, deny := ButtonActiveStyle, ButtonStyle
+	if d.deny {
+		approve, deny = ButtonStyle, ButtonActiveStyle
+	}
+	lines = append(lines, "",
+		approve.Render("Approve")+LogStyle.Render("  ")+deny.Render("Deny"),
+		"",
+		DimmedStyle.Render(truncate("y approve · n/esc deny · ←/→ select · enter choose", inner)))
+	if queued := len(d.pending) - 1; queued > 0 {
+		lines = append(lines, DimmedStyle.Render(truncate(fmt.Sprintf("%d more waiting", queued), inner)))
+	}
+
+	lineStyle := lipgloss.NewStyle().Background(LogStyle.GetBackground()).Width(inner)
+	for i, line := range lines {
+		lines[i] = lineStyle.Render(line)
+	}
+	dialog := DialogStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, dialog,
+		lipgloss.WithWhitespaceBackground(LogStyle.GetBackground()))
+}
+
+// approvalArguments formats tool call arguments as indented JSON lines cut
+// to width, at most maxApprovalArgLines.
+func approvalArguments(arguments json.RawMessage, width int) []string {
+	if len(bytes.TrimSpace(arguments)) == 0 || string(bytes.TrimSpace(arguments)) == "{}" {
+		return []string{"(no arguments)"}
+	}
+	var buf bytes.Buffer
+	text := string(arguments)
+	if json.Indent(&buf, arguments, "", "  ") == nil {
+		text = buf.String()
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxApprovalArgLines {
+		lines = append(lines[:maxApprovalArgLines-1], "...")
+	}
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+	return lines
+}
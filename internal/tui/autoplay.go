@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// maxGoalLines is the number of rows the autoplay panel wraps the goal to.
+const maxGoalLines = 3
+
+// AutoplayPanel shows a running autoplay at the top of the side column:
+// goal, countdown to the next turn, turn counter and errors, with the keys
+// controlling it.
+type AutoplayPanel struct {
+	status features.AutoplayStatus
+	known  bool // A status was received for the current run
+}
+
+// SetStatus replaces the autoplay status.
+func (p *AutoplayPanel) SetStatus(status features.AutoplayStatus) {
+	p.status = status
+	p.known = true
+}
+
+// Clear forgets the status when autoplay stops.
+func (p *AutoplayPanel) Clear() {
+	p.status = features.AutoplayStatus{}
+	p.known = false
+}
+
+// Empty reports whether no status was received for the current run.
+func (p AutoplayPanel) Empty() bool {
+	return !p.known
+}
+
+// Height returns the rows the panel needs at width, including a blank row
+// separating it from the panels below.
+func (p AutoplayPanel) Height(width int) int {
+	return len(p.lines(width, time.Now())) + 1
+}
+
+// View renders the panel at width and height.
+func (p AutoplayPanel) View(width, height int) string {
+	lines := p.lines(width, time.Now())
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return PanelStyle.
+		Width(width - 1). // The left border adds a column
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// lines renders the panel content at width, with the countdown from now.
+func (p AutoplayPanel) lines(width int, now time.Time) []string {
+	inner := width - 3 // Border and padding
+	s := p.status
+
+	title := "Autoplay"
+	switch {
+	case s.Paused:
+		title += " (paused)"
+	case s.DryRun:
+		title += " (dry run)"
+	case s.Safe:
+		title += " (safe)"
+	}
+	lines := []string{BrandTitleStyle.Render(title)}
+
+	goal := s.Message
+	if s.Goals > 1 {
+		goal = fmt.Sprintf("%d/%d %s", s.Goal, s.Goals, goal)
+	}
+	wrapped := strings.Split(lipgloss.NewStyle().Width(inner).Render(strings.Join(strings.Fields(goal), " ")), "\n")
+	if len(wrapped) > maxGoalLines {
+		wrapped = wrapped[:maxGoalLines]
+		wrapped[maxGoalLines-1] = truncate(strings.TrimRight(wrapped[maxGoalLines-1], " ")+"...", inner)
+	}
+	for _, line := range wrapped {
+		lines = append(lines, AssistantStyle.Render(strings.TrimRight(line, " ")))
+	}
+
+	row := func(label, value string, style lipgloss.Style) {
+		lines = append(lines, DimmedStyle.Render(fmt.Sprintf("%-8s", label))+style.Render(truncate(value, inner-8)))
+	}
+	next := "running"
+	switch {
+	case s.Paused:
+		next = "paused"
+	case s.BreakerOpen:
+		next = "cooldown until " + s.BreakerUntil.Local().Format("15:04:05")
+	case !s.InWindow && !s.NextWindow.IsZero():
+		next = "window at " + s.NextWindow.Local().Format("15:04")
+	case !s.NextTurnAt.IsZero() && styles.ReducedMotion():
+		next = "at " + s.NextTurnAt.Local().Format("15:04:05") // Not redrawn every second
+	case !s.NextTurnAt.IsZero():
+		next = "in " + max(s.NextTurnAt.Sub(now), 0).Round(time.Second).String()
+	}
+	row("Next", next, AssistantStyle)
+
+	turns := fmt.Sprintf("%d", s.Turns)
+	if s.Limits.MaxTurns > 0 {
+		turns += fmt.Sprintf(" of %d", s.Limits.MaxTurns)
+	}
+	row("Turns", turns, AssistantStyle)
+
+	errors := fmt.Sprintf("%d", s.FailedTurns)
+	errorStyle := AssistantStyle
+	if s.ConsecutiveErrors > 0 {
+		errors += fmt.Sprintf(" (%d in a row)", s.ConsecutiveErrors)
+		errorStyle = NotificationWarningStyle
+	}
+	row("Errors", errors, errorStyle)
+	if s.Queued > 0 {
+		row("Queued", fmt.Sprintf("%d", s.Queued), AssistantStyle)
+	}
+
+	pause := "pause"
+	if s.Paused {
+		pause = "resume"
+	}
+	lines = append(lines, DimmedStyle.Render(truncate(
+		keys.PauseAutoplay.Help().Key+" "+pause+" · "+keys.StopAutoplay.Help().Key+" stop", inner)))
+	return lines
+}
@@ -0,0 +1,9 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package tui
+
+// cellSize returns the usual size of a terminal cell in pixels where it
+// cannot be asked.
+func cellSize() (width, height int) {
+	return defaultCellWidth, defaultCellHeight
+}
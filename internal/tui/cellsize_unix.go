@@ -0,0 +1,19 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package tui
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cellSize returns the size of a terminal cell in pixels, as reported by
+// the terminal, or defaultCellWidth×defaultCellHeight.
+func cellSize() (width, height int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Xpixel == 0 || ws.Ypixel == 0 || ws.Col == 0 || ws.Row == 0 {
+		return defaultCellWidth, defaultCellHeight
+	}
+	return max(int(ws.Xpixel/ws.Col), 1), max(int(ws.Ypixel/ws.Row), 1)
+}
@@ -1,12 +1,15 @@
 package tui
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
 	"github.com/xonecas/mysis/internal/provider"
 	"github.com/xonecas/mysis/internal/styles"
 )
@@ -17,10 +20,33 @@ type Conversation struct {
 	messages []provider.Message
 	width    int
 	height   int
+	markdown *markdownRenderer // nil renders assistant messages as raw text
+	images   *imageRenderer    // nil shows tool result images as placeholder lines
+	stream   *strings.Builder  // Assistant text streaming in, nil when not streaming
+
+	hideReasoning bool          // Reasoning lines are hidden, see SetShowReasoning
+	timestamps    TimestampMode // How message timestamps are shown
+
+	expanded     map[int]bool // Tool results shown in full, by message index
+	selected     int          // Message index of the selected message or tool result, -1 for none
+	messageLines []int        // First rendered line of each message
+
+	search       string // Highlighted search text, "" when not searching
+	matchLines   []int  // Rendered lines containing search
+	currentMatch int    // Index in matchLines of the selected match
+
+	unread int // Messages added while scrolled up, see Unread
+
+	lines      []string // Rendered lines, for copy mode
+	copying    bool     // Copy mode is on, see StartCopy
+	copyCursor int      // Line of the copy cursor
+	copyAnchor int      // Line where the selection started, -1 for none
 }
 
-// NewConversation creates a new conversation viewport.
+// NewConversation creates a new conversation viewport. The last column of
+// width holds the scrollbar.
 func NewConversation(width, height int) Conversation {
+	width -= scrollbarWidth
 	vp := viewport.New(width, height)
 	vp.Style = LogStyle
 
@@ -32,11 +58,14 @@ func NewConversation(width, height int) Conversation {
 		messages: []provider.Message{},
 		width:    width,
 		height:   height,
+		expanded: make(map[int]bool),
+		selected: -1,
 	}
 }
 
-// SetSize updates the viewport size.
+// SetSize updates the viewport size, including the scrollbar.
 func (c *Conversation) SetSize(width, height int) {
+	width -= scrollbarWidth
 	c.width = width
 	c.height = height
 	c.viewport.Width = width
@@ -48,18 +77,92 @@ func (c *Conversation) SetSize(width, height int) {
 // SetMessages updates the conversation messages and re-renders.
 func (c *Conversation) SetMessages(messages []provider.Message) {
 	c.messages = messages
+	c.expanded = make(map[int]bool)
+	c.selected = -1
+	c.updateContent()
+}
+
+// SetMarkdown turns Markdown rendering of assistant messages on or off.
+func (c *Conversation) SetMarkdown(enabled bool) {
+	c.markdown = nil
+	if enabled {
+		c.markdown = &markdownRenderer{}
+	}
+	c.updateContent()
+}
+
+// SetImages sets the graphics protocol drawing tool result images,
+// config.ImagesKitty or config.ImagesSixel, "" for placeholder lines.
+func (c *Conversation) SetImages(protocol string) {
+	c.images = newImageRenderer(protocol)
+	c.updateContent()
+}
+
+// FlushImages returns the kitty graphics commands transmitting the images
+// drawn since the last call, "" if there are none.
+func (c Conversation) FlushImages() string {
+	return c.images.flush()
+}
+
+// SetShowReasoning shows or hides the reasoning lines of assistant messages.
+func (c *Conversation) SetShowReasoning(show bool) {
+	c.hideReasoning = !show
+	c.updateContent()
+}
+
+// ShowReasoning reports whether reasoning lines are shown.
+func (c Conversation) ShowReasoning() bool {
+	return !c.hideReasoning
+}
+
+// SetTimestampMode sets how message timestamps are shown.
+func (c *Conversation) SetTimestampMode(mode TimestampMode) {
+	c.timestamps = mode
+	c.updateContent()
+}
+
+// TimestampMode returns how message timestamps are shown.
+func (c Conversation) TimestampMode() TimestampMode {
+	return c.timestamps
+}
+
+// Refresh re-renders the messages, e.g. to update relative timestamps.
+func (c *Conversation) Refresh() {
 	c.updateContent()
 }
 
 // AddMessage appends a message and re-renders.
 func (c *Conversation) AddMessage(msg provider.Message) {
+	if !c.viewport.AtBottom() {
+		c.unread++
+	}
 	c.messages = append(c.messages, msg)
 	c.updateContent()
 }
 
+// AppendStream appends streamed assistant text, shown as a message being
+// typed until EndStream.
+func (c *Conversation) AppendStream(content string) {
+	if c.stream == nil {
+		c.stream = &strings.Builder{}
+	}
+	c.stream.WriteString(content)
+	c.updateContent()
+}
+
+// EndStream drops the streamed text, once the complete message arrived or
+// the turn ended.
+func (c *Conversation) EndStream() {
+	if c.stream == nil {
+		return
+	}
+	c.stream = nil
+	c.updateContent()
+}
+
 // updateContent renders all messages and sets viewport content.
 func (c *Conversation) updateContent() {
-	if len(c.messages) == 0 {
+	if len(c.messages) == 0 && c.stream == nil {
 		c.viewport.SetContent(DimmedStyle.Render("No conversation history."))
 		return
 	}
@@ -67,16 +170,44 @@ func (c *Conversation) updateContent() {
 	// Remember if user was at bottom before updating
 	wasAtBottom := c.viewport.AtBottom()
 
+	// Messages of a turn are grouped: a rule separates turns, and a run of
+	// messages with the same role shares one label
 	var lines []string
-	for _, msg := range c.messages {
-		lines = append(lines, c.renderMessage(msg)...)
-		// Blank line with background - must fill width
-		blankStyle := lipgloss.NewStyle().
-			Background(styles.ColorBg).
-			Width(c.width)
+	c.messageLines = c.messageLines[:0]
+	blankStyle := lipgloss.NewStyle().
+		Background(styles.ColorBg).
+		Width(c.width)
+	now := time.Now()
+	for i, msg := range c.messages {
+		continued := i > 0 && msg.Role != "user" && msg.Role == c.messages[i-1].Role
+		switch {
+		case i > 0 && msg.Role == "user":
+			lines = append(lines, blankStyle.Render(""), c.renderTurnRule())
+		case i > 0 && !continued:
+			// Blank line with background - must fill width
+			lines = append(lines, blankStyle.Render(""))
+		}
+		c.messageLines = append(c.messageLines, len(lines))
+		lines = append(lines, c.renderMessage(i, msg, !continued, now)...)
+		if i == c.selected && msg.Role != "tool" {
+			// Tool results mark the selection themselves
+			first := c.messageLines[i]
+			lines[first] = ToolSelectedStyle.Width(c.width).Render(truncate(xansi.Strip(lines[first]), c.width))
+		}
+	}
+	if len(c.messages) > 0 {
 		lines = append(lines, blankStyle.Render(""))
 	}
+	if c.stream != nil {
+		lines = append(lines, c.renderStream()...)
+	}
+	c.highlightMatches(lines)
 
+	c.lines = lines
+	if c.copying {
+		c.copyCursor = min(c.copyCursor, len(lines)-1)
+		c.copyAnchor = min(c.copyAnchor, len(lines)-1)
+	}
 	content := strings.Join(lines, "\n")
 	c.viewport.SetContent(content)
 
@@ -84,38 +215,124 @@ func (c *Conversation) updateContent() {
 	if wasAtBottom {
 		c.viewport.GotoBottom()
 	}
+	c.markRead()
+}
+
+// SetSearch highlights the lines containing text, ignoring case, and
+// selects the last match. An empty text ends the search.
+func (c *Conversation) SetSearch(text string) {
+	c.search = text
+	c.currentMatch = -1 // Select the last match once lines are rendered
+	c.updateContent()
+	c.scrollToMatch()
+}
+
+// NextMatch selects the next match below (delta 1) or above (delta -1),
+// wrapping around, and scrolls to it.
+func (c *Conversation) NextMatch(delta int) {
+	if len(c.matchLines) == 0 {
+		return
+	}
+	c.currentMatch = (c.currentMatch + delta + len(c.matchLines)) % len(c.matchLines)
+	c.updateContent()
+	c.scrollToMatch()
+}
+
+// SearchPosition returns the selected match and the number of matches.
+func (c Conversation) SearchPosition() (current, total int) {
+	return c.currentMatch, len(c.matchLines)
+}
+
+// scrollToMatch centers the selected match in the viewport.
+func (c *Conversation) scrollToMatch() {
+	if c.currentMatch < 0 || c.currentMatch >= len(c.matchLines) {
+		return
+	}
+	c.viewport.SetYOffset(c.matchLines[c.currentMatch] - c.height/2)
+}
+
+// highlightMatches records the lines containing the search text and
+// re-renders them as plain text with the matches highlighted.
+func (c *Conversation) highlightMatches(lines []string) {
+	c.matchLines = c.matchLines[:0]
+	if c.search == "" {
+		return
+	}
+	needle := strings.ToLower(c.search)
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(xansi.Strip(line)), needle) {
+			c.matchLines = append(c.matchLines, i)
+		}
+	}
+	if c.currentMatch < 0 || c.currentMatch >= len(c.matchLines) {
+		c.currentMatch = len(c.matchLines) - 1
+	}
+
+	lineStyle := lipgloss.NewStyle().Background(styles.ColorBg)
+	for n, i := range c.matchLines {
+		matchStyle := SearchMatchStyle
+		if n == c.currentMatch {
+			matchStyle = SearchCurrentStyle
+		}
+		lines[i] = highlightLine(xansi.Strip(lines[i]), needle, lineStyle, matchStyle)
+	}
+}
+
+// highlightLine renders line with each occurrence of needle (lower case) in
+// matchStyle. Lines whose case folding changes their length are highlighted
+// as a whole.
+func highlightLine(line, needle string, lineStyle, matchStyle lipgloss.Style) string {
+	lower := strings.ToLower(line)
+	if len(lower) != len(line) {
+		return matchStyle.Render(line)
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, needle)
+		if i < 0 {
+			b.WriteString(lineStyle.Render(line))
+			return b.String()
+		}
+		end := i + len(needle)
+		b.WriteString(lineStyle.Render(line[:i]))
+		b.WriteString(matchStyle.Render(line[i:end]))
+		line, lower = line[end:], lower[end:]
+	}
 }
 
 // renderMessage renders a single message with role, content, and tool calls.
-func (c Conversation) renderMessage(msg provider.Message) []string {
+// Without label, the message continues the previous one's role label.
+func (c Conversation) renderMessage(index int, msg provider.Message, label bool, now time.Time) []string {
 	var lines []string
 
-	// Timestamp first, then role label
-	var roleLabelText string
+	if label {
+		// Timestamp first, then role label
+		var roleLabelText string
 
-	// Add timestamp if present
-	if !msg.CreatedAt.IsZero() {
-		timestamp := msg.CreatedAt.Format("15:04:05")
-		timestampStyled := DimmedStyle.Render("[" + timestamp + "] ")
-		roleLabelText = timestampStyled
-	}
+		// Add timestamp if present
+		if timestamp := formatTimestamp(msg.CreatedAt, c.timestamps, now); timestamp != "" && !msg.CreatedAt.IsZero() {
+			roleLabelText = DimmedStyle.Render("[" + timestamp + "] ")
+		}
 
-	// Add role label
-	roleLabelText += RoleLabel(msg.Role)
+		// Add role label
+		roleLabelText += RoleLabel(msg.Role)
 
-	roleLineStyle := lipgloss.NewStyle().
-		Background(styles.ColorBg).
-		Width(c.width)
-	lines = append(lines, roleLineStyle.Render(roleLabelText))
+		roleLineStyle := lipgloss.NewStyle().
+			Background(styles.ColorBg).
+			Width(c.width)
+		lines = append(lines, roleLineStyle.Render(roleLabelText))
+	}
 
 	// Reasoning (if present, for assistant messages)
-	if msg.Reasoning != "" {
+	if msg.Reasoning != "" && !c.hideReasoning {
 		reasoningLines := c.renderReasoning(msg.Reasoning)
 		lines = append(lines, reasoningLines...)
 	}
 
 	// Content (if present)
-	if msg.Content != "" {
+	if msg.Role == "tool" {
+		lines = append(lines, c.renderToolResult(index, msg)...)
+	} else if msg.Content != "" {
 		contentLines := c.renderContent(msg.Content, msg.Role)
 		lines = append(lines, contentLines...)
 	}
@@ -129,6 +346,165 @@ func (c Conversation) renderMessage(msg provider.Message) []string {
 	return lines
 }
 
+// renderTurnRule renders the rule separating turns.
+func (c Conversation) renderTurnRule() string {
+	return DimmedStyle.Width(c.width).Render(strings.Repeat(styles.SymbolRule, c.width))
+}
+
+// renderStream renders the streamed assistant text as raw text with a
+// typing indicator; Markdown is rendered once the message is complete.
+func (c Conversation) renderStream() []string {
+	lineStyle := lipgloss.NewStyle().
+		Background(styles.ColorBg).
+		Width(c.width)
+	lines := []string{lineStyle.Render(RoleLabel("assistant") + DimmedStyle.Render(" typing..."))}
+	lines = append(lines, c.renderText(c.stream.String()+styles.SymbolCursor, "assistant")...)
+	return lines
+}
+
+// renderToolResult renders a tool result as a collapsible block: a one-line
+// summary, or the full result with JSON indented once expanded.
+func (c Conversation) renderToolResult(index int, msg provider.Message) []string {
+	style := ToolStyle
+	if index == c.selected {
+		style = ToolSelectedStyle
+	}
+	name := c.toolName(index, msg.ToolCallID)
+
+	if !c.expanded[index] {
+		summary := strings.Join(strings.Fields(msg.Content), " ")
+		lines := []string{style.Width(c.width).Render("  " + styles.SymbolCollapsed + " " + name + ": " + c.truncateContent(summary, "tool"))}
+		return append(lines, c.renderImages(msg.Content)...)
+	}
+
+	lines := []string{style.Width(c.width).Render("  " + styles.SymbolExpanded + " " + name)}
+	content := msg.Content
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(content), "", "  "); err == nil {
+		content = indented.String()
+	}
+	bodyStyle := ToolStyle.Width(c.width)
+	for _, line := range strings.Split(content, "\n") {
+		lines = append(lines, bodyStyle.Render("    "+line))
+	}
+	return append(lines, c.renderImages(msg.Content)...)
+}
+
+// toolName returns the name of the tool call a result answers, looking
+// back from the result at index.
+func (c Conversation) toolName(index int, toolCallID string) string {
+	for i := index - 1; i >= 0; i-- {
+		for _, tc := range c.messages[i].ToolCalls {
+			if tc.ID == toolCallID {
+				return tc.Name
+			}
+		}
+	}
+	return "result"
+}
+
+// Announcement returns the last message on one line for screen readers,
+// e.g. "Agent: Docked at Sol." or "Tool get_status: {...}". Labels are
+// plain text in screen-reader mode, which has no colors.
+func (c Conversation) Announcement() string {
+	if len(c.messages) == 0 {
+		return ""
+	}
+	i := len(c.messages) - 1
+	msg := c.messages[i]
+	label := RoleLabel(msg.Role)
+	text := strings.Join(strings.Fields(msg.Content), " ")
+	switch {
+	case msg.Role == "tool":
+		label += " " + c.toolName(i, msg.ToolCallID)
+	case text == "" && len(msg.ToolCalls) > 0:
+		names := make([]string, len(msg.ToolCalls))
+		for j, tc := range msg.ToolCalls {
+			names[j] = tc.Name
+		}
+		text = "calls " + strings.Join(names, ", ")
+	}
+	return label + ": " + text
+}
+
+// SelectToolResult selects the next tool result below (delta 1) or above
+// (delta -1) the selected one, starting from the latest, and scrolls to
+// it. Returns false if there are no tool results.
+func (c *Conversation) SelectToolResult(delta int) bool {
+	var results []int
+	for i, msg := range c.messages {
+		if msg.Role == "tool" {
+			results = append(results, i)
+		}
+	}
+	if len(results) == 0 {
+		return false
+	}
+
+	pos := len(results) - 1 // Nothing selected: start at the latest
+	for n, i := range results {
+		if i == c.selected {
+			pos = min(max(n+delta, 0), len(results)-1)
+		}
+	}
+	c.selected = results[pos]
+	c.updateContent()
+	c.scrollToMessage(c.selected)
+	return true
+}
+
+// SelectMessage selects the next message below (delta 1) or above (delta
+// -1) the selected one, starting from the latest, and scrolls to it.
+// Returns false when there are no messages.
+func (c *Conversation) SelectMessage(delta int) bool {
+	if len(c.messages) == 0 {
+		return false
+	}
+	if c.selected < 0 || c.selected >= len(c.messages) {
+		c.selected = len(c.messages) - 1
+	} else {
+		c.selected = min(max(c.selected+delta, 0), len(c.messages)-1)
+	}
+	c.updateContent()
+	c.scrollToMessage(c.selected)
+	return true
+}
+
+// SelectedMessage returns the selected message.
+func (c Conversation) SelectedMessage() (provider.Message, bool) {
+	if c.selected < 0 || c.selected >= len(c.messages) {
+		return provider.Message{}, false
+	}
+	return c.messages[c.selected], true
+}
+
+// ClearSelection unselects the message or tool result.
+func (c *Conversation) ClearSelection() {
+	c.selected = -1
+	c.updateContent()
+}
+
+// ToggleSelected expands or collapses the selected tool result.
+func (c *Conversation) ToggleSelected() {
+	if c.selected < 0 {
+		return
+	}
+	c.expanded[c.selected] = !c.expanded[c.selected]
+	c.updateContent()
+	c.scrollToMessage(c.selected)
+}
+
+// scrollToMessage scrolls the message at index into view if it is not.
+func (c *Conversation) scrollToMessage(index int) {
+	if index >= len(c.messageLines) {
+		return
+	}
+	line := c.messageLines[index]
+	if line < c.viewport.YOffset || line >= c.viewport.YOffset+c.height {
+		c.viewport.SetYOffset(line - c.height/2)
+	}
+}
+
 // truncateContent truncates content similar to CLI behavior.
 // - Tool results: truncated to 100 chars
 // - Reasoning: truncated to 200 chars
@@ -161,11 +537,29 @@ func (c Conversation) renderReasoning(reasoning string) []string {
 
 	// Apply dimmed style with symbol prefix
 	style := DimmedStyle.Width(c.width)
-	return []string{style.Render("  ∴ " + reasoning)}
+	return []string{style.Render("  " + styles.SymbolReasoning + " " + reasoning)}
 }
 
 // renderContent renders message content with truncation but no word wrap per design spec.
+// Assistant messages are rendered as Markdown when enabled, see SetMarkdown.
 func (c Conversation) renderContent(content string, role string) []string {
+	if role == "assistant" && c.markdown != nil {
+		if rendered, ok := c.markdown.render(content, c.width); ok {
+			// Glamour styles the text; the line style fills the background
+			style := lipgloss.NewStyle().Background(styles.ColorBg).Width(c.width)
+			lines := make([]string, len(rendered))
+			for i, line := range rendered {
+				lines[i] = style.Render("  " + line)
+			}
+			return lines
+		}
+	}
+
+	return c.renderText(content, role)
+}
+
+// renderText renders content as raw text in the role style.
+func (c Conversation) renderText(content string, role string) []string {
 	// Truncate content based on role (like CLI does)
 	truncated := c.truncateContent(content, role)
 
@@ -191,7 +585,7 @@ func (c Conversation) renderToolCalls(toolCalls []provider.ToolCall) []string {
 
 	for _, tc := range toolCalls {
 		// Build tool line content
-		content := fmt.Sprintf("  ⚙ %s", tc.Name)
+		content := fmt.Sprintf("  %s %s", styles.SymbolTool, tc.Name)
 
 		// Arguments (truncated)
 		var args map[string]interface{}
@@ -215,19 +609,72 @@ func (c Conversation) renderToolCalls(toolCalls []provider.ToolCall) []string {
 // Update handles viewport updates (scrolling, etc).
 func (c Conversation) Update(msg interface{}) (Conversation, bool) {
 	c.viewport, _ = c.viewport.Update(msg)
+	c.markRead()
 	return c, false
 }
 
-// View renders the conversation viewport.
+// View renders the conversation viewport and its scrollbar, with a pill
+// over the last row counting the unread messages, or the copy mode hint.
 func (c Conversation) View() string {
 	// The viewport already has LogStyle which includes background
-	// Just return it directly - the parent will handle sizing
-	return c.viewport.View()
+	view := c.viewport.View()
+	pill := c.unreadPill()
+	if c.copying {
+		view = c.highlightCopy(view)
+		pill = c.copyPill()
+	}
+	if pill != "" || c.images != nil {
+		lines := strings.Split(view, "\n")
+		if c.images != nil {
+			clipSixels(lines)
+		}
+		if pill != "" {
+			pad := c.width - lipgloss.Width(pill) - 1
+			lines[len(lines)-1] = LogStyle.Width(pad).Render("") + pill + LogStyle.Render(" ")
+		}
+		view = strings.Join(lines, "\n")
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, view, c.scrollbar())
+}
+
+// unreadPill renders the unread message count with the key jumping to
+// them, "" when there are none or it does not fit.
+func (c Conversation) unreadPill() string {
+	if c.unread == 0 || c.viewport.AtBottom() {
+		return ""
+	}
+	arrow := "↓"
+	if styles.Plain() {
+		arrow = "v"
+	}
+	noun := "messages"
+	if c.unread == 1 {
+		noun = "message"
+	}
+	pill := UnreadPillStyle.Render(fmt.Sprintf("%d new %s %s %s", c.unread, noun, arrow, scrollKeys.Latest.Help().Key))
+	if lipgloss.Width(pill)+1 > c.width {
+		return ""
+	}
+	return pill
+}
+
+// Unread returns the number of messages added while scrolled up, reset
+// once the bottom is reached.
+func (c Conversation) Unread() int {
+	return c.unread
+}
+
+// markRead resets the unread count when the bottom is shown.
+func (c *Conversation) markRead() {
+	if c.viewport.AtBottom() {
+		c.unread = 0
+	}
 }
 
 // GotoBottom scrolls to the bottom.
 func (c *Conversation) GotoBottom() {
 	c.viewport.GotoBottom()
+	c.unread = 0
 }
 
 // ScrollPercent returns the current scroll percentage.
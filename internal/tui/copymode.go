@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// Copy mode key bindings. Copy mode releases the mouse so the terminal can
+// select text, and selects lines of the conversation with vi-like keys.
+var copyKeys = struct {
+	Open   key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Yank   key.Binding
+	Close  key.Binding
+}{
+	Open:   key.NewBinding(key.WithKeys("alt+c"), key.WithHelp("alt+c", "copy mode: select and copy conversation lines")),
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("up/k", "move the cursor up")),
+	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("down/j", "move the cursor down")),
+	Select: key.NewBinding(key.WithKeys("v", " "), key.WithHelp("v/space", "start or drop a selection")),
+	Yank:   key.NewBinding(key.WithKeys("y", "enter"), key.WithHelp("y/enter", "copy the selection and leave")),
+	Close:  key.NewBinding(key.WithKeys("esc", "q", "alt+c"), key.WithHelp("esc/q", "leave copy mode")),
+}
+
+// StartCopy enters copy mode with the cursor on the last shown line above
+// the hint.
+func (c *Conversation) StartCopy() {
+	c.copying = true
+	c.copyAnchor = -1
+	c.copyCursor = max(min(c.viewport.YOffset+c.height-2, len(c.lines)-1), 0)
+}
+
+// StopCopy leaves copy mode.
+func (c *Conversation) StopCopy() {
+	c.copying = false
+}
+
+// Copying reports whether copy mode is on.
+func (c Conversation) Copying() bool {
+	return c.copying
+}
+
+// MoveCopyCursor moves the copy cursor by delta lines, scrolling to keep it
+// shown above the hint.
+func (c *Conversation) MoveCopyCursor(delta int) {
+	c.copyCursor = max(min(c.copyCursor+delta, len(c.lines)-1), 0)
+	rows := max(c.height-1, 1)
+	switch {
+	case c.copyCursor < c.viewport.YOffset:
+		c.viewport.SetYOffset(c.copyCursor)
+	case c.copyCursor >= c.viewport.YOffset+rows:
+		c.viewport.SetYOffset(c.copyCursor - rows + 1)
+	}
+	c.markRead()
+}
+
+// ToggleCopySelection starts a selection at the cursor, or drops it.
+func (c *Conversation) ToggleCopySelection() {
+	if c.copyAnchor >= 0 {
+		c.copyAnchor = -1
+		return
+	}
+	c.copyAnchor = c.copyCursor
+}
+
+// copyRange returns the first and last selected line: the selection, or the
+// cursor line without one.
+func (c Conversation) copyRange() (int, int) {
+	if c.copyAnchor < 0 {
+		return c.copyCursor, c.copyCursor
+	}
+	return min(c.copyAnchor, c.copyCursor), max(c.copyAnchor, c.copyCursor)
+}
+
+// CopySelection returns the text of the selected lines without styling or
+// trailing blanks.
+func (c Conversation) CopySelection() string {
+	from, to := c.copyRange()
+	if from < 0 || to >= len(c.lines) {
+		return ""
+	}
+	lines := make([]string, 0, to-from+1)
+	for _, line := range c.lines[from : to+1] {
+		lines = append(lines, strings.TrimRight(xansi.Strip(line), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightCopy marks the selected lines among the viewport rows of view.
+func (c Conversation) highlightCopy(view string) string {
+	rows := strings.Split(view, "\n")
+	from, to := c.copyRange()
+	for i := range rows {
+		if line := c.viewport.YOffset + i; line >= from && line <= to {
+			rows[i] = CopySelectionStyle.Width(c.width).Render(truncate(xansi.Strip(rows[i]), c.width))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// copyPill renders the copy mode hint shown over the last row.
+func (c Conversation) copyPill() string {
+	from, to := c.copyRange()
+	hint := "COPY · v select · y copy · esc leave"
+	if c.copyAnchor >= 0 {
+		hint = fmt.Sprintf("COPY · %d lines · y copy · esc leave", to-from+1)
+	}
+	pill := UnreadPillStyle.Render(hint)
+	if lipgloss.Width(pill)+1 > c.width {
+		return ""
+	}
+	return pill
+}
+
+// updateCopy handles keys in copy mode. The mouse is given back to the
+// application when it ends.
+func (m Model) updateCopy(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	switch {
+	case key.Matches(msg, copyKeys.Up):
+		m.conversation.MoveCopyCursor(-1)
+	case key.Matches(msg, copyKeys.Down):
+		m.conversation.MoveCopyCursor(1)
+	case key.Matches(msg, scrollKeys.PageUp):
+		m.conversation.MoveCopyCursor(-m.conversation.height)
+	case key.Matches(msg, scrollKeys.PageDown):
+		m.conversation.MoveCopyCursor(m.conversation.height)
+	case key.Matches(msg, scrollKeys.HalfUp):
+		m.conversation.MoveCopyCursor(-m.conversation.height / 2)
+	case key.Matches(msg, scrollKeys.HalfDown):
+		m.conversation.MoveCopyCursor(m.conversation.height / 2)
+	case key.Matches(msg, scrollKeys.Top):
+		m.conversation.MoveCopyCursor(-len(m.conversation.lines))
+	case key.Matches(msg, scrollKeys.Bottom), key.Matches(msg, scrollKeys.Latest):
+		m.conversation.MoveCopyCursor(len(m.conversation.lines))
+	case key.Matches(msg, copyKeys.Select):
+		m.conversation.ToggleCopySelection()
+	case key.Matches(msg, copyKeys.Yank):
+		text := m.conversation.CopySelection()
+		m.conversation.StopCopy()
+		lines := strings.Count(text, "\n") + 1
+		return m, tea.Batch(tea.EnableMouseCellMotion, copyToClipboard(text),
+			m.statusBar.SetWarning(fmt.Sprintf("Copied %d line(s) to the clipboard", lines)))
+	case key.Matches(msg, copyKeys.Close):
+		m.conversation.StopCopy()
+		return m, tea.EnableMouseCellMotion
+	}
+	return m, nil
+}
+
+// copyToClipboard sets the terminal clipboard to text with an OSC 52
+// sequence, wrapped for tmux and screen, which pass it on when allowed.
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(text)
+		switch {
+		case os.Getenv("TMUX") != "":
+			seq = seq.Tmux()
+		case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+			seq = seq.Screen()
+		}
+		_, _ = seq.WriteTo(os.Stdout)
+		return nil
+	}
+}
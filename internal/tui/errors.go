@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// maxErrorEntries is the number of recent errors the error overlay keeps.
+const maxErrorEntries = 20
+
+// Error overlay key bindings
+var errorKeys = struct {
+	Open  key.Binding
+	Copy  key.Binding
+	Close key.Binding
+}{
+	Open:  key.NewBinding(key.WithKeys("alt+e"), key.WithHelp("alt+e", "show the full text of recent errors")),
+	Copy:  key.NewBinding(key.WithKeys("y", "c"), key.WithHelp("y/c", "copy the last error")),
+	Close: key.NewBinding(key.WithKeys("esc", "q", "alt+e"), key.WithHelp("esc/q", "close the errors")),
+}
+
+// errorEntry is an error shown in the status bar.
+type errorEntry struct {
+	At     time.Time
+	Text   string
+	Detail string // Stack trace or context lines, see ErrorMsg
+}
+
+// ErrorLog keeps the most recent errors, oldest first, for the error
+// overlay. The status bar shows only the start of the last.
+type ErrorLog struct {
+	entries []errorEntry
+}
+
+// Add records an error, dropping the oldest past maxErrorEntries.
+func (l *ErrorLog) Add(text, detail string, at time.Time) {
+	if len(l.entries) == maxErrorEntries {
+		l.entries = append(l.entries[:0], l.entries[1:]...)
+	}
+	l.entries = append(l.entries, errorEntry{At: at, Text: text, Detail: detail})
+}
+
+// Last returns the text of the last error with its detail, "" without
+// errors.
+func (l ErrorLog) Last() string {
+	if len(l.entries) == 0 {
+		return ""
+	}
+	e := l.entries[len(l.entries)-1]
+	if e.Detail == "" {
+		return e.Text
+	}
+	return e.Text + "\n\n" + e.Detail
+}
+
+// errorsContent renders the error overlay at width: the errors newest
+// first, wrapped in full, with their detail.
+func errorsContent(l ErrorLog, width int) string {
+	lineStyle := lipgloss.NewStyle().Background(styles.ColorBg).Width(width)
+	wrap := lipgloss.NewStyle().Width(max(width-4, 10))
+	var lines []string
+	add := func(text string, style lipgloss.Style) {
+		for _, line := range strings.Split(wrap.Render(text), "\n") {
+			lines = append(lines, lineStyle.Render("  "+style.Render(strings.TrimRight(line, " "))))
+		}
+	}
+
+	title := fmt.Sprintf("Errors (%d, newest first)", len(l.entries))
+	lines = append(lines, lineStyle.Render(BrandTitleStyle.Render(title)+LogStyle.Render("  ")+
+		ButtonActiveStyle.Render("Copy last ("+errorKeys.Copy.Help().Key+")")))
+	if len(l.entries) == 0 {
+		lines = append(lines, lineStyle.Render(""), lineStyle.Render(DimmedStyle.Render("  No errors this session.")))
+	}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		lines = append(lines, lineStyle.Render(""), lineStyle.Render(DimmedStyle.Render(e.At.Format("15:04:05"))))
+		add(e.Text, ToolErrorStyle)
+		if e.Detail != "" {
+			add(e.Detail, DimmedStyle)
+		}
+	}
+
+	lines = append(lines, lineStyle.Render(""), lineStyle.Render(DimmedStyle.Render("up/down to scroll, y to copy the last error, esc to close")))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/xonecas/mysis/internal/features"
+)
+
+// Transcript export key bindings
+var exportKeys = struct {
+	Markdown key.Binding
+	JSON     key.Binding
+}{
+	Markdown: key.NewBinding(key.WithKeys("alt+x"), key.WithHelp("alt+x", "export the session as a Markdown transcript")),
+	JSON:     key.NewBinding(key.WithKeys("alt+X"), key.WithHelp("alt+shift+x", "export the session as a JSON transcript")),
+}
+
+// TranscriptExportedMsg reports the file a transcript was written to.
+type TranscriptExportedMsg struct {
+	Path string
+}
+
+// exportTranscript writes the stored session history as a transcript in
+// format, see features.ExportTranscript, through the callback.
+func (m Model) exportTranscript(format string) tea.Cmd {
+	onExport := m.onExport
+	return func() tea.Msg {
+		if onExport == nil {
+			return ErrorMsg{Error: "exporting transcripts is not supported here"}
+		}
+		path, err := onExport(format)
+		if err != nil {
+			return ErrorMsg{Error: "Export failed: " + err.Error()}
+		}
+		return TranscriptExportedMsg{Path: path}
+	}
+}
+
+// exportFormat returns the transcript format of an export key.
+func exportFormat(msg tea.KeyMsg) string {
+	if key.Matches(msg, exportKeys.JSON) {
+		return features.TranscriptJSON
+	}
+	return features.TranscriptMarkdown
+}
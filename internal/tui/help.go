@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// HelpInfo is the session configuration and command list the help overlay
+// shows, taken from the runner when the overlay opens.
+type HelpInfo struct {
+	Session  string   // Session name, "" for anonymous sessions
+	Provider string   // Current provider
+	Model    string   // Current model
+	Upstream string   // MCP upstream
+	Commands []string // Slash command lines, see features.Commands.HelpLines
+}
+
+// Overlay is a scrollable text view shown in place of the conversation,
+// e.g. the "?" help listing key bindings, slash commands and the session
+// configuration.
+type Overlay struct {
+	viewport viewport.Model
+	visible  bool
+	close    key.Binding // Keys closing the overlay
+	copy     key.Binding // Keys copying copyText, see SetCopy
+	copyText string
+}
+
+// Help overlay key bindings
+var helpKeys = struct {
+	Open  key.Binding
+	Close key.Binding
+}{
+	Open:  key.NewBinding(key.WithKeys("?", "f1"), key.WithHelp("? / f1", "show this help (? on an empty input)")),
+	Close: key.NewBinding(key.WithKeys("esc", "?", "q"), key.WithHelp("esc/q", "close the help")),
+}
+
+// keyGroups are the key bindings listed by the help overlay.
+func keyGroups() []struct {
+	title    string
+	bindings []key.Binding
+} {
+	return []struct {
+		title    string
+		bindings []key.Binding
+	}{
+		{"Keys", []key.Binding{keys.Enter, newlineKey, historyKeys.Up, historyKeys.Down, keys.PauseAutoplay, keys.StopAutoplay,
+			keys.ClearNotifications, keys.ToggleReasoning, keys.ShowReasoning, timestampKeys.Cycle, keys.Escape, helpKeys.Open, keys.Quit}},
+		{"Layout", []key.Binding{layoutKeys.ToggleAutoplay, layoutKeys.ToggleGame, layoutKeys.ToggleMap, layoutKeys.ToggleNotifications, layoutKeys.ToggleLogs,
+			layoutKeys.GrowInput, layoutKeys.ShrinkInput, layoutKeys.WidenPanel, layoutKeys.NarrowPanel}},
+		{"Scrolling", []key.Binding{scrollKeys.PageUp, scrollKeys.PageDown, scrollKeys.HalfUp, scrollKeys.HalfDown,
+			scrollKeys.Top, scrollKeys.Bottom, scrollKeys.Latest}},
+		{"Model picker", []key.Binding{modelKeys.Open, modelKeys.Up, modelKeys.Down, modelKeys.Choose, modelKeys.Close}},
+		{"Split view", []key.Binding{splitKeys.Focus}},
+		{"New session", []key.Binding{newSessionKeys.Open, newSessionKeys.Next, newSessionKeys.Prev, newSessionKeys.Left, newSessionKeys.Right,
+			newSessionKeys.Create, newSessionKeys.Close}},
+		{"Export", []key.Binding{exportKeys.Markdown, exportKeys.JSON}},
+		{"Errors", []key.Binding{errorKeys.Open, errorKeys.Copy, errorKeys.Close}},
+		{"Messages", []key.Binding{messageKeys.Browse, toolKeys.Up, toolKeys.Down, messageKeys.Menu, actionKeys.Close}},
+		{"Copy mode", []key.Binding{copyKeys.Open, copyKeys.Up, copyKeys.Down, copyKeys.Select, copyKeys.Yank, copyKeys.Close}},
+		{"Search", []key.Binding{searchKeys.Open, searchKeys.Next, searchKeys.Prev, searchKeys.Edit}},
+		{"Tool approval", []key.Binding{approvalKeys.Approve, approvalKeys.Deny, approvalKeys.Switch, approvalKeys.Confirm}},
+		{"Tool results", []key.Binding{toolKeys.Browse, toolKeys.Up, toolKeys.Down, toolKeys.Toggle}},
+	}
+}
+
+// Open shows content at the given size until a close key is pressed.
+func (o *Overlay) Open(content string, close key.Binding, width, height int) {
+	o.viewport = viewport.New(width, height)
+	o.viewport.Style = LogStyle
+	o.viewport.SetContent(content)
+	o.close = close
+	o.copyText = ""
+	o.visible = true
+}
+
+// SetCopy makes the copy keys put text on the clipboard while the overlay
+// is open.
+func (o *Overlay) SetCopy(text string, copy key.Binding) {
+	o.copyText = text
+	o.copy = copy
+}
+
+// Close hides the overlay.
+func (o *Overlay) Close() {
+	o.visible = false
+}
+
+// Update closes the overlay on its close keys and scrolls it otherwise.
+func (o Overlay) Update(msg tea.Msg) (Overlay, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, o.close) {
+		o.Close()
+		return o, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && o.copyText != "" && key.Matches(msg, o.copy) {
+		return o, tea.Batch(copyToClipboard(o.copyText), func() tea.Msg {
+			return WarningMsg{Warning: "Copied to the clipboard"}
+		})
+	}
+	var cmd tea.Cmd
+	o.viewport, cmd = o.viewport.Update(msg)
+	return o, cmd
+}
+
+// View renders the overlay.
+func (o Overlay) View() string {
+	return o.viewport.View()
+}
+
+// helpContent renders the overlay text at width.
+func helpContent(info HelpInfo, width int) string {
+	lineStyle := lipgloss.NewStyle().Background(styles.ColorBg).Width(width)
+	var lines []string
+	section := func(title string) {
+		if len(lines) > 0 {
+			lines = append(lines, lineStyle.Render(""))
+		}
+		lines = append(lines, lineStyle.Render(BrandTitleStyle.Render(title)))
+	}
+	row := func(name, desc string, nameWidth int) {
+		lines = append(lines, lineStyle.Render("  "+InputTextStyle.Render(fmt.Sprintf("%-*s", nameWidth, name))+"  "+AssistantStyle.Render(desc)))
+	}
+
+	section("Session")
+	session := info.Session
+	if session == "" {
+		session = "(anonymous)"
+	}
+	row("session", session, 10)
+	row("provider", info.Provider, 10)
+	row("model", info.Model, 10)
+	row("mcp", info.Upstream, 10)
+
+	for _, group := range keyGroups() {
+		section(group.title)
+		for _, b := range group.bindings {
+			row(b.Help().Key, b.Help().Desc, 12)
+		}
+	}
+
+	section("Commands")
+	for _, line := range info.Commands {
+		lines = append(lines, lineStyle.Render("  "+AssistantStyle.Render(line)))
+	}
+
+	lines = append(lines, lineStyle.Render(""), lineStyle.Render(DimmedStyle.Render("up/down to scroll, esc to close")))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,334 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif" // Decoders of the image formats tools return
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// Size limits of images in the conversation, in cells.
+const (
+	maxImageColumns = 60
+	maxImageRows    = 16
+)
+
+// Size of a terminal cell in pixels when the terminal does not report it.
+const (
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+)
+
+// kittyPlaceholder is the character kitty replaces with a cell of the
+// image whose ID is in the foreground color.
+const kittyPlaceholder = "\U0010EEEE"
+
+// kittyDiacritics encode the row of the first placeholder of each image
+// row, and its column 0; kitty infers the following columns. See the
+// rowcolumn-diacritics table of the kitty graphics protocol.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+	0x035B, 0x0363, 0x0364, 0x0365,
+}
+
+// imageProtocol returns the graphics protocol of a tui.images setting,
+// config.ImagesKitty or config.ImagesSixel, or "" for placeholders. Auto
+// detection goes by the terminal's environment variables; tmux and
+// screen-reader mode get placeholders.
+func imageProtocol(setting string) string {
+	switch setting {
+	case config.ImagesKitty, config.ImagesSixel:
+		return setting
+	case config.ImagesOff:
+		return ""
+	}
+	if styles.ScreenReader() || os.Getenv("TMUX") != "" {
+		return ""
+	}
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case term == "xterm-kitty", os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-ghostty", program == "ghostty":
+		return config.ImagesKitty
+	case strings.HasPrefix(term, "foot"), term == "mlterm", program == "WezTerm", program == "iTerm.app":
+		return config.ImagesSixel
+	}
+	return ""
+}
+
+// imageRenderer draws tool result images in the conversation, caching the
+// drawing of each image since the conversation is re-rendered on every
+// update. Kitty images are transmitted once, see flush, and drawn with
+// placeholder characters, so they scroll with the text. Sixel images are
+// drawn by their caption line, over the blank lines above it.
+type imageRenderer struct {
+	protocol string              // config.ImagesKitty or config.ImagesSixel
+	cache    map[string][]string // Lines by path and size
+	failed   map[string]bool     // Paths that cannot be shown
+	nextID   int
+	pending  []string // Kitty transmissions to write
+}
+
+// newImageRenderer returns a renderer for protocol, nil for placeholders.
+func newImageRenderer(protocol string) *imageRenderer {
+	if protocol == "" {
+		return nil
+	}
+	return &imageRenderer{protocol: protocol, cache: make(map[string][]string), failed: make(map[string]bool)}
+}
+
+// render returns the lines drawing img at most width cells wide after
+// indent columns, ending with caption, or false if it cannot be drawn.
+// Lines drawn with placeholders are not padded.
+func (r *imageRenderer) render(img features.ToolImage, indent, width int, caption string) ([]string, bool) {
+	if img.Path == "" || r.failed[img.Path] {
+		return nil, false
+	}
+	key := img.Path + "@" + strconv.Itoa(width)
+	if lines, ok := r.cache[key]; ok {
+		return lines, true
+	}
+	lines, err := r.draw(img.Path, indent, width, caption)
+	if err != nil {
+		log.Debug().Err(err).Str("path", img.Path).Msg("Failed to draw image - showing a placeholder")
+		r.failed[img.Path] = true
+		return nil, false
+	}
+	r.cache[key] = lines
+	return lines, true
+}
+
+// draw decodes the image at path and draws it for the protocol.
+func (r *imageRenderer) draw(path string, indent, width int, caption string) ([]string, error) {
+	//nolint:gosec // G304: Path from an image marker in the data directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	cellW, cellH := cellSize()
+	bounds := decoded.Bounds()
+	cols, rows := imageCells(bounds.Dx(), bounds.Dy(), cellW, cellH, min(width, maxImageColumns))
+
+	if r.protocol == config.ImagesSixel {
+		// The caption line draws the image over the blank lines above it
+		// once they are written, see clipSixels
+		lines := make([]string, rows, rows+1)
+		sixel := encodeSixel(decoded, cols*cellW, rows*cellH)
+		return append(lines, fmt.Sprintf("\x1b7\x1b[%dA\x1b[%dC%s\x1b8%s", rows, indent, sixel, caption)), nil
+	}
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, decoded); err != nil {
+		return nil, err
+	}
+	r.nextID = r.nextID%255 + 1
+	id := r.nextID
+	r.pending = append(r.pending, kittyTransmission(id, encoded.Bytes(), cols, rows))
+	lines := make([]string, rows, rows+1)
+	for row := range rows {
+		lines[row] = "\x1b[38;5;" + strconv.Itoa(id) + "m" + kittyPlaceholder +
+			string(kittyDiacritics[row]) + string(kittyDiacritics[0]) +
+			strings.Repeat(kittyPlaceholder, cols-1) + "\x1b[39m"
+	}
+	return append(lines, caption), nil
+}
+
+// flush returns the kitty transmissions of the images drawn since the last
+// flush.
+func (r *imageRenderer) flush() string {
+	if r == nil || len(r.pending) == 0 {
+		return ""
+	}
+	out := strings.Join(r.pending, "")
+	r.pending = nil
+	return out
+}
+
+// imageCells returns the cells an image of w×h pixels takes at its size,
+// scaled down to fit maxCols and maxImageRows.
+func imageCells(w, h, cellW, cellH, maxCols int) (cols, rows int) {
+	cols = max((w+cellW-1)/cellW, 1)
+	rows = max((h+cellH-1)/cellH, 1)
+	if cols > maxCols {
+		rows = max(rows*maxCols/cols, 1)
+		cols = maxCols
+	}
+	if rows > maxImageRows {
+		cols = max(cols*maxImageRows/rows, 1)
+		rows = maxImageRows
+	}
+	return max(cols, 1), rows
+}
+
+// kittyTransmission returns the kitty graphics commands transmitting a PNG
+// as image id, in chunks, and creating its virtual placement of cols×rows
+// cells for the placeholder characters.
+func kittyTransmission(id int, data []byte, cols, rows int) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for first := true; first || encoded != ""; first = false {
+		chunk := encoded[:min(len(encoded), 4096)]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if encoded != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=t,f=100,t=d,q=2,i=%d,m=%d;%s\x1b\\", id, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	fmt.Fprintf(&b, "\x1b_Ga=p,U=1,q=2,i=%d,c=%d,r=%d\x1b\\", id, cols, rows)
+	return b.String()
+}
+
+// encodeSixel returns img scaled to w×h pixels as sixel graphics, with
+// the colors of a 6×6×6 cube and transparent pixels left alone.
+func encodeSixel(img image.Image, w, h int) string {
+	bounds := img.Bounds()
+	pixels := make([]int, w*h) // Palette index, -1 for transparent
+	used := make([]bool, 216)
+	for y := range h {
+		for x := range w {
+			c := img.At(bounds.Min.X+x*bounds.Dx()/w, bounds.Min.Y+y*bounds.Dy()/h)
+			r, g, b, a := c.RGBA()
+			if a < 0x8000 {
+				pixels[y*w+x] = -1
+				continue
+			}
+			i := int(r*5/0xffff)*36 + int(g*5/0xffff)*6 + int(b*5/0xffff)
+			pixels[y*w+x] = i
+			used[i] = true
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i, ok := range used {
+		if ok {
+			fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+	row := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		for i, ok := range used {
+			if !ok {
+				continue
+			}
+			present := false
+			for x := range w {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if pixels[(top+dy)*w+x] == i {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+				present = present || bits != 0
+			}
+			if !present {
+				continue
+			}
+			fmt.Fprintf(&out, "#%d", i)
+			writeSixelRun(&out, row)
+			out.WriteByte('$')
+		}
+		out.WriteByte('-')
+	}
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+// writeSixelRun writes a band of sixel characters, compressing repeats.
+func writeSixelRun(out *strings.Builder, row []byte) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, row[x])
+		} else {
+			out.Write(row[x : x+n])
+		}
+		x += n
+	}
+}
+
+// renderImages returns the lines showing the images of a tool result:
+// each drawn with its caption under it, or a placeholder line.
+func (c Conversation) renderImages(content string) []string {
+	lineStyle := ToolStyle.Width(c.width)
+	var lines []string
+	for _, img := range features.FindToolImages(content) {
+		caption := "    " + imageCaption(img)
+		if c.images == nil {
+			lines = append(lines, lineStyle.Render(caption))
+			continue
+		}
+		drawn, ok := c.images.render(img, 4, c.width-4, lineStyle.Render(caption))
+		if !ok {
+			lines = append(lines, lineStyle.Render(caption))
+			continue
+		}
+		for _, line := range drawn[:len(drawn)-1] {
+			pad := max(c.width-4-lipgloss.Width(line), 0)
+			lines = append(lines, LogStyle.Render("    ")+line+LogStyle.Render(strings.Repeat(" ", pad)))
+		}
+		lines = append(lines, drawn[len(drawn)-1])
+	}
+	return lines
+}
+
+// sixelImage matches the drawing of a sixel image by its caption line, see
+// imageRenderer.draw.
+var sixelImage = regexp.MustCompile(`\x1b7\x1b\[(\d+)A.*?\x1b8`)
+
+// clipSixels drops the sixel images of a view whose top is scrolled out,
+// as they would be drawn over the rows above the conversation.
+func clipSixels(lines []string) {
+	for i, line := range lines {
+		if !strings.Contains(line, "\x1b7\x1b[") {
+			continue
+		}
+		lines[i] = sixelImage.ReplaceAllStringFunc(line, func(drawing string) string {
+			rows, _ := strconv.Atoi(sixelImage.FindStringSubmatch(drawing)[1])
+			if rows > i {
+				return ""
+			}
+			return drawing
+		})
+	}
+}
+
+// imageCaption describes an image, e.g. "image image/png · 5 KB · path".
+func imageCaption(img features.ToolImage) string {
+	parts := []string{"image " + img.MimeType}
+	if img.Size > 0 {
+		parts = append(parts, fmt.Sprintf("%d KB", (img.Size+1023)/1024))
+	}
+	if img.Path != "" {
+		parts = append(parts, img.Path)
+	} else {
+		parts = append(parts, "not saved")
+	}
+	return strings.Join(parts, " · ")
+}
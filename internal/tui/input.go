@@ -1,51 +1,114 @@
 package tui
 
 import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/xonecas/mysis/internal/styles"
 )
 
-const maxHistorySize = 100
+const (
+	maxHistorySize = 100
+	maxInputLines  = 8 // Rows the input grows to before scrolling
+	inputPrompt    = "> "
+)
 
-// Input handles text input with history navigation.
+// Input handles multi-line text input with history navigation. Enter sends,
+// Alt+Enter or Ctrl+J inserts a newline and the input grows with its text
+// up to maxInputLines.
 type Input struct {
-	textInput    textinput.Model
+	textInput    textarea.Model
 	history      []string // Previous messages
 	historyIndex int      // Current position in history (-1 = not browsing)
 	draft        string   // Saved draft when browsing history
 	width        int
+	minHeight    int // Rows shown even when the text takes fewer, see SetMinHeight
 }
 
 // NewInput creates a new input component.
 func NewInput(width int) Input {
-	ti := textinput.New()
-	ti.Placeholder = "Type message or command..."
-	ti.Prompt = "> "
-	ti.CharLimit = 2000
-	ti.Width = width - 6 // Account for: border (2) + padding (2) + prompt (2)
-	ti.Focus()
+	ta := textarea.New()
+	ta.Placeholder = "Type message or command..."
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 10000 // Room for pasted strategies
+	ta.MaxHeight = maxInputLines
+	ta.KeyMap.InsertNewline = newlineKey
+	ta.SetPromptFunc(len(inputPrompt), func(line int) string {
+		if line == 0 {
+			return inputPrompt
+		}
+		return strings.Repeat(" ", len(inputPrompt))
+	})
+	ta.SetHeight(1)
 
 	// Set text input colors to match our theme
-	ti.PromptStyle = InputPromptStyle
-	ti.TextStyle = InputTextStyle
-	ti.PlaceholderStyle = InputPlaceholderStyle
+	focused := textarea.Style{
+		Base:        lipgloss.NewStyle().Background(styles.ColorBg),
+		CursorLine:  InputTextStyle,
+		EndOfBuffer: InputTextStyle,
+		Placeholder: InputPlaceholderStyle,
+		Prompt:      InputPromptStyle,
+		Text:        InputTextStyle,
+	}
+	ta.FocusedStyle = focused
+	ta.BlurredStyle = focused
+	if styles.ReducedMotion() {
+		ta.Cursor.SetMode(cursor.CursorStatic)
+	}
+	ta.Focus()
 
-	return Input{
-		textInput:    ti,
+	i := Input{
+		textInput:    ta,
 		history:      make([]string, 0, maxHistorySize),
 		historyIndex: -1,
-		width:        width,
 	}
+	i.SetWidth(width)
+	return i
 }
 
+// newlineKey inserts a newline in the input. Terminals send Shift+Enter as
+// Enter unless configured to send it as Alt+Enter.
+var newlineKey = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"), key.WithHelp("alt+enter", "insert a newline (shift+enter where the terminal sends alt+enter)"))
+
 // SetWidth updates the input width.
 func (i *Input) SetWidth(width int) {
 	i.width = width
-	// Account for: border (2) + padding (2) + prompt (2) = 6 chars
-	i.textInput.Width = width - 6
+	// Account for: padding (2), the prompt is included
+	i.textInput.SetWidth(width - 2)
+	i.fitHeight()
+}
+
+// SetMinHeight sets the rows the input keeps when its text takes fewer,
+// between 1 and maxInputLines.
+func (i *Input) SetMinHeight(rows int) {
+	i.minHeight = min(max(rows, 1), maxInputLines)
+	i.fitHeight()
+}
+
+// MinHeight returns the rows set with SetMinHeight.
+func (i Input) MinHeight() int {
+	return max(i.minHeight, 1)
+}
+
+// Height returns the rows the input takes, between its minimum height and
+// maxInputLines.
+func (i Input) Height() int {
+	return i.textInput.Height()
+}
+
+// fitHeight grows or shrinks the input to its wrapped text.
+func (i *Input) fitHeight() {
+	width := max(i.width-2-len(inputPrompt), 1)
+	rows := 0
+	for _, line := range strings.Split(i.textInput.Value(), "\n") {
+		rows += max((lipgloss.Width(line)+width-1)/width, 1)
+	}
+	i.textInput.SetHeight(min(max(rows, i.minHeight), maxInputLines))
 }
 
 // Focus focuses the input.
@@ -66,6 +129,7 @@ func (i Input) Value() string {
 // SetValue sets the input value.
 func (i *Input) SetValue(value string) {
 	i.textInput.SetValue(value)
+	i.fitHeight()
 }
 
 // Reset clears the input.
@@ -73,6 +137,7 @@ func (i *Input) Reset() {
 	i.textInput.Reset()
 	i.historyIndex = -1
 	i.draft = ""
+	i.fitHeight()
 }
 
 // AddToHistory adds a message to the history.
@@ -94,24 +159,47 @@ func (i *Input) AddToHistory(message string) {
 	}
 }
 
+// History returns the sent messages, oldest first.
+func (i Input) History() []string {
+	return slices.Clone(i.history)
+}
+
+// SetHistory replaces the sent messages, e.g. with those of a previous run.
+func (i *Input) SetHistory(history []string) {
+	if len(history) > maxHistorySize {
+		history = history[len(history)-maxHistorySize:]
+	}
+	i.history = slices.Clone(history)
+	i.historyIndex = -1
+}
+
+// Draft returns the unsent text, also while a history entry is shown.
+func (i Input) Draft() string {
+	if i.historyIndex != -1 {
+		return i.draft
+	}
+	return i.textInput.Value()
+}
+
 // History key bindings
 var historyKeys = struct {
 	Up   key.Binding
 	Down key.Binding
 }{
-	Up:   key.NewBinding(key.WithKeys("up")),
-	Down: key.NewBinding(key.WithKeys("down")),
+	Up:   key.NewBinding(key.WithKeys("up"), key.WithHelp("up", "previous input (on the first line)")),
+	Down: key.NewBinding(key.WithKeys("down"), key.WithHelp("down", "next input (on the last line)")),
 }
 
-// Update handles input updates.
+// Update handles input updates. Up and Down move between the lines of a
+// multi-line input and browse the history from its first and last line.
 func (i Input) Update(msg tea.Msg) (Input, tea.Cmd) {
 	// Handle history navigation
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch {
-		case key.Matches(keyMsg, historyKeys.Up):
+		case key.Matches(keyMsg, historyKeys.Up) && i.textInput.Line() == 0:
 			i.navigateHistory(1) // Go back in history
 			return i, nil
-		case key.Matches(keyMsg, historyKeys.Down):
+		case key.Matches(keyMsg, historyKeys.Down) && i.textInput.Line() == i.textInput.LineCount()-1:
 			i.navigateHistory(-1) // Go forward in history
 			return i, nil
 		}
@@ -119,6 +207,7 @@ func (i Input) Update(msg tea.Msg) (Input, tea.Cmd) {
 
 	var cmd tea.Cmd
 	i.textInput, cmd = i.textInput.Update(msg)
+	i.fitHeight()
 	return i, cmd
 }
 
@@ -149,13 +238,11 @@ func (i *Input) navigateHistory(direction int) {
 	// Update input value
 	if i.historyIndex == -1 {
 		// Back to draft
-		i.textInput.SetValue(i.draft)
-		i.textInput.CursorEnd()
+		i.SetValue(i.draft)
 	} else {
 		// Show history item (most recent is at end of slice)
 		historyIdx := len(i.history) - 1 - i.historyIndex
-		i.textInput.SetValue(i.history[historyIdx])
-		i.textInput.CursorEnd()
+		i.SetValue(i.history[historyIdx])
 	}
 }
 
@@ -165,7 +252,7 @@ func (i Input) View() string {
 	// The textinput component's placeholder doesn't respect our background color
 	if i.textInput.Value() == "" {
 		// Render prompt
-		prompt := InputPromptStyle.Render(i.textInput.Prompt)
+		prompt := InputPromptStyle.Render(inputPrompt)
 
 		// Render placeholder with remaining width
 		placeholderStyle := lipgloss.NewStyle().
@@ -173,6 +260,7 @@ func (i Input) View() string {
 			Foreground(styles.ColorMuted)
 
 		placeholder := prompt + placeholderStyle.Render(i.textInput.Placeholder)
+		placeholder += strings.Repeat("\n", i.Height()-1) // Keep the minimum height
 		return InputBorderStyle.Width(i.width).Render(placeholder)
 	}
 
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+const (
+	defaultLogsHeight = 8 // Log pane rows when the layout sets none
+	panelWidthStep    = 4 // Columns a panel resize key adds or removes
+	logTickInterval   = time.Second
+)
+
+// Layout key bindings, resizing and toggling the panes. Changes are saved
+// with the callback set by SetOnLayout.
+var layoutKeys = struct {
+	ToggleAutoplay      key.Binding
+	ToggleGame          key.Binding
+	ToggleMap           key.Binding
+	ToggleNotifications key.Binding
+	ToggleLogs          key.Binding
+	GrowInput           key.Binding
+	ShrinkInput         key.Binding
+	WidenPanel          key.Binding
+	NarrowPanel         key.Binding
+}{
+	ToggleAutoplay:      key.NewBinding(key.WithKeys("alt+a"), key.WithHelp("alt+a", "show or hide the autoplay panel")),
+	ToggleGame:          key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "show or hide the game state panel")),
+	ToggleMap:           key.NewBinding(key.WithKeys("alt+m"), key.WithHelp("alt+m", "show or hide the map panel")),
+	ToggleNotifications: key.NewBinding(key.WithKeys("alt+n"), key.WithHelp("alt+n", "show or hide the notification panel")),
+	ToggleLogs:          key.NewBinding(key.WithKeys("alt+l"), key.WithHelp("alt+l", "show or hide the log pane")),
+	GrowInput:           key.NewBinding(key.WithKeys("alt+up"), key.WithHelp("alt+up", "grow the input")),
+	ShrinkInput:         key.NewBinding(key.WithKeys("alt+down"), key.WithHelp("alt+down", "shrink the input")),
+	WidenPanel:          key.NewBinding(key.WithKeys("alt+=", "alt++"), key.WithHelp("alt+=", "widen the side panels")),
+	NarrowPanel:         key.NewBinding(key.WithKeys("alt+-"), key.WithHelp("alt+-", "narrow the side panels")),
+}
+
+// LogTickMsg refreshes the log pane while it is shown.
+type LogTickMsg struct{}
+
+// logTick schedules the next LogTickMsg.
+func logTick() tea.Cmd {
+	return tea.Tick(logTickInterval, func(time.Time) tea.Msg { return LogTickMsg{} })
+}
+
+// logsView renders the log pane at width and height: a titled border and the
+// latest log lines, warnings and errors colored.
+func logsView(width, height int) string {
+	lines := features.RecentLogs()
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+
+	lineStyle := lipgloss.NewStyle().Background(styles.ColorBg).Width(width)
+	rows := make([]string, 0, height+1)
+	title := " Logs (" + layoutKeys.ToggleLogs.Help().Key + " to hide) "
+	rows = append(rows, lineStyle.Render(DimmedStyle.Render("──"+title+strings.Repeat("─", max(width-2-lipgloss.Width(title), 0)))))
+	for _, line := range lines {
+		style := DimmedStyle
+		switch {
+		case strings.Contains(line, " ERR ") || strings.Contains(line, " FTL "):
+			style = NotificationCriticalStyle
+		case strings.Contains(line, " WRN "):
+			style = NotificationWarningStyle
+		}
+		rows = append(rows, lineStyle.Render(style.Render(truncate(line, width))))
+	}
+	for len(rows) < height+1 {
+		rows = append(rows, lineStyle.Render(""))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// SetLayout applies pane sizes and visibility, e.g. the saved layout at
+// startup.
+func (m *Model) SetLayout(layout config.TUILayout) {
+	m.panes = layout
+	m.input.SetMinHeight(layout.InputHeight)
+	m.logTicks = layout.ShowLogs
+	m.layout()
+}
+
+// SetOnLayout sets the callback saving the layout after a layout key.
+func (m *Model) SetOnLayout(fn func(config.TUILayout)) {
+	m.onLayout = fn
+}
+
+// isLayoutKey reports whether msg is a layout key.
+func isLayoutKey(msg tea.KeyMsg) bool {
+	return key.Matches(msg, layoutKeys.ToggleAutoplay, layoutKeys.ToggleGame, layoutKeys.ToggleMap, layoutKeys.ToggleNotifications,
+		layoutKeys.ToggleLogs, layoutKeys.GrowInput, layoutKeys.ShrinkInput, layoutKeys.WidenPanel, layoutKeys.NarrowPanel)
+}
+
+// updateLayout resizes or toggles a pane for a layout key and saves the
+// layout.
+func (m *Model) updateLayout(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, layoutKeys.ToggleAutoplay):
+		m.panes.HideAutoplay = !m.panes.HideAutoplay
+	case key.Matches(msg, layoutKeys.ToggleGame):
+		m.panes.HideGameState = !m.panes.HideGameState
+	case key.Matches(msg, layoutKeys.ToggleMap):
+		m.panes.HideMap = !m.panes.HideMap
+	case key.Matches(msg, layoutKeys.ToggleNotifications):
+		m.panes.HideNotifications = !m.panes.HideNotifications
+	case key.Matches(msg, layoutKeys.ToggleLogs):
+		m.panes.ShowLogs = !m.panes.ShowLogs
+		if m.panes.ShowLogs && !m.logTicks {
+			m.logTicks = true
+			cmd = logTick()
+		}
+	case key.Matches(msg, layoutKeys.GrowInput):
+		m.panes.InputHeight = min(m.input.MinHeight()+1, config.MaxInputHeight)
+	case key.Matches(msg, layoutKeys.ShrinkInput):
+		m.panes.InputHeight = max(m.input.MinHeight()-1, 1)
+	case key.Matches(msg, layoutKeys.WidenPanel):
+		m.panes.PanelWidth = min(m.sideWidth()+panelWidthStep, config.MaxPanelWidth)
+	case key.Matches(msg, layoutKeys.NarrowPanel):
+		m.panes.PanelWidth = max(m.sideWidth()-panelWidthStep, config.MinPanelWidth)
+	default:
+		return nil
+	}
+
+	m.input.SetMinHeight(m.panes.InputHeight)
+	m.layout()
+	if m.onLayout != nil {
+		m.onLayout(m.panes)
+	}
+	return cmd
+}
+
+// sideWidth returns the width of the side panels.
+func (m Model) sideWidth() int {
+	if m.panes.PanelWidth > 0 {
+		return m.panes.PanelWidth
+	}
+	return panelWidth
+}
+
+// logsHeight returns the rows of log lines the log pane shows.
+func (m Model) logsHeight() int {
+	if m.panes.LogsHeight > 0 {
+		return m.panes.LogsHeight
+	}
+	return defaultLogsHeight
+}
+
+// autoplayShown reports whether the autoplay panel is shown.
+func (m Model) autoplayShown() bool {
+	return m.autoplayActive && !m.panes.HideAutoplay && !m.autoplay.Empty() && m.width-m.sideWidth() >= minConversationWidth
+}
+
+// gameShown reports whether the game state panel is shown.
+func (m Model) gameShown() bool {
+	return !m.panes.HideGameState && !m.panel.Empty() && m.width-m.sideWidth() >= minConversationWidth
+}
+
+// mapShown reports whether the map panel is shown.
+func (m Model) mapShown() bool {
+	return !m.panes.HideMap && !m.starMap.Empty() && m.width-m.sideWidth() >= minConversationWidth
+}
+
+// notificationsShown reports whether the notification panel is shown.
+func (m Model) notificationsShown() bool {
+	return !m.panes.HideNotifications && !m.notifications.Empty() && m.width-m.sideWidth() >= minConversationWidth
+}
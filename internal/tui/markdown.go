@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	glamourstyles "github.com/charmbracelet/glamour/styles"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// markdownRenderer renders assistant messages as Markdown with glamour,
+// caching the output per message since the conversation is re-rendered on
+// every update.
+type markdownRenderer struct {
+	renderer *glamour.TermRenderer
+	width    int
+	cache    map[string][]string // Rendered lines by content
+}
+
+// render returns the lines of content rendered to width, or false if it
+// cannot be rendered.
+func (m *markdownRenderer) render(content string, width int) ([]string, bool) {
+	if m.renderer == nil || m.width != width {
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithStyles(markdownStyle()),
+			glamour.WithWordWrap(max(width-4, 20)),
+		)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to create Markdown renderer")
+			return nil, false
+		}
+		m.renderer = renderer
+		m.width = width
+		m.cache = make(map[string][]string)
+	}
+
+	if lines, ok := m.cache[content]; ok {
+		return lines, true
+	}
+	out, err := m.renderer.Render(content)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to render Markdown - showing raw text")
+		return nil, false
+	}
+	lines := trimBlankLines(strings.Split(out, "\n"))
+	m.cache[content] = lines
+	return lines, true
+}
+
+// trimBlankLines drops the lines without visible text glamour puts around
+// a document.
+func trimBlankLines(lines []string) []string {
+	blank := func(line string) bool { return strings.TrimSpace(xansi.Strip(line)) == "" }
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// markdownStyle returns the glamour style: dark, or ASCII in plain mode,
+// without the document margin since lines are indented like other content.
+func markdownStyle() ansi.StyleConfig {
+	style := glamourstyles.DarkStyleConfig
+	switch {
+	case styles.Plain():
+		style = glamourstyles.ASCIIStyleConfig
+	case styles.LightTheme():
+		style = glamourstyles.LightStyleConfig
+	}
+	var margin uint
+	style.Document.Margin = &margin
+	return style
+}
@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/features"
+)
+
+// ProviderMsg reports the provider and model of the next turn, after a
+// switch or failover.
+type ProviderMsg struct {
+	Name  string
+	Model string
+}
+
+// ModelsListedMsg carries the models of the configured providers for the
+// model picker.
+type ModelsListedMsg struct {
+	Providers []features.ProviderModels
+}
+
+// Model picker key bindings
+var modelKeys = struct {
+	Open   key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Choose key.Binding
+	Close  key.Binding
+}{
+	Open:   key.NewBinding(key.WithKeys("alt+p"), key.WithHelp("alt+p", "switch provider and model (or click it)")),
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("up/k", "previous model")),
+	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("down/j", "next model")),
+	Choose: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "switch to the selected model")),
+	Close:  key.NewBinding(key.WithKeys("esc", "q", "alt+p"), key.WithHelp("esc/q", "close the picker")),
+}
+
+// pickerEntry is a row of the model picker: a provider heading, or one of
+// its models.
+type pickerEntry struct {
+	provider string
+	model    string // "" for a heading
+	note     string // Shown dimmed after the row
+}
+
+// ModelPicker is the overlay listing the configured providers and their
+// models, shown in place of the conversation, to switch on the fly.
+type ModelPicker struct {
+	active   bool
+	loading  bool
+	entries  []pickerEntry
+	cursor   int    // Index in entries of the selected model
+	provider string // Current provider and model, marked in the list
+	model    string
+}
+
+// Open shows the picker while the models load, marking the current model.
+func (p *ModelPicker) Open(provider, model string) {
+	p.active = true
+	p.loading = true
+	p.entries = nil
+	p.cursor = 0
+	p.provider = provider
+	p.model = model
+}
+
+// Close hides the picker.
+func (p *ModelPicker) Close() {
+	p.active = false
+}
+
+// Active reports whether the picker is shown.
+func (p ModelPicker) Active() bool {
+	return p.active
+}
+
+// SetProviders fills the picker, selecting the current model. A provider
+// that cannot list its models offers its configured model.
+func (p *ModelPicker) SetProviders(providers []features.ProviderModels) {
+	p.loading = false
+	p.entries = nil
+	p.cursor = -1
+	for _, prov := range providers {
+		heading := pickerEntry{provider: prov.Name}
+		if prov.Err != nil {
+			heading.note = "could not list models"
+		}
+		p.entries = append(p.entries, heading)
+
+		models := prov.Models
+		if prov.Model != "" && !slices.Contains(models, prov.Model) {
+			models = append([]string{prov.Model}, models...)
+		}
+		for _, model := range models {
+			entry := pickerEntry{provider: prov.Name, model: model}
+			if model == prov.Model && prov.Name != p.provider {
+				entry.note = "configured"
+			}
+			if prov.Name == p.provider && model == p.model {
+				entry.note = "current"
+				p.cursor = len(p.entries)
+			}
+			p.entries = append(p.entries, entry)
+		}
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+		p.move(1)
+	}
+}
+
+// Selected returns the selected provider and model, false while loading or
+// without models.
+func (p ModelPicker) Selected() (string, string, bool) {
+	if p.loading || p.cursor < 0 || p.cursor >= len(p.entries) || p.entries[p.cursor].model == "" {
+		return "", "", false
+	}
+	return p.entries[p.cursor].provider, p.entries[p.cursor].model, true
+}
+
+// move selects the next model in direction delta, skipping headings.
+func (p *ModelPicker) move(delta int) {
+	for i := p.cursor + delta; i >= 0 && i < len(p.entries); i += delta {
+		if p.entries[i].model != "" {
+			p.cursor = i
+			return
+		}
+	}
+}
+
+// updateModelPicker handles the keys of the model picker. Choosing a model
+// switches through /provider, which reports the switch.
+func (m Model) updateModelPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, modelKeys.Up):
+		m.models.move(-1)
+	case key.Matches(msg, modelKeys.Down):
+		m.models.move(1)
+	case key.Matches(msg, modelKeys.Choose):
+		name, model, ok := m.models.Selected()
+		if !ok {
+			return m, nil
+		}
+		m.models.Close()
+		if name == m.models.provider && model == m.models.model {
+			return m, nil
+		}
+		return m, m.executeCommand("/provider " + name + " " + model)
+	case key.Matches(msg, modelKeys.Close):
+		m.models.Close()
+	}
+	return m, nil
+}
+
+// openModelPicker opens the picker and lists the models through the
+// callback.
+func (m *Model) openModelPicker() tea.Cmd {
+	name, model := m.statusBar.Provider()
+	m.models.Open(name, model)
+	onList := m.onListModels
+	return func() tea.Msg {
+		if onList == nil {
+			return ModelsListedMsg{}
+		}
+		return ModelsListedMsg{Providers: onList()}
+	}
+}
+
+// View renders the picker centered at width and height, scrolled to keep
+// the selection shown.
+func (p ModelPicker) View(width, height int) string {
+	inner := min(width-8, 60)
+	rows := max(height-8, 3)
+
+	lines := []string{BrandTitleStyle.Render("Switch provider and model"), ""}
+	switch {
+	case p.loading:
+		lines = append(lines, DimmedStyle.Render("Listing models..."))
+	case len(p.entries) == 0:
+		lines = append(lines, DimmedStyle.Render("No providers configured."))
+	default:
+		first := max(min(p.cursor-rows/2, len(p.entries)-rows), 0)
+		for i := first; i < min(first+rows, len(p.entries)); i++ {
+			lines = append(lines, p.entryLine(i, inner))
+		}
+	}
+	lines = append(lines, "", DimmedStyle.Render(truncate("↑/↓ select · enter switch · esc close", inner)))
+
+	lineStyle := lipgloss.NewStyle().Background(LogStyle.GetBackground()).Width(inner)
+	for i, line := range lines {
+		lines[i] = lineStyle.Render(line)
+	}
+	dialog := DialogStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, dialog,
+		lipgloss.WithWhitespaceBackground(LogStyle.GetBackground()))
+}
+
+// entryLine renders the entry at index i cut to width.
+func (p ModelPicker) entryLine(i, width int) string {
+	e := p.entries[i]
+	if e.model == "" {
+		text := ToolStyle.Render(truncate(e.provider, width))
+		if e.note != "" {
+			text += DimmedStyle.Render(truncate(fmt.Sprintf(" (%s)", e.note), width-lipgloss.Width(text)))
+		}
+		return text
+	}
+	marker := "  "
+	if i == p.cursor {
+		marker = "> "
+	}
+	text := marker + e.model
+	if e.note != "" {
+		text += " (" + e.note + ")"
+	}
+	text = truncate(text, width)
+	if i == p.cursor {
+		return ToolSelectedStyle.Render(text)
+	}
+	return AssistantStyle.Render(text)
+}
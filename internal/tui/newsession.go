@@ -0,0 +1,320 @@
+package tui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// NewSessionOptions are the choices of the new-session dialog.
+type NewSessionOptions struct {
+	Providers []string          // Configured providers, sorted
+	Models    map[string]string // Configured model of each provider
+	Templates []string          // System prompt templates, see features.TemplateNames
+	Provider  string            // Current provider, selected first
+}
+
+// NewSessionRequest is a session to create from the new-session dialog.
+type NewSessionRequest struct {
+	Name     string
+	Provider string
+	Model    string // "" for the configured model of Provider
+	Template string // "" for no system prompt
+}
+
+// SessionSwitchedMsg reports the session the TUI switched to, with its
+// conversation. The input history starts empty, as the session is new.
+type SessionSwitchedMsg struct {
+	Name     string
+	Messages []provider.Message
+}
+
+// newSessionDoneMsg carries the result of creating a session.
+type newSessionDoneMsg struct {
+	Err error
+}
+
+// New-session dialog key bindings. Left/Right choose the provider and
+// template; the name and model are typed.
+var newSessionKeys = struct {
+	Open   key.Binding
+	Next   key.Binding
+	Prev   key.Binding
+	Left   key.Binding
+	Right  key.Binding
+	Create key.Binding
+	Close  key.Binding
+}{
+	Open:   key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "create a new session")),
+	Next:   key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "next field")),
+	Prev:   key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "previous field")),
+	Left:   key.NewBinding(key.WithKeys("left"), key.WithHelp("left", "previous choice")),
+	Right:  key.NewBinding(key.WithKeys("right"), key.WithHelp("right", "next choice")),
+	Create: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "create the session and switch to it")),
+	Close:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// Fields of the new-session dialog, in tab order.
+const (
+	fieldName = iota
+	fieldProvider
+	fieldModel
+	fieldTemplate
+	fieldCount
+)
+
+// noTemplate is the template choice without a system prompt.
+const noTemplate = "(none)"
+
+// NewSessionDialog is the dialog creating a named session, shown in place
+// of the conversation.
+type NewSessionDialog struct {
+	active   bool
+	creating bool // The session is being created
+	options  NewSessionOptions
+	field    int
+	name     textinput.Model
+	model    textinput.Model
+	provider int // Index in options.Providers
+	template int // Index in templates()
+	err      string
+}
+
+// Open shows the dialog with the current provider and its configured model
+// selected.
+func (d *NewSessionDialog) Open(options NewSessionOptions) tea.Cmd {
+	d.active = true
+	d.creating = false
+	d.options = options
+	d.field = fieldName
+	d.err = ""
+	d.template = 0
+	d.provider = max(slices.Index(options.Providers, options.Provider), 0)
+
+	d.name = newSessionInput(64)
+	d.model = newSessionInput(128)
+	d.model.SetValue(d.configuredModel())
+	return d.focus()
+}
+
+// newSessionInput returns a text field of the dialog.
+func newSessionInput(limit int) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = limit
+	ti.TextStyle = InputTextStyle
+	if styles.ReducedMotion() {
+		ti.Cursor.SetMode(cursor.CursorStatic)
+	}
+	return ti
+}
+
+// Close hides the dialog.
+func (d *NewSessionDialog) Close() {
+	d.active = false
+	d.name.Blur()
+	d.model.Blur()
+}
+
+// Active reports whether the dialog is shown.
+func (d NewSessionDialog) Active() bool {
+	return d.active
+}
+
+// templates returns the template choices, noTemplate first.
+func (d NewSessionDialog) templates() []string {
+	return append([]string{noTemplate}, d.options.Templates...)
+}
+
+// selectedProvider returns the chosen provider, "" without providers.
+func (d NewSessionDialog) selectedProvider() string {
+	if d.provider >= len(d.options.Providers) {
+		return ""
+	}
+	return d.options.Providers[d.provider]
+}
+
+// configuredModel returns the configured model of the chosen provider.
+func (d NewSessionDialog) configuredModel() string {
+	return d.options.Models[d.selectedProvider()]
+}
+
+// focus focuses the text field of the selected field, if any.
+func (d *NewSessionDialog) focus() tea.Cmd {
+	d.name.Blur()
+	d.model.Blur()
+	switch d.field {
+	case fieldName:
+		return d.name.Focus()
+	case fieldModel:
+		d.model.CursorEnd()
+		return d.model.Focus()
+	}
+	return nil
+}
+
+// choose moves the choice of the provider or template field by delta. A
+// model left as configured follows the provider.
+func (d *NewSessionDialog) choose(delta int) {
+	switch d.field {
+	case fieldProvider:
+		if len(d.options.Providers) == 0 {
+			return
+		}
+		followModel := d.model.Value() == "" || d.model.Value() == d.configuredModel()
+		d.provider = (d.provider + delta + len(d.options.Providers)) % len(d.options.Providers)
+		if followModel {
+			d.model.SetValue(d.configuredModel())
+		}
+	case fieldTemplate:
+		n := len(d.templates())
+		d.template = (d.template + delta + n) % n
+	}
+}
+
+// Request returns the session to create, or an error message when the
+// dialog is incomplete.
+func (d NewSessionDialog) Request() (NewSessionRequest, string) {
+	name := strings.TrimSpace(d.name.Value())
+	if name == "" {
+		return NewSessionRequest{}, "Enter a session name"
+	}
+	req := NewSessionRequest{Name: name, Provider: d.selectedProvider()}
+	if req.Provider == "" {
+		return NewSessionRequest{}, "No providers configured"
+	}
+	if model := strings.TrimSpace(d.model.Value()); model != d.configuredModel() {
+		req.Model = model
+	}
+	if d.template > 0 {
+		req.Template = d.templates()[d.template]
+	}
+	return req, ""
+}
+
+// updateNewSession handles the keys of the new-session dialog. Enter
+// creates the session through the callback; the dialog stays open with
+// the error if that fails.
+func (m Model) updateNewSession(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.newSession.creating {
+		return m, nil
+	}
+	d := &m.newSession
+	switch {
+	case key.Matches(msg, newSessionKeys.Close):
+		d.Close()
+		return m, m.input.Focus()
+	case key.Matches(msg, newSessionKeys.Next):
+		d.field = (d.field + 1) % fieldCount
+		return m, d.focus()
+	case key.Matches(msg, newSessionKeys.Prev):
+		d.field = (d.field + fieldCount - 1) % fieldCount
+		return m, d.focus()
+	case key.Matches(msg, newSessionKeys.Create):
+		req, problem := d.Request()
+		if problem != "" {
+			d.err = problem
+			return m, nil
+		}
+		d.err = ""
+		d.creating = true
+		// Keep the draft of the session being left
+		if m.onInputHistory != nil {
+			m.onInputHistory(m.InputHistory())
+		}
+		onNew := m.onNewSession
+		return m, func() tea.Msg {
+			if onNew == nil {
+				return newSessionDoneMsg{Err: errors.New("creating sessions is not supported here")}
+			}
+			return newSessionDoneMsg{Err: onNew(req)}
+		}
+	case d.field == fieldProvider || d.field == fieldTemplate:
+		if key.Matches(msg, newSessionKeys.Left) {
+			d.choose(-1)
+		} else if key.Matches(msg, newSessionKeys.Right) {
+			d.choose(1)
+		}
+		return m, nil
+	}
+
+	d.err = ""
+	var cmd tea.Cmd
+	if d.field == fieldName {
+		d.name, cmd = d.name.Update(msg)
+	} else {
+		d.model, cmd = d.model.Update(msg)
+	}
+	return m, cmd
+}
+
+// openNewSession opens the dialog with the choices from the callback.
+func (m *Model) openNewSession() tea.Cmd {
+	var options NewSessionOptions
+	if m.onNewSessionOptions != nil {
+		options = m.onNewSessionOptions()
+	}
+	m.input.Blur()
+	return m.newSession.Open(options)
+}
+
+// View renders the dialog centered at width and height.
+func (d NewSessionDialog) View(width, height int) string {
+	inner := min(width-8, 60)
+	fieldWidth := max(inner-12, 10)
+	d.name.Width = fieldWidth
+	d.model.Width = fieldWidth
+
+	label := func(field int, text string) string {
+		text += strings.Repeat(" ", max(10-len(text), 1))
+		if field == d.field {
+			return ToolSelectedStyle.Render(text)
+		}
+		return DimmedStyle.Render(text)
+	}
+	choice := func(field int, value string) string {
+		if field == d.field {
+			return AssistantStyle.Render(truncate("< "+value+" >", fieldWidth))
+		}
+		return AssistantStyle.Render(truncate("  "+value, fieldWidth))
+	}
+
+	provider := d.selectedProvider()
+	if provider == "" {
+		provider = "no providers configured"
+	}
+	lines := []string{
+		BrandTitleStyle.Render("New session"),
+		"",
+		label(fieldName, "Name") + d.name.View(),
+		label(fieldProvider, "Provider") + choice(fieldProvider, provider),
+		label(fieldModel, "Model") + d.model.View(),
+		label(fieldTemplate, "Template") + choice(fieldTemplate, d.templates()[d.template]),
+		"",
+	}
+	switch {
+	case d.creating:
+		lines = append(lines, DimmedStyle.Render("Creating the session..."))
+	case d.err != "":
+		lines = append(lines, ToolErrorStyle.Render(truncate(d.err, inner)))
+	default:
+		lines = append(lines, DimmedStyle.Render(truncate("The current session is kept and can be resumed.", inner)))
+	}
+	lines = append(lines, "", DimmedStyle.Render(truncate("tab field · ←/→ choose · enter create · esc cancel", inner)))
+
+	lineStyle := lipgloss.NewStyle().Background(LogStyle.GetBackground()).Width(inner)
+	for i, line := range lines {
+		lines[i] = lineStyle.Render(line)
+	}
+	dialog := DialogStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, dialog,
+		lipgloss.WithWhitespaceBackground(LogStyle.GetBackground()))
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/features"
+)
+
+// maxNotifications is the number of unread notifications the panel keeps.
+const maxNotifications = 50
+
+// NotificationPanel lists unread game notifications, newest first, under
+// the game state panel. Ctrl+L marks them read.
+type NotificationPanel struct {
+	unread []features.Notification // Oldest first
+}
+
+// Add appends notifications, dropping the oldest past maxNotifications.
+func (p *NotificationPanel) Add(notifications []features.Notification) {
+	p.unread = append(p.unread, notifications...)
+	if len(p.unread) > maxNotifications {
+		p.unread = p.unread[len(p.unread)-maxNotifications:]
+	}
+}
+
+// Clear marks all notifications read.
+func (p *NotificationPanel) Clear() {
+	p.unread = nil
+}
+
+// Empty reports whether there are no unread notifications.
+func (p NotificationPanel) Empty() bool {
+	return len(p.unread) == 0
+}
+
+// View renders the panel at width and height, with as many notifications
+// as fit.
+func (p NotificationPanel) View(width, height int) string {
+	inner := width - 3 // Border and padding
+	lines := []string{BrandTitleStyle.Render(fmt.Sprintf("Notifications (%d)", len(p.unread)))}
+	footer := DimmedStyle.Render(truncate(keys.ClearNotifications.Help().Key+" mark read", inner))
+
+	textStyle := lipgloss.NewStyle().Width(inner - 3)
+	for i := len(p.unread) - 1; i >= 0; i-- {
+		n := p.unread[i]
+		marker, style := notificationMarker(n.Severity)
+		wrapped := strings.Split(textStyle.Render(n.ReceivedAt.Local().Format("15:04")+" "+n.Text), "\n")
+		if len(lines)+len(wrapped)+2 > height {
+			if len(lines)+3 <= height && len(wrapped) > 1 {
+				wrapped = wrapped[:1] // Show the newest that fits in part
+			} else {
+				break
+			}
+		}
+		for j, line := range wrapped {
+			prefix := "   "
+			if j == 0 {
+				prefix = marker
+			}
+			lines = append(lines, style.Render(prefix+strings.TrimRight(line, " ")))
+		}
+	}
+	lines = append(lines, "", footer)
+
+	return PanelStyle.
+		Width(width - 1). // The left border adds a column
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// notificationMarker returns the line prefix and style of a severity. The
+// prefix tells severities apart without colors.
+func notificationMarker(severity string) (string, lipgloss.Style) {
+	switch severity {
+	case features.NotificationCritical:
+		return "!! ", NotificationCriticalStyle
+	case features.NotificationWarning:
+		return "!  ", NotificationWarningStyle
+	default:
+		return "-  ", NotificationInfoStyle
+	}
+}
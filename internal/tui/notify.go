@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/xonecas/mysis/internal/config"
+)
+
+// maxNotificationBody is the length desktop notifications are cut to.
+const maxNotificationBody = 200
+
+// windowTitle returns the terminal title: the session and what it is
+// doing, e.g. "mysis · miner · thinking".
+func (m Model) windowTitle() string {
+	state := "idle"
+	switch {
+	case m.autoplayActive && m.autoplayPaused:
+		state = "autoplay paused"
+	case m.autoplayActive:
+		state = "autoplay"
+	case m.thinking:
+		state = "thinking"
+	}
+	if m.sessionName == "" {
+		return "mysis · " + state
+	}
+	return "mysis · " + m.sessionName + " · " + state
+}
+
+// updateTitle returns the command setting the terminal title when it
+// changed, nil otherwise or when tui.title is off.
+func (m *Model) updateTitle() tea.Cmd {
+	if !m.showTitle {
+		return nil
+	}
+	title := m.windowTitle()
+	if title == m.title {
+		return nil
+	}
+	m.title = title
+	return tea.SetWindowTitle(title)
+}
+
+// sessionLabel returns the session name for notifications.
+func (m Model) sessionLabel() string {
+	if m.sessionName == "" {
+		return "session"
+	}
+	return m.sessionName
+}
+
+// alert rings the bell or shows a desktop notification as set by
+// tui.notify, when the terminal is not focused.
+func (m Model) alert(title, body string) tea.Cmd {
+	if !m.unfocused || m.notify == "" || m.notify == config.NotifyOff {
+		return nil
+	}
+	if m.notify == config.NotifyDesktop {
+		return writeTerminal(desktopNotification(title, body))
+	}
+	return writeTerminal("\a")
+}
+
+// writeTerminal returns the command writing escape sequences the renderer
+// does not know about to the terminal.
+func writeTerminal(seq string) tea.Cmd {
+	return func() tea.Msg {
+		_, _ = os.Stdout.WriteString(seq)
+		return nil
+	}
+}
+
+// desktopNotification returns the OSC 777 sequence showing a desktop
+// notification, wrapped for tmux and screen like OSC 52, see
+// copyToClipboard.
+func desktopNotification(title, body string) string {
+	clean := func(s string) string {
+		s = strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f || r == ';' {
+				return ' '
+			}
+			return r
+		}, s)
+		return truncate(strings.Join(strings.Fields(s), " "), maxNotificationBody)
+	}
+	seq := "\x1b]777;notify;" + clean(title) + ";" + clean(body) + "\x07"
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+const (
+	panelWidth           = 32 // Default width of the side panels, including their border
+	minConversationWidth = 68 // Conversation width below which the side panels are hidden
+	gaugeWidth           = 10 // Cells in a gauge bar
+)
+
+// GamePanel shows the latest game state reported by get_status and
+// get_ship next to the conversation.
+type GamePanel struct {
+	state features.GameState
+	known bool // A status result was seen
+}
+
+// SetState replaces the displayed game state.
+func (p *GamePanel) SetState(state features.GameState) {
+	p.state = state
+	p.known = true
+}
+
+// Empty reports whether no game state was seen yet.
+func (p GamePanel) Empty() bool {
+	return !p.known
+}
+
+// Height returns the rows the panel needs at width.
+func (p GamePanel) Height(width int) int {
+	return len(p.lines(width))
+}
+
+// View renders the panel at width and height, cutting rows that do not fit.
+func (p GamePanel) View(width, height int) string {
+	lines := p.lines(width)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return PanelStyle.
+		Width(width - 1). // The left border adds a column
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// lines renders the panel content at width.
+func (p GamePanel) lines(width int) []string {
+	inner := width - 3 // Border and padding
+	s := p.state
+
+	lines := []string{BrandTitleStyle.Render("Game state")}
+	row := func(label, value string) {
+		lines = append(lines, DimmedStyle.Render(fmt.Sprintf("%-8s", label))+AssistantStyle.Render(truncate(value, inner-8)))
+	}
+	if s.Ship != "" {
+		row("Ship", s.Ship)
+	}
+	if s.Location != "" {
+		row("Where", s.Location)
+	}
+	if s.Credits.Known {
+		row("Credits", formatAmount(s.Credits.Value))
+	}
+	for _, g := range []struct {
+		label string
+		gauge features.Gauge
+	}{{"Hull", s.Hull}, {"Shield", s.Shield}, {"Fuel", s.Fuel}, {"Cargo", s.Cargo}} {
+		if g.gauge.Known {
+			lines = append(lines, renderGauge(g.label, g.gauge))
+		}
+	}
+	for _, item := range s.CargoList {
+		lines = append(lines, DimmedStyle.Render(truncate(fmt.Sprintf("  %s x%s", item.Name, formatAmount(item.Quantity)), inner)))
+	}
+	if !s.UpdatedAt.IsZero() {
+		lines = append(lines, "", DimmedStyle.Render("updated "+s.UpdatedAt.Local().Format("15:04:05")))
+	}
+	return lines
+}
+
+// renderGauge renders a labelled value with a bar when the maximum is
+// known, e.g. "Hull    80/100 ████████░░".
+func renderGauge(label string, g features.Gauge) string {
+	text := DimmedStyle.Render(fmt.Sprintf("%-8s", label))
+	if g.Max <= 0 {
+		return text + AssistantStyle.Render(formatAmount(g.Value))
+	}
+
+	full, empty := "█", "░"
+	if styles.Plain() {
+		full, empty = "#", "-"
+	}
+	filled := min(max(int(g.Value/g.Max*gaugeWidth+0.5), 0), gaugeWidth)
+	style := GaugeStyle
+	if g.Value/g.Max < 0.25 {
+		style = GaugeLowStyle
+	}
+	value := fmt.Sprintf("%-8s", formatAmount(g.Value)+"/"+formatAmount(g.Max))
+	return text + AssistantStyle.Render(value) +
+		style.Render(strings.Repeat(full, filled)) + DimmedStyle.Render(strings.Repeat(empty, gaugeWidth-filled))
+}
+
+// formatAmount formats a number without decimals when it is whole, with
+// thousands separators, e.g. 12,500.
+func formatAmount(v float64) string {
+	if v != float64(int64(v)) {
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	digits := strconv.FormatInt(int64(v), 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + digits
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// Reasoning overlay key bindings
+var reasoningKeys = struct {
+	Close key.Binding
+}{
+	Close: key.NewBinding(key.WithKeys("esc", "q", "ctrl+o"), key.WithHelp("esc/q", "close the reasoning")),
+}
+
+// reasoningContent renders the untruncated reasoning of messages, wrapped
+// at width, for the reasoning overlay.
+func reasoningContent(messages []provider.Message, width int) string {
+	lineStyle := lipgloss.NewStyle().Background(styles.ColorBg).Width(width)
+	textStyle := AssistantStyle.Width(width - 2)
+	var lines []string
+	for _, msg := range messages {
+		reasoning := strings.TrimSpace(msg.Reasoning)
+		if reasoning == "" {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, lineStyle.Render(""))
+		}
+		title := styles.SymbolReasoning + " Reasoning"
+		if !msg.CreatedAt.IsZero() {
+			title = DimmedStyle.Render("["+msg.CreatedAt.Format("15:04:05")+"] ") + BrandTitleStyle.Render(title)
+		} else {
+			title = BrandTitleStyle.Render(title)
+		}
+		lines = append(lines, lineStyle.Render(title))
+		for _, line := range strings.Split(textStyle.Render(reasoning), "\n") {
+			lines = append(lines, lineStyle.Render("  "+line))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, lineStyle.Render(DimmedStyle.Render("No reasoning in this conversation.")))
+	}
+
+	lines = append(lines, lineStyle.Render(""), lineStyle.Render(DimmedStyle.Render("up/down to scroll, esc to close")))
+	return strings.Join(lines, "\n")
+}
@@ -2,112 +2,311 @@ package tui
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
 	"github.com/xonecas/mysis/internal/features"
 	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/mcp"
 	"github.com/xonecas/mysis/internal/provider"
 	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/store"
 )
 
+// messageSender delivers messages to the TUI: the program, or a pane of
+// the split view, see paneSender.
+type messageSender interface {
+	Send(msg tea.Msg)
+}
+
 // Runner manages the TUI application lifecycle.
 type Runner struct {
-	program         *tea.Program
+	program         messageSender
+	app             *tea.Program // Runs the TUI; nil for the panes of the split view
+	cfg             *config.Config
 	sessionMgr      *session.Manager
-	sessionID       string
-	provider        provider.Provider
+	sessionID       string             // Switched by the new-session dialog while no turn runs
+	provider        provider.Provider  // Guarded by historyMu; switched on failover
+	model           string             // Model of provider, guarded by historyMu
+	registry        *provider.Registry // Creates backup providers for failover
 	proxy           *mcp.Proxy
 	tools           []mcp.Tool
-	autoplayService *features.Service // Autoplay service (display-agnostic)
+	autoplayService *features.Service   // Autoplay service (display-agnostic)
+	commands        *features.Commands  // Slash commands
+	stats           *llm.Stats          // Metrics for this run
+	failures        *llm.FailureTracker // Of the session, guarded by historyMu
+	events          *llm.EventLog       // Event log of the session, guarded by historyMu
+	script          *features.Script    // Session script from [scripts], guarded by historyMu
 
 	// Conversation history maintained by runner
 	// This is the source of truth for history, separate from the TUI display
 	history   []provider.Message
 	historyMu sync.Mutex
+	gameState features.GameState // Shown in the side panel, guarded by historyMu
+	starMap   features.StarMap   // Shown in the map panel, guarded by historyMu
+
+	inputHistoryPath string       // Input history file of the session, "" if unknown
+	turns            atomic.Int32 // Turns in progress, see processTurn
 }
 
 // NewRunner creates a new TUI runner.
 func NewRunner(
 	ctx context.Context,
+	cfg *config.Config,
 	sessionMgr *session.Manager,
 	sessionID string,
 	prov provider.Provider,
+	model string,
+	registry *provider.Registry,
 	proxy *mcp.Proxy,
 	tools []mcp.Tool,
 	history []provider.Message,
 ) (*Runner, error) {
+	r, tuiModel, err := newRunner(ctx, cfg, sessionMgr, sessionID, prov, model, registry, proxy, tools, history)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create bubbletea program
+	r.app = tea.NewProgram(
+		tuiModel,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithReportFocus(),
+	)
+	r.program = r.app
+	return r, nil
+}
+
+// newRunner creates a runner and the model it drives, without a program to
+// send to; NewRunner and the split view set one.
+func newRunner(
+	ctx context.Context,
+	cfg *config.Config,
+	sessionMgr *session.Manager,
+	sessionID string,
+	prov provider.Provider,
+	model string,
+	registry *provider.Registry,
+	proxy *mcp.Proxy,
+	tools []mcp.Tool,
+	history []provider.Message,
+) (*Runner, Model, error) {
 	// P2: Validate critical dependencies
+	if cfg == nil {
+		return nil, Model{}, fmt.Errorf("config cannot be nil")
+	}
 	if prov == nil {
-		return nil, fmt.Errorf("provider cannot be nil")
+		return nil, Model{}, fmt.Errorf("provider cannot be nil")
 	}
 	if proxy == nil {
-		return nil, fmt.Errorf("proxy cannot be nil")
+		return nil, Model{}, fmt.Errorf("proxy cannot be nil")
 	}
 
-	model := NewModel(ctx)
-	model.SetMessages(history)
+	applyStyles()
+	tuiModel := NewModel(ctx)
+	tuiModel.SetMarkdown(cfg.TUI.Markdown)
+	tuiModel.SetImages(cfg.TUI.Images)
+	tuiModel.SetShowReasoning(cfg.TUI.Reasoning)
+	tuiModel.SetMessages(history)
+	gameState, ok := features.GameStateFromHistory(history)
+	if ok {
+		tuiModel.SetGameState(gameState)
+	}
+	starMap, _ := features.StarMapFromHistory(history)
+	tuiModel.SetStarMap(starMap)
 
 	r := &Runner{
+		cfg:        cfg,
 		sessionMgr: sessionMgr,
 		sessionID:  sessionID,
 		provider:   prov,
+		model:      model,
+		registry:   registry,
 		proxy:      proxy,
 		tools:      tools,
 		history:    history, // Keep our own copy of history
+		gameState:  gameState,
+		starMap:    starMap,
+		stats:      llm.NewStats(),
+		events:     features.OpenEventLog(sessionID),
+		failures:   llm.NewFailureTracker(),
 	}
 
 	// P0: Connect the mutex between Runner and Model
-	model.historyMu = &r.historyMu
+	tuiModel.historyMu = &r.historyMu
 
 	// Set up message callback
-	model.SetOnSendMessage(r.handleSendMessage)
-	model.SetOnCommand(r.handleCommand)
+	tuiModel.SetOnSendMessage(r.handleSendMessage)
+	tuiModel.SetOnCommand(r.handleCommand)
+	tuiModel.SetOnSearch(r.searchCompacted)
+	tuiModel.SetOnHelp(r.helpInfo)
+	tuiModel.SetOnListModels(r.listModels)
+	tuiModel.SetOnMessageActions(r.deleteMessage, r.retryFrom)
+	tuiModel.SetOnNewSession(r.newSessionOptions, r.newSession)
+	tuiModel.SetOnExport(r.exportTranscript)
 
-	// Create bubbletea program
-	r.program = tea.NewProgram(
-		model,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	// Restore the input history of the session, saved after each message
+	// and with the unsent draft on exit
+	if path, err := features.InputHistoryPath(sessionID); err != nil {
+		log.Warn().Err(err).Msg("Failed to locate input history")
+	} else {
+		r.inputHistoryPath = path
+		history, err := features.LoadInputHistory(path)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to load input history")
+		}
+		tuiModel.SetInputHistory(history)
+		tuiModel.SetOnInputHistory(r.saveInputHistory)
+	}
+	tuiModel.SetProvider(prov.Name(), model)
+	if name, err := sessionMgr.Name(sessionID); err == nil {
+		tuiModel.SetSessionName(name)
+		if r.script, err = features.SessionScript(cfg, name); err != nil {
+			return nil, Model{}, err
+		}
+	}
+	tuiModel.SetTerminal(cfg.TUI.Title, cfg.TUI.Notify)
 
-	// Initialize autoplay service
+	// Restore the layout saved by the layout keys
+	layout, err := config.LoadLayout(cfg.TUI.Layout)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load TUI layout")
+	}
+	tuiModel.SetLayout(layout)
+	tuiModel.SetOnLayout(func(layout config.TUILayout) {
+		if err := config.SaveLayout(layout); err != nil {
+			log.Warn().Err(err).Msg("Failed to save TUI layout")
+		}
+	})
+
+	// Initialize autoplay service and slash commands
 	r.initAutoplayService()
+	r.initCommands()
 
-	return r, nil
+	return r, tuiModel, nil
 }
 
 // Run starts the TUI application.
 func (r *Runner) Run() error {
-	_, err := r.program.Run()
+	stop := r.startPolling()
+	defer stop()
+
+	final, err := r.app.Run()
+	if m, ok := final.(Model); ok {
+		r.saveInputHistory(m.InputHistory())
+	}
+	r.historyMu.Lock()
+	_ = r.events.Close()
+	r.historyMu.Unlock()
 	return err
 }
 
+// startPolling starts polling notifications when configured; the returned
+// function stops it.
+func (r *Runner) startPolling() context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	if interval := r.cfg.TUI.NotificationPoll; interval > 0 && r.proxy.HasUpstream() {
+		go r.pollNotifications(ctx, interval)
+	}
+	return cancel
+}
+
+// saveInputHistory saves the input history of the session.
+func (r *Runner) saveInputHistory(h features.InputHistory) {
+	if r.inputHistoryPath == "" {
+		return
+	}
+	if err := features.SaveInputHistory(r.inputHistoryPath, h); err != nil {
+		log.Warn().Err(err).Msg("Failed to save input history")
+	}
+}
+
+// pollNotifications calls get_notifications every interval for the
+// notification panel. The game drops notifications once returned, so while
+// autoplay runs they are also queued for its next turn.
+func (r *Runner) pollNotifications(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result, err := r.proxy.CallTool(ctx, "get_notifications", json.RawMessage(`{}`))
+		if err != nil || result == nil || result.IsError {
+			log.Debug().Err(err).Msg("Notification poll failed")
+			continue
+		}
+		r.program.Send(MCPActivityMsg{})
+		var text strings.Builder
+		for _, block := range result.Content {
+			text.WriteString(block.Text)
+		}
+		notifications := features.ParseNotifications(text.String(), time.Now())
+		if len(notifications) == 0 {
+			continue
+		}
+		r.program.Send(NotificationsMsg{Notifications: notifications})
+		if r.autoplayService.Status().Enabled {
+			if n, err := r.autoplayService.Interject(features.FormatNotifications(notifications)); err == nil {
+				r.program.Send(AutoplayQueueMsg{Pending: n})
+			}
+		}
+	}
+}
+
 // Start creates a TUI runner and starts the application.
 // This is the main entry point for TUI mode.
 func Start(
 	ctx context.Context,
+	cfg *config.Config,
 	sessionMgr *session.Manager,
 	sessionID string,
 	prov provider.Provider,
+	model string,
+	registry *provider.Registry,
 	proxy *mcp.Proxy,
 	tools []mcp.Tool,
 	history []provider.Message,
+	autoplayMsg string,
+	playbook *features.Playbook,
 ) error {
-	runner, err := NewRunner(ctx, sessionMgr, sessionID, prov, proxy, tools, history)
+	runner, err := NewRunner(ctx, cfg, sessionMgr, sessionID, prov, model, registry, proxy, tools, history)
 	if err != nil {
 		return fmt.Errorf("failed to create runner: %w", err)
 	}
+
+	// Start autoplay if requested; callbacks queue messages until the program runs
+	if err := runner.autoplayService.StartFromFlags(ctx, autoplayMsg, playbook); err != nil {
+		return err
+	}
 	return runner.Run()
 }
 
 // handleSendMessage sends a message through the LLM loop.
+// While autoplay runs, the message is queued for its next turn instead.
 func (r *Runner) handleSendMessage(content string) error {
+	if r.autoplayService.Status().Enabled {
+		if n, err := r.autoplayService.Interject(content); err == nil {
+			r.program.Send(AutoplayQueueMsg{Pending: n})
+			r.program.Send(CommandOutputMsg{Output: "Queued for the next autoplay turn: " + content})
+			return nil
+		}
+	}
+
 	// Create user message
 	userMsg := provider.Message{
 		Role:      "user",
@@ -135,42 +334,104 @@ func (r *Runner) handleSendMessage(content string) error {
 		defer func() {
 			if rec := recover(); rec != nil {
 				log.Error().Interface("panic", rec).Msg("Panic in processTurn goroutine")
-				r.program.Send(ErrorMsg{Error: fmt.Sprintf("Internal error: %v", rec)})
+				r.program.Send(ErrorMsg{Error: fmt.Sprintf("Internal error: %v", rec), Detail: string(debug.Stack())})
 			}
 		}()
 		// Use background context for normal messages (no cancellation needed)
-		r.processTurn(context.Background(), historyCopy)
+		_, _ = r.processTurn(context.Background(), r.currentProvider(), historyCopy)
 	}()
 
 	return nil
 }
 
-// processTurn handles LLM processing and tool calls.
-func (r *Runner) processTurn(ctx context.Context, history []provider.Message) {
+// processTurn handles LLM processing and tool calls with prov.
+func (r *Runner) processTurn(ctx context.Context, prov provider.Provider, history []provider.Message) (*llm.TurnResult, error) {
 
 	// User message is already in history (added synchronously in handleSendMessage)
 	// No need to append it again
 
+	r.turns.Add(1)
+	defer r.turns.Add(-1)
+
 	// Notify TUI of LLM activity
 	r.program.Send(LLMActivityMsg{})
 
+	r.historyMu.Lock()
+	events, script, failures := r.events, r.script, r.failures
+	r.historyMu.Unlock()
+
 	// Process turn
-	err := llm.ProcessTurn(ctx, llm.ProcessTurnOptions{
-		Provider:        r.provider,
-		Proxy:           r.proxy,
-		Tools:           r.tools,
-		History:         history,
-		OnMessage:       r.onMessage,
-		OnToolCall:      r.onToolCall,
-		MaxToolRounds:   20,
-		HistoryKeepLast: 10,
-		SuppressOutput:  true, // Suppress stdout in TUI mode
+	result, err := llm.ProcessTurn(ctx, llm.ProcessTurnOptions{
+		Provider:           prov,
+		Proxy:              r.proxy,
+		Tools:              r.tools,
+		History:            history,
+		OnMessage:          r.onMessage,
+		OnToolCall:         r.onToolCall,
+		OnRequest:          r.NotifyLLMActivity,
+		OnDelta:            r.onDelta,
+		ApproveTool:        r.toolApprover(),
+		ToolHooks:          script,
+		Stats:              r.stats,
+		Events:             events,
+		Failures:           failures,
+		Pricing:            features.PricingFor(r.cfg, prov.Name()),
+		MaxToolRounds:      20,
+		HistoryKeepLast:    r.cfg.History.KeepTurns,
+		HistoryTokenBudget: r.cfg.History.TokenWindow(),
+		SuppressOutput:     true, // Suppress stdout in TUI mode
+		ImageText:          features.ToolImageText,
 	})
+	r.program.Send(StreamEndedMsg{Failed: err != nil})
+	if plugins := features.Plugins(); features.HasPluginHook(plugins, features.PluginHookTurn) {
+		features.RunTurnHooks(ctx, plugins, features.NewPluginTurn(r.sessionInfo(), history, result, err))
+	}
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to process turn")
-		r.program.Send(ErrorMsg{Error: err.Error()})
+		r.program.Send(ErrorMsg{Error: err.Error(), Detail: r.turnErrorDetail(prov, err)})
 	}
+	return result, err
+}
+
+// turnErrorDetail returns the context of a failed turn for the error
+// overlay: the provider, session and the errors err wraps.
+func (r *Runner) turnErrorDetail(prov provider.Provider, err error) string {
+	r.historyMu.Lock()
+	model := r.model
+	current := prov == r.provider
+	r.historyMu.Unlock()
+
+	lines := []string{"provider: " + prov.Name()}
+	if current {
+		lines = append(lines, "model: "+model)
+	}
+	lines = append(lines, "session: "+r.sessionID)
+	for e := errors.Unwrap(err); e != nil; e = errors.Unwrap(e) {
+		lines = append(lines, "caused by: "+e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runTurn starts a turn on the current history for /retry, closing prov
+// afterwards unless it is the session provider.
+func (r *Runner) runTurn(ctx context.Context, prov provider.Provider) error {
+	history := r.historySnapshot()
+	temporary := prov != r.currentProvider()
+
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().Interface("panic", rec).Msg("Panic in processTurn goroutine")
+				r.program.Send(ErrorMsg{Error: fmt.Sprintf("Internal error: %v", rec), Detail: string(debug.Stack())})
+			}
+		}()
+		if temporary {
+			defer func() { _ = prov.Close() }()
+		}
+		_, _ = r.processTurn(ctx, prov, history)
+	}()
+	return nil
 }
 
 // trimHistory trims the history to keep only the last 100 messages.
@@ -191,10 +452,38 @@ func (r *Runner) onMessage(msg provider.Message) {
 	r.historyMu.Lock()
 	r.history = append(r.history, msg)
 	r.trimHistory()
+	var tool string
+	if msg.Role == "tool" {
+		tool = r.toolName(msg.ToolCallID)
+	}
+	stateChanged := tool != "" && features.UpdateGameState(&r.gameState, tool, msg.Content, time.Now())
+	gameState := r.gameState
+	mapChanged := tool != "" && features.UpdateStarMap(&r.starMap, tool, msg.Content, time.Now())
+	starMap := r.starMap
+	provName := r.provider.Name()
 	r.historyMu.Unlock()
 
 	// Send to TUI for display
 	r.program.Send(MessageReceivedMsg{Message: msg})
+	if msg.Role == "assistant" {
+		stats := r.stats.Snapshot()
+		r.program.Send(UsageMsg{
+			Usage:   stats.Usage,
+			Context: stats.LastContext.Total(),
+			Window:  r.cfg.Providers[provName].ContextWindow,
+		})
+	}
+	if stateChanged {
+		r.program.Send(GameStateMsg{State: gameState})
+	}
+	if mapChanged {
+		r.program.Send(StarMapMsg{Map: starMap})
+	}
+	if features.IsNotificationsTool(tool) {
+		if notifications := features.ParseNotifications(msg.Content, time.Now()); len(notifications) > 0 {
+			r.program.Send(NotificationsMsg{Notifications: notifications})
+		}
+	}
 
 	// Save to database
 	if err := r.sessionMgr.SaveMessage(r.sessionID, msg); err != nil {
@@ -202,29 +491,477 @@ func (r *Runner) onMessage(msg provider.Message) {
 	}
 }
 
+// helpInfo returns the session configuration and commands for the help
+// overlay.
+func (r *Runner) helpInfo() HelpInfo {
+	name, err := r.sessionMgr.Name(r.sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to look up session name")
+	}
+	r.historyMu.Lock()
+	prov, model := r.provider.Name(), r.model
+	r.historyMu.Unlock()
+
+	return HelpInfo{
+		Session:  name,
+		Provider: prov,
+		Model:    model,
+		Upstream: r.cfg.MCP.Upstream,
+		Commands: r.commands.HelpLines(),
+	}
+}
+
+// searchCompacted returns the messages replaced by /compact summaries that
+// contain query.
+func (r *Runner) searchCompacted(query string) ([]provider.Message, error) {
+	return r.sessionMgr.SearchHistory(r.sessionID, query, true, maxOlderMatches)
+}
+
+// onDelta is called with each piece of assistant text as it streams in.
+func (r *Runner) onDelta(content string) {
+	r.program.Send(StreamDeltaMsg{Content: content})
+}
+
+// toolName returns the name of the tool call with id in the history.
+// Must be called with historyMu held.
+func (r *Runner) toolName(id string) string {
+	for i := len(r.history) - 1; i >= 0; i-- {
+		for _, tc := range r.history[i].ToolCalls {
+			if tc.ID == id {
+				return tc.Name
+			}
+		}
+	}
+	return ""
+}
+
+// currentProvider returns the provider used for the next turn.
+func (r *Runner) currentProvider() provider.Provider {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+	return r.provider
+}
+
+// failover switches the conversation to the named backup provider, records
+// the switch on the session and in its history, and closes the old provider.
+func (r *Runner) failover(providerName string) error {
+	prov, model, err := features.CreateProvider(r.cfg, r.registry, providerName)
+	if err != nil {
+		return err
+	}
+
+	oldName := r.replaceProvider(prov, model)
+	r.onMessage(provider.Message{
+		Role:      "system",
+		Content:   features.FailoverNote(oldName, providerName, model),
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+// switchProvider switches the conversation to the named provider and
+// model for /provider and /model.
+func (r *Runner) switchProvider(ctx context.Context, name, model string) error {
+	prov, model, err := features.SwitchProvider(ctx, r.cfg, r.registry, name, model)
+	if err != nil {
+		return err
+	}
+	r.replaceProvider(prov, model)
+	return nil
+}
+
+// replaceProvider makes prov the provider for the next turn, closes the old
+// provider and records the switch on the session. Returns the old name.
+func (r *Runner) replaceProvider(prov provider.Provider, model string) string {
+	r.historyMu.Lock()
+	old := r.provider
+	r.provider = prov
+	r.model = model
+	r.historyMu.Unlock()
+
+	if err := old.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close provider")
+	}
+	if err := r.sessionMgr.SetProvider(r.sessionID, prov.Name(), model); err != nil {
+		log.Warn().Err(err).Msg("Failed to record provider switch")
+	}
+	r.program.Send(ProviderMsg{Name: prov.Name(), Model: model})
+	return old.Name()
+}
+
+// listModels lists the models of the configured providers for the model
+// picker.
+func (r *Runner) listModels() []features.ProviderModels {
+	r.historyMu.Lock()
+	prov, model := r.provider, r.model
+	r.historyMu.Unlock()
+	return features.ListProviderModels(context.Background(), r.cfg, r.registry, prov, model)
+}
+
+// newSessionOptions lists the providers and templates for the new-session
+// dialog.
+func (r *Runner) newSessionOptions() NewSessionOptions {
+	options := NewSessionOptions{Models: make(map[string]string, len(r.cfg.Providers))}
+	for name, provCfg := range r.cfg.Providers {
+		options.Providers = append(options.Providers, name)
+		options.Models[name] = provCfg.Model
+	}
+	sort.Strings(options.Providers)
+
+	if dir, err := features.TemplatesDir(); err == nil {
+		if options.Templates, err = features.TemplateNames(dir); err != nil {
+			log.Warn().Err(err).Msg("Failed to list templates")
+		}
+	}
+	options.Provider = r.currentProvider().Name()
+	return options
+}
+
+// newSession creates the named session of the new-session dialog with its
+// provider and template prompt, and switches the TUI to it. The session
+// left behind stays stored and can be resumed with --session.
+func (r *Runner) newSession(req NewSessionRequest) error {
+	if r.autoplayService.Status().Enabled {
+		return fmt.Errorf("stop autoplay before switching sessions")
+	}
+	if r.turns.Load() > 0 {
+		return fmt.Errorf("wait for the turn to finish before switching sessions")
+	}
+	existing, err := r.sessionMgr.GetByName(req.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("session %q already exists", req.Name)
+	}
+
+	ctx := context.Background()
+	prov, model, err := features.SwitchProvider(ctx, r.cfg, r.registry, req.Provider, req.Model)
+	if err != nil {
+		return err
+	}
+	var prompt string
+	if req.Template != "" {
+		dir, err := features.TemplatesDir()
+		if err == nil {
+			prompt, err = features.LoadTemplate(dir, req.Template, features.TemplateVars(req.Name, req.Provider, model))
+		}
+		if err != nil {
+			_ = prov.Close()
+			return err
+		}
+	} else if prompt, err = features.DefaultSystemPrompt(r.cfg, req.Provider); err != nil {
+		_ = prov.Close()
+		return err
+	}
+
+	result, err := r.sessionMgr.Initialize(req.Name, req.Provider, model)
+	if err != nil {
+		_ = prov.Close()
+		return err
+	}
+	script, err := features.SessionScript(r.cfg, req.Name)
+	if err != nil {
+		_ = prov.Close()
+		return err
+	}
+	var history []provider.Message
+	if prompt != "" {
+		// Stored on the session, so resuming it keeps the prompt
+		if err := r.sessionMgr.SetSystemPrompt(result.SessionID, prompt); err != nil {
+			log.Warn().Err(err).Msg("Failed to record system prompt")
+		}
+		history = features.SetSystemPrompt(history, prompt)
+	}
+
+	inputPath, err := features.InputHistoryPath(result.SessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to locate input history")
+	}
+
+	// The credential and agent messaging tools are scoped to the session
+	r.proxy.RegisterTool(mcp.NewSaveCredentialsTool(), mcp.MakeSaveCredentialsHandler(r.sessionMgr, result.SessionID))
+	r.proxy.RegisterTool(mcp.NewGetCredentialsTool(), mcp.MakeGetCredentialsHandler(r.sessionMgr, result.SessionID))
+	r.proxy.RegisterTool(mcp.NewSendToAgentTool(), mcp.MakeSendToAgentHandler(r.sessionMgr, result.SessionID))
+	r.proxy.RegisterTool(mcp.NewReadAgentInboxTool(), mcp.MakeReadAgentInboxHandler(r.sessionMgr, result.SessionID))
+
+	r.historyMu.Lock()
+	old, oldEvents, oldScript := r.provider, r.events, r.script
+	r.sessionID = result.SessionID
+	r.events = features.OpenEventLog(result.SessionID)
+	r.failures = llm.NewFailureTracker()
+	r.script = script
+	r.provider = prov
+	r.model = model
+	r.history = history
+	r.inputHistoryPath = inputPath
+	display := make([]provider.Message, len(history))
+	copy(display, history)
+	r.historyMu.Unlock()
+
+	if err := old.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close provider")
+	}
+	if err := oldEvents.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close event log")
+	}
+	r.autoplayService.SetScript(script)
+	oldScript.Close()
+	log.Info().Str("session_id", result.SessionID).Str("name", req.Name).Msg("Switched to new session")
+
+	r.program.Send(SessionSwitchedMsg{Name: req.Name, Messages: display})
+	r.program.Send(ProviderMsg{Name: prov.Name(), Model: model})
+	r.printLines([]string{result.SessionInfo})
+	return nil
+}
+
 // onToolCall is called when tool calls are about to be executed.
 func (r *Runner) onToolCall() {
 	// Notify TUI of MCP activity
 	r.program.Send(MCPActivityMsg{})
 }
 
+// toolApprover returns the approver asking the user about tool calls, or
+// nil when tui.approve_tools is off.
+func (r *Runner) toolApprover() llm.ToolApprover {
+	mode := r.cfg.TUI.ApproveTools
+	if mode == "" || mode == config.ApproveToolsOff {
+		return nil
+	}
+	return func(ctx context.Context, call provider.ToolCall) bool {
+		if mode == config.ApproveToolsMutating && mcp.IsReadOnlyTool(call.Name) {
+			return true
+		}
+		reply := make(chan bool, 1)
+		r.program.Send(ApprovalRequestMsg{Call: call, Reply: reply})
+		select {
+		case approved := <-reply:
+			log.Info().Str("tool", call.Name).Bool("approved", approved).Msg("Tool call approval")
+			return approved
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
 // handleCommand handles slash commands.
 func (r *Runner) handleCommand(cmd string) error {
-	parts := strings.Fields(cmd)
-	if len(parts) == 0 {
+	// Background context: commands such as /autoplay outlive the key press
+	err := r.commands.Run(context.Background(), cmd)
+	if errors.Is(err, features.ErrQuit) {
+		r.Stop()
 		return nil
 	}
+	return err
+}
+
+// initCommands registers the TUI handlers of the slash commands.
+func (r *Runner) initCommands() {
+	r.commands = features.NewSessionCommands(r.cfg, features.CommandHandlers{
+		Print:    r.printLines,
+		Autoplay: r.handleAutoplayCommand,
+		Compact:  r.handleCompactCommand,
+		Provider: func() (provider.Provider, string) {
+			r.historyMu.Lock()
+			defer r.historyMu.Unlock()
+			return r.provider, r.model
+		},
+		SwitchProvider: r.switchProvider,
+		History: func() ([]provider.Message, error) {
+			return r.sessionMgr.LoadHistory(r.sessionID)
+		},
+		Context:         r.historySnapshot,
+		ReplaceContext:  r.replaceHistory,
+		AutoplayStatus:  r.autoplayService.Status,
+		Stats:           r.stats.Snapshot,
+		SessionInfo:     r.sessionInfo,
+		Proxy:           func() *mcp.Proxy { return r.proxy },
+		Registry:        func() *provider.Registry { return r.registry },
+		RunTurn:         r.runTurn,
+		Undo:            r.undo,
+		SetSystemPrompt: r.setSystemPrompt,
+	})
+}
+
+// sessionInfo describes the session for transcripts.
+func (r *Runner) sessionInfo() features.TranscriptInfo {
+	name, err := r.sessionMgr.Name(r.sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to look up session name")
+	}
+	var goal string
+	if r.autoplayService != nil {
+		goal = r.autoplayService.Status().Message
+	}
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+	return features.TranscriptInfo{Session: name, Provider: r.provider.Name(), Model: r.model, Goal: goal}
+}
+
+// exportTranscript writes the stored history of the session as a
+// transcript in format into the data dir, like /save.
+func (r *Runner) exportTranscript(format string) (string, error) {
+	r.historyMu.Lock()
+	sessionID := r.sessionID
+	r.historyMu.Unlock()
+	messages, err := r.sessionMgr.LoadHistory(sessionID)
+	if err != nil {
+		return "", err
+	}
+	return features.ExportTranscript(r.sessionInfo(), messages, format, "")
+}
+
+// printLines shows command output in the conversation.
+func (r *Runner) printLines(lines []string) {
+	r.program.Send(CommandOutputMsg{Output: strings.Join(lines, "\n")})
+}
+
+// handleCompactCommand handles /compact [turns].
+// It summarizes older turns and persists the compacted history.
+func (r *Runner) handleCompactCommand(ctx context.Context, args []string) error {
+	keepTurns, err := features.ParseCompactArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if r.autoplayService.Status().Enabled {
+		return fmt.Errorf("stop autoplay before compacting")
+	}
+
+	r.program.Send(LLMActivityMsg{})
+
+	compacted, err := llm.CompactHistory(ctx, llm.CompactOptions{
+		Provider:  r.currentProvider(),
+		History:   r.historySnapshot(),
+		KeepTurns: keepTurns,
+	})
+	r.program.Send(LLMDoneMsg{})
+	if err != nil {
+		return err
+	}
+	return r.replaceHistory(compacted)
+}
+
+// historySnapshot returns a copy of the conversation history.
+func (r *Runner) historySnapshot() []provider.Message {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+	historyCopy := make([]provider.Message, len(r.history))
+	copy(historyCopy, r.history)
+	return historyCopy
+}
+
+// replaceHistory persists a rewritten history, makes it current and
+// redraws the conversation.
+func (r *Runner) replaceHistory(messages []provider.Message) error {
+	if err := r.sessionMgr.ReplaceHistory(r.sessionID, messages); err != nil {
+		return err
+	}
+
+	r.historyMu.Lock()
+	r.history = messages
+	display := make([]provider.Message, len(messages))
+	copy(display, messages)
+	r.historyMu.Unlock()
+
+	r.program.Send(HistoryReplacedMsg{Messages: display})
+	return nil
+}
+
+// setSystemPrompt replaces the system prompt in the history for /system
+// and stores it on the session.
+func (r *Runner) setSystemPrompt(prompt string) error {
+	if err := r.sessionMgr.SetSystemPrompt(r.sessionID, prompt); err != nil {
+		return err
+	}
+	r.historyMu.Lock()
+	r.history = features.SetSystemPrompt(r.history, prompt)
+	r.historyMu.Unlock()
+	return nil
+}
+
+// undo deletes the last exchange from the store and the history for /undo,
+// and redraws the conversation.
+func (r *Runner) undo() (int, error) {
+	removed, err := r.sessionMgr.UndoExchange(r.sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	r.historyMu.Lock()
+	r.history = store.RemoveLastExchange(r.history)
+	display := make([]provider.Message, len(r.history))
+	copy(display, r.history)
+	r.historyMu.Unlock()
+
+	r.program.Send(HistoryReplacedMsg{Messages: display})
+	return removed, nil
+}
+
+// deleteMessage deletes a message of the context from the store and the
+// history for the message action menu, with the tool results or call that
+// go with it, and redraws the conversation.
+func (r *Runner) deleteMessage(msg provider.Message) error {
+	if r.autoplayService.Status().Enabled {
+		return fmt.Errorf("stop autoplay before deleting messages")
+	}
+	r.historyMu.Lock()
+	remove := store.MessageGroup(r.history, indexOfMessage(r.history, msg))
+	r.historyMu.Unlock()
+	if len(remove) == 0 {
+		return fmt.Errorf("the message is no longer in the context")
+	}
 
-	switch parts[0] {
-	case "/autoplay":
-		return r.handleAutoplayCommand(cmd)
-	default:
-		log.Info().Str("command", cmd).Msg("Unknown command")
+	if _, err := r.sessionMgr.DeleteMessage(r.sessionID, msg); err != nil {
+		return err
 	}
+	r.historyMu.Lock()
+	r.history = store.RemoveMessages(r.history, remove)
+	display := make([]provider.Message, len(r.history))
+	copy(display, r.history)
+	r.historyMu.Unlock()
 
+	r.program.Send(HistoryReplacedMsg{Messages: display})
+	r.printLines([]string{fmt.Sprintf("Deleted %d message(s)", len(remove))})
 	return nil
 }
 
+// retryFrom runs the turn of a message again for the message action menu:
+// like /retry, the messages after the user message starting the turn are
+// set aside (they stay stored).
+func (r *Runner) retryFrom(msg provider.Message) error {
+	if r.autoplayService.Status().Enabled {
+		return fmt.Errorf("stop autoplay before retrying a turn")
+	}
+	history := r.historySnapshot()
+	i := indexOfMessage(history, msg)
+	if i < 0 {
+		return fmt.Errorf("the message is no longer in the context")
+	}
+	retry, err := features.RetryContext(history[:i+1])
+	if err != nil {
+		return err
+	}
+	if err := r.replaceHistory(retry); err != nil {
+		return err
+	}
+	r.printLines([]string{fmt.Sprintf("Retrying from here - %d messages set aside (still stored)", len(history)-len(retry))})
+	return r.runTurn(context.Background(), r.currentProvider())
+}
+
+// indexOfMessage returns the index of the last message in messages equal
+// to msg as displayed, or -1.
+func indexOfMessage(messages []provider.Message, msg provider.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if m.Role == msg.Role && m.Content == msg.Content && m.ToolCallID == msg.ToolCallID &&
+			m.Reasoning == msg.Reasoning && len(m.ToolCalls) == len(msg.ToolCalls) && m.CreatedAt.Equal(msg.CreatedAt) {
+			return i
+		}
+	}
+	return -1
+}
+
 // SendMessage sends a message to the TUI (for external use).
 func (r *Runner) SendMessage(msg provider.Message) {
 	r.program.Send(MessageReceivedMsg{Message: msg})
@@ -255,15 +992,122 @@ func (r *Runner) Stop() {
 // initAutoplayService initializes the autoplay service with TUI-specific callbacks.
 // This should be called once when creating the Runner.
 func (r *Runner) initAutoplayService() {
-	r.autoplayService = features.NewAutoplayService(features.AutoplayCallbacks{
-		OnStarted: func(message string, interval time.Duration) {
+	r.autoplayService = features.NewAutoplayService(r.cfg.Autoplay, features.AutoplayCallbacks{
+		OnStarted: func(status features.AutoplayStatus) {
+			message := status.Message
+			if status.GoalName != "" {
+				message = "rotating goals, starting with " + status.GoalName
+			}
+			if status.DryRun {
+				message = "[dry run] " + message
+			}
+			if status.Safe {
+				message = "[safe] " + message
+			}
 			// Send started message to TUI - use goroutine to avoid deadlock if called from Update
-			go r.program.Send(AutoplayStartedMsg{Message: message})
+			go func() {
+				r.program.Send(AutoplayStartedMsg{Message: message})
+				r.program.Send(AutoplayStatusMsg{Status: status})
+			}()
 		},
-		OnStopped: func() {
+		OnStopped: func(summary features.AutoplaySummary) {
+			r.program.Send(CommandOutputMsg{Output: strings.Join(summary.Lines(), "\n")})
 			r.program.Send(AutoplayStoppedMsg{})
 		},
-		OnTurn: func(ctx context.Context, message string) error {
+		OnPaused: func() {
+			// Use goroutine to avoid deadlock if called from Update
+			go func() {
+				r.program.Send(AutoplayPausedMsg{})
+				r.program.Send(AutoplayStatusMsg{Status: r.autoplayService.Status()})
+			}()
+		},
+		OnResumed: func() {
+			go func() {
+				r.program.Send(AutoplayResumedMsg{})
+				r.program.Send(AutoplayStatusMsg{Status: r.autoplayService.Status()})
+			}()
+		},
+		OnGoalChanged: func(status features.AutoplayStatus) {
+			r.program.Send(CommandOutputMsg{Output: fmt.Sprintf("Playbook goal %d/%d: %s", status.Goal, status.Goals, status.Message)})
+			r.program.Send(AutoplayStartedMsg{Message: status.Message})
+			r.program.Send(AutoplayStatusMsg{Status: status})
+		},
+		OnRefine: func(ctx context.Context, goal string) (string, error) {
+			r.historyMu.Lock()
+			historyCopy := make([]provider.Message, len(r.history))
+			copy(historyCopy, r.history)
+			r.historyMu.Unlock()
+
+			r.NotifyLLMActivity()
+			defer r.program.Send(LLMDoneMsg{})
+			return llm.RefineGoal(ctx, llm.RefineOptions{
+				Provider: r.currentProvider(),
+				History:  historyCopy,
+				Goal:     goal,
+			})
+		},
+		OnGoalRefined: func(status features.AutoplayStatus) {
+			r.program.Send(CommandOutputMsg{Output: "Autoplay goal refined: " + status.Message})
+			r.program.Send(AutoplayStartedMsg{Message: status.Message})
+			r.program.Send(AutoplayStatusMsg{Status: status})
+		},
+		OnFailover: func(ctx context.Context, providerName string) error {
+			if err := r.failover(providerName); err != nil {
+				return err
+			}
+			r.program.Send(WarningMsg{Warning: "Failed over to " + providerName})
+			return nil
+		},
+		OnWatchdog: func(match features.WatchdogMatch, action string) {
+			r.program.Send(CommandOutputMsg{Output: "Autoplay watchdog: " + match.String() + " - " + action})
+			r.program.Send(WarningMsg{Warning: "Watchdog: " + match.Kind})
+		},
+		OnNudge: func(message string) {
+			r.onMessage(provider.Message{
+				Role:      "system",
+				Content:   message,
+				CreatedAt: time.Now(),
+			})
+		},
+		OnStopCondition: func(match features.StopConditionMatch) {
+			r.program.Send(CommandOutputMsg{Output: "Autoplay stop condition met - " + match.String()})
+			r.program.Send(WarningMsg{Warning: "Stop condition: " + match.Condition.Name})
+		},
+		OnInterjection: func(message string) {
+			userMsg := provider.Message{
+				Role:      "user",
+				Content:   message,
+				CreatedAt: time.Now(),
+			}
+
+			r.historyMu.Lock()
+			r.history = append(r.history, userMsg)
+			r.historyMu.Unlock()
+
+			r.program.Send(MessageReceivedMsg{Message: userMsg})
+			r.program.Send(AutoplayQueueMsg{Pending: r.autoplayService.Status().Queued})
+
+			if err := r.sessionMgr.SaveMessage(r.sessionID, userMsg); err != nil {
+				log.Warn().Err(err).Msg("Failed to save queued message")
+			}
+		},
+		OnTurnDone: func(status features.AutoplayStatus) {
+			r.program.Send(AutoplayProgressMsg{
+				Turns:             status.Turns,
+				ConsecutiveErrors: status.ConsecutiveErrors,
+				NextTurnAt:        status.NextTurnAt,
+			})
+			r.program.Send(AutoplayStatusMsg{Status: status})
+		},
+		OnAlert: func(alert features.AlertMatch) {
+			r.program.Send(CommandOutputMsg{Output: "Autoplay alert - " + alert.String()})
+			r.program.Send(ErrorMsg{Error: "Alert: " + alert.Notification})
+		},
+		OnSchedule: func(inWindow bool, next time.Time) {
+			r.program.Send(AutoplayScheduleMsg{InWindow: inWindow, NextWindow: next})
+			r.program.Send(AutoplayStatusMsg{Status: r.autoplayService.Status()})
+		},
+		OnTurn: func(ctx context.Context, message string) (*llm.TurnResult, error) {
 			// Create user message
 			userMsg := provider.Message{
 				Role:      "user",
@@ -289,45 +1133,58 @@ func (r *Runner) initAutoplayService() {
 			// Process turn (synchronously for autoplay to prevent overlapping turns)
 			// Use background context - let the current turn complete even if autoplay is stopped
 			// The autoplay loop will check ctx.Done() after this returns
-			r.processTurn(context.Background(), historyCopy)
-
-			return nil
+			// Errors are already shown by processTurn; returning them feeds the circuit breaker
+			return r.processTurn(context.Background(), r.currentProvider(), historyCopy)
+		},
+		OnBreaker: func(open bool, until time.Time) {
+			r.program.Send(AutoplayBreakerMsg{Open: open, Until: until})
+			r.program.Send(AutoplayStatusMsg{Status: r.autoplayService.Status()})
 		},
 		OnError: func(err error) {
+			// Already displayed by processTurn
 			log.Error().Err(err).Msg("Autoplay error")
-			r.program.Send(ErrorMsg{Error: err.Error()})
 		},
 	})
+	r.autoplayService.SetToolGate(r.proxy)
+	r.autoplayService.SetScript(r.script)
 }
 
 // handleAutoplayCommand handles the /autoplay command.
-func (r *Runner) handleAutoplayCommand(cmd string) error {
-	parts := strings.Fields(cmd)
-
-	// Check for "stop" subcommand
-	if len(parts) >= 2 && parts[1] == "stop" {
-		if err := r.autoplayService.Stop(); err != nil {
-			return err
-		}
-		log.Info().Msg("Autoplay stopped")
-		// The AutoplayStoppedMsg will be sent by the service's OnStopped callback
+func (r *Runner) handleAutoplayCommand(ctx context.Context, args []string) error {
+	parsed, err := features.ParseAutoplayCommand(args)
+	if err != nil {
+		return err
+	}
+	if parsed.Action == features.AutoplayActionStatus {
+		r.showAutoplayStatus()
 		return nil
 	}
+	// The run is controlled by /autoplay stop, not this command
+	return r.autoplayService.Execute(ctx, parsed)
+}
 
-	// Start autoplay - need a message
-	if len(parts) < 2 {
-		return fmt.Errorf("usage: /autoplay <message> or /autoplay stop")
+// showAutoplayStatus prints the autoplay state to the conversation.
+func (r *Runner) showAutoplayStatus() {
+	status := r.autoplayService.Status()
+	if !status.Enabled {
+		r.program.Send(CommandOutputMsg{Output: "Autoplay not active\n" + features.AutoplayUsage})
+		return
 	}
 
-	message := strings.Join(parts[1:], " ")
-	if message == "" {
-		return fmt.Errorf("missing message for autoplay")
+	state := "active"
+	if status.Paused {
+		state = "paused"
 	}
-
-	// Start autoplay
-	if err := r.autoplayService.Start(context.Background(), message); err != nil {
-		return fmt.Errorf("%s - use '/autoplay stop' first", err.Error())
+	lines := []string{fmt.Sprintf("Autoplay %s: %q", state, status.Message)}
+	if status.DryRun {
+		lines = append(lines, "Dry run: game actions are simulated")
 	}
-
-	return nil
+	if status.Safe {
+		lines = append(lines, "Safe mode: tools limited to "+strings.Join(r.proxy.ToolFilter(), ", "))
+	}
+	lines = append(lines, status.DetailLines()...)
+	if schedule := status.ScheduleText(); schedule != "" {
+		lines = append(lines, "Schedule: "+schedule)
+	}
+	r.program.Send(CommandOutputMsg{Output: strings.Join(lines, "\n")})
 }
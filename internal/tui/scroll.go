@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// scrollbarWidth is the width of the conversation scrollbar.
+const scrollbarWidth = 1
+
+// Conversation scrolling key bindings. PgUp, PgDn and the jump to the latest
+// message always scroll; the others edit the input, so they scroll only while
+// it is empty, and g and G only while browsing tool results or search
+// matches.
+var scrollKeys = struct {
+	PageUp   key.Binding
+	PageDown key.Binding
+	HalfUp   key.Binding
+	HalfDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Latest   key.Binding
+}{
+	PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "scroll up a page")),
+	PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdn", "scroll down a page")),
+	HalfUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "scroll up half a page (empty input)")),
+	HalfDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "scroll down half a page (empty input)")),
+	Top:      key.NewBinding(key.WithKeys("home", "g"), key.WithHelp("home/g", "scroll to the top (g while browsing)")),
+	Bottom:   key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("end/G", "scroll to the bottom (G while browsing)")),
+	Latest:   key.NewBinding(key.WithKeys("ctrl+end", "alt+j"), key.WithHelp("alt+j", "jump to the latest message")),
+}
+
+// isScrollKey reports whether msg scrolls the conversation, given whether
+// the input is empty and whether tool results or search matches are being
+// browsed.
+func isScrollKey(msg tea.KeyMsg, inputEmpty, browsing bool) bool {
+	switch {
+	case key.Matches(msg, scrollKeys.PageUp, scrollKeys.PageDown, scrollKeys.Latest):
+		return true
+	case msg.String() == "g" || msg.String() == "G":
+		return browsing
+	default:
+		return (inputEmpty || browsing) && key.Matches(msg, scrollKeys.HalfUp, scrollKeys.HalfDown, scrollKeys.Top, scrollKeys.Bottom)
+	}
+}
+
+// Scroll scrolls the conversation for a scroll key, see isScrollKey.
+func (c *Conversation) Scroll(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, scrollKeys.PageUp):
+		c.viewport.PageUp()
+	case key.Matches(msg, scrollKeys.PageDown):
+		c.viewport.PageDown()
+	case key.Matches(msg, scrollKeys.HalfUp):
+		c.viewport.HalfPageUp()
+	case key.Matches(msg, scrollKeys.HalfDown):
+		c.viewport.HalfPageDown()
+	case key.Matches(msg, scrollKeys.Top):
+		c.viewport.GotoTop()
+	case key.Matches(msg, scrollKeys.Bottom, scrollKeys.Latest):
+		c.viewport.GotoBottom()
+	}
+	c.markRead()
+}
+
+// scrollbar renders the scroll position as a column of c.height rows, blank
+// when all lines fit.
+func (c Conversation) scrollbar() string {
+	rows := make([]string, c.height)
+	total := c.viewport.TotalLineCount()
+	if total <= c.height || c.height <= 0 {
+		for i := range rows {
+			rows[i] = ScrollbarStyle.Render(" ")
+		}
+		return strings.Join(rows, "\n")
+	}
+
+	track, thumb := "│", "┃"
+	if styles.Plain() {
+		track, thumb = "|", "#"
+	}
+	size := max(c.height*c.height/total, 1)
+	start := int(c.viewport.ScrollPercent()*float64(c.height-size) + 0.5)
+	for i := range rows {
+		if i >= start && i < start+size {
+			rows[i] = ScrollbarThumbStyle.Render(thumb)
+		} else {
+			rows[i] = ScrollbarStyle.Render(track)
+		}
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// maxOlderMatches is the number of compacted messages a search lists.
+const maxOlderMatches = 20
+
+// SearchBar is the "/" prompt of search mode, shown in place of the input.
+// While typing, matches are highlighted as the query changes; after Enter
+// it browses them with n and N.
+type SearchBar struct {
+	textInput textinput.Model
+	active    bool
+	browsing  bool // The query was submitted
+	width     int
+}
+
+// NewSearchBar creates a search bar.
+func NewSearchBar(width int) SearchBar {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.CharLimit = 200
+	ti.Width = width - 6
+	ti.PromptStyle = InputPromptStyle
+	ti.TextStyle = InputTextStyle
+	if styles.ReducedMotion() {
+		ti.Cursor.SetMode(cursor.CursorStatic)
+	}
+
+	return SearchBar{textInput: ti, width: width}
+}
+
+// SetWidth updates the search bar width.
+func (s *SearchBar) SetWidth(width int) {
+	s.width = width
+	s.textInput.Width = width - 6
+}
+
+// Open starts typing a query, keeping the previous one for editing.
+func (s *SearchBar) Open() tea.Cmd {
+	s.active = true
+	s.browsing = false
+	s.textInput.CursorEnd()
+	return s.textInput.Focus()
+}
+
+// Submit switches from typing to browsing matches.
+func (s *SearchBar) Submit() {
+	s.browsing = true
+	s.textInput.Blur()
+}
+
+// Close leaves search mode.
+func (s *SearchBar) Close() {
+	s.active = false
+	s.browsing = false
+	s.textInput.Blur()
+}
+
+// Value returns the query.
+func (s SearchBar) Value() string {
+	return s.textInput.Value()
+}
+
+// Update handles query editing.
+func (s SearchBar) Update(msg tea.Msg) (SearchBar, tea.Cmd) {
+	var cmd tea.Cmd
+	s.textInput, cmd = s.textInput.Update(msg)
+	return s, cmd
+}
+
+// View renders the prompt, with the match position when browsing.
+func (s SearchBar) View(current, total int) string {
+	content := s.textInput.View()
+	if s.browsing {
+		var status string
+		switch {
+		case total == 0:
+			status = "no matches"
+		default:
+			status = fmt.Sprintf("match %d/%d", current+1, total)
+		}
+		content = InputPromptStyle.Render("/") + InputTextStyle.Render(s.Value()) +
+			DimmedStyle.Render("  "+status+" · n/N next/prev · / edit · esc close")
+	}
+
+	bgStyle := lipgloss.NewStyle().
+		Background(styles.ColorBg).
+		Width(s.width - 2)
+	return InputBorderStyle.Width(s.width).Render(bgStyle.Render(content))
+}
+
+// Search key bindings, active in search mode
+var searchKeys = struct {
+	Open key.Binding
+	Next key.Binding
+	Prev key.Binding
+	Edit key.Binding
+}{
+	Open: key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search the conversation")),
+	Next: key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next match")),
+	Prev: key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "previous match")),
+	Edit: key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "edit the query")),
+}
+
+// formatOlderMatches lists matches from compacted history, which the
+// conversation no longer shows, as one line each.
+func formatOlderMatches(query string, messages []provider.Message) string {
+	lines := []string{fmt.Sprintf("Matches for %q in compacted history:", query)}
+	for _, msg := range messages {
+		lines = append(lines, fmt.Sprintf("[%s] %s: %s",
+			msg.CreatedAt.Local().Format("2006-01-02 15:04"), msg.Role, matchSnippet(msg.Content, query)))
+	}
+	if len(messages) == maxOlderMatches {
+		lines = append(lines, fmt.Sprintf("(first %d shown)", maxOlderMatches))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchSnippet returns the single-line text around the first match of
+// query in content.
+func matchSnippet(content, query string) string {
+	const context = 40
+	content = strings.Join(strings.Fields(content), " ")
+	i := strings.Index(strings.ToLower(content), strings.ToLower(query))
+	if i < 0 || len(strings.ToLower(content)) != len(content) {
+		return truncate(content, 2*context+len(query))
+	}
+	start := max(i-context, 0)
+	end := min(i+len(query)+context, len(content))
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
+	}
+	snippet := content[start:end]
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(content) {
+		snippet += "..."
+	}
+	return snippet
+}
@@ -0,0 +1,343 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/mysis/internal/config"
+	"github.com/xonecas/mysis/internal/features"
+	"github.com/xonecas/mysis/internal/mcp"
+	"github.com/xonecas/mysis/internal/provider"
+	"github.com/xonecas/mysis/internal/session"
+	"github.com/xonecas/mysis/internal/styles"
+)
+
+// splitMinWidth is the width under which a pane of the split view asks for
+// a larger terminal.
+const splitMinWidth = 40
+
+// Split view key bindings
+var splitKeys = struct {
+	Focus key.Binding
+}{
+	Focus: key.NewBinding(key.WithKeys("alt+w", "f6"), key.WithHelp("alt+w", "move the input to the other session of the split view")),
+}
+
+// teaPackage is the import path of Bubble Tea, whose own messages are not
+// addressed to a pane, see toPane.
+var teaPackage = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// paneMsg carries a message to a pane of the split view.
+type paneMsg struct {
+	pane int
+	msg  tea.Msg
+}
+
+// paneSender delivers the messages of a Runner to its pane of the split
+// view.
+type paneSender struct {
+	program *tea.Program
+	pane    int
+}
+
+// Send addresses msg to the pane and sends it to the program.
+func (s paneSender) Send(msg tea.Msg) {
+	s.program.Send(toPane(s.pane, msg))
+}
+
+// toPane addresses msg to pane. Bubble Tea's own messages, such as quit or
+// the mouse mode, are left for the program to handle; the commands of a
+// batch are addressed one by one.
+func toPane(pane int, msg tea.Msg) tea.Msg {
+	switch msg := msg.(type) {
+	case nil:
+		return nil
+	case tea.BatchMsg:
+		cmds := make(tea.BatchMsg, len(msg))
+		for i, cmd := range msg {
+			cmds[i] = paneCmd(pane, cmd)
+		}
+		return cmds
+	}
+	if reflect.TypeOf(msg).PkgPath() == teaPackage {
+		return msg
+	}
+	return paneMsg{pane: pane, msg: msg}
+}
+
+// paneCmd addresses the message of cmd to pane.
+func paneCmd(pane int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return toPane(pane, cmd())
+	}
+}
+
+// SplitModel shows two sessions side by side, each a Model driven by its
+// own Runner. Keys go to the focused pane; mouse events to the pane under
+// the pointer.
+type SplitModel struct {
+	panes  [2]Model
+	focus  int
+	width  int
+	height int
+}
+
+// newSplitModel creates the split view with the input in the left pane.
+func newSplitModel(left, right Model) SplitModel {
+	left.split = true
+	right.split = true
+	right.input.Blur()
+	right.showTitle = false // The left session names the terminal
+	return SplitModel{panes: [2]Model{left, right}}
+}
+
+// Init initializes both panes.
+func (s SplitModel) Init() tea.Cmd {
+	return tea.Batch(paneCmd(0, s.panes[0].Init()), paneCmd(1, s.panes[1].Init()))
+}
+
+// paneWidth returns the width of pane, leaving a column between the panes.
+func (s SplitModel) paneWidth(pane int) int {
+	left := (s.width - 1) / 2
+	if pane == 0 {
+		return left
+	}
+	return s.width - 1 - left
+}
+
+// Update routes messages to the panes.
+func (s SplitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		var cmds []tea.Cmd
+		for i := range s.panes {
+			var cmd tea.Cmd
+			s, cmd = s.update(i, tea.WindowSizeMsg{Width: s.paneWidth(i), Height: msg.Height})
+			cmds = append(cmds, cmd)
+		}
+		return s, tea.Batch(cmds...)
+
+	case tea.FocusMsg, tea.BlurMsg:
+		var cmds []tea.Cmd
+		for i := range s.panes {
+			var cmd tea.Cmd
+			s, cmd = s.update(i, msg)
+			cmds = append(cmds, cmd)
+		}
+		return s, tea.Batch(cmds...)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, splitKeys.Focus) {
+			return s, s.moveFocus()
+		}
+		return s.update(s.focus, msg)
+
+	case tea.MouseMsg:
+		pane := 0
+		if msg.X == s.paneWidth(0) {
+			return s, nil // The separator
+		}
+		if msg.X > s.paneWidth(0) {
+			pane = 1
+			msg.X -= s.paneWidth(0) + 1
+		}
+		var focus, cmd tea.Cmd
+		if pane != s.focus && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			focus = s.moveFocus()
+		}
+		s, cmd = s.update(pane, msg)
+		return s, tea.Batch(focus, cmd)
+
+	case paneMsg:
+		return s.update(msg.pane, msg.msg)
+	}
+	return s, nil
+}
+
+// update passes msg to pane.
+func (s SplitModel) update(pane int, msg tea.Msg) (SplitModel, tea.Cmd) {
+	updated, cmd := s.panes[pane].Update(msg)
+	s.panes[pane] = updated.(Model)
+	return s, paneCmd(pane, cmd)
+}
+
+// moveFocus moves the input focus to the other pane. A pane browsing
+// messages or tool results keeps its input blurred.
+func (s *SplitModel) moveFocus() tea.Cmd {
+	s.panes[s.focus].input.Blur()
+	s.focus = 1 - s.focus
+	m := &s.panes[s.focus]
+	if m.browsingTools || m.browsingMessages || m.search.active || m.newSession.Active() {
+		return nil
+	}
+	return paneCmd(s.focus, m.input.Focus())
+}
+
+// View renders the panes with a separator between them, its top marked
+// with an arrow towards the focused pane.
+func (s SplitModel) View() string {
+	if s.height == 0 {
+		return "Initializing..."
+	}
+	marker := "<"
+	if s.focus == 1 {
+		marker = ">"
+	}
+	line := "│"
+	if styles.Plain() {
+		line = "|"
+	}
+	separator := make([]string, s.height)
+	separator[0] = SplitFocusStyle.Render(marker)
+	for i := 1; i < s.height; i++ {
+		separator[i] = SplitSeparatorStyle.Render(line)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, s.panes[0].View(), strings.Join(separator, "\n"), s.panes[1].View())
+}
+
+// StartSplit starts the TUI with two sessions side by side: the session of
+// the command line on the left and the session named split on the right,
+// resumed or created, with its own Runner, provider and MCP connection.
+// Autoplay flags apply to the left session.
+func StartSplit(
+	ctx context.Context,
+	cfg *config.Config,
+	sessionMgr *session.Manager,
+	sessionID string,
+	prov provider.Provider,
+	model string,
+	registry *provider.Registry,
+	proxy *mcp.Proxy,
+	tools []mcp.Tool,
+	history []provider.Message,
+	autoplayMsg string,
+	playbook *features.Playbook,
+	split string,
+) error {
+	if name, err := sessionMgr.Name(sessionID); err == nil && name == split {
+		return fmt.Errorf("--split needs another session than %q", split)
+	}
+	left, leftModel, err := newRunner(ctx, cfg, sessionMgr, sessionID, prov, model, registry, proxy, tools, history)
+	if err != nil {
+		return fmt.Errorf("failed to create runner: %w", err)
+	}
+	right, rightModel, closeRight, err := openSplitSession(ctx, cfg, sessionMgr, registry, split)
+	if err != nil {
+		return fmt.Errorf("failed to open session %q: %w", split, err)
+	}
+	defer closeRight()
+
+	app := tea.NewProgram(
+		newSplitModel(leftModel, rightModel),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithReportFocus(),
+	)
+	left.program = paneSender{program: app, pane: 0}
+	right.program = paneSender{program: app, pane: 1}
+
+	// Start autoplay if requested; callbacks queue messages until the program runs
+	if err := left.autoplayService.StartFromFlags(ctx, autoplayMsg, playbook); err != nil {
+		return err
+	}
+	defer left.startPolling()()
+	defer right.startPolling()()
+
+	final, err := app.Run()
+	if m, ok := final.(SplitModel); ok {
+		left.saveInputHistory(m.panes[0].InputHistory())
+		right.saveInputHistory(m.panes[1].InputHistory())
+	}
+	return err
+}
+
+// openSplitSession resumes or creates the named session for the right pane
+// of the split view, as the command line does for the left: its provider
+// and model, a proxy of its own with the session's credential tools, and
+// its history with the system prompt set with /system. The returned
+// function closes the provider and proxy.
+func openSplitSession(ctx context.Context, cfg *config.Config, sessionMgr *session.Manager, registry *provider.Registry, name string) (*Runner, Model, func(), error) {
+	selected, err := sessionMgr.SelectProvider(cfg, name, "")
+	if err != nil {
+		return nil, Model{}, nil, err
+	}
+	providerCfg, ok := cfg.Providers[selected.Provider]
+	if !ok {
+		return nil, Model{}, nil, fmt.Errorf("provider '%s' not found in config", selected.Provider)
+	}
+	prov, err := registry.Create(selected.Provider, selected.Model, features.ProviderOptions(providerCfg))
+	if err != nil {
+		return nil, Model{}, nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	proxy := mcp.NewProxy(mcp.NewUpstream(cfg.MCP.Upstream))
+	if err := proxy.Initialize(ctx); err != nil {
+		log.Warn().Err(err).Str("session", name).Msg("Failed to initialize MCP - continuing without game tools")
+	}
+	closeAll := func() {
+		if err := proxy.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close MCP proxy")
+		}
+		if err := prov.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close provider")
+		}
+	}
+
+	result, err := sessionMgr.Initialize(name, selected.Provider, selected.Model)
+	if err != nil {
+		closeAll()
+		return nil, Model{}, nil, err
+	}
+	proxy.RegisterTool(mcp.NewSaveCredentialsTool(), mcp.MakeSaveCredentialsHandler(sessionMgr, result.SessionID))
+	proxy.RegisterTool(mcp.NewGetCredentialsTool(), mcp.MakeGetCredentialsHandler(sessionMgr, result.SessionID))
+	proxy.RegisterTool(mcp.NewSendToAgentTool(), mcp.MakeSendToAgentHandler(sessionMgr, result.SessionID))
+	proxy.RegisterTool(mcp.NewReadAgentInboxTool(), mcp.MakeReadAgentInboxHandler(sessionMgr, result.SessionID))
+	features.RegisterPluginTools(proxy, features.Plugins())
+	tools, err := proxy.ListTools(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("session", name).Msg("Failed to list tools - continuing without tools")
+		tools = []mcp.Tool{}
+	}
+
+	history, err := sessionMgr.LoadHistory(result.SessionID)
+	if err == nil {
+		var prompt string
+		prompt, err = sessionMgr.SystemPrompt(result.SessionID)
+		if err == nil && prompt == "" && len(history) == 0 {
+			if prompt, err = features.DefaultSystemPrompt(cfg, selected.Provider); prompt != "" {
+				if err := sessionMgr.SetSystemPrompt(result.SessionID, prompt); err != nil {
+					log.Warn().Err(err).Msg("Failed to record system prompt")
+				}
+			}
+		}
+		if prompt != "" {
+			history = features.SetSystemPrompt(history, prompt)
+		}
+	}
+	if err != nil {
+		closeAll()
+		return nil, Model{}, nil, err
+	}
+
+	r, m, err := newRunner(ctx, cfg, sessionMgr, result.SessionID, prov, selected.Model, registry, proxy, tools, history)
+	if err != nil {
+		closeAll()
+		return nil, Model{}, nil, err
+	}
+	// The provider may have been switched in the session
+	return r, m, func() {
+		prov = r.currentProvider()
+		closeAll()
+	}, nil
+}
@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/xonecas/mysis/internal/features"
+)
+
+const (
+	mapGridRows   = 9 // Rows of the map grid
+	maxMapLegend  = 8 // Points of interest listed under the grid
+	pointMarkers  = "123456789abcdefghijklmnopqrstuvwxyz"
+	shipMarker    = '@'
+	systemMarker  = '*' // Systems of the galaxy view
+	emptyMapCell  = ' '
+	mapCellAspect = 2.0 // Terminal cells are about twice as high as wide
+)
+
+// MapPanel draws the points of interest of the current system, or the
+// galaxy when only get_map was seen, with the ship's location.
+type MapPanel struct {
+	starMap features.StarMap
+}
+
+// SetMap replaces the map.
+func (p *MapPanel) SetMap(m features.StarMap) {
+	p.starMap = m
+}
+
+// Empty reports whether no points were seen yet.
+func (p MapPanel) Empty() bool {
+	return len(p.starMap.POIs) == 0 && len(p.starMap.Systems) == 0
+}
+
+// Height returns the rows the panel needs at width, including a blank row
+// separating it from the panels below.
+func (p MapPanel) Height(width int) int {
+	return len(p.lines(width)) + 1
+}
+
+// View renders the panel at width and height, cutting rows that do not fit.
+func (p MapPanel) View(width, height int) string {
+	lines := p.lines(width)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return PanelStyle.
+		Width(width - 1). // The left border adds a column
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// systemView reports whether the panel shows the current system rather
+// than the galaxy.
+func (p MapPanel) systemView() bool {
+	m := p.starMap
+	if len(m.POIs) == 0 {
+		return false
+	}
+	return len(m.Systems) == 0 || m.POISystem == "" || m.System == "" || m.POISystem == m.System
+}
+
+// lines renders the panel content at width.
+func (p MapPanel) lines(width int) []string {
+	inner := width - 3 // Border and padding
+	m := p.starMap
+
+	points, current, title := m.Systems, m.System, "Galaxy"
+	if p.systemView() {
+		points, current, title = m.POIs, m.POI, "System"
+		if m.POISystem != "" {
+			title = m.POISystem
+		}
+	}
+	lines := []string{BrandTitleStyle.Render(truncate("Map · "+title, inner))}
+
+	grid := plotMap(points, current, !p.systemView(), inner, mapGridRows)
+	for _, row := range grid {
+		var b strings.Builder
+		for _, cell := range row {
+			switch cell {
+			case shipMarker:
+				b.WriteString(BrandTitleStyle.Render(string(cell)))
+			case emptyMapCell:
+				b.WriteRune(cell)
+			default:
+				b.WriteString(AssistantStyle.Render(string(cell)))
+			}
+		}
+		lines = append(lines, b.String())
+	}
+
+	if !p.systemView() {
+		lines = append(lines, DimmedStyle.Render(truncate(fmt.Sprintf("%d systems, @ %s", len(points), current), inner)))
+		if links := mapLinks(m, current); len(links) > 0 {
+			lines = append(lines, DimmedStyle.Render(truncate("links: "+strings.Join(links, ", "), inner)))
+		}
+		return lines
+	}
+
+	for i, point := range points {
+		if i == maxMapLegend {
+			lines = append(lines, DimmedStyle.Render(fmt.Sprintf("  +%d more", len(points)-i)))
+			break
+		}
+		label := point.Label()
+		if point.Type != "" {
+			label += " (" + point.Type + ")"
+		}
+		if point.Matches(current) {
+			lines = append(lines, BrandTitleStyle.Render(truncate(string(shipMarker)+" "+label, inner)))
+			continue
+		}
+		lines = append(lines, AssistantStyle.Render(truncate(string(pointMarker(i))+" "+label, inner)))
+	}
+	return lines
+}
+
+// pointMarker returns the grid marker of the point at index i.
+func pointMarker(i int) rune {
+	if i < len(pointMarkers) {
+		return rune(pointMarkers[i])
+	}
+	return '+'
+}
+
+// plotMap places points on a width by height grid, scaled to their
+// positions, or on an ellipse when none reported one. The current point is
+// drawn with shipMarker; galaxy maps draw the other systems with
+// systemMarker.
+func plotMap(points []features.MapPoint, current string, galaxy bool, width, height int) [][]rune {
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(string(emptyMapCell), width))
+	}
+	if len(points) == 0 || width < 1 || height < 1 {
+		return grid
+	}
+
+	positioned := false
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, point := range points {
+		if point.HasPos {
+			positioned = true
+			minX, maxX = min(minX, point.X), max(maxX, point.X)
+			minY, maxY = min(minY, point.Y), max(maxY, point.Y)
+		}
+	}
+
+	place := func(i int, point features.MapPoint) (int, int) {
+		if !positioned {
+			angle := 2 * math.Pi * float64(i) / float64(len(points))
+			col := int(math.Round((1 + math.Cos(angle)) / 2 * float64(width-1)))
+			row := int(math.Round((1 + math.Sin(angle)) / 2 * float64(height-1)))
+			return col, row
+		}
+		if !point.HasPos {
+			return -1, -1
+		}
+		col := scale(point.X, minX, maxX, width)
+		row := scale(point.Y, minY, maxY, height)
+		// Keep the proportions of square maps when the grid allows
+		if maxX-minX > 0 && maxY-minY > 0 && float64(width) > float64(height)*mapCellAspect {
+			used := int(float64(height) * mapCellAspect * (maxX - minX) / (maxY - minY))
+			if used < width {
+				col = (width-used)/2 + scale(point.X, minX, maxX, max(used, 1))
+			}
+		}
+		return col, row
+	}
+
+	var ship [2]int
+	shipFound := false
+	for i, point := range points {
+		col, row := place(i, point)
+		if col < 0 {
+			continue
+		}
+		if point.Matches(current) {
+			ship, shipFound = [2]int{col, row}, true
+			continue
+		}
+		if grid[row][col] != emptyMapCell {
+			continue // Overlapping points keep the first
+		}
+		if galaxy {
+			grid[row][col] = systemMarker
+		} else {
+			grid[row][col] = pointMarker(i)
+		}
+	}
+	if shipFound {
+		grid[ship[1]][ship[0]] = shipMarker
+	}
+	return grid
+}
+
+// scale maps v in [lo, hi] to a cell in [0, cells).
+func scale(v, lo, hi float64, cells int) int {
+	if hi <= lo {
+		return cells / 2
+	}
+	return min(int(math.Round((v-lo)/(hi-lo)*float64(cells-1))), cells-1)
+}
+
+// mapLinks returns the labels of the systems linked to current.
+func mapLinks(m features.StarMap, current string) []string {
+	label := make(map[string]string, len(m.Systems))
+	var self features.MapPoint
+	for _, s := range m.Systems {
+		if s.ID != "" {
+			label[s.ID] = s.Label()
+		}
+		if s.Matches(current) {
+			self = s
+		}
+	}
+	var links []string
+	for _, link := range m.Links {
+		var other string
+		switch {
+		case self.Matches(link[0]):
+			other = link[1]
+		case self.Matches(link[1]):
+			other = link[0]
+		default:
+			continue
+		}
+		if l, ok := label[other]; ok {
+			other = l
+		}
+		links = append(links, other)
+	}
+	return links
+}
@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/xonecas/mysis/internal/llm"
 	"github.com/xonecas/mysis/internal/styles"
 )
 
@@ -23,9 +25,33 @@ type StatusBar struct {
 	currentFrame int // Current animation frame (0-11 for 12 frames @ 8 FPS)
 
 	// Status text
-	errorText    string
-	warningText  string
-	autoplayText string
+	errorText      string
+	warningText    string
+	autoplayText   string
+	autoplayPaused bool
+	autoplayWait   time.Time // Next schedule window when outside the autoplay schedule
+	breakerUntil   time.Time // End of the circuit breaker cooldown while it is open
+	autoplayTurns  int
+	autoplayErrors int       // Consecutive failed turns
+	autoplayNext   time.Time // When the next autoplay turn is due
+	autoplayQueued int       // User messages waiting for the next turn
+
+	// Usage indicators, shown once the first response arrived
+	usage         llm.Usage
+	contextTokens int // Estimated tokens of the last request
+	contextWindow int // Model context window, 0 when unknown
+	usageKnown    bool
+
+	// Provider and model of the next turn, see SetProvider
+	providerName string
+	modelName    string
+
+	// LLM wait spinner, shown while a provider call is in flight
+	llmWaitSince time.Time // Start of the call; zero when none is in flight
+	llmWaitFrame int
+	llmWaitTicks bool // An LLMWaitTickMsg tick is scheduled
+
+	announcement string // Last message, shown for screen readers, see Announce
 }
 
 const (
@@ -35,8 +61,21 @@ const (
 	fastCycles           = 2                                                  // Number of fast cycles before deceleration
 	decelerationFrames   = 12                                                 // Number of frames for deceleration phase
 	totalAnimationFrames = (fastCycles * framesPerCycle) + decelerationFrames // 24 + 12 = 36
+
+	contextWarnFraction = 0.8 // Context fill shown in the warning color from here
+
+	llmWaitInterval = 100 * time.Millisecond // Spinner frame duration
+)
+
+// Spinner frames of the LLM wait, ASCII in plain mode
+var (
+	llmWaitFrames      = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	llmWaitPlainFrames = []string{"|", "/", "-", "\\"}
 )
 
+// LLMWaitTickMsg advances the LLM wait spinner.
+type LLMWaitTickMsg struct{}
+
 // StatusBarTickMsg is sent every animation frame.
 type StatusBarTickMsg struct{}
 
@@ -65,8 +104,8 @@ func (s StatusBar) tick() tea.Cmd {
 	maxFrames := max(s.autoplayFrames, s.infoFrames, s.warningFrames,
 		s.errorFrames, s.llmFrames, s.mcpFrames)
 
-	// No animation needed if all icons are idle
-	if maxFrames == 0 {
+	// No animation needed if all icons are idle, nor in reduced motion
+	if maxFrames == 0 || styles.ReducedMotion() {
 		return nil // Stop ticking when idle (icons at baseline/thinnest frame)
 	}
 
@@ -88,6 +127,15 @@ func (s StatusBar) tick() tea.Cmd {
 
 // Update handles status bar updates.
 func (s StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
+	if _, ok := msg.(LLMWaitTickMsg); ok {
+		if s.llmWaitSince.IsZero() {
+			s.llmWaitTicks = false
+			return s, nil
+		}
+		s.llmWaitFrame++
+		return s, llmWaitTick()
+	}
+
 	if _, ok := msg.(StatusBarTickMsg); ok {
 		// Advance animation frame
 		s.currentFrame = (s.currentFrame + 1) % framesPerCycle
@@ -226,6 +274,137 @@ func (s *StatusBar) SetAutoplayText(text string) tea.Cmd {
 // ClearAutoplayText clears the autoplay text.
 func (s *StatusBar) ClearAutoplayText() {
 	s.autoplayText = ""
+	s.autoplayPaused = false
+	s.autoplayWait = time.Time{}
+	s.breakerUntil = time.Time{}
+	s.autoplayTurns = 0
+	s.autoplayErrors = 0
+	s.autoplayNext = time.Time{}
+	s.autoplayQueued = 0
+}
+
+// SetAutoplayQueued sets the number of user messages queued for the next turn.
+func (s *StatusBar) SetAutoplayQueued(n int) {
+	s.autoplayQueued = n
+}
+
+// SetAutoplayProgress updates the autoplay turn counters and next-turn time.
+func (s *StatusBar) SetAutoplayProgress(turns, consecutiveErrors int, next time.Time) {
+	s.autoplayTurns = turns
+	s.autoplayErrors = consecutiveErrors
+	s.autoplayNext = next
+}
+
+// autoplayCountdownTick schedules the next countdown redraw.
+func autoplayCountdownTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return AutoplayCountdownMsg{}
+	})
+}
+
+// SetAutoplayWait marks autoplay as waiting for the schedule window at next.
+// A zero time clears the wait.
+func (s *StatusBar) SetAutoplayWait(next time.Time) {
+	s.autoplayWait = next
+}
+
+// SetAutoplayBreaker marks the autoplay circuit breaker as open until the
+// given time. A zero time marks it closed.
+func (s *StatusBar) SetAutoplayBreaker(until time.Time) {
+	s.breakerUntil = until
+}
+
+// SetAutoplayPaused marks the autoplay text as paused or running.
+func (s *StatusBar) SetAutoplayPaused(paused bool) {
+	s.autoplayPaused = paused
+}
+
+// StartLLMWait shows the spinner and elapsed time of a provider call,
+// keeping the start of a wait already shown. Returns the command starting
+// the spinner ticks if needed.
+func (s *StatusBar) StartLLMWait() tea.Cmd {
+	if s.llmWaitSince.IsZero() {
+		s.llmWaitSince = time.Now()
+	}
+	if s.llmWaitTicks || styles.ReducedMotion() {
+		return nil
+	}
+	s.llmWaitTicks = true
+	return llmWaitTick()
+}
+
+// StopLLMWait hides the spinner once the response arrived.
+func (s *StatusBar) StopLLMWait() {
+	s.llmWaitSince = time.Time{}
+}
+
+// llmWaitTick schedules the next spinner frame.
+func llmWaitTick() tea.Cmd {
+	return tea.Tick(llmWaitInterval, func(time.Time) tea.Msg {
+		return LLMWaitTickMsg{}
+	})
+}
+
+// renderLLMWait returns the spinner and elapsed seconds, e.g. " ⠹ 12s ",
+// or "" when no provider call is in flight.
+func (s StatusBar) renderLLMWait() string {
+	if s.llmWaitSince.IsZero() {
+		return ""
+	}
+	if styles.ScreenReader() {
+		return " waiting for the model "
+	}
+	if styles.ReducedMotion() {
+		return " waiting since " + s.llmWaitSince.Format("15:04:05") + " "
+	}
+	frames := llmWaitFrames
+	if styles.Plain() {
+		frames = llmWaitPlainFrames
+	}
+	elapsed := int(time.Since(s.llmWaitSince).Seconds())
+	return fmt.Sprintf(" %s %ds ", frames[s.llmWaitFrame%len(frames)], elapsed)
+}
+
+// SetUsage sets the run's token usage and cost and the context fill of the
+// last request. A zero window hides the fill percentage.
+func (s *StatusBar) SetUsage(usage llm.Usage, contextTokens, window int) {
+	s.usage = usage
+	s.contextTokens = contextTokens
+	s.contextWindow = window
+	s.usageKnown = true
+}
+
+// SetProvider sets the provider and model shown.
+func (s *StatusBar) SetProvider(name, model string) {
+	s.providerName = name
+	s.modelName = model
+}
+
+// Provider returns the provider and model shown.
+func (s StatusBar) Provider() (string, string) {
+	return s.providerName, s.modelName
+}
+
+// renderProvider returns the provider indicator, e.g. " ollama · llama3 ",
+// or "" when no provider was set.
+func (s StatusBar) renderProvider() string {
+	if s.providerName == "" {
+		return ""
+	}
+	text := s.providerName
+	if s.modelName != "" {
+		text += " · " + truncate(s.modelName, 32)
+	}
+	return " " + text + " "
+}
+
+// ProviderHit reports whether column x of the status bar row falls on the
+// provider indicator. It is placed first, left of the connection icons.
+func (s StatusBar) ProviderHit(x int) bool {
+	text := s.renderProvider()
+	end := s.width - 7 // Right icons
+	start := end - lipgloss.Width(text)
+	return text != "" && s.width-14-7-lipgloss.Width(text) >= 20 && x >= start && x < end
 }
 
 // View renders the status bar.
@@ -263,6 +442,28 @@ func (s StatusBar) View() string {
 		availableWidth = 0
 	}
 
+	// The provider and model sit at the right while the status text keeps
+	// 20 chars; clicking them opens the model picker, see ProviderHit
+	providerPart := ""
+	if providerText := s.renderProvider(); providerText != "" && availableWidth-lipgloss.Width(providerText) >= 20 {
+		availableWidth -= lipgloss.Width(providerText)
+		providerPart = StatusProviderStyle.Render(providerText)
+	}
+
+	// Usage indicators sit right of the status text while it keeps 20 chars
+	usagePart := ""
+	if usageText, usageStyle := s.renderUsage(); usageText != "" && availableWidth-lipgloss.Width(usageText) >= 20 {
+		availableWidth -= lipgloss.Width(usageText)
+		usagePart = usageStyle.Background(styles.ColorBg).Render(usageText)
+	}
+
+	// The LLM wait spinner sits right of the status text too
+	waitPart := ""
+	if waitText := s.renderLLMWait(); waitText != "" && availableWidth-lipgloss.Width(waitText) >= 20 {
+		availableWidth -= lipgloss.Width(waitText)
+		waitPart = IconLLMStyle.UnsetWidth().Render(waitText)
+	}
+
 	// Truncate text if too long (before styling)
 	// Need at least 3 chars for "..." truncation
 	if availableWidth < 3 {
@@ -277,7 +478,7 @@ func (s StatusBar) View() string {
 		Width(availableWidth)
 	textPart := textStyle.Render(statusTextPlain)
 
-	bar := leftIconsPart + textPart + rightIconsPart
+	bar := leftIconsPart + textPart + waitPart + usagePart + providerPart + rightIconsPart
 
 	// Apply status bar style (border) without width constraint
 	// We've already built the content to exact width
@@ -288,7 +489,10 @@ func (s StatusBar) View() string {
 // When idle (frames=0), shows the baseline/thinnest frame (last in sequence).
 // When animating (frames>0), cycles through frames based on currentFrame.
 func (s StatusBar) renderIcon(frames int, icons []string) string {
-	if frames <= 0 {
+	if styles.ScreenReader() {
+		return " " // The status text says it in words, see renderStatusText
+	}
+	if frames <= 0 || styles.ReducedMotion() {
 		// Idle: return the baseline/thinnest frame (last frame in sequence)
 		return icons[len(icons)-1]
 	}
@@ -302,6 +506,9 @@ func (s StatusBar) renderIcon(frames int, icons []string) string {
 // Priority: Error > Warning > Autoplay > Default
 // Returns plain text and the style to apply
 func (s StatusBar) renderStatusText() (string, lipgloss.Style) {
+	if styles.ScreenReader() {
+		return s.renderLabeledText()
+	}
 	if s.errorText != "" {
 		return s.errorText, StatusTextErrorStyle
 	}
@@ -309,11 +516,107 @@ func (s StatusBar) renderStatusText() (string, lipgloss.Style) {
 		return s.warningText, StatusTextStyle
 	}
 	if s.autoplayText != "" {
-		return "⟳ " + s.autoplayText, StatusTextStyle
+		if s.autoplayPaused {
+			return "⏸ " + s.autoplayText + " (paused)", StatusTextStyle
+		}
+		if !s.breakerUntil.IsZero() {
+			return "⚡ " + s.autoplayText + " (breaker open, retry " + s.breakerUntil.Format("15:04") + ")", StatusTextErrorStyle
+		}
+		if !s.autoplayWait.IsZero() {
+			return "⏾ " + s.autoplayText + " (scheduled " + s.autoplayWait.Format("Mon 15:04") + ")", StatusTextStyle
+		}
+		return "⟳ " + s.autoplayText + s.autoplayCounters(), StatusTextStyle
 	}
 	return "All systems operational", StatusTextOKStyle
 }
 
+// renderLabeledText returns the status text for screen readers: labeled
+// in words instead of icons, with the last announced message in place of
+// the default text.
+func (s StatusBar) renderLabeledText() (string, lipgloss.Style) {
+	switch {
+	case s.errorText != "":
+		return "Error: " + s.errorText, StatusTextErrorStyle
+	case s.warningText != "":
+		return "Warning: " + s.warningText, StatusTextStyle
+	case s.announcement != "":
+		return s.announcement, StatusTextStyle
+	case s.autoplayText == "":
+		return "Ready", StatusTextOKStyle
+	case s.autoplayPaused:
+		return "Autoplay paused: " + s.autoplayText, StatusTextStyle
+	case !s.breakerUntil.IsZero():
+		return "Autoplay halted after errors until " + s.breakerUntil.Format("15:04") + ": " + s.autoplayText, StatusTextErrorStyle
+	case !s.autoplayWait.IsZero():
+		return "Autoplay scheduled for " + s.autoplayWait.Format("Mon 15:04") + ": " + s.autoplayText, StatusTextStyle
+	}
+	return "Autoplay: " + s.autoplayText + s.autoplayCounters(), StatusTextStyle
+}
+
+// Announce shows a new message on the status line for screen readers.
+func (s *StatusBar) Announce(text string) {
+	s.announcement = text
+}
+
+// renderUsage returns the usage indicators and their style, e.g.
+// " ~12.3k tok · $0.0123 · ctx 45% ", in the warning color when the last
+// request filled most of the context window.
+func (s StatusBar) renderUsage() (string, lipgloss.Style) {
+	if !s.usageKnown {
+		return "", StatusTextStyle
+	}
+	text := "~" + formatTokens(s.usage.Tokens()) + " tok"
+	if s.usage.Cost > 0 {
+		text += fmt.Sprintf(" · $%.4f", s.usage.Cost)
+	}
+	style := StatusTextStyle
+	if s.contextWindow > 0 {
+		fill := float64(s.contextTokens) / float64(s.contextWindow)
+		text += fmt.Sprintf(" · ctx %d%%", int(fill*100+0.5))
+		if fill >= contextWarnFraction {
+			style = StatusTextWarningStyle
+		}
+	}
+	return " " + text + " ", style
+}
+
+// formatTokens formats a token count compactly, e.g. 950, 12.3k or 1.2M.
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// autoplayCounters renders the turn count, consecutive errors, queued
+// messages and next-turn countdown, e.g. " · 12 turns · 1 queued · next 0:42".
+func (s StatusBar) autoplayCounters() string {
+	var text string
+	if s.autoplayTurns > 0 {
+		text = fmt.Sprintf(" · %d turns", s.autoplayTurns)
+	}
+	if s.autoplayErrors == 1 {
+		text += " · 1 error"
+	} else if s.autoplayErrors > 1 {
+		text += fmt.Sprintf(" · %d errors", s.autoplayErrors)
+	}
+	if s.autoplayQueued > 0 {
+		text += fmt.Sprintf(" · %d queued", s.autoplayQueued)
+	}
+	if left := time.Until(s.autoplayNext); left > 0 && styles.ReducedMotion() {
+		// A countdown would be redrawn, and read out, every second
+		text += " · next at " + s.autoplayNext.Format("15:04:05")
+	} else if left > 0 {
+		secs := int(left.Round(time.Second).Seconds())
+		text += fmt.Sprintf(" · next %d:%02d", secs/60, secs%60)
+	}
+	return text
+}
+
 // Icon animation sequences
 // Each sequence progresses from full/thick to thin/empty, ending at the baseline frame
 var (
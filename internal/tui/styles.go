@@ -6,127 +6,285 @@ import (
 	"github.com/xonecas/mysis/internal/styles"
 )
 
-// TUI-specific styles building on base styles
+// TUI-specific styles building on base styles, built from the palette by
+// buildStyles
 var (
+	LogStyle                  lipgloss.Style
+	UserStyle                 lipgloss.Style
+	AssistantStyle            lipgloss.Style
+	SystemStyle               lipgloss.Style
+	ToolStyle                 lipgloss.Style
+	ToolSelectedStyle         lipgloss.Style
+	ToolSuccessStyle          lipgloss.Style
+	ToolErrorStyle            lipgloss.Style
+	InputBorderStyle          lipgloss.Style
+	InputPromptStyle          lipgloss.Style
+	InputTextStyle            lipgloss.Style
+	InputPlaceholderStyle     lipgloss.Style
+	StatusBarStyle            lipgloss.Style
+	IconAutoplayStyle         lipgloss.Style
+	IconInfoStyle             lipgloss.Style
+	IconWarningStyle          lipgloss.Style
+	IconErrorStyle            lipgloss.Style
+	IconLLMStyle              lipgloss.Style
+	IconMCPStyle              lipgloss.Style
+	StatusTextStyle           lipgloss.Style
+	StatusTextErrorStyle      lipgloss.Style
+	StatusTextOKStyle         lipgloss.Style
+	StatusTextWarningStyle    lipgloss.Style
+	StatusProviderStyle       lipgloss.Style
+	ScrollbarStyle            lipgloss.Style
+	ScrollbarThumbStyle       lipgloss.Style
+	SearchMatchStyle          lipgloss.Style
+	SearchCurrentStyle        lipgloss.Style
+	PanelStyle                lipgloss.Style
+	BrandTitleStyle           lipgloss.Style
+	GaugeStyle                lipgloss.Style
+	GaugeLowStyle             lipgloss.Style
+	DimmedStyle               lipgloss.Style
+	NotificationInfoStyle     lipgloss.Style
+	NotificationWarningStyle  lipgloss.Style
+	NotificationCriticalStyle lipgloss.Style
+	DialogStyle               lipgloss.Style
+	ButtonStyle               lipgloss.Style
+	ButtonActiveStyle         lipgloss.Style
+	UnreadPillStyle           lipgloss.Style
+	CopySelectionStyle        lipgloss.Style
+	SplitSeparatorStyle       lipgloss.Style
+	SplitFocusStyle           lipgloss.Style
+)
+
+func init() {
+	buildStyles()
+}
+
+// buildStyles builds the TUI styles from the palette, see styles.ApplyTheme.
+func buildStyles() {
 	// Log/Conversation styles
 	LogStyle = lipgloss.NewStyle().
-			Background(styles.ColorBg)
+		Background(styles.ColorBg)
 
 	// Role-based message styles
 	UserStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorUser).
-			Background(styles.ColorBg).
-			Bold(true)
+		Foreground(styles.ColorUser).
+		Background(styles.ColorBg).
+		Bold(true)
 
 	AssistantStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorAssistant).
-			Background(styles.ColorBg)
+		Foreground(styles.ColorAssistant).
+		Background(styles.ColorBg)
 
 	SystemStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorSystem).
-			Background(styles.ColorBg).
-			Italic(true)
+		Foreground(styles.ColorSystem).
+		Background(styles.ColorBg).
+		Italic(true)
 
 	ToolStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorTool).
-			Background(styles.ColorBg)
+		Foreground(styles.ColorTool).
+		Background(styles.ColorBg)
+
+	ToolSelectedStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTeal).
+		Background(styles.ColorBgPanel).
+		Bold(true)
 
 	ToolSuccessStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorSuccess).
-				Background(styles.ColorBg)
+		Foreground(styles.ColorSuccess).
+		Background(styles.ColorBg)
 
 	ToolErrorStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorError).
-			Background(styles.ColorBg)
+		Foreground(styles.ColorError).
+		Background(styles.ColorBg)
 
 	// Input styles
 	InputBorderStyle = lipgloss.NewStyle().
-				Border(lipgloss.NormalBorder(), true, false, false, false). // Top border only
-				BorderForeground(styles.ColorBorder).
-				Background(styles.ColorBg).
-				Padding(0, 1)
+		Border(lipgloss.NormalBorder(), true, false, false, false). // Top border only
+		BorderForeground(styles.ColorBorder).
+		Background(styles.ColorBg).
+		Padding(0, 1)
 
 	InputPromptStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorBrand).
-				Background(styles.ColorBg).
-				Bold(true)
+		Foreground(styles.ColorBrand).
+		Background(styles.ColorBg).
+		Bold(true)
 
 	InputTextStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorTeal).
-			Background(styles.ColorBg)
+		Foreground(styles.ColorTeal).
+		Background(styles.ColorBg)
 
 	InputPlaceholderStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorMuted).
-				Background(styles.ColorBg).
-				Italic(true)
+		Foreground(styles.ColorMuted).
+		Background(styles.ColorBg).
+		Italic(true)
 
 	// Status bar styles
 	StatusBarStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), true, false, false, false). // Top border only
-			BorderForeground(styles.ColorBorder).
-			Background(styles.ColorBg)
+		Border(lipgloss.NormalBorder(), true, false, false, false). // Top border only
+		BorderForeground(styles.ColorBorder).
+		Background(styles.ColorBg)
 
 	// Status icon styles (3-char width each: [ <icon> ])
 	IconAutoplayStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorTeal).
-				Background(styles.ColorBg).
-				Width(3).
-				Align(lipgloss.Center)
+		Foreground(styles.ColorTeal).
+		Background(styles.ColorBg).
+		Width(3).
+		Align(lipgloss.Center)
 
 	IconInfoStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorSuccess).
-			Background(styles.ColorBg).
-			Width(3).
-			Align(lipgloss.Center)
+		Foreground(styles.ColorSuccess).
+		Background(styles.ColorBg).
+		Width(3).
+		Align(lipgloss.Center)
 
 	IconWarningStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorTool).
-				Background(styles.ColorBg).
-				Width(3).
-				Align(lipgloss.Center)
+		Foreground(styles.ColorTool).
+		Background(styles.ColorBg).
+		Width(3).
+		Align(lipgloss.Center)
 
 	IconErrorStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorError).
-			Background(styles.ColorBg).
-			Width(3).
-			Align(lipgloss.Center)
+		Foreground(styles.ColorError).
+		Background(styles.ColorBg).
+		Width(3).
+		Align(lipgloss.Center)
 
 	// Connection status icons (network activity)
 	IconLLMStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorTeal). // Cyan/teal for LLM thinking
-			Background(styles.ColorBg).
-			Width(3).
-			Align(lipgloss.Center)
+		Foreground(styles.ColorTeal). // Cyan/teal for LLM thinking
+		Background(styles.ColorBg).
+		Width(3).
+		Align(lipgloss.Center)
 
 	IconMCPStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorBrand). // Purple for MCP server communication
-			Background(styles.ColorBg).
-			Width(3).
-			Align(lipgloss.Center)
+		Foreground(styles.ColorBrand). // Purple for MCP server communication
+		Background(styles.ColorBg).
+		Width(3).
+		Align(lipgloss.Center)
 
 	// Status text styles
 	StatusTextStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorMuted).
-			Background(styles.ColorBg)
+		Foreground(styles.ColorMuted).
+		Background(styles.ColorBg)
 
 	StatusTextErrorStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorError).
-				Background(styles.ColorBg)
+		Foreground(styles.ColorError).
+		Background(styles.ColorBg)
 
 	StatusTextOKStyle = lipgloss.NewStyle().
-				Foreground(styles.ColorSuccess).
-				Background(styles.ColorBg)
+		Foreground(styles.ColorSuccess).
+		Background(styles.ColorBg)
+
+	StatusProviderStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTealDim).
+		Background(styles.ColorBg)
+
+	StatusTextWarningStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorError).
+		Background(styles.ColorBg)
 
 	// Scrollbar style
 	ScrollbarStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorBorder).
-			Background(styles.ColorBg)
+		Foreground(styles.ColorBorder).
+		Background(styles.ColorBg)
+
+	ScrollbarThumbStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTealDim).
+		Background(styles.ColorBg)
+
+	// Split view, see SplitModel
+	SplitSeparatorStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorBorder).
+		Background(styles.ColorBg)
+
+	SplitFocusStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTeal).
+		Background(styles.ColorBg).
+		Bold(true)
+
+	// Search matches, see Conversation.SetSearch
+	SearchMatchStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTealDim).
+		Background(styles.ColorBg).
+		Reverse(true)
+
+	SearchCurrentStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTeal).
+		Background(styles.ColorBg).
+		Reverse(true).
+		Bold(true)
+
+	// Game state panel, see GamePanel
+	PanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true). // Left border only
+		BorderForeground(styles.ColorBorder).
+		Background(styles.ColorBg).
+		Padding(0, 1)
+
+	BrandTitleStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorBrand).
+		Background(styles.ColorBg).
+		Bold(true)
+
+	GaugeStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTeal).
+		Background(styles.ColorBg)
+
+	GaugeLowStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorError).
+		Background(styles.ColorBg)
+
+	// Notification severities
+	NotificationInfoStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorTealDim).
+		Background(styles.ColorBg)
+
+	NotificationWarningStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorBrand).
+		Background(styles.ColorBg)
+
+	NotificationCriticalStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorError).
+		Background(styles.ColorBg).
+		Bold(true)
 
 	// Dimmed text
 	DimmedStyle = lipgloss.NewStyle().
-			Foreground(styles.ColorMuted).
-			Background(styles.ColorBg)
-)
+		Foreground(styles.ColorMuted).
+		Background(styles.ColorBg)
+
+	// Modal dialogs, see ApprovalDialog
+	DialogStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorBrand).
+		Background(styles.ColorBg).
+		Padding(0, 1)
+
+	ButtonStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Background(styles.ColorBgAlt).
+		Padding(0, 1)
+
+	ButtonActiveStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorBg).
+		Background(styles.ColorTeal).
+		Bold(true).
+		Padding(0, 1)
+
+	// UnreadPillStyle counts the messages below the scroll position
+	UnreadPillStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorBg).
+		Background(styles.ColorTeal).
+		Bold(true).
+		Padding(0, 1)
+
+	// Lines selected in copy mode
+	CopySelectionStyle = lipgloss.NewStyle().
+		Foreground(styles.ColorBg).
+		Background(styles.ColorTealDim)
+}
+
+// commandRole is the display-only role used for slash command output.
+const commandRole = "command"
 
 // RoleStyle returns the appropriate style for a message role.
 func RoleStyle(role string) lipgloss.Style {
@@ -139,6 +297,8 @@ func RoleStyle(role string) lipgloss.Style {
 		return SystemStyle
 	case "tool":
 		return ToolStyle
+	case commandRole:
+		return DimmedStyle
 	default:
 		return SystemStyle
 	}
@@ -155,7 +315,35 @@ func RoleLabel(role string) string {
 		return SystemStyle.Render("System")
 	case "tool":
 		return ToolStyle.Render("Tool")
+	case commandRole:
+		return DimmedStyle.Render("Mysis")
 	default:
 		return DimmedStyle.Render("Unknown")
 	}
 }
+
+// applyStyles rebuilds the styles from the palette, see styles.ApplyTheme.
+// In plain mode, see styles.SetPlain, it switches the borders to ASCII and
+// marks the selection without colors; in screen-reader mode, see
+// styles.SetScreenReader, the borders are blank.
+func applyStyles() {
+	buildStyles()
+	if !styles.Plain() {
+		return
+	}
+	InputBorderStyle = InputBorderStyle.BorderStyle(lipgloss.ASCIIBorder())
+	StatusBarStyle = StatusBarStyle.BorderStyle(lipgloss.ASCIIBorder())
+	PanelStyle = PanelStyle.BorderStyle(lipgloss.ASCIIBorder())
+	DialogStyle = DialogStyle.BorderStyle(lipgloss.ASCIIBorder())
+	ButtonActiveStyle = ButtonActiveStyle.Reverse(true)
+	UnreadPillStyle = UnreadPillStyle.Reverse(true)
+	CopySelectionStyle = CopySelectionStyle.Reverse(true)
+	ToolSelectedStyle = ToolSelectedStyle.Reverse(true) // The panel background is off
+	if styles.ScreenReader() {
+		// Blank borders keep the layout without lines to read out
+		InputBorderStyle = InputBorderStyle.BorderStyle(lipgloss.HiddenBorder())
+		StatusBarStyle = StatusBarStyle.BorderStyle(lipgloss.HiddenBorder())
+		PanelStyle = PanelStyle.BorderStyle(lipgloss.HiddenBorder())
+		DialogStyle = DialogStyle.BorderStyle(lipgloss.HiddenBorder())
+	}
+}
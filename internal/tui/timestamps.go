@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TimestampMode is how message timestamps are shown.
+type TimestampMode int
+
+// Timestamp modes, in the order the toggle key cycles through them.
+const (
+	TimestampAbsolute TimestampMode = iota // 15:04:05
+	TimestampRelative                      // 2m ago
+	TimestampHidden
+)
+
+// timestampRefresh is how often relative timestamps are re-rendered.
+const timestampRefresh = 30 * time.Second
+
+// Timestamp key bindings
+var timestampKeys = struct {
+	Cycle key.Binding
+}{
+	Cycle: key.NewBinding(key.WithKeys("alt+t"), key.WithHelp("alt+t", "cycle timestamps: absolute, relative, hidden")),
+}
+
+// TimestampTickMsg re-renders relative timestamps.
+type TimestampTickMsg struct{}
+
+// timestampTick schedules the next relative timestamp refresh.
+func timestampTick() tea.Cmd {
+	return tea.Tick(timestampRefresh, func(time.Time) tea.Msg {
+		return TimestampTickMsg{}
+	})
+}
+
+// formatTimestamp formats t for mode, "" when hidden.
+func formatTimestamp(t time.Time, mode TimestampMode, now time.Time) string {
+	switch mode {
+	case TimestampAbsolute:
+		return t.Format("15:04:05")
+	case TimestampRelative:
+		return formatAgo(now.Sub(t))
+	default:
+		return ""
+	}
+}
+
+// formatAgo formats an elapsed time, e.g. "just now", "2m ago" or "3d ago".
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < 10*time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}